@@ -186,6 +186,42 @@ func (src *MachineHealthCheck) ConvertTo(dstRaw conversion.Hub) error {
 	if restored.Spec.UnhealthyRange != nil {
 		dst.Spec.UnhealthyRange = restored.Spec.UnhealthyRange
 	}
+	dst.Spec.ExpectedMachinesPolicy = restored.Spec.ExpectedMachinesPolicy
+	dst.Spec.MinHealthy = restored.Spec.MinHealthy
+	dst.Spec.RemediationStrategy = restored.Spec.RemediationStrategy
+	dst.Spec.FailureDomainAware = restored.Spec.FailureDomainAware
+	dst.Spec.PauseDuringUpgrade = restored.Spec.PauseDuringUpgrade
+	dst.Spec.DeferRemediationOnBlockingPDB = restored.Spec.DeferRemediationOnBlockingPDB
+	dst.Spec.RemediateOnFailureReason = restored.Spec.RemediateOnFailureReason
+	dst.Spec.MaxTotalRemediations = restored.Spec.MaxTotalRemediations
+	dst.Spec.RemediationOrder = restored.Spec.RemediationOrder
+	dst.Spec.ClusterOutageThreshold = restored.Spec.ClusterOutageThreshold
+	dst.Spec.RequireAPIUnreachable = restored.Spec.RequireAPIUnreachable
+	dst.Spec.APIUnreachableTimeout = restored.Spec.APIUnreachableTimeout
+	dst.Spec.RemediationSchedule = restored.Spec.RemediationSchedule
+	dst.Spec.UnhealthyWeightThreshold = restored.Spec.UnhealthyWeightThreshold
+	dst.Spec.RemediationBudgetScope = restored.Spec.RemediationBudgetScope
+	dst.Spec.ReplacementTimeout = restored.Spec.ReplacementTimeout
+	dst.Spec.StaleNodeStatusTimeout = restored.Spec.StaleNodeStatusTimeout
+	dst.Spec.StaleNodeStatusThreshold = restored.Spec.StaleNodeStatusThreshold
+	dst.Spec.RemediationStagger = restored.Spec.RemediationStagger
+	dst.Spec.NodeGonePolicy = restored.Spec.NodeGonePolicy
+	dst.Status.LastUpdated = restored.Status.LastUpdated
+	dst.Status.HealthSummary = restored.Status.HealthSummary
+	dst.Status.TotalRemediations = restored.Status.TotalRemediations
+	dst.Status.CurrentSuspect = restored.Status.CurrentSuspect
+	dst.Status.LastRemediationTime = restored.Status.LastRemediationTime
+	dst.Status.LastSuccessfulReconcile = restored.Status.LastSuccessfulReconcile
+	for i := range dst.Spec.UnhealthyConditions {
+		if i >= len(restored.Spec.UnhealthyConditions) {
+			break
+		}
+		if restored.Spec.UnhealthyConditions[i].Type == dst.Spec.UnhealthyConditions[i].Type &&
+			restored.Spec.UnhealthyConditions[i].Status == dst.Spec.UnhealthyConditions[i].Status {
+			dst.Spec.UnhealthyConditions[i].Action = restored.Spec.UnhealthyConditions[i].Action
+			dst.Spec.UnhealthyConditions[i].Weight = restored.Spec.UnhealthyConditions[i].Weight
+		}
+	}
 
 	return nil
 }
@@ -230,6 +266,14 @@ func Convert_v1alpha4_MachineHealthCheckSpec_To_v1alpha3_MachineHealthCheckSpec(
 	return autoConvert_v1alpha4_MachineHealthCheckSpec_To_v1alpha3_MachineHealthCheckSpec(in, out, s)
 }
 
+func Convert_v1alpha4_MachineHealthCheckStatus_To_v1alpha3_MachineHealthCheckStatus(in *v1alpha4.MachineHealthCheckStatus, out *MachineHealthCheckStatus, s apiconversion.Scope) error {
+	return autoConvert_v1alpha4_MachineHealthCheckStatus_To_v1alpha3_MachineHealthCheckStatus(in, out, s)
+}
+
+func Convert_v1alpha4_UnhealthyCondition_To_v1alpha3_UnhealthyCondition(in *v1alpha4.UnhealthyCondition, out *UnhealthyCondition, s apiconversion.Scope) error {
+	return autoConvert_v1alpha4_UnhealthyCondition_To_v1alpha3_UnhealthyCondition(in, out, s)
+}
+
 func Convert_v1alpha3_ClusterStatus_To_v1alpha4_ClusterStatus(in *ClusterStatus, out *v1alpha4.ClusterStatus, s apiconversion.Scope) error {
 	return autoConvert_v1alpha3_ClusterStatus_To_v1alpha4_ClusterStatus(in, out, s)
 }
@@ -219,11 +219,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha4.MachineHealthCheckStatus)(nil), (*MachineHealthCheckStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha4_MachineHealthCheckStatus_To_v1alpha3_MachineHealthCheckStatus(a.(*v1alpha4.MachineHealthCheckStatus), b.(*MachineHealthCheckStatus), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*MachineList)(nil), (*v1alpha4.MachineList)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_MachineList_To_v1alpha4_MachineList(a.(*MachineList), b.(*v1alpha4.MachineList), scope)
 	}); err != nil {
@@ -329,11 +324,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha4.UnhealthyCondition)(nil), (*UnhealthyCondition)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha4_UnhealthyCondition_To_v1alpha3_UnhealthyCondition(a.(*v1alpha4.UnhealthyCondition), b.(*UnhealthyCondition), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddConversionFunc((*Bootstrap)(nil), (*v1alpha4.Bootstrap)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_Bootstrap_To_v1alpha4_Bootstrap(a.(*Bootstrap), b.(*v1alpha4.Bootstrap), scope)
 	}); err != nil {
@@ -354,11 +344,21 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1alpha4.MachineHealthCheckStatus)(nil), (*MachineHealthCheckStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha4_MachineHealthCheckStatus_To_v1alpha3_MachineHealthCheckStatus(a.(*v1alpha4.MachineHealthCheckStatus), b.(*MachineHealthCheckStatus), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1alpha4.MachineRollingUpdateDeployment)(nil), (*MachineRollingUpdateDeployment)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha4_MachineRollingUpdateDeployment_To_v1alpha3_MachineRollingUpdateDeployment(a.(*v1alpha4.MachineRollingUpdateDeployment), b.(*MachineRollingUpdateDeployment), scope)
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1alpha4.UnhealthyCondition)(nil), (*UnhealthyCondition)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha4_UnhealthyCondition_To_v1alpha3_UnhealthyCondition(a.(*v1alpha4.UnhealthyCondition), b.(*UnhealthyCondition), scope)
+	}); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -949,7 +949,17 @@ func Convert_v1alpha4_MachineHealthCheckList_To_v1alpha3_MachineHealthCheckList(
 func autoConvert_v1alpha3_MachineHealthCheckSpec_To_v1alpha4_MachineHealthCheckSpec(in *MachineHealthCheckSpec, out *v1alpha4.MachineHealthCheckSpec, s conversion.Scope) error {
 	out.ClusterName = in.ClusterName
 	out.Selector = in.Selector
-	out.UnhealthyConditions = *(*[]v1alpha4.UnhealthyCondition)(unsafe.Pointer(&in.UnhealthyConditions))
+	if in.UnhealthyConditions != nil {
+		in, out := &in.UnhealthyConditions, &out.UnhealthyConditions
+		*out = make([]v1alpha4.UnhealthyCondition, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha3_UnhealthyCondition_To_v1alpha4_UnhealthyCondition(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.UnhealthyConditions = nil
+	}
 	out.MaxUnhealthy = (*intstr.IntOrString)(unsafe.Pointer(in.MaxUnhealthy))
 	out.NodeStartupTimeout = (*metav1.Duration)(unsafe.Pointer(in.NodeStartupTimeout))
 	out.RemediationTemplate = (*v1.ObjectReference)(unsafe.Pointer(in.RemediationTemplate))
@@ -964,11 +974,41 @@ func Convert_v1alpha3_MachineHealthCheckSpec_To_v1alpha4_MachineHealthCheckSpec(
 func autoConvert_v1alpha4_MachineHealthCheckSpec_To_v1alpha3_MachineHealthCheckSpec(in *v1alpha4.MachineHealthCheckSpec, out *MachineHealthCheckSpec, s conversion.Scope) error {
 	out.ClusterName = in.ClusterName
 	out.Selector = in.Selector
-	out.UnhealthyConditions = *(*[]UnhealthyCondition)(unsafe.Pointer(&in.UnhealthyConditions))
+	if in.UnhealthyConditions != nil {
+		in, out := &in.UnhealthyConditions, &out.UnhealthyConditions
+		*out = make([]UnhealthyCondition, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha4_UnhealthyCondition_To_v1alpha3_UnhealthyCondition(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.UnhealthyConditions = nil
+	}
 	out.MaxUnhealthy = (*intstr.IntOrString)(unsafe.Pointer(in.MaxUnhealthy))
+	// WARNING: in.MinHealthy requires manual conversion: does not exist in peer-type
+	// WARNING: in.ClusterOutageThreshold requires manual conversion: does not exist in peer-type
 	// WARNING: in.UnhealthyRange requires manual conversion: does not exist in peer-type
+	// WARNING: in.ExpectedMachinesPolicy requires manual conversion: does not exist in peer-type
 	out.NodeStartupTimeout = (*metav1.Duration)(unsafe.Pointer(in.NodeStartupTimeout))
 	out.RemediationTemplate = (*v1.ObjectReference)(unsafe.Pointer(in.RemediationTemplate))
+	// WARNING: in.RemediationStrategy requires manual conversion: does not exist in peer-type
+	// WARNING: in.FailureDomainAware requires manual conversion: does not exist in peer-type
+	// WARNING: in.PauseDuringUpgrade requires manual conversion: does not exist in peer-type
+	// WARNING: in.DeferRemediationOnBlockingPDB requires manual conversion: does not exist in peer-type
+	// WARNING: in.RemediateOnFailureReason requires manual conversion: does not exist in peer-type
+	// WARNING: in.MaxTotalRemediations requires manual conversion: does not exist in peer-type
+	// WARNING: in.RemediationOrder requires manual conversion: does not exist in peer-type
+	// WARNING: in.RequireAPIUnreachable requires manual conversion: does not exist in peer-type
+	// WARNING: in.APIUnreachableTimeout requires manual conversion: does not exist in peer-type
+	// WARNING: in.RemediationSchedule requires manual conversion: does not exist in peer-type
+	// WARNING: in.UnhealthyWeightThreshold requires manual conversion: does not exist in peer-type
+	// WARNING: in.RemediationBudgetScope requires manual conversion: does not exist in peer-type
+	// WARNING: in.ReplacementTimeout requires manual conversion: does not exist in peer-type
+	// WARNING: in.StaleNodeStatusTimeout requires manual conversion: does not exist in peer-type
+	// WARNING: in.StaleNodeStatusThreshold requires manual conversion: does not exist in peer-type
+	// WARNING: in.RemediationStagger requires manual conversion: does not exist in peer-type
+	// WARNING: in.NodeGonePolicy requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -990,18 +1030,19 @@ func Convert_v1alpha3_MachineHealthCheckStatus_To_v1alpha4_MachineHealthCheckSta
 func autoConvert_v1alpha4_MachineHealthCheckStatus_To_v1alpha3_MachineHealthCheckStatus(in *v1alpha4.MachineHealthCheckStatus, out *MachineHealthCheckStatus, s conversion.Scope) error {
 	out.ExpectedMachines = in.ExpectedMachines
 	out.CurrentHealthy = in.CurrentHealthy
+	// WARNING: in.CurrentSuspect requires manual conversion: does not exist in peer-type
 	out.RemediationsAllowed = in.RemediationsAllowed
 	out.ObservedGeneration = in.ObservedGeneration
+	// WARNING: in.LastUpdated requires manual conversion: does not exist in peer-type
+	// WARNING: in.HealthSummary requires manual conversion: does not exist in peer-type
+	// WARNING: in.TotalRemediations requires manual conversion: does not exist in peer-type
+	// WARNING: in.LastRemediationTime requires manual conversion: does not exist in peer-type
+	// WARNING: in.LastSuccessfulReconcile requires manual conversion: does not exist in peer-type
 	out.Targets = *(*[]string)(unsafe.Pointer(&in.Targets))
 	out.Conditions = *(*Conditions)(unsafe.Pointer(&in.Conditions))
 	return nil
 }
 
-// Convert_v1alpha4_MachineHealthCheckStatus_To_v1alpha3_MachineHealthCheckStatus is an autogenerated conversion function.
-func Convert_v1alpha4_MachineHealthCheckStatus_To_v1alpha3_MachineHealthCheckStatus(in *v1alpha4.MachineHealthCheckStatus, out *MachineHealthCheckStatus, s conversion.Scope) error {
-	return autoConvert_v1alpha4_MachineHealthCheckStatus_To_v1alpha3_MachineHealthCheckStatus(in, out, s)
-}
-
 func autoConvert_v1alpha3_MachineList_To_v1alpha4_MachineList(in *MachineList, out *v1alpha4.MachineList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
@@ -1367,10 +1408,7 @@ func autoConvert_v1alpha4_UnhealthyCondition_To_v1alpha3_UnhealthyCondition(in *
 	out.Type = v1.NodeConditionType(in.Type)
 	out.Status = v1.ConditionStatus(in.Status)
 	out.Timeout = in.Timeout
+	// WARNING: in.Action requires manual conversion: does not exist in peer-type
+	// WARNING: in.Weight requires manual conversion: does not exist in peer-type
 	return nil
 }
-
-// Convert_v1alpha4_UnhealthyCondition_To_v1alpha3_UnhealthyCondition is an autogenerated conversion function.
-func Convert_v1alpha4_UnhealthyCondition_To_v1alpha3_UnhealthyCondition(in *v1alpha4.UnhealthyCondition, out *UnhealthyCondition, s conversion.Scope) error {
-	return autoConvert_v1alpha4_UnhealthyCondition_To_v1alpha3_UnhealthyCondition(in, out, s)
-}
@@ -73,6 +73,46 @@ const (
 	// MachineSkipRemediationAnnotation is the annotation used to mark the machines that should not be considered for remediation by MachineHealthCheck reconciler.
 	MachineSkipRemediationAnnotation = "cluster.x-k8s.io/skip-remediation"
 
+	// MachineRemediationCordonedAnnotation is set by the MachineHealthCheck reconciler on a machine whose node it has
+	// cordoned for the CordonAndWait remediation strategy. Its value is the RFC3339 time at which the node was cordoned.
+	MachineRemediationCordonedAnnotation = "cluster.x-k8s.io/remediation-cordoned"
+
+	// MachineHealthCheckTransitionsAnnotation is set by the MachineHealthCheck reconciler on a machine to record the
+	// most recent transitions of its HealthCheckSucceeded condition, as a JSON list of {"time", "status"} objects,
+	// oldest first.
+	MachineHealthCheckTransitionsAnnotation = "cluster.x-k8s.io/health-check-transitions"
+
+	// ClusterUpgradeInProgressAnnotation is set on a Cluster while its machines are being upgraded. MachineHealthChecks
+	// with PauseDuringUpgrade set do not remediate the machines of the Cluster while it is present.
+	ClusterUpgradeInProgressAnnotation = "cluster.x-k8s.io/upgrade-in-progress"
+
+	// MachineHealthCheckResetRemediationBudgetAnnotation is set by operators on a MachineHealthCheck whose remediation
+	// budget is exhausted to resume remediation; the MachineHealthCheck reconciler resets the budget and removes it.
+	MachineHealthCheckResetRemediationBudgetAnnotation = "cluster.x-k8s.io/reset-remediation-budget"
+
+	// MachineRemediationReasonAnnotation is set by the MachineHealthCheck reconciler on a machine it marks for remediation
+	// by its owner, so that the cause of the remediation is retained through the deletion of the machine. Its value is the
+	// reason and the message of the failed MachineHealthCheckSucceeded condition, e.g. "UnhealthyNode: <message>".
+	MachineRemediationReasonAnnotation = "cluster.x-k8s.io/remediation-reason"
+
+	// MachineNeedsRemediationAnnotation is set to "true" by the MachineHealthCheck reconciler on an unhealthy machine
+	// for the AnnotateOnly remediation strategy, in place of marking it for remediation by its owner.
+	MachineNeedsRemediationAnnotation = "cluster.x-k8s.io/needs-remediation"
+
+	// MachineAPIUnreachableSinceAnnotation is set by the MachineHealthCheck reconciler on an unhealthy machine when the
+	// kubelet of its node is first found unreachable through the API server of the cluster, for MachineHealthChecks
+	// requiring it. Its value is the RFC3339 time of the first failed probe; it is removed once the kubelet is reachable.
+	MachineAPIUnreachableSinceAnnotation = "cluster.x-k8s.io/api-unreachable-since"
+
+	// MachineHealthCheckExplanationAnnotation is set by the MachineHealthCheck reconciler on a machine to explain its
+	// last decision to operators, as a JSON object: the unhealthy conditions evaluated against the node, with their
+	// timeouts and how long the node has met them, and whether remediation is allowed, and why.
+	MachineHealthCheckExplanationAnnotation = "cluster.x-k8s.io/mhc-explanation"
+
+	// MachineHealthCheckListUnhealthyTargetsAnnotation is set to "true" by operators on a MachineHealthCheck to list its
+	// unhealthy machines in its conditions, as <machine name>/HealthCheckSucceeded conditions.
+	MachineHealthCheckListUnhealthyTargetsAnnotation = "cluster.x-k8s.io/list-unhealthy-targets"
+
 	// ClusterSecretType defines the type of secret created by core components.
 	ClusterSecretType corev1.SecretType = "cluster.x-k8s.io/secret" //nolint:gosec
 
@@ -89,6 +89,15 @@ const (
 	// NOTE: Having the control plane machine available is a pre-condition for joining additional control planes
 	// or workers nodes.
 	WaitingForControlPlaneAvailableReason = "WaitingForControlPlaneAvailable"
+
+	// MachinesHealthyCondition reports a summary of the health of the cluster's Machines, as seen by all the
+	// MachineHealthChecks of the cluster. This condition is set by the MachineHealthCheck controller, hence it
+	// exists only for clusters with at least one MachineHealthCheck.
+	MachinesHealthyCondition ConditionType = "MachinesHealthy"
+
+	// UnhealthyMachinesReason (Severity=Warning) documents a cluster with fewer healthy Machines than expected
+	// by its MachineHealthChecks.
+	UnhealthyMachinesReason = "UnhealthyMachines"
 )
 
 // Conditions and condition Reasons for the Machine object
@@ -130,14 +139,20 @@ const (
 	// In the event that the health check fails it will be set to False.
 	MachineHealthCheckSuccededCondition ConditionType = "HealthCheckSucceeded"
 
-	// MachineHasFailureReason is the reason used when a machine has either a FailureReason or a FailureMessage set on its status.
+	// MachineHasFailureReason (Severity=Error) is the reason used when a machine has either a FailureReason or a FailureMessage
+	// set on its status.
 	MachineHasFailureReason = "MachineHasFailure"
 
-	// NodeStartupTimeoutReason is the reason used when a machine's node does not appear within the specified timeout.
+	// NodeStartupTimeoutReason (Severity=Warning) is the reason used when a machine's node does not appear within the specified timeout.
 	NodeStartupTimeoutReason = "NodeStartupTimeout"
 
-	// UnhealthyNodeConditionReason is the reason used when a machine's node has one of the MachineHealthCheck's unhealthy conditions.
+	// UnhealthyNodeConditionReason (Severity=Warning) is the reason used when a machine's node has one of the MachineHealthCheck's
+	// unhealthy conditions.
 	UnhealthyNodeConditionReason = "UnhealthyNode"
+
+	// HealthEvaluatorReportedUnhealthyReason (Severity=Warning) is the reason used when a health evaluator plugged into the MachineHealthCheck
+	// controller reports a machine unhealthy.
+	HealthEvaluatorReportedUnhealthyReason = "HealthEvaluatorReportedUnhealthy"
 )
 
 const (
@@ -167,6 +182,14 @@ const (
 
 	// ExternalRemediationRequestCreationFailed is the reason used when a machine health check fails to create external remediation request.
 	ExternalRemediationRequestCreationFailed = "ExternalRemediationRequestCreationFailed"
+
+	// DrainAllowedCondition is set on machines which failed a health check and whose MachineHealthCheck defers their
+	// remediation while PodDisruptionBudgets would block the drain of their node.
+	DrainAllowedCondition ConditionType = "DrainAllowed"
+
+	// DrainBlockedByPDBReason (Severity=Warning) documents a machine whose remediation is deferred because at least one
+	// PodDisruptionBudget allowing no disruption selects a pod running on its node.
+	DrainBlockedByPDBReason = "DrainBlockedByPDB"
 )
 
 // Conditions and condition Reasons for the Machine's Node object.
@@ -200,4 +223,73 @@ const (
 	// TooManyUnhealthy is the reason used when too many Machines are unhealthy and the MachineHealthCheck is blocked
 	// from making any further remediations.
 	TooManyUnhealthyReason = "TooManyUnhealthy"
+
+	// UpgradeInProgressCondition is set on MachineHealthChecks while their Cluster has the
+	// ClusterUpgradeInProgressAnnotation, and removed once the upgrade completes.
+	UpgradeInProgressCondition ConditionType = "UpgradeInProgress"
+
+	// UpgradeInProgressReason is the reason used when the Cluster is being upgraded; it is set on the UpgradeInProgress
+	// condition and, when the MachineHealthCheck does not remediate any Machines until the upgrade completes, on the
+	// RemediationAllowed condition (Severity=Info).
+	UpgradeInProgressReason = "UpgradeInProgress"
+
+	// ClusterWideOutageSuspectedCondition is set on MachineHealthChecks when the unhealthy Machines across the Cluster
+	// reach its ClusterOutageThreshold; such an outage is likely caused by the control plane or the network rather than
+	// by the Machines, and the MachineHealthCheck does not remediate any Machines while it lasts.
+	ClusterWideOutageSuspectedCondition ConditionType = "ClusterWideOutageSuspected"
+
+	// ClusterOutageThresholdReachedReason is the reason used when the unhealthy Machines across the Cluster reach the
+	// ClusterOutageThreshold of the MachineHealthCheck; it is set on the RemediationAllowed condition (Severity=Warning)
+	// and on the ClusterWideOutageSuspected condition.
+	ClusterOutageThresholdReachedReason = "ClusterOutageThresholdReached"
+
+	// NodeStatusStaleCondition is set on MachineHealthChecks when the nodes of their targets with a stale status reach
+	// the StaleNodeStatusThreshold; their unhealthiness is then likely an artifact of the reporting of the node status
+	// rather than of the nodes, and the MachineHealthCheck does not remediate any Machines while it lasts.
+	NodeStatusStaleCondition ConditionType = "NodeStatusStale"
+
+	// StaleNodeStatusThresholdReachedReason is the reason used when the nodes with a stale status reach the
+	// StaleNodeStatusThreshold of the MachineHealthCheck; it is set on the RemediationAllowed condition
+	// (Severity=Warning) and on the NodeStatusStale condition.
+	StaleNodeStatusThresholdReachedReason = "StaleNodeStatusThresholdReached"
+
+	// SelectorExclusiveCondition is set on MachineHealthChecks whose selector may match the same Machines as the selector
+	// of another MachineHealthCheck for the same Cluster; such Machines are counted, and possibly remediated, by both.
+	SelectorExclusiveCondition ConditionType = "SelectorExclusive"
+
+	// SelectorOverlapReason (Severity=Warning) documents a MachineHealthCheck whose selector overlaps with the selector
+	// of at least one other MachineHealthCheck for the same Cluster.
+	SelectorOverlapReason = "SelectorOverlap"
+
+	// KubeconfigAvailableCondition is set on MachineHealthChecks whose Cluster kubeconfig Secret cannot be found;
+	// while it is missing, the Machines cannot be health checked and the last observed status is preserved.
+	KubeconfigAvailableCondition ConditionType = "KubeconfigAvailable"
+
+	// KubeconfigMissingReason (Severity=Warning) documents a MachineHealthCheck whose Cluster kubeconfig Secret
+	// does not exist.
+	KubeconfigMissingReason = "KubeconfigMissing"
+
+	// RemediationBudgetAvailableCondition is set on MachineHealthChecks with MaxTotalRemediations whose remediation
+	// budget is exhausted; no further remediation is triggered until an operator resets the budget.
+	RemediationBudgetAvailableCondition ConditionType = "RemediationBudgetAvailable"
+
+	// RemediationBudgetExhaustedReason (Severity=Warning) documents a MachineHealthCheck which triggered
+	// MaxTotalRemediations remediations.
+	RemediationBudgetExhaustedReason = "RemediationBudgetExhausted"
+
+	// UnhealthyTargetsListedCondition is set on MachineHealthChecks with more unhealthy Machines than can be listed in
+	// their conditions; each listed Machine has a <machine name>/HealthCheckSucceeded condition mirroring its own.
+	UnhealthyTargetsListedCondition ConditionType = "UnhealthyTargetsListed"
+
+	// TooManyUnhealthyTargetsReason (Severity=Info) documents a MachineHealthCheck whose conditions only list some of
+	// its unhealthy Machines.
+	TooManyUnhealthyTargetsReason = "TooManyUnhealthyTargets"
+
+	// AwaitingReplacementCondition is set on MachineHealthChecks with a ReplacementTimeout while they hold back further
+	// remediations, until a Machine created since the last remediation is healthy or the timeout expires.
+	AwaitingReplacementCondition ConditionType = "AwaitingReplacement"
+
+	// ReplacementNotHealthyReason documents a MachineHealthCheck waiting for the replacement of the last remediated
+	// Machine to be healthy.
+	ReplacementNotHealthyReason = "ReplacementNotHealthy"
 )
@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha4
 
 import (
+	"fmt"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -35,7 +37,8 @@ type MachineHealthCheckSpec struct {
 
 	// UnhealthyConditions contains a list of the conditions that determine
 	// whether a node is considered unhealthy.  The conditions are combined in a
-	// logical OR, i.e. if any of the conditions is met, the node is unhealthy.
+	// logical OR, i.e. if any of the conditions is met, the node is unhealthy,
+	// unless UnhealthyWeightThreshold is set.
 	//
 	// +kubebuilder:validation:MinItems=1
 	UnhealthyConditions []UnhealthyCondition `json:"unhealthyConditions"`
@@ -45,8 +48,21 @@ type MachineHealthCheckSpec struct {
 	// +optional
 	MaxUnhealthy *intstr.IntOrString `json:"maxUnhealthy,omitempty"`
 
+	// Any further remediation is only allowed if at least "MinHealthy" machines selected by
+	// "selector" are healthy. Percentages are computed on ExpectedMachines and rounded up.
+	// This floor applies in addition to MaxUnhealthy and UnhealthyRange.
+	// +optional
+	MinHealthy *intstr.IntOrString `json:"minHealthy,omitempty"`
+
+	// ClusterOutageThreshold is the number or percentage of all the machines of the Cluster, rounded up, which
+	// being unhealthy at once suggests a cluster-wide outage, e.g. of the API server, rather than independent
+	// failures; no remediation is allowed then. It only applies to Clusters with more than one machine.
+	// Defaults to 100%; 0 disables it.
+	// +optional
+	ClusterOutageThreshold *intstr.IntOrString `json:"clusterOutageThreshold,omitempty"`
+
 	// Any further remediation is only allowed if the number of machines selected by "selector" as not healthy
-	// is within the range of "UnhealthyRange". Takes precedence over MaxUnhealthy.
+	// is within the range of "UnhealthyRange". Cannot be set together with MaxUnhealthy.
 	// Eg. "[3-5]" - This means that remediation will be allowed only when:
 	// (a) there are at least 3 unhealthy machines (and)
 	// (b) there are at most 5 unhealthy machines
@@ -54,6 +70,12 @@ type MachineHealthCheckSpec struct {
 	// +kubebuilder:validation:Pattern=^\[[0-9]+-[0-9]+\]$
 	UnhealthyRange *string `json:"unhealthyRange,omitempty"`
 
+	// ExpectedMachinesPolicy determines which machines selected by "selector" are counted
+	// towards ExpectedMachines, and therefore the base used to compute MaxUnhealthy percentages.
+	// Defaults to "All"; "ReadyOnly" excludes machines that never had a Node.
+	// +optional
+	ExpectedMachinesPolicy ExpectedMachinesPolicy `json:"expectedMachinesPolicy,omitempty"`
+
 	// Machines older than this duration without a node will be considered to have
 	// failed and will be remediated.
 	// +optional
@@ -67,10 +89,241 @@ type MachineHealthCheckSpec struct {
 	// a controller that lives outside of Cluster API.
 	// +optional
 	RemediationTemplate *corev1.ObjectReference `json:"remediationTemplate,omitempty"`
+
+	// RemediationStrategy configures how unhealthy machines are handed off to remediation.
+	// Defaults to remediating them as soon as they are detected unhealthy.
+	// +optional
+	RemediationStrategy *RemediationStrategy `json:"remediationStrategy,omitempty"`
+
+	// FailureDomainAware, if true, makes the MachineHealthCheck treat a failure domain in which
+	// all the selected machines are unhealthy as an infrastructure outage, and skip the remediation
+	// of the machines in that failure domain. Failure domains with a single selected machine are
+	// never considered down.
+	// +optional
+	FailureDomainAware *bool `json:"failureDomainAware,omitempty"`
+
+	// PauseDuringUpgrade, if true, pauses the remediation of unhealthy machines while the Cluster has the
+	// "cluster.x-k8s.io/upgrade-in-progress" annotation, as machines are expected to be briefly unhealthy
+	// during a rolling upgrade. Machines are still health checked while remediation is paused.
+	// +optional
+	PauseDuringUpgrade *bool `json:"pauseDuringUpgrade,omitempty"`
+
+	// DeferRemediationOnBlockingPDB, if true, defers the remediation of an unhealthy machine while a
+	// PodDisruptionBudget allowing no disruption selects a pod on its node, as the drain of the node
+	// would be blocked until the PodDisruptionBudget allows it.
+	// +optional
+	DeferRemediationOnBlockingPDB *bool `json:"deferRemediationOnBlockingPDB,omitempty"`
+
+	// RemediateOnFailureReason specifies whether machines reporting a terminal failure, through their
+	// FailureReason or FailureMessage, are considered unhealthy regardless of the conditions of their node.
+	// Defaults to true.
+	// +optional
+	RemediateOnFailureReason *bool `json:"remediateOnFailureReason,omitempty"`
+
+	// MaxTotalRemediations is the maximum number of remediations triggered by the MachineHealthCheck, as a
+	// safety valve against remediating machines in a loop. Once reached, remediation stops until the
+	// "cluster.x-k8s.io/reset-remediation-budget" annotation is set on the MachineHealthCheck.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxTotalRemediations *int32 `json:"maxTotalRemediations,omitempty"`
+
+	// RemediationOrder determines which unhealthy machines are remediated first when not all of them can be,
+	// e.g. once the remediation budget is about to be exhausted. Defaults to "OldestUnhealthyFirst";
+	// "LeastCriticalFirst" prefers the machines whose node runs the fewest critical pods.
+	// +optional
+	RemediationOrder RemediationOrder `json:"remediationOrder,omitempty"`
+
+	// RequireAPIUnreachable, if true, only remediates a machine whose node matches one of the UnhealthyConditions
+	// once the kubelet of the node has also been unreachable through the API server of the Cluster for
+	// APIUnreachableTimeout, so that nodes which are merely slow to report their conditions are not remediated.
+	// +optional
+	RequireAPIUnreachable *bool `json:"requireAPIUnreachable,omitempty"`
+
+	// APIUnreachableTimeout is how long the kubelet of a node must be unreachable through the API server of the
+	// Cluster before its machine is remediated, when RequireAPIUnreachable is true. Defaults to 1m.
+	// +optional
+	APIUnreachableTimeout *metav1.Duration `json:"apiUnreachableTimeout,omitempty"`
+
+	// RemediationSchedule defines maintenance windows, e.g. for nightly backups, during which unhealthy machines
+	// are still marked as such, but their remediation is deferred until the window closes.
+	// +optional
+	RemediationSchedule *Schedule `json:"remediationSchedule,omitempty"`
+
+	// UnhealthyWeightThreshold, if set, makes a node unhealthy only once the combined Weight of the UnhealthyConditions
+	// it has met for longer than their timeouts reaches the threshold, e.g. 2 for any two conditions of weight 1.
+	// AlertOnly conditions are not weighed. Defaults to any condition making the node unhealthy.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	UnhealthyWeightThreshold *int32 `json:"unhealthyWeightThreshold,omitempty"`
+
+	// RemediationBudgetScope determines which machines MaxUnhealthy, MinHealthy and UnhealthyRange are computed
+	// against. Defaults to "MachineHealthCheck", i.e. the machines it selects; "MachineDeployment" also counts the
+	// machines of the MachineDeployments of its targets it does not select, e.g. those of the other MachineSets
+	// during a rolling update, so that the deployment as a whole is not over-remediated.
+	// +optional
+	RemediationBudgetScope RemediationBudgetScope `json:"remediationBudgetScope,omitempty"`
+
+	// ReplacementTimeout, if set, holds back further remediations after each remediation until a machine created
+	// since then is healthy, i.e. until the replacement of the remediated machine is healthy, or until the timeout
+	// expires, so that broken infrastructure does not get every machine remediated in turn.
+	// +optional
+	ReplacementTimeout *metav1.Duration `json:"replacementTimeout,omitempty"`
+
+	// StaleNodeStatusTimeout, if set, is how long the conditions of a node may go without a heartbeat before the
+	// status of the node is considered stale. It must exceed the frequency the kubelet reports the status of its node
+	// with, 5m by default.
+	// +optional
+	StaleNodeStatusTimeout *metav1.Duration `json:"staleNodeStatusTimeout,omitempty"`
+
+	// StaleNodeStatusThreshold is the number or percentage of the nodes of the targets, rounded up, which having a
+	// stale status at once suggests that the reporting of the node status is broken, e.g. the kubelet heartbeats or
+	// the node lifecycle controller, rather than the nodes; no remediation is allowed then. It only applies when
+	// StaleNodeStatusTimeout is set. Defaults to 50%.
+	// +optional
+	StaleNodeStatusThreshold *intstr.IntOrString `json:"staleNodeStatusThreshold,omitempty"`
+
+	// RemediationStagger, if set, is the minimum interval between two remediations, so that the machines allowed to be
+	// remediated at once are remediated one at a time instead, e.g. not to overwhelm the provisioning of the
+	// infrastructure provider with their replacements.
+	// +optional
+	RemediationStagger *metav1.Duration `json:"remediationStagger,omitempty"`
+
+	// NodeGonePolicy determines how machines whose node is gone are remediated. Defaults to "Immediate", i.e. right
+	// away; "Classify" tells a node deleted on purpose from a node lost along with the infrastructure of its machine,
+	// e.g. on a host failure, which may come back and is given NodeStartupTimeout to do so.
+	// +optional
+	NodeGonePolicy NodeGonePolicy `json:"nodeGonePolicy,omitempty"`
 }
 
 // ANCHOR_END: MachineHealthCHeckSpec
 
+// ExpectedMachinesPolicy defines which machines are counted towards ExpectedMachines.
+// +kubebuilder:validation:Enum=All;ReadyOnly
+type ExpectedMachinesPolicy string
+
+const (
+	// ExpectedMachinesPolicyAll counts every machine selected by the MachineHealthCheck.
+	ExpectedMachinesPolicyAll ExpectedMachinesPolicy = "All"
+
+	// ExpectedMachinesPolicyReadyOnly counts only machines which have been assigned a Node,
+	// i.e. machines that never became ready do not affect the MaxUnhealthy computation.
+	ExpectedMachinesPolicyReadyOnly ExpectedMachinesPolicy = "ReadyOnly"
+)
+
+// RemediationStrategyType defines how unhealthy machines are handed off to remediation.
+// +kubebuilder:validation:Enum=Immediate;CordonAndWait;AnnotateOnly
+type RemediationStrategyType string
+
+const (
+	// RemediationStrategyImmediate marks unhealthy machines for remediation as soon as they are detected.
+	RemediationStrategyImmediate RemediationStrategyType = "Immediate"
+
+	// RemediationStrategyCordonAndWait cordons the node of an unhealthy machine and gives it
+	// GracePeriod to recover before marking it for remediation. If the machine recovers in
+	// the meantime, its node is uncordoned again.
+	RemediationStrategyCordonAndWait RemediationStrategyType = "CordonAndWait"
+
+	// RemediationStrategyAnnotateOnly sets the MachineNeedsRemediationAnnotation on unhealthy machines, without
+	// ever marking them for remediation by their owner, e.g. for a GitOps tool to delete them instead. The
+	// annotation is removed again once the machine is healthy.
+	RemediationStrategyAnnotateOnly RemediationStrategyType = "AnnotateOnly"
+)
+
+// RemediationOrder defines which unhealthy machines are remediated first.
+// +kubebuilder:validation:Enum=OldestUnhealthyFirst;LeastCriticalFirst
+type RemediationOrder string
+
+const (
+	// RemediationOrderOldestUnhealthyFirst remediates first the machines which failed their health check first.
+	RemediationOrderOldestUnhealthyFirst RemediationOrder = "OldestUnhealthyFirst"
+
+	// RemediationOrderLeastCriticalFirst remediates first the machines whose node runs the fewest critical pods,
+	// i.e. pods with a priority greater than zero, as given by their PriorityClass. Ties are broken by
+	// remediating first the machines which failed their health check first.
+	RemediationOrderLeastCriticalFirst RemediationOrder = "LeastCriticalFirst"
+)
+
+// RemediationBudgetScope defines which machines the remediation budget is computed against.
+// +kubebuilder:validation:Enum=MachineHealthCheck;MachineDeployment
+type RemediationBudgetScope string
+
+const (
+	// RemediationBudgetScopeMachineHealthCheck computes the remediation budget against the machines selected by
+	// the MachineHealthCheck.
+	RemediationBudgetScopeMachineHealthCheck RemediationBudgetScope = "MachineHealthCheck"
+
+	// RemediationBudgetScopeMachineDeployment computes the remediation budget against all the machines of the
+	// MachineDeployments of the selected machines, across all of their MachineSets. The machines which are not
+	// selected are not health checked by the MachineHealthCheck: they count as unhealthy if their
+	// MachineHealthCheckSucceeded condition, set by another MachineHealthCheck, is false, and are not counted
+	// while being deleted, e.g. when scaled down by the rolling update.
+	RemediationBudgetScopeMachineDeployment RemediationBudgetScope = "MachineDeployment"
+)
+
+// NodeGonePolicy defines how machines whose node is gone are remediated.
+// +kubebuilder:validation:Enum=Immediate;Classify
+type NodeGonePolicy string
+
+const (
+	// NodeGonePolicyImmediate treats every node gone as terminal, remediating its machine right away.
+	NodeGonePolicyImmediate NodeGonePolicy = "Immediate"
+
+	// NodeGonePolicyClassify treats a node gone as terminal, remediating its machine right away, when the machine is
+	// being deleted or its infrastructure is ready, i.e. when the node was deleted on purpose. Otherwise the node
+	// vanished along with the infrastructure, possibly transiently: its machine is only remediated once the
+	// infrastructure has not been ready for NodeStartupTimeout.
+	NodeGonePolicyClassify NodeGonePolicy = "Classify"
+)
+
+// UnhealthyConditionAction defines what happens when an unhealthy condition is matched.
+// +kubebuilder:validation:Enum=RemediateAndAlert;AlertOnly
+type UnhealthyConditionAction string
+
+const (
+	// UnhealthyConditionActionRemediateAndAlert marks the machine as unhealthy and triggers its remediation.
+	UnhealthyConditionActionRemediateAndAlert UnhealthyConditionAction = "RemediateAndAlert"
+
+	// UnhealthyConditionActionAlertOnly marks the machine as unhealthy without triggering its remediation.
+	UnhealthyConditionActionAlertOnly UnhealthyConditionAction = "AlertOnly"
+)
+
+// RemediationStrategy defines how unhealthy machines are handed off to remediation.
+type RemediationStrategy struct {
+	// Type of the remediation strategy, either "Immediate", "CordonAndWait" or "AnnotateOnly".
+	// Defaults to "Immediate".
+	// +optional
+	Type RemediationStrategyType `json:"type,omitempty"`
+
+	// GracePeriod is the time a cordoned machine is given to recover before it is remediated.
+	// Required when Type is "CordonAndWait".
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// Schedule defines time windows recurring every day.
+type Schedule struct {
+	// TimeZone of the windows, as an IANA time zone name, e.g. "Europe/Berlin".
+	// Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Windows of the schedule.
+	// +kubebuilder:validation:MinItems=1
+	Windows []TimeWindow `json:"windows"`
+}
+
+// TimeWindow defines a time window recurring every day.
+type TimeWindow struct {
+	// Start is the time of day the window opens, as HH:MM, e.g. "01:00".
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// End is the time of day the window closes, as HH:MM, e.g. "03:00". A window closing before it opens
+	// spans midnight.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+}
+
 // ANCHOR: UnhealthyCondition
 
 // UnhealthyCondition represents a Node condition type and value with a timeout
@@ -86,6 +339,18 @@ type UnhealthyCondition struct {
 	Status corev1.ConditionStatus `json:"status"`
 
 	Timeout metav1.Duration `json:"timeout"`
+
+	// Action to take when the condition is matched, either "RemediateAndAlert" or "AlertOnly".
+	// AlertOnly conditions mark the machine as unhealthy, but never trigger its remediation.
+	// Defaults to "RemediateAndAlert".
+	// +optional
+	Action UnhealthyConditionAction `json:"action,omitempty"`
+
+	// Weight of the condition, counted towards the UnhealthyWeightThreshold of the MachineHealthCheck
+	// when the condition is met. Defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Weight *int32 `json:"weight,omitempty"`
 }
 
 // ANCHOR_END: UnhealthyCondition
@@ -94,14 +359,25 @@ type UnhealthyCondition struct {
 
 // MachineHealthCheckStatus defines the observed state of MachineHealthCheck.
 type MachineHealthCheckStatus struct {
-	// total number of machines counted by this machine health check
+	// total number of machines counted by this machine health check.
+	// With the MachineDeployment RemediationBudgetScope, it includes the machines of the MachineDeployments
+	// of the targets which are not targeted, as they count towards the remediation budget.
 	// +kubebuilder:validation:Minimum=0
 	ExpectedMachines int32 `json:"expectedMachines,omitempty"`
 
-	// total number of healthy machines counted by this machine health check
+	// total number of healthy machines counted by this machine health check.
+	// With the MachineDeployment RemediationBudgetScope, it includes the healthy machines of the
+	// MachineDeployments of the targets which are not targeted, as they count towards the remediation budget.
 	// +kubebuilder:validation:Minimum=0
 	CurrentHealthy int32 `json:"currentHealthy,omitempty"`
 
+	// CurrentSuspect is the number of machines whose node meets one of the UnhealthyConditions, but for less than
+	// its timeout. They are neither counted in CurrentHealthy nor remediated until the timeout expires, but already
+	// count against MaxUnhealthy.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CurrentSuspect int32 `json:"currentSuspect,omitempty"`
+
 	// RemediationsAllowed is the number of further remediations allowed by this machine health check before
 	// maxUnhealthy short circuiting will be applied
 	// +kubebuilder:validation:Minimum=0
@@ -111,6 +387,30 @@ type MachineHealthCheckStatus struct {
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
+	// LastUpdated is the time the controller last completed a reconciliation of the MachineHealthCheck.
+	// +optional
+	LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
+
+	// HealthSummary is a human readable summary of the status, e.g. "2/3 healthy, remediation allowed".
+	// +optional
+	HealthSummary string `json:"healthSummary,omitempty"`
+
+	// TotalRemediations is the number of remediations triggered by the MachineHealthCheck since its remediation
+	// budget was last reset, counted against MaxTotalRemediations.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TotalRemediations int32 `json:"totalRemediations,omitempty"`
+
+	// LastRemediationTime is the time the MachineHealthCheck last triggered a remediation.
+	// +optional
+	LastRemediationTime *metav1.Time `json:"lastRemediationTime,omitempty"`
+
+	// LastSuccessfulReconcile is the time the controller last completed a full reconciliation of the
+	// MachineHealthCheck without error, health checking its targets. It is left unchanged by failed reconciliations,
+	// and by the ones waiting for the kubeconfig of the Cluster.
+	// +optional
+	LastSuccessfulReconcile *metav1.Time `json:"lastSuccessfulReconcile,omitempty"`
+
 	// Targets shows the current list of machines the machine health check is watching
 	// +optional
 	Targets []string `json:"targets,omitempty"`
@@ -120,6 +420,33 @@ type MachineHealthCheckStatus struct {
 	Conditions Conditions `json:"conditions,omitempty"`
 }
 
+// Summary returns a human readable summary of the status, made of the number of healthy and expected
+// machines, of suspect ones if any, and, once known, whether remediation is allowed, e.g.
+// "2/3 healthy (1 suspect), remediation allowed".
+func (s MachineHealthCheckStatus) Summary() string {
+	summary := fmt.Sprintf("%d/%d healthy", s.CurrentHealthy, s.ExpectedMachines)
+	if s.CurrentSuspect > 0 {
+		summary += fmt.Sprintf(" (%d suspect)", s.CurrentSuspect)
+	}
+
+	for _, c := range s.Conditions {
+		if c.Type != RemediationAllowedCondition {
+			continue
+		}
+		switch {
+		case c.Status == corev1.ConditionTrue:
+			return summary + ", remediation allowed"
+		case c.Reason == TooManyUnhealthyReason:
+			return summary + ", remediation short-circuited"
+		case c.Reason == UpgradeInProgressReason:
+			return summary + ", remediation paused during upgrade"
+		default:
+			return summary + ", remediation not allowed"
+		}
+	}
+	return summary
+}
+
 // ANCHOR_END: MachineHealthCheckStatus
 
 // +kubebuilder:object:root=true
@@ -129,6 +456,7 @@ type MachineHealthCheckStatus struct {
 // +kubebuilder:printcolumn:name="MaxUnhealthy",type="string",JSONPath=".spec.maxUnhealthy",description="Maximum number of unhealthy machines allowed"
 // +kubebuilder:printcolumn:name="ExpectedMachines",type="integer",JSONPath=".status.expectedMachines",description="Number of machines currently monitored"
 // +kubebuilder:printcolumn:name="CurrentHealthy",type="integer",JSONPath=".status.currentHealthy",description="Current observed healthy machines"
+// +kubebuilder:printcolumn:name="Summary",type="string",JSONPath=".status.healthSummary",description="Summary of the health of the machines"
 
 // MachineHealthCheck is the Schema for the machinehealthchecks API.
 type MachineHealthCheck struct {
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestMachineHealthCheckStatusSummary(t *testing.T) {
+	tests := []struct {
+		name   string
+		status MachineHealthCheckStatus
+		expect string
+	}{
+		{
+			name: "when the MachineHealthCheck has not been reconciled yet",
+			status: MachineHealthCheckStatus{
+				ExpectedMachines: 3,
+				CurrentHealthy:   2,
+			},
+			expect: "2/3 healthy",
+		},
+		{
+			name: "when remediation is allowed",
+			status: MachineHealthCheckStatus{
+				ExpectedMachines: 3,
+				CurrentHealthy:   2,
+				Conditions: Conditions{
+					{Type: RemediationAllowedCondition, Status: corev1.ConditionTrue},
+				},
+			},
+			expect: "2/3 healthy, remediation allowed",
+		},
+		{
+			name: "when remediation is short-circuited by maxUnhealthy",
+			status: MachineHealthCheckStatus{
+				ExpectedMachines: 3,
+				CurrentHealthy:   0,
+				Conditions: Conditions{
+					{Type: RemediationAllowedCondition, Status: corev1.ConditionFalse, Reason: TooManyUnhealthyReason},
+				},
+			},
+			expect: "0/3 healthy, remediation short-circuited",
+		},
+		{
+			name: "when some machines are suspect",
+			status: MachineHealthCheckStatus{
+				ExpectedMachines: 3,
+				CurrentHealthy:   2,
+				CurrentSuspect:   1,
+				Conditions: Conditions{
+					{Type: RemediationAllowedCondition, Status: corev1.ConditionTrue},
+				},
+			},
+			expect: "2/3 healthy (1 suspect), remediation allowed",
+		},
+		{
+			name: "when remediation is paused during an upgrade",
+			status: MachineHealthCheckStatus{
+				ExpectedMachines: 3,
+				CurrentHealthy:   1,
+				Conditions: Conditions{
+					{Type: RemediationAllowedCondition, Status: corev1.ConditionFalse, Reason: UpgradeInProgressReason},
+				},
+			},
+			expect: "1/3 healthy, remediation paused during upgrade",
+		},
+		{
+			name: "when remediation is not allowed for another reason",
+			status: MachineHealthCheckStatus{
+				ExpectedMachines: 3,
+				CurrentHealthy:   1,
+				Conditions: Conditions{
+					{Type: RemediationAllowedCondition, Status: corev1.ConditionUnknown},
+				},
+			},
+			expect: "1/3 healthy, remediation not allowed",
+		},
+		{
+			name: "when other conditions are set",
+			status: MachineHealthCheckStatus{
+				Conditions: Conditions{
+					{Type: KubeconfigAvailableCondition, Status: corev1.ConditionFalse, Reason: KubeconfigMissingReason},
+				},
+			},
+			expect: "0/0 healthy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(tt.status.Summary()).To(Equal(tt.expect))
+		})
+	}
+}
@@ -17,19 +17,34 @@ limitations under the License.
 package v1alpha4
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/pkg/errors"
+	admissionv1 "k8s.io/api/admission/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
+// timeWindowLayout is the layout of the times of day of TimeWindows.
+const timeWindowLayout = "15:04"
+
 var (
 	// Default time allowed for a node to start up. Can be made longer as part of
 	// spec if required for particular provider.
@@ -50,6 +65,11 @@ func SetMinNodeStartupTimeout(d metav1.Duration) {
 }
 
 func (m *MachineHealthCheck) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	// The validating webhook is registered first, so that the builder does not register its own for the
+	// webhook.Validator implementation; it also warns about overlapping selectors, which needs a client.
+	mgr.GetWebhookServer().Register("/validate-cluster-x-k8s-io-v1alpha4-machinehealthcheck", &webhook.Admission{
+		Handler: newMachineHealthCheckValidator(mgr.GetClient()),
+	})
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(m).
 		Complete()
@@ -68,13 +88,21 @@ func (m *MachineHealthCheck) Default() {
 	}
 	m.Labels[ClusterLabelName] = m.Spec.ClusterName
 
-	if m.Spec.MaxUnhealthy == nil {
+	// UnhealthyRange replaces MaxUnhealthy as the remediation short-circuiting limit when set, and both cannot be
+	// set together; MaxUnhealthy is only defaulted when neither of them is set.
+	if m.Spec.MaxUnhealthy == nil && m.Spec.UnhealthyRange == nil {
 		defaultMaxUnhealthy := intstr.FromString("100%")
 		m.Spec.MaxUnhealthy = &defaultMaxUnhealthy
 	}
 
+	// The default is copied, so that changing the timeout of a MachineHealthCheck does not change it.
 	if m.Spec.NodeStartupTimeout == nil {
-		m.Spec.NodeStartupTimeout = &defaultNodeStartupTimeout
+		nodeStartupTimeout := defaultNodeStartupTimeout
+		m.Spec.NodeStartupTimeout = &nodeStartupTimeout
+	}
+
+	if m.Spec.RemediateOnFailureReason == nil {
+		m.Spec.RemediateOnFailureReason = pointer.BoolPtr(true)
 	}
 }
 
@@ -130,31 +158,266 @@ func (m *MachineHealthCheck) validate(old *MachineHealthCheck) error {
 		)
 	}
 
-	if m.Spec.NodeStartupTimeout != nil && m.Spec.NodeStartupTimeout.Seconds() < minNodeStartupTimeout.Seconds() {
+	if m.Spec.NodeStartupTimeout != nil && m.Spec.NodeStartupTimeout.Duration <= 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "nodeStartupTimeout"), m.Spec.NodeStartupTimeout.Seconds(), "must be greater than zero"),
+		)
+	} else if m.Spec.NodeStartupTimeout != nil && m.Spec.NodeStartupTimeout.Seconds() < minNodeStartupTimeout.Seconds() {
 		allErrs = append(
 			allErrs,
 			field.Invalid(field.NewPath("spec", "nodeStartupTimeout"), m.Spec.NodeStartupTimeout.Seconds(), "must be at least 30s"),
 		)
 	}
 
+	// A condition with no timeout would make machines unhealthy as soon as their node reports it.
+	for i, c := range m.Spec.UnhealthyConditions {
+		if c.Timeout.Duration <= 0 {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "unhealthyConditions").Index(i).Child("timeout"), c.Timeout.Seconds(), "must be greater than zero"),
+			)
+		}
+	}
+
 	if m.Spec.MaxUnhealthy != nil {
-		if _, err := intstr.GetValueFromIntOrPercent(m.Spec.MaxUnhealthy, 0, false); err != nil {
+		allErrs = append(allErrs, validateIntOrPercent(field.NewPath("spec", "maxUnhealthy"), m.Spec.MaxUnhealthy)...)
+	}
+
+	// MachineHealthChecks created before this was enforced may have an UnhealthyRange and a defaulted MaxUnhealthy,
+	// in which case UnhealthyRange takes precedence; do not prevent them from being updated.
+	if m.Spec.MaxUnhealthy != nil && m.Spec.UnhealthyRange != nil && (old == nil || old.Spec.MaxUnhealthy == nil || old.Spec.UnhealthyRange == nil) {
+		allErrs = append(
+			allErrs,
+			field.Forbidden(field.NewPath("spec", "maxUnhealthy"), "cannot be set together with unhealthyRange"),
+		)
+	}
+
+	if m.Spec.MinHealthy != nil {
+		allErrs = append(allErrs, validateIntOrPercent(field.NewPath("spec", "minHealthy"), m.Spec.MinHealthy)...)
+	}
+
+	if m.Spec.ClusterOutageThreshold != nil {
+		allErrs = append(allErrs, validateIntOrPercent(field.NewPath("spec", "clusterOutageThreshold"), m.Spec.ClusterOutageThreshold)...)
+	}
+
+	if m.Spec.StaleNodeStatusThreshold != nil {
+		allErrs = append(allErrs, validateIntOrPercent(field.NewPath("spec", "staleNodeStatusThreshold"), m.Spec.StaleNodeStatusThreshold)...)
+	}
+
+	if m.Spec.StaleNodeStatusTimeout != nil && m.Spec.StaleNodeStatusTimeout.Duration <= 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "staleNodeStatusTimeout"), m.Spec.StaleNodeStatusTimeout.Seconds(), "must be greater than zero"),
+		)
+	}
+
+	if m.Spec.APIUnreachableTimeout != nil && m.Spec.APIUnreachableTimeout.Duration <= 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "apiUnreachableTimeout"), m.Spec.APIUnreachableTimeout.Seconds(), "must be greater than zero"),
+		)
+	}
+
+	if m.Spec.RemediationStagger != nil && m.Spec.RemediationStagger.Duration <= 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "remediationStagger"), m.Spec.RemediationStagger.Seconds(), "must be greater than zero"),
+		)
+	}
+
+	if m.Spec.ReplacementTimeout != nil && m.Spec.ReplacementTimeout.Duration <= 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "replacementTimeout"), m.Spec.ReplacementTimeout.Seconds(), "must be greater than zero"),
+		)
+	}
+
+	if m.Spec.RemediationSchedule != nil {
+		allErrs = append(allErrs, validateSchedule(field.NewPath("spec", "remediationSchedule"), m.Spec.RemediationSchedule)...)
+	}
+
+	if m.Spec.RemediationStrategy != nil && m.Spec.RemediationStrategy.Type == RemediationStrategyCordonAndWait {
+		if m.Spec.RemediationStrategy.GracePeriod == nil || m.Spec.RemediationStrategy.GracePeriod.Duration <= 0 {
 			allErrs = append(
 				allErrs,
-				field.Invalid(field.NewPath("spec", "maxUnhealthy"), m.Spec.MaxUnhealthy, "must be either an int or a percentage"),
+				field.Required(field.NewPath("spec", "remediationStrategy", "gracePeriod"), "must be greater than zero when type is CordonAndWait"),
 			)
-		} else if m.Spec.MaxUnhealthy.Type == intstr.String {
-			if len(validation.IsValidPercent(m.Spec.MaxUnhealthy.StrVal)) != 0 {
-				allErrs = append(
-					allErrs,
-					field.Invalid(field.NewPath("spec", "maxUnhealthy"), m.Spec.MaxUnhealthy, "must be either an int or a percentage"),
-				)
-			}
 		}
 	}
 
+	if m.Spec.RemediationStrategy != nil && m.Spec.RemediationStrategy.Type == RemediationStrategyAnnotateOnly && m.Spec.RemediationTemplate != nil {
+		allErrs = append(
+			allErrs,
+			field.Forbidden(field.NewPath("spec", "remediationTemplate"), "cannot be set when the remediation strategy type is AnnotateOnly"),
+		)
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("MachineHealthCheck").GroupKind(), m.Name, allErrs)
 }
+
+// validateIntOrPercent checks that the value is either an int or a valid percentage.
+func validateIntOrPercent(fldPath *field.Path, value *intstr.IntOrString) field.ErrorList {
+	if _, err := intstr.GetValueFromIntOrPercent(value, 0, false); err != nil {
+		return field.ErrorList{field.Invalid(fldPath, value, "must be either an int or a percentage")}
+	}
+	if value.Type == intstr.String && len(validation.IsValidPercent(value.StrVal)) != 0 {
+		return field.ErrorList{field.Invalid(fldPath, value, "must be either an int or a percentage")}
+	}
+	return nil
+}
+
+// validateSchedule checks that the time zone of the schedule is known, and that its windows open and close at
+// different times of day, given as HH:MM.
+func validateSchedule(fldPath *field.Path, schedule *Schedule) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if _, err := time.LoadLocation(schedule.TimeZone); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeZone"), schedule.TimeZone, "must be an IANA time zone name"))
+	}
+	if len(schedule.Windows) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("windows"), "must have at least one window"))
+	}
+	for i, window := range schedule.Windows {
+		start, err := time.Parse(timeWindowLayout, window.Start)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("windows").Index(i).Child("start"), window.Start, "must be a time of day as HH:MM"))
+		}
+		end, err := time.Parse(timeWindowLayout, window.End)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("windows").Index(i).Child("end"), window.End, "must be a time of day as HH:MM"))
+		} else if end.Equal(start) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("windows").Index(i).Child("end"), window.End, "must be different from start"))
+		}
+	}
+
+	return allErrs
+}
+
+// machineHealthCheckValidator validates MachineHealthChecks with their webhook.Validator implementation, then warns
+// about the other MachineHealthChecks whose selector overlaps with theirs on create and update. The warnings never
+// block the request.
+type machineHealthCheckValidator struct {
+	Client    client.Reader
+	validator admission.Handler
+	decoder   *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &machineHealthCheckValidator{}
+
+func newMachineHealthCheckValidator(c client.Reader) *machineHealthCheckValidator {
+	return &machineHealthCheckValidator{
+		Client:    c,
+		validator: admission.ValidatingWebhookFor(&MachineHealthCheck{}).Handler,
+	}
+}
+
+// InjectDecoder injects the decoder into the machineHealthCheckValidator and its webhook.Validator handler.
+func (v *machineHealthCheckValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	_, err := admission.InjectDecoderInto(d, v.validator)
+	return err
+}
+
+// Handle handles admission requests.
+func (v *machineHealthCheckValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	resp := v.validator.Handle(ctx, req)
+	if !resp.Allowed || (req.Operation != admissionv1.Create && req.Operation != admissionv1.Update) {
+		return resp
+	}
+
+	m := &MachineHealthCheck{}
+	if err := v.decoder.Decode(req, m); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	overlapping, err := m.OverlappingMachineHealthChecks(ctx, v.Client)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if len(overlapping) == 0 {
+		return resp
+	}
+	return resp.WithWarnings(fmt.Sprintf("MachineHealthCheck %s selector overlaps with MachineHealthCheck(s) %s, "+
+		"the Machines they both match are counted, and possibly remediated, by each of them", m.Name, strings.Join(overlapping, ", ")))
+}
+
+// OverlappingMachineHealthChecks returns the sorted names of the other MachineHealthChecks for the same Cluster
+// whose selector may match the same machines as the selector of the MachineHealthCheck.
+func (m *MachineHealthCheck) OverlappingMachineHealthChecks(ctx context.Context, c client.Reader) ([]string, error) {
+	mhcList := &MachineHealthCheckList{}
+	if err := c.List(ctx, mhcList, client.InNamespace(m.Namespace)); err != nil {
+		return nil, errors.Wrap(err, "failed to list MachineHealthChecks")
+	}
+
+	var names []string
+	for i := range mhcList.Items {
+		mhc := &mhcList.Items[i]
+		if mhc.Name == m.Name || mhc.Spec.ClusterName != m.Spec.ClusterName || !mhc.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if selectorsOverlap(m.Spec.Selector, mhc.Spec.Selector) {
+			names = append(names, mhc.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// selectorsOverlap verifies whether a set of Labels exists which is matched by both Label Selectors.
+// Invalid or empty selectors match nothing, so they never overlap.
+func selectorsOverlap(a, b metav1.LabelSelector) bool {
+	requirements := map[string][]labels.Requirement{}
+	for _, s := range []metav1.LabelSelector{a, b} {
+		selector, err := metav1.LabelSelectorAsSelector(&s)
+		if err != nil || selector.Empty() {
+			return false
+		}
+		reqs, _ := selector.Requirements()
+		for _, r := range reqs {
+			requirements[r.Key()] = append(requirements[r.Key()], r)
+		}
+	}
+
+	for _, reqs := range requirements {
+		if !requirementsSatisfiable(reqs) {
+			return false
+		}
+	}
+	return true
+}
+
+// requirementsSatisfiable verifies whether a value, or the absence of a value, exists for a label key which
+// satisfies all the given Requirements on that key.
+func requirementsSatisfiable(reqs []labels.Requirement) bool {
+	var in sets.String
+	notIn := sets.NewString()
+	mustExist, mustNotExist := false, false
+	for _, r := range reqs {
+		switch r.Operator() {
+		case selection.In, selection.Equals, selection.DoubleEquals:
+			mustExist = true
+			if in == nil {
+				in = sets.NewString(r.Values().List()...)
+			} else {
+				in = in.Intersection(r.Values())
+			}
+		case selection.NotIn, selection.NotEquals:
+			notIn.Insert(r.Values().List()...)
+		case selection.Exists:
+			mustExist = true
+		case selection.DoesNotExist:
+			mustNotExist = true
+		}
+	}
+
+	if mustExist && mustNotExist {
+		return false
+	}
+	if in != nil && in.Difference(notIn).Len() == 0 {
+		return false
+	}
+	return true
+}
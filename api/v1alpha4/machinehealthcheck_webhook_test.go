@@ -17,14 +17,22 @@ limitations under the License.
 package v1alpha4
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
 	. "github.com/onsi/gomega"
 
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
 	utildefaulting "sigs.k8s.io/cluster-api/util/defaulting"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 func TestMachineHealthCheckDefault(t *testing.T) {
@@ -43,6 +51,13 @@ func TestMachineHealthCheckDefault(t *testing.T) {
 	g.Expect(mhc.Spec.MaxUnhealthy.String()).To(Equal("100%"))
 	g.Expect(mhc.Spec.NodeStartupTimeout).ToNot(BeNil())
 	g.Expect(*mhc.Spec.NodeStartupTimeout).To(Equal(metav1.Duration{Duration: 10 * time.Minute}))
+	g.Expect(mhc.Spec.RemediateOnFailureReason).To(Equal(pointer.BoolPtr(true)))
+
+	// Changing the defaulted timeout does not change the default.
+	mhc.Spec.NodeStartupTimeout.Duration = 0
+	other := &MachineHealthCheck{}
+	other.Default()
+	g.Expect(*other.Spec.NodeStartupTimeout).To(Equal(metav1.Duration{Duration: 10 * time.Minute}))
 }
 
 func TestMachineHealthCheckLabelSelectorAsSelectorValidation(t *testing.T) {
@@ -147,9 +162,10 @@ func TestMachineHealthCheckNodeStartupTimeout(t *testing.T) {
 	minusOneMinute := metav1.Duration{Duration: -1 * time.Minute}
 
 	tests := []struct {
-		name      string
-		timeout   *metav1.Duration
-		expectErr bool
+		name          string
+		timeout       *metav1.Duration
+		expectErr     bool
+		expectMessage string
 	}{
 		{
 			name:      "when the nodeStartupTimeout is not given",
@@ -167,19 +183,22 @@ func TestMachineHealthCheckNodeStartupTimeout(t *testing.T) {
 			expectErr: false,
 		},
 		{
-			name:      "when the nodeStartupTimeout is 29s",
-			timeout:   &twentyNineSeconds,
-			expectErr: true,
+			name:          "when the nodeStartupTimeout is 29s",
+			timeout:       &twentyNineSeconds,
+			expectErr:     true,
+			expectMessage: "must be at least 30s",
 		},
 		{
-			name:      "when the nodeStartupTimeout is less than 0",
-			timeout:   &minusOneMinute,
-			expectErr: true,
+			name:          "when the nodeStartupTimeout is less than 0",
+			timeout:       &minusOneMinute,
+			expectErr:     true,
+			expectMessage: "spec.nodeStartupTimeout: Invalid value: -60: must be greater than zero",
 		},
 		{
-			name:      "when the nodeStartupTimeout is 0",
-			timeout:   &zero,
-			expectErr: true,
+			name:          "when the nodeStartupTimeout is 0",
+			timeout:       &zero,
+			expectErr:     true,
+			expectMessage: "spec.nodeStartupTimeout: Invalid value: 0: must be greater than zero",
 		},
 	}
 
@@ -198,7 +217,7 @@ func TestMachineHealthCheckNodeStartupTimeout(t *testing.T) {
 		}
 
 		if tt.expectErr {
-			g.Expect(mhc.ValidateCreate()).NotTo(Succeed())
+			g.Expect(mhc.ValidateCreate()).To(MatchError(ContainSubstring(tt.expectMessage)))
 			g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed())
 		} else {
 			g.Expect(mhc.ValidateCreate()).To(Succeed())
@@ -207,6 +226,296 @@ func TestMachineHealthCheckNodeStartupTimeout(t *testing.T) {
 	}
 }
 
+func TestMachineHealthCheckUnhealthyConditionTimeout(t *testing.T) {
+	tests := []struct {
+		name          string
+		timeout       metav1.Duration
+		expectErr     bool
+		expectMessage string
+	}{
+		{
+			name:      "when the timeout is positive",
+			timeout:   metav1.Duration{Duration: 5 * time.Minute},
+			expectErr: false,
+		},
+		{
+			name:          "when the timeout is 0",
+			timeout:       metav1.Duration{Duration: 0},
+			expectErr:     true,
+			expectMessage: "spec.unhealthyConditions[0].timeout: Invalid value: 0: must be greater than zero",
+		},
+		{
+			name:          "when the timeout is negative",
+			timeout:       metav1.Duration{Duration: -1 * time.Minute},
+			expectErr:     true,
+			expectMessage: "spec.unhealthyConditions[0].timeout: Invalid value: -60: must be greater than zero",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			mhc := &MachineHealthCheck{
+				Spec: MachineHealthCheckSpec{
+					Selector: metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"test": "test",
+						},
+					},
+					UnhealthyConditions: []UnhealthyCondition{
+						{
+							Type:    corev1.NodeReady,
+							Status:  corev1.ConditionUnknown,
+							Timeout: tt.timeout,
+						},
+					},
+				},
+			}
+
+			if tt.expectErr {
+				err := mhc.ValidateCreate()
+				g.Expect(err).To(MatchError(ContainSubstring(tt.expectMessage)))
+				g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed())
+			} else {
+				g.Expect(mhc.ValidateCreate()).To(Succeed())
+				g.Expect(mhc.ValidateUpdate(mhc)).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestMachineHealthCheckAPIUnreachableTimeout(t *testing.T) {
+	tests := []struct {
+		name          string
+		timeout       *metav1.Duration
+		expectErr     bool
+		expectMessage string
+	}{
+		{
+			name:      "when the apiUnreachableTimeout is not given",
+			timeout:   nil,
+			expectErr: false,
+		},
+		{
+			name:      "when the apiUnreachableTimeout is positive",
+			timeout:   &metav1.Duration{Duration: 2 * time.Minute},
+			expectErr: false,
+		},
+		{
+			name:          "when the apiUnreachableTimeout is 0",
+			timeout:       &metav1.Duration{Duration: 0},
+			expectErr:     true,
+			expectMessage: "spec.apiUnreachableTimeout: Invalid value: 0: must be greater than zero",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			mhc := &MachineHealthCheck{
+				Spec: MachineHealthCheckSpec{
+					Selector: metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"test": "test",
+						},
+					},
+					RequireAPIUnreachable: pointer.BoolPtr(true),
+					APIUnreachableTimeout: tt.timeout,
+				},
+			}
+
+			if tt.expectErr {
+				err := mhc.ValidateCreate()
+				g.Expect(err).To(MatchError(ContainSubstring(tt.expectMessage)))
+				g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed())
+			} else {
+				g.Expect(mhc.ValidateCreate()).To(Succeed())
+				g.Expect(mhc.ValidateUpdate(mhc)).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestMachineHealthCheckReplacementTimeout(t *testing.T) {
+	tests := []struct {
+		name          string
+		timeout       *metav1.Duration
+		expectErr     bool
+		expectMessage string
+	}{
+		{
+			name:      "when the replacementTimeout is not given",
+			timeout:   nil,
+			expectErr: false,
+		},
+		{
+			name:      "when the replacementTimeout is positive",
+			timeout:   &metav1.Duration{Duration: 30 * time.Minute},
+			expectErr: false,
+		},
+		{
+			name:          "when the replacementTimeout is 0",
+			timeout:       &metav1.Duration{Duration: 0},
+			expectErr:     true,
+			expectMessage: "spec.replacementTimeout: Invalid value: 0: must be greater than zero",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			mhc := &MachineHealthCheck{
+				Spec: MachineHealthCheckSpec{
+					Selector: metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"test": "test",
+						},
+					},
+					ReplacementTimeout: tt.timeout,
+				},
+			}
+
+			if tt.expectErr {
+				err := mhc.ValidateCreate()
+				g.Expect(err).To(MatchError(ContainSubstring(tt.expectMessage)))
+				g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed())
+			} else {
+				g.Expect(mhc.ValidateCreate()).To(Succeed())
+				g.Expect(mhc.ValidateUpdate(mhc)).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestMachineHealthCheckRemediationStagger(t *testing.T) {
+	tests := []struct {
+		name          string
+		stagger       *metav1.Duration
+		expectErr     bool
+		expectMessage string
+	}{
+		{
+			name:      "when the remediationStagger is not given",
+			stagger:   nil,
+			expectErr: false,
+		},
+		{
+			name:      "when the remediationStagger is positive",
+			stagger:   &metav1.Duration{Duration: time.Minute},
+			expectErr: false,
+		},
+		{
+			name:          "when the remediationStagger is 0",
+			stagger:       &metav1.Duration{Duration: 0},
+			expectErr:     true,
+			expectMessage: "spec.remediationStagger: Invalid value: 0: must be greater than zero",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			mhc := &MachineHealthCheck{
+				Spec: MachineHealthCheckSpec{
+					Selector: metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"test": "test",
+						},
+					},
+					RemediationStagger: tt.stagger,
+				},
+			}
+
+			if tt.expectErr {
+				err := mhc.ValidateCreate()
+				g.Expect(err).To(MatchError(ContainSubstring(tt.expectMessage)))
+				g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed())
+			} else {
+				g.Expect(mhc.ValidateCreate()).To(Succeed())
+				g.Expect(mhc.ValidateUpdate(mhc)).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestMachineHealthCheckRemediationSchedule(t *testing.T) {
+	tests := []struct {
+		name          string
+		schedule      *Schedule
+		expectErr     bool
+		expectMessage string
+	}{
+		{
+			name:      "when the remediationSchedule is not given",
+			schedule:  nil,
+			expectErr: false,
+		},
+		{
+			name: "when the remediationSchedule has a window spanning midnight",
+			schedule: &Schedule{
+				TimeZone: "Europe/Berlin",
+				Windows:  []TimeWindow{{Start: "23:30", End: "01:00"}},
+			},
+			expectErr: false,
+		},
+		{
+			name: "when the remediationSchedule has an unknown time zone",
+			schedule: &Schedule{
+				TimeZone: "Mars/Olympus_Mons",
+				Windows:  []TimeWindow{{Start: "01:00", End: "03:00"}},
+			},
+			expectErr:     true,
+			expectMessage: "spec.remediationSchedule.timeZone: Invalid value: \"Mars/Olympus_Mons\": must be an IANA time zone name",
+		},
+		{
+			name: "when the remediationSchedule has a malformed window",
+			schedule: &Schedule{
+				Windows: []TimeWindow{{Start: "1am", End: "03:00"}},
+			},
+			expectErr:     true,
+			expectMessage: "spec.remediationSchedule.windows[0].start: Invalid value: \"1am\": must be a time of day as HH:MM",
+		},
+		{
+			name: "when the remediationSchedule has an empty window",
+			schedule: &Schedule{
+				Windows: []TimeWindow{{Start: "03:00", End: "03:00"}},
+			},
+			expectErr:     true,
+			expectMessage: "spec.remediationSchedule.windows[0].end: Invalid value: \"03:00\": must be different from start",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			mhc := &MachineHealthCheck{
+				Spec: MachineHealthCheckSpec{
+					Selector: metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"test": "test",
+						},
+					},
+					RemediationSchedule: tt.schedule,
+				},
+			}
+
+			if tt.expectErr {
+				err := mhc.ValidateCreate()
+				g.Expect(err).To(MatchError(ContainSubstring(tt.expectMessage)))
+				g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed())
+			} else {
+				g.Expect(mhc.ValidateCreate()).To(Succeed())
+				g.Expect(mhc.ValidateUpdate(mhc)).To(Succeed())
+			}
+		})
+	}
+}
+
 func TestMachineHealthCheckMaxUnhealthy(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -260,6 +569,320 @@ func TestMachineHealthCheckMaxUnhealthy(t *testing.T) {
 	}
 }
 
+func TestMachineHealthCheckUnhealthyRange(t *testing.T) {
+	maxUnhealthy := intstr.FromInt(3)
+	defaultMaxUnhealthy := intstr.FromString("100%")
+	unhealthyRange := "[1-3]"
+
+	tests := []struct {
+		name                 string
+		maxUnhealthy         *intstr.IntOrString
+		unhealthyRange       *string
+		expectErr            bool
+		expectedMaxUnhealthy *intstr.IntOrString
+	}{
+		{
+			name:           "when both maxUnhealthy and unhealthyRange are set",
+			maxUnhealthy:   &maxUnhealthy,
+			unhealthyRange: &unhealthyRange,
+			expectErr:      true,
+		},
+		{
+			name:                 "when only unhealthyRange is set",
+			unhealthyRange:       &unhealthyRange,
+			expectErr:            false,
+			expectedMaxUnhealthy: nil,
+		},
+		{
+			name:                 "when neither maxUnhealthy nor unhealthyRange are set",
+			expectErr:            false,
+			expectedMaxUnhealthy: &defaultMaxUnhealthy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			mhc := &MachineHealthCheck{
+				Spec: MachineHealthCheckSpec{
+					MaxUnhealthy:   tt.maxUnhealthy,
+					UnhealthyRange: tt.unhealthyRange,
+					Selector: metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"test": "test",
+						},
+					},
+				},
+			}
+			mhc.Default()
+
+			if tt.expectErr {
+				g.Expect(mhc.ValidateCreate()).NotTo(Succeed())
+				return
+			}
+			g.Expect(mhc.ValidateCreate()).To(Succeed())
+			g.Expect(mhc.Spec.MaxUnhealthy).To(Equal(tt.expectedMaxUnhealthy))
+		})
+	}
+
+	t.Run("when both were set before this was enforced", func(t *testing.T) {
+		g := NewWithT(t)
+
+		mhc := &MachineHealthCheck{
+			Spec: MachineHealthCheckSpec{
+				MaxUnhealthy:   &maxUnhealthy,
+				UnhealthyRange: &unhealthyRange,
+				Selector: metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test": "test",
+					},
+				},
+			},
+		}
+		g.Expect(mhc.ValidateUpdate(mhc.DeepCopy())).To(Succeed())
+	})
+}
+
+func TestMachineHealthCheckMinHealthy(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     intstr.IntOrString
+		expectErr bool
+	}{
+		{
+			name:      "when the value is an integer",
+			value:     intstr.Parse("10"),
+			expectErr: false,
+		},
+		{
+			name:      "when the value is a percentage",
+			value:     intstr.Parse("10%"),
+			expectErr: false,
+		},
+		{
+			name:      "when the value is a random string",
+			value:     intstr.Parse("abcdef"),
+			expectErr: true,
+		},
+		{
+			name:      "when the value stringified integer",
+			value:     intstr.FromString("10"),
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		g := NewWithT(t)
+
+		minHealthy := tt.value
+		mhc := &MachineHealthCheck{
+			Spec: MachineHealthCheckSpec{
+				MinHealthy: &minHealthy,
+				Selector: metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test": "test",
+					},
+				},
+			},
+		}
+
+		if tt.expectErr {
+			g.Expect(mhc.ValidateCreate()).NotTo(Succeed())
+			g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed())
+		} else {
+			g.Expect(mhc.ValidateCreate()).To(Succeed())
+			g.Expect(mhc.ValidateUpdate(mhc)).To(Succeed())
+		}
+	}
+}
+
+func TestMachineHealthCheckClusterOutageThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     intstr.IntOrString
+		expectErr bool
+	}{
+		{
+			name:      "when the value is an integer",
+			value:     intstr.Parse("10"),
+			expectErr: false,
+		},
+		{
+			name:      "when the value is a percentage",
+			value:     intstr.Parse("10%"),
+			expectErr: false,
+		},
+		{
+			name:      "when the value is a random string",
+			value:     intstr.Parse("abcdef"),
+			expectErr: true,
+		},
+		{
+			name:      "when the value stringified integer",
+			value:     intstr.FromString("10"),
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		g := NewWithT(t)
+
+		clusterOutageThreshold := tt.value
+		mhc := &MachineHealthCheck{
+			Spec: MachineHealthCheckSpec{
+				ClusterOutageThreshold: &clusterOutageThreshold,
+				Selector: metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test": "test",
+					},
+				},
+			},
+		}
+
+		if tt.expectErr {
+			g.Expect(mhc.ValidateCreate()).NotTo(Succeed())
+			g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed())
+		} else {
+			g.Expect(mhc.ValidateCreate()).To(Succeed())
+			g.Expect(mhc.ValidateUpdate(mhc)).To(Succeed())
+		}
+	}
+}
+
+func TestMachineHealthCheckStaleNodeStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		timeout   *metav1.Duration
+		threshold intstr.IntOrString
+		expectErr bool
+	}{
+		{
+			name:      "when the threshold is a percentage",
+			timeout:   &metav1.Duration{Duration: 10 * time.Minute},
+			threshold: intstr.Parse("50%"),
+			expectErr: false,
+		},
+		{
+			name:      "when the threshold is a random string",
+			timeout:   &metav1.Duration{Duration: 10 * time.Minute},
+			threshold: intstr.Parse("abcdef"),
+			expectErr: true,
+		},
+		{
+			name:      "when the timeout is 0",
+			timeout:   &metav1.Duration{Duration: 0},
+			threshold: intstr.Parse("2"),
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		g := NewWithT(t)
+
+		staleNodeStatusThreshold := tt.threshold
+		mhc := &MachineHealthCheck{
+			Spec: MachineHealthCheckSpec{
+				StaleNodeStatusTimeout:   tt.timeout,
+				StaleNodeStatusThreshold: &staleNodeStatusThreshold,
+				Selector: metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test": "test",
+					},
+				},
+			},
+		}
+
+		if tt.expectErr {
+			g.Expect(mhc.ValidateCreate()).NotTo(Succeed())
+			g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed())
+		} else {
+			g.Expect(mhc.ValidateCreate()).To(Succeed())
+			g.Expect(mhc.ValidateUpdate(mhc)).To(Succeed())
+		}
+	}
+}
+
+func TestMachineHealthCheckRemediationStrategy(t *testing.T) {
+	tests := []struct {
+		name                string
+		strategy            *RemediationStrategy
+		remediationTemplate *corev1.ObjectReference
+		expectErr           bool
+	}{
+		{
+			name:      "when the strategy is not set",
+			strategy:  nil,
+			expectErr: false,
+		},
+		{
+			name:      "when the strategy is Immediate",
+			strategy:  &RemediationStrategy{Type: RemediationStrategyImmediate},
+			expectErr: false,
+		},
+		{
+			name: "when the strategy is CordonAndWait with a grace period",
+			strategy: &RemediationStrategy{
+				Type:        RemediationStrategyCordonAndWait,
+				GracePeriod: &metav1.Duration{Duration: 5 * time.Minute},
+			},
+			expectErr: false,
+		},
+		{
+			name:      "when the strategy is CordonAndWait without a grace period",
+			strategy:  &RemediationStrategy{Type: RemediationStrategyCordonAndWait},
+			expectErr: true,
+		},
+		{
+			name: "when the strategy is CordonAndWait with a zero grace period",
+			strategy: &RemediationStrategy{
+				Type:        RemediationStrategyCordonAndWait,
+				GracePeriod: &metav1.Duration{},
+			},
+			expectErr: true,
+		},
+		{
+			name:      "when the strategy is AnnotateOnly",
+			strategy:  &RemediationStrategy{Type: RemediationStrategyAnnotateOnly},
+			expectErr: false,
+		},
+		{
+			name:     "when the strategy is AnnotateOnly with a remediation template",
+			strategy: &RemediationStrategy{Type: RemediationStrategyAnnotateOnly},
+			remediationTemplate: &corev1.ObjectReference{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+				Kind:       "InfraRemediationTemplate",
+				Name:       "remediation-template",
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		g := NewWithT(t)
+
+		mhc := &MachineHealthCheck{
+			Spec: MachineHealthCheckSpec{
+				RemediationStrategy: tt.strategy,
+				RemediationTemplate: tt.remediationTemplate,
+				Selector: metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test": "test",
+					},
+				},
+			},
+		}
+
+		if tt.expectErr {
+			g.Expect(mhc.ValidateCreate()).NotTo(Succeed())
+			g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed())
+		} else {
+			g.Expect(mhc.ValidateCreate()).To(Succeed())
+			g.Expect(mhc.ValidateUpdate(mhc)).To(Succeed())
+		}
+	}
+}
+
 func TestMachineHealthCheckSelectorValidation(t *testing.T) {
 	g := NewWithT(t)
 	mhc := &MachineHealthCheck{}
@@ -290,3 +913,147 @@ func TestMachineHealthCheckClusterNameSelectorValidation(t *testing.T) {
 	delete(mhc.Spec.Selector.MatchLabels, ClusterLabelName)
 	g.Expect(mhc.validate(nil)).To(Succeed())
 }
+
+func TestMachineHealthCheckOverlappingSelectorsWarning(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(AddToScheme(scheme)).To(Succeed())
+	decoder, err := admission.NewDecoder(scheme)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	newMHC := func(name, clusterName string, matchLabels map[string]string) *MachineHealthCheck {
+		return &MachineHealthCheck{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: GroupVersion.String(),
+				Kind:       "MachineHealthCheck",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: MachineHealthCheckSpec{
+				ClusterName: clusterName,
+				Selector:    metav1.LabelSelector{MatchLabels: matchLabels},
+				UnhealthyConditions: []UnhealthyCondition{
+					{
+						Type:    corev1.NodeReady,
+						Status:  corev1.ConditionFalse,
+						Timeout: metav1.Duration{Duration: 5 * time.Minute},
+					},
+				},
+			},
+		}
+	}
+	// Matches a subset of the same machines.
+	overlapping := newMHC("overlapping", "test-cluster", map[string]string{"nodepool": "a", "role": "worker"})
+	// Matches a different node pool.
+	disjoint := newMHC("disjoint", "test-cluster", map[string]string{"nodepool": "b"})
+	// Targets another cluster.
+	otherCluster := newMHC("other-cluster", "other-cluster", map[string]string{"nodepool": "a"})
+
+	v := newMachineHealthCheckValidator(fake.NewClientBuilder().WithScheme(scheme).WithObjects(overlapping, disjoint, otherCluster).Build())
+	g.Expect(v.InjectDecoder(decoder)).To(Succeed())
+
+	request := func(operation admissionv1.Operation, mhc, old *MachineHealthCheck) admission.Request {
+		req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: operation}}
+		raw, err := json.Marshal(mhc)
+		g.Expect(err).NotTo(HaveOccurred())
+		req.Object.Raw = raw
+		if old != nil {
+			raw, err := json.Marshal(old)
+			g.Expect(err).NotTo(HaveOccurred())
+			req.OldObject.Raw = raw
+		}
+		return req
+	}
+
+	// The overlap is only a warning, the MachineHealthCheck is still allowed.
+	mhc := newMHC("mhc", "test-cluster", map[string]string{"nodepool": "a"})
+	resp := v.Handle(context.Background(), request(admissionv1.Create, mhc, nil))
+	g.Expect(resp.Allowed).To(BeTrue())
+	g.Expect(resp.Warnings).To(HaveLen(1))
+	g.Expect(resp.Warnings[0]).To(ContainSubstring("MachineHealthCheck mhc selector overlaps with MachineHealthCheck(s) overlapping"))
+
+	// The selector of an updated MachineHealthCheck no longer overlapping is allowed without warnings.
+	updated := newMHC("mhc", "test-cluster", map[string]string{"nodepool": "c"})
+	resp = v.Handle(context.Background(), request(admissionv1.Update, updated, mhc))
+	g.Expect(resp.Allowed).To(BeTrue())
+	g.Expect(resp.Warnings).To(BeEmpty())
+
+	// An invalid MachineHealthCheck is denied by its validation, without warnings.
+	invalid := newMHC("mhc", "test-cluster", map[string]string{"nodepool": "a"})
+	invalid.Spec.MaxUnhealthy = &intstr.IntOrString{Type: intstr.String, StrVal: "not-a-percentage"}
+	resp = v.Handle(context.Background(), request(admissionv1.Create, invalid, nil))
+	g.Expect(resp.Allowed).To(BeFalse())
+	g.Expect(resp.Warnings).To(BeEmpty())
+}
+
+func TestMachineHealthCheckSelectorsOverlap(t *testing.T) {
+	testCases := []struct {
+		name     string
+		a        metav1.LabelSelector
+		b        metav1.LabelSelector
+		expected bool
+	}{
+		{
+			name:     "selectors are equal",
+			a:        metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+			b:        metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+			expected: true,
+		},
+		{
+			name:     "selectors match different labels",
+			a:        metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+			b:        metav1.LabelSelector{MatchLabels: map[string]string{"nodepool": "a"}},
+			expected: true,
+		},
+		{
+			name:     "selectors match different values of the same label",
+			a:        metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+			b:        metav1.LabelSelector{MatchLabels: map[string]string{"foo": "baz"}},
+			expected: false,
+		},
+		{
+			name: "selector excludes the value matched by the other selector",
+			a:    metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+			b: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "foo", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"bar"}},
+			}},
+			expected: false,
+		},
+		{
+			name: "selectors share a value",
+			a: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "foo", Operator: metav1.LabelSelectorOpIn, Values: []string{"bar", "baz"}},
+			}},
+			b: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "foo", Operator: metav1.LabelSelectorOpIn, Values: []string{"baz", "qux"}},
+			}},
+			expected: true,
+		},
+		{
+			name: "selector requires the label to be absent",
+			a:    metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+			b: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "foo", Operator: metav1.LabelSelectorOpDoesNotExist},
+			}},
+			expected: false,
+		},
+		{
+			name:     "selector is empty",
+			a:        metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+			b:        metav1.LabelSelector{},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(selectorsOverlap(tc.a, tc.b)).To(Equal(tc.expected))
+			g.Expect(selectorsOverlap(tc.b, tc.a)).To(Equal(tc.expected))
+		})
+	}
+}
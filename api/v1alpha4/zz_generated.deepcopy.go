@@ -570,13 +570,25 @@ func (in *MachineHealthCheckSpec) DeepCopyInto(out *MachineHealthCheckSpec) {
 	if in.UnhealthyConditions != nil {
 		in, out := &in.UnhealthyConditions, &out.UnhealthyConditions
 		*out = make([]UnhealthyCondition, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.MaxUnhealthy != nil {
 		in, out := &in.MaxUnhealthy, &out.MaxUnhealthy
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.MinHealthy != nil {
+		in, out := &in.MinHealthy, &out.MinHealthy
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.ClusterOutageThreshold != nil {
+		in, out := &in.ClusterOutageThreshold, &out.ClusterOutageThreshold
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
 	if in.UnhealthyRange != nil {
 		in, out := &in.UnhealthyRange, &out.UnhealthyRange
 		*out = new(string)
@@ -592,6 +604,76 @@ func (in *MachineHealthCheckSpec) DeepCopyInto(out *MachineHealthCheckSpec) {
 		*out = new(v1.ObjectReference)
 		**out = **in
 	}
+	if in.RemediationStrategy != nil {
+		in, out := &in.RemediationStrategy, &out.RemediationStrategy
+		*out = new(RemediationStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailureDomainAware != nil {
+		in, out := &in.FailureDomainAware, &out.FailureDomainAware
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PauseDuringUpgrade != nil {
+		in, out := &in.PauseDuringUpgrade, &out.PauseDuringUpgrade
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DeferRemediationOnBlockingPDB != nil {
+		in, out := &in.DeferRemediationOnBlockingPDB, &out.DeferRemediationOnBlockingPDB
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RemediateOnFailureReason != nil {
+		in, out := &in.RemediateOnFailureReason, &out.RemediateOnFailureReason
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxTotalRemediations != nil {
+		in, out := &in.MaxTotalRemediations, &out.MaxTotalRemediations
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RequireAPIUnreachable != nil {
+		in, out := &in.RequireAPIUnreachable, &out.RequireAPIUnreachable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.APIUnreachableTimeout != nil {
+		in, out := &in.APIUnreachableTimeout, &out.APIUnreachableTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RemediationSchedule != nil {
+		in, out := &in.RemediationSchedule, &out.RemediationSchedule
+		*out = new(Schedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UnhealthyWeightThreshold != nil {
+		in, out := &in.UnhealthyWeightThreshold, &out.UnhealthyWeightThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ReplacementTimeout != nil {
+		in, out := &in.ReplacementTimeout, &out.ReplacementTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.StaleNodeStatusTimeout != nil {
+		in, out := &in.StaleNodeStatusTimeout, &out.StaleNodeStatusTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.StaleNodeStatusThreshold != nil {
+		in, out := &in.StaleNodeStatusThreshold, &out.StaleNodeStatusThreshold
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.RemediationStagger != nil {
+		in, out := &in.RemediationStagger, &out.RemediationStagger
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineHealthCheckSpec.
@@ -607,6 +689,18 @@ func (in *MachineHealthCheckSpec) DeepCopy() *MachineHealthCheckSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineHealthCheckStatus) DeepCopyInto(out *MachineHealthCheckStatus) {
 	*out = *in
+	if in.LastUpdated != nil {
+		in, out := &in.LastUpdated, &out.LastUpdated
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRemediationTime != nil {
+		in, out := &in.LastRemediationTime, &out.LastRemediationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulReconcile != nil {
+		in, out := &in.LastSuccessfulReconcile, &out.LastSuccessfulReconcile
+		*out = (*in).DeepCopy()
+	}
 	if in.Targets != nil {
 		in, out := &in.Targets, &out.Targets
 		*out = make([]string, len(*in))
@@ -953,10 +1047,70 @@ func (in *ObjectMeta) DeepCopy() *ObjectMeta {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationStrategy) DeepCopyInto(out *RemediationStrategy) {
+	*out = *in
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationStrategy.
+func (in *RemediationStrategy) DeepCopy() *RemediationStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Schedule) DeepCopyInto(out *Schedule) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]TimeWindow, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Schedule.
+func (in *Schedule) DeepCopy() *Schedule {
+	if in == nil {
+		return nil
+	}
+	out := new(Schedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimeWindow) DeepCopyInto(out *TimeWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeWindow.
+func (in *TimeWindow) DeepCopy() *TimeWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(TimeWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UnhealthyCondition) DeepCopyInto(out *UnhealthyCondition) {
 	*out = *in
 	out.Timeout = in.Timeout
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnhealthyCondition.
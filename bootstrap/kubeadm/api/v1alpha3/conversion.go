@@ -19,19 +19,40 @@ package v1alpha3
 import (
 	apiconversion "k8s.io/apimachinery/pkg/conversion"
 	kubeadmbootstrapv1alpha4 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 // ConvertTo converts this KubeadmConfig to the Hub version (v1alpha4).
 func (src *KubeadmConfig) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*kubeadmbootstrapv1alpha4.KubeadmConfig)
-	return Convert_v1alpha3_KubeadmConfig_To_v1alpha4_KubeadmConfig(src, dst, nil)
+
+	if err := Convert_v1alpha3_KubeadmConfig_To_v1alpha4_KubeadmConfig(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &kubeadmbootstrapv1alpha4.KubeadmConfig{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	RestoreKubeadmConfigSpec(&restored.Spec, &dst.Spec)
+	dst.Status.RenderedFormat = restored.Status.RenderedFormat
+
+	return nil
 }
 
 // ConvertFrom converts from the KubeadmConfig Hub version (v1alpha4) to this version.
 func (dst *KubeadmConfig) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*kubeadmbootstrapv1alpha4.KubeadmConfig)
-	return Convert_v1alpha4_KubeadmConfig_To_v1alpha3_KubeadmConfig(src, dst, nil)
+
+	if err := Convert_v1alpha4_KubeadmConfig_To_v1alpha3_KubeadmConfig(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	return utilconversion.MarshalData(src, dst)
 }
 
 // ConvertTo converts this KubeadmConfigList to the Hub version (v1alpha4).
@@ -49,13 +70,32 @@ func (dst *KubeadmConfigList) ConvertFrom(srcRaw conversion.Hub) error {
 // ConvertTo converts this KubeadmConfigTemplate to the Hub version (v1alpha4).
 func (src *KubeadmConfigTemplate) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*kubeadmbootstrapv1alpha4.KubeadmConfigTemplate)
-	return Convert_v1alpha3_KubeadmConfigTemplate_To_v1alpha4_KubeadmConfigTemplate(src, dst, nil)
+
+	if err := Convert_v1alpha3_KubeadmConfigTemplate_To_v1alpha4_KubeadmConfigTemplate(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &kubeadmbootstrapv1alpha4.KubeadmConfigTemplate{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	RestoreKubeadmConfigSpec(&restored.Spec.Template.Spec, &dst.Spec.Template.Spec)
+
+	return nil
 }
 
 // ConvertFrom converts from the KubeadmConfigTemplate Hub version (v1alpha4) to this version.
 func (dst *KubeadmConfigTemplate) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*kubeadmbootstrapv1alpha4.KubeadmConfigTemplate)
-	return Convert_v1alpha4_KubeadmConfigTemplate_To_v1alpha3_KubeadmConfigTemplate(src, dst, nil)
+
+	if err := Convert_v1alpha4_KubeadmConfigTemplate_To_v1alpha3_KubeadmConfigTemplate(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	return utilconversion.MarshalData(src, dst)
 }
 
 // ConvertTo converts this KubeadmConfigTemplateList to the Hub version (v1alpha3).
@@ -75,3 +115,149 @@ func Convert_v1alpha3_KubeadmConfigStatus_To_v1alpha4_KubeadmConfigStatus(in *Ku
 	// KubeadmConfigStatus.BootstrapData has been removed in v1alpha4 because its content has been moved to the bootstrap data secret, value will be lost during conversion.
 	return autoConvert_v1alpha3_KubeadmConfigStatus_To_v1alpha4_KubeadmConfigStatus(in, out, s)
 }
+
+func Convert_v1alpha4_KubeadmConfigStatus_To_v1alpha3_KubeadmConfigStatus(in *kubeadmbootstrapv1alpha4.KubeadmConfigStatus, out *KubeadmConfigStatus, s apiconversion.Scope) error { //nolint
+	// KubeadmConfigStatus.RenderedFormat does not exist in v1alpha3, the value is restored from annotations.
+	return autoConvert_v1alpha4_KubeadmConfigStatus_To_v1alpha3_KubeadmConfigStatus(in, out, s)
+}
+
+// RestoreKubeadmConfigSpec restores the KubeadmConfigSpec fields which do not exist in v1alpha3
+// from the Hub data preserved on down-conversion.
+func RestoreKubeadmConfigSpec(restored *kubeadmbootstrapv1alpha4.KubeadmConfigSpec, dst *kubeadmbootstrapv1alpha4.KubeadmConfigSpec) {
+	dst.GracefulShutdown = restored.GracefulShutdown
+	dst.WaitForNodeReady = restored.WaitForNodeReady
+	dst.InstallCrictlConfig = restored.InstallCrictlConfig
+	dst.PrePullImages = restored.PrePullImages
+	dst.PersistentJournal = restored.PersistentJournal
+	dst.LoginBanner = restored.LoginBanner
+	dst.AuditPolicy = restored.AuditPolicy
+	dst.AuditLogConfig = restored.AuditLogConfig
+	dst.GrowRootFilesystem = restored.GrowRootFilesystem
+	dst.NetworkConfig = restored.NetworkConfig
+	dst.SensitiveFields = restored.SensitiveFields
+	dst.NodeLocalDNS = restored.NodeLocalDNS
+	dst.SystemdTimers = restored.SystemdTimers
+	dst.Packages = restored.Packages
+	dst.SandboxImage = restored.SandboxImage
+	dst.RemountOptions = restored.RemountOptions
+	dst.IgnorePreflightErrors = restored.IgnorePreflightErrors
+	dst.EtcdDataDir = restored.EtcdDataDir
+	dst.PublishConfigTo = restored.PublishConfigTo
+	dst.RotateKubeletServerCertificate = restored.RotateKubeletServerCertificate
+	dst.SSHHardening = restored.SSHHardening
+	dst.BootstrapTokenTTL = restored.BootstrapTokenTTL
+	dst.BootstrapTokenUsages = restored.BootstrapTokenUsages
+	dst.CloudProvider = restored.CloudProvider
+	dst.SeccompDefault = restored.SeccompDefault
+	dst.UserNamespaces = restored.UserNamespaces
+	dst.FIPSMode = restored.FIPSMode
+	dst.ReservedResources = restored.ReservedResources
+	dst.Kdump = restored.Kdump
+	dst.InstallNodeProblemDetector = restored.InstallNodeProblemDetector
+	dst.StaticPods = restored.StaticPods
+	dst.RuntimeHandlers = restored.RuntimeHandlers
+	dst.Timezone = restored.Timezone
+	dst.MaxPods = restored.MaxPods
+	dst.ImageGC = restored.ImageGC
+	dst.UploadBootLogsTo = restored.UploadBootLogsTo
+	dst.StartupTaint = restored.StartupTaint
+	dst.KubeletRootDir = restored.KubeletRootDir
+	dst.HostDNS = restored.HostDNS
+	dst.DNSManagement = restored.DNSManagement
+	dst.ContainerdMetrics = restored.ContainerdMetrics
+	dst.CgroupV2 = restored.CgroupV2
+	dst.FailSwapOn = restored.FailSwapOn
+	dst.AuditdRules = restored.AuditdRules
+	dst.SystemLimits = restored.SystemLimits
+	dst.KubeletServingCertSANs = restored.KubeletServingCertSANs
+	dst.ContainerdPull = restored.ContainerdPull
+
+	// Restore the File sources which could not be represented in v1alpha3; this is done only
+	// when the first source has not been changed in the meantime.
+	// File.Template is restored as long as the file still has the same path.
+	for i := range dst.Files {
+		if i >= len(restored.Files) {
+			continue
+		}
+		if dst.Files[i].Path == restored.Files[i].Path {
+			dst.Files[i].Template = restored.Files[i].Template
+		}
+		if len(restored.Files[i].ContentFrom) <= 1 {
+			continue
+		}
+		if len(dst.Files[i].ContentFrom) == 1 && dst.Files[i].ContentFrom[0] == restored.Files[i].ContentFrom[0] {
+			dst.Files[i].ContentFrom = restored.Files[i].ContentFrom
+		}
+	}
+
+	// Restore the explicit partition numbers and sizes, and the wipe confirmations, as long as the partitions
+	// still refer to the same devices.
+	if restored.DiskSetup != nil && dst.DiskSetup != nil {
+		for i := range dst.DiskSetup.Partitions {
+			if i >= len(restored.DiskSetup.Partitions) || dst.DiskSetup.Partitions[i].Device != restored.DiskSetup.Partitions[i].Device {
+				continue
+			}
+			dst.DiskSetup.Partitions[i].Number = restored.DiskSetup.Partitions[i].Number
+			dst.DiskSetup.Partitions[i].SizeMiB = restored.DiskSetup.Partitions[i].SizeMiB
+			dst.DiskSetup.Partitions[i].ConfirmWipe = restored.DiskSetup.Partitions[i].ConfirmWipe
+		}
+	}
+}
+
+func Convert_v1alpha4_KubeadmConfigSpec_To_v1alpha3_KubeadmConfigSpec(in *kubeadmbootstrapv1alpha4.KubeadmConfigSpec, out *KubeadmConfigSpec, s apiconversion.Scope) error { //nolint
+	// KubeadmConfigSpec.GracefulShutdown, KubeadmConfigSpec.WaitForNodeReady, KubeadmConfigSpec.InstallCrictlConfig,
+	// KubeadmConfigSpec.PrePullImages, KubeadmConfigSpec.PersistentJournal, KubeadmConfigSpec.LoginBanner,
+	// KubeadmConfigSpec.AuditPolicy, KubeadmConfigSpec.AuditLogConfig, KubeadmConfigSpec.GrowRootFilesystem,
+	// KubeadmConfigSpec.NetworkConfig, KubeadmConfigSpec.SensitiveFields, KubeadmConfigSpec.NodeLocalDNS,
+	// KubeadmConfigSpec.SystemdTimers, KubeadmConfigSpec.Packages, KubeadmConfigSpec.SandboxImage,
+	// KubeadmConfigSpec.RemountOptions, KubeadmConfigSpec.IgnorePreflightErrors, KubeadmConfigSpec.EtcdDataDir,
+	// KubeadmConfigSpec.PublishConfigTo, KubeadmConfigSpec.RotateKubeletServerCertificate, KubeadmConfigSpec.SSHHardening,
+	// KubeadmConfigSpec.BootstrapTokenTTL, KubeadmConfigSpec.BootstrapTokenUsages, KubeadmConfigSpec.CloudProvider,
+	// KubeadmConfigSpec.SeccompDefault, KubeadmConfigSpec.UserNamespaces, KubeadmConfigSpec.FIPSMode,
+	// KubeadmConfigSpec.ReservedResources, KubeadmConfigSpec.Kdump, KubeadmConfigSpec.InstallNodeProblemDetector,
+	// KubeadmConfigSpec.StaticPods, KubeadmConfigSpec.RuntimeHandlers, KubeadmConfigSpec.Timezone, KubeadmConfigSpec.MaxPods,
+	// KubeadmConfigSpec.ImageGC, KubeadmConfigSpec.UploadBootLogsTo, KubeadmConfigSpec.StartupTaint,
+	// KubeadmConfigSpec.KubeletRootDir, KubeadmConfigSpec.HostDNS, KubeadmConfigSpec.DNSManagement,
+	// KubeadmConfigSpec.ContainerdMetrics, KubeadmConfigSpec.CgroupV2, KubeadmConfigSpec.FailSwapOn,
+	// KubeadmConfigSpec.AuditdRules, KubeadmConfigSpec.SystemLimits, KubeadmConfigSpec.KubeletServingCertSANs and
+	// KubeadmConfigSpec.ContainerdPull do not exist in v1alpha3, values are restored from annotations.
+	return autoConvert_v1alpha4_KubeadmConfigSpec_To_v1alpha3_KubeadmConfigSpec(in, out, s)
+}
+
+func Convert_v1alpha3_File_To_v1alpha4_File(in *File, out *kubeadmbootstrapv1alpha4.File, s apiconversion.Scope) error { //nolint
+	if err := autoConvert_v1alpha3_File_To_v1alpha4_File(in, out, s); err != nil {
+		return err
+	}
+
+	out.ContentFrom = nil
+	if in.ContentFrom != nil {
+		out.ContentFrom = make([]kubeadmbootstrapv1alpha4.FileSource, 1)
+		if err := Convert_v1alpha3_FileSource_To_v1alpha4_FileSource(in.ContentFrom, &out.ContentFrom[0], s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func Convert_v1alpha4_File_To_v1alpha3_File(in *kubeadmbootstrapv1alpha4.File, out *File, s apiconversion.Scope) error { //nolint
+	if err := autoConvert_v1alpha4_File_To_v1alpha3_File(in, out, s); err != nil {
+		return err
+	}
+
+	// File.ContentFrom supports only one source in v1alpha3, additional sources are restored from annotations.
+	// File.Template does not exist in v1alpha3, values are restored from annotations.
+	out.ContentFrom = nil
+	if len(in.ContentFrom) > 0 {
+		out.ContentFrom = &FileSource{}
+		if err := Convert_v1alpha4_FileSource_To_v1alpha3_FileSource(&in.ContentFrom[0], out.ContentFrom, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func Convert_v1alpha4_Partition_To_v1alpha3_Partition(in *kubeadmbootstrapv1alpha4.Partition, out *Partition, s apiconversion.Scope) error { //nolint
+	// Partition.Number, Partition.SizeMiB and Partition.ConfirmWipe do not exist in v1alpha3, values are restored
+	// from annotations.
+	return autoConvert_v1alpha4_Partition_To_v1alpha3_Partition(in, out, s)
+}
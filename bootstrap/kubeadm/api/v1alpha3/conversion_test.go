@@ -26,6 +26,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	kubeadmv1beta1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
 	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 )
 
@@ -39,15 +40,44 @@ func TestFuzzyConversion(t *testing.T) {
 		Scheme:      scheme,
 		Hub:         &v1alpha4.KubeadmConfig{},
 		Spoke:       &KubeadmConfig{},
-		FuzzerFuncs: []fuzzer.FuzzerFuncs{KubeadmConfigStatusFuzzFuncs},
+		FuzzerFuncs: []fuzzer.FuzzerFuncs{KubeadmConfigStatusFuzzFuncs, fuzzFuncs},
 	}))
 	t.Run("for KubeadmConfigTemplate", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
-		Scheme: scheme,
-		Hub:    &v1alpha4.KubeadmConfigTemplate{},
-		Spoke:  &KubeadmConfigTemplate{},
+		Scheme:      scheme,
+		Hub:         &v1alpha4.KubeadmConfigTemplate{},
+		Spoke:       &KubeadmConfigTemplate{},
+		FuzzerFuncs: []fuzzer.FuzzerFuncs{fuzzFuncs},
 	}))
 }
 
+func fuzzFuncs(_ runtimeserializer.CodecFactory) []interface{} {
+	// This custom function is needed when ConvertTo/ConvertFrom functions
+	// uses the json package to unmarshal the bootstrap token string.
+	//
+	// The Kubeadm v1beta1.BootstrapTokenString type ships with a custom
+	// json string representation, in particular it supplies a customized
+	// UnmarshalJSON function that can return an error if the string
+	// isn't in the correct form.
+	//
+	// This function effectively disables any fuzzing for the token by setting
+	// the values for ID and Secret to working alphanumeric values.
+	return []interface{}{
+		kubeadmBootstrapTokenStringFuzzer,
+		cabpkBootstrapTokenStringFuzzer,
+		rawExtensionFuzzer,
+	}
+}
+
+func kubeadmBootstrapTokenStringFuzzer(in *kubeadmv1beta1.BootstrapTokenString, c fuzz.Continue) {
+	in.ID = "abcdef"
+	in.Secret = "abcdef0123456789"
+}
+
+func cabpkBootstrapTokenStringFuzzer(in *v1alpha4.BootstrapTokenString, c fuzz.Continue) {
+	in.ID = "abcdef"
+	in.Secret = "abcdef0123456789"
+}
+
 func KubeadmConfigStatusFuzzFuncs(_ runtimeserializer.CodecFactory) []interface{} {
 	return []interface{}{
 		KubeadmConfigStatusFuzzer,
@@ -60,3 +90,10 @@ func KubeadmConfigStatusFuzzer(obj *KubeadmConfigStatus, c fuzz.Continue) {
 	// KubeadmConfigStatus.BootstrapData has been removed in v1alpha4, so setting it to nil in order to avoid v1alpha3 --> v1alpha4 --> v1alpha3 round trip errors.
 	obj.BootstrapData = nil
 }
+
+// rawExtensionFuzzer sets raw extensions, such as the audit policy, to a fixed JSON document,
+// as fuzzed documents are not preserved byte for byte through the annotations used by the conversion.
+func rawExtensionFuzzer(in *runtime.RawExtension, c fuzz.Continue) {
+	in.Raw = []byte(`{"apiVersion":"audit.k8s.io/v1","kind":"Policy"}`)
+	in.Object = nil
+}
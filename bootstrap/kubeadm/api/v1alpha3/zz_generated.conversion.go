@@ -48,16 +48,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*File)(nil), (*v1alpha4.File)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha3_File_To_v1alpha4_File(a.(*File), b.(*v1alpha4.File), scope)
-	}); err != nil {
-		return err
-	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha4.File)(nil), (*File)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha4_File_To_v1alpha3_File(a.(*v1alpha4.File), b.(*File), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*FileSource)(nil), (*v1alpha4.FileSource)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_FileSource_To_v1alpha4_FileSource(a.(*FileSource), b.(*v1alpha4.FileSource), scope)
 	}); err != nil {
@@ -103,16 +93,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha4.KubeadmConfigSpec)(nil), (*KubeadmConfigSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha4_KubeadmConfigSpec_To_v1alpha3_KubeadmConfigSpec(a.(*v1alpha4.KubeadmConfigSpec), b.(*KubeadmConfigSpec), scope)
-	}); err != nil {
-		return err
-	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha4.KubeadmConfigStatus)(nil), (*KubeadmConfigStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha4_KubeadmConfigStatus_To_v1alpha3_KubeadmConfigStatus(a.(*v1alpha4.KubeadmConfigStatus), b.(*KubeadmConfigStatus), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*KubeadmConfigTemplate)(nil), (*v1alpha4.KubeadmConfigTemplate)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_KubeadmConfigTemplate_To_v1alpha4_KubeadmConfigTemplate(a.(*KubeadmConfigTemplate), b.(*v1alpha4.KubeadmConfigTemplate), scope)
 	}); err != nil {
@@ -168,11 +148,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha4.Partition)(nil), (*Partition)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha4_Partition_To_v1alpha3_Partition(a.(*v1alpha4.Partition), b.(*Partition), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*SecretFileSource)(nil), (*v1alpha4.SecretFileSource)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_SecretFileSource_To_v1alpha4_SecretFileSource(a.(*SecretFileSource), b.(*v1alpha4.SecretFileSource), scope)
 	}); err != nil {
@@ -193,16 +168,51 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*File)(nil), (*v1alpha4.File)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_File_To_v1alpha4_File(a.(*File), b.(*v1alpha4.File), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*KubeadmConfigStatus)(nil), (*v1alpha4.KubeadmConfigStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha3_KubeadmConfigStatus_To_v1alpha4_KubeadmConfigStatus(a.(*KubeadmConfigStatus), b.(*v1alpha4.KubeadmConfigStatus), scope)
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1alpha4.File)(nil), (*File)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha4_File_To_v1alpha3_File(a.(*v1alpha4.File), b.(*File), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha4.KubeadmConfigSpec)(nil), (*KubeadmConfigSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha4_KubeadmConfigSpec_To_v1alpha3_KubeadmConfigSpec(a.(*v1alpha4.KubeadmConfigSpec), b.(*KubeadmConfigSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha4.KubeadmConfigStatus)(nil), (*KubeadmConfigStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha4_KubeadmConfigStatus_To_v1alpha3_KubeadmConfigStatus(a.(*v1alpha4.KubeadmConfigStatus), b.(*KubeadmConfigStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha4.Partition)(nil), (*Partition)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha4_Partition_To_v1alpha3_Partition(a.(*v1alpha4.Partition), b.(*Partition), scope)
+	}); err != nil {
+		return err
+	}
 	return nil
 }
 
 func autoConvert_v1alpha3_DiskSetup_To_v1alpha4_DiskSetup(in *DiskSetup, out *v1alpha4.DiskSetup, s conversion.Scope) error {
-	out.Partitions = *(*[]v1alpha4.Partition)(unsafe.Pointer(&in.Partitions))
+	if in.Partitions != nil {
+		in, out := &in.Partitions, &out.Partitions
+		*out = make([]v1alpha4.Partition, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha3_Partition_To_v1alpha4_Partition(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Partitions = nil
+	}
 	out.Filesystems = *(*[]v1alpha4.Filesystem)(unsafe.Pointer(&in.Filesystems))
 	return nil
 }
@@ -213,7 +223,17 @@ func Convert_v1alpha3_DiskSetup_To_v1alpha4_DiskSetup(in *DiskSetup, out *v1alph
 }
 
 func autoConvert_v1alpha4_DiskSetup_To_v1alpha3_DiskSetup(in *v1alpha4.DiskSetup, out *DiskSetup, s conversion.Scope) error {
-	out.Partitions = *(*[]Partition)(unsafe.Pointer(&in.Partitions))
+	if in.Partitions != nil {
+		in, out := &in.Partitions, &out.Partitions
+		*out = make([]Partition, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha4_Partition_To_v1alpha3_Partition(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Partitions = nil
+	}
 	out.Filesystems = *(*[]Filesystem)(unsafe.Pointer(&in.Filesystems))
 	return nil
 }
@@ -229,30 +249,21 @@ func autoConvert_v1alpha3_File_To_v1alpha4_File(in *File, out *v1alpha4.File, s
 	out.Permissions = in.Permissions
 	out.Encoding = v1alpha4.Encoding(in.Encoding)
 	out.Content = in.Content
-	out.ContentFrom = (*v1alpha4.FileSource)(unsafe.Pointer(in.ContentFrom))
+	// WARNING: in.ContentFrom requires manual conversion: inconvertible types (*sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3.FileSource vs []sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4.FileSource)
 	return nil
 }
 
-// Convert_v1alpha3_File_To_v1alpha4_File is an autogenerated conversion function.
-func Convert_v1alpha3_File_To_v1alpha4_File(in *File, out *v1alpha4.File, s conversion.Scope) error {
-	return autoConvert_v1alpha3_File_To_v1alpha4_File(in, out, s)
-}
-
 func autoConvert_v1alpha4_File_To_v1alpha3_File(in *v1alpha4.File, out *File, s conversion.Scope) error {
 	out.Path = in.Path
 	out.Owner = in.Owner
 	out.Permissions = in.Permissions
 	out.Encoding = Encoding(in.Encoding)
 	out.Content = in.Content
-	out.ContentFrom = (*FileSource)(unsafe.Pointer(in.ContentFrom))
+	// WARNING: in.ContentFrom requires manual conversion: inconvertible types ([]sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4.FileSource vs *sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3.FileSource)
+	// WARNING: in.Template requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha4_File_To_v1alpha3_File is an autogenerated conversion function.
-func Convert_v1alpha4_File_To_v1alpha3_File(in *v1alpha4.File, out *File, s conversion.Scope) error {
-	return autoConvert_v1alpha4_File_To_v1alpha3_File(in, out, s)
-}
-
 func autoConvert_v1alpha3_FileSource_To_v1alpha4_FileSource(in *FileSource, out *v1alpha4.FileSource, s conversion.Scope) error {
 	if err := Convert_v1alpha3_SecretFileSource_To_v1alpha4_SecretFileSource(&in.Secret, &out.Secret, s); err != nil {
 		return err
@@ -387,8 +398,26 @@ func autoConvert_v1alpha3_KubeadmConfigSpec_To_v1alpha4_KubeadmConfigSpec(in *Ku
 	out.ClusterConfiguration = (*v1alpha4.ClusterConfiguration)(unsafe.Pointer(in.ClusterConfiguration))
 	out.InitConfiguration = (*v1alpha4.InitConfiguration)(unsafe.Pointer(in.InitConfiguration))
 	out.JoinConfiguration = (*v1alpha4.JoinConfiguration)(unsafe.Pointer(in.JoinConfiguration))
-	out.Files = *(*[]v1alpha4.File)(unsafe.Pointer(&in.Files))
-	out.DiskSetup = (*v1alpha4.DiskSetup)(unsafe.Pointer(in.DiskSetup))
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]v1alpha4.File, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha3_File_To_v1alpha4_File(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Files = nil
+	}
+	if in.DiskSetup != nil {
+		in, out := &in.DiskSetup, &out.DiskSetup
+		*out = new(v1alpha4.DiskSetup)
+		if err := Convert_v1alpha3_DiskSetup_To_v1alpha4_DiskSetup(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.DiskSetup = nil
+	}
 	out.Mounts = *(*[]v1alpha4.MountPoints)(unsafe.Pointer(&in.Mounts))
 	out.PreKubeadmCommands = *(*[]string)(unsafe.Pointer(&in.PreKubeadmCommands))
 	out.PostKubeadmCommands = *(*[]string)(unsafe.Pointer(&in.PostKubeadmCommands))
@@ -409,8 +438,26 @@ func autoConvert_v1alpha4_KubeadmConfigSpec_To_v1alpha3_KubeadmConfigSpec(in *v1
 	out.ClusterConfiguration = (*v1beta1.ClusterConfiguration)(unsafe.Pointer(in.ClusterConfiguration))
 	out.InitConfiguration = (*v1beta1.InitConfiguration)(unsafe.Pointer(in.InitConfiguration))
 	out.JoinConfiguration = (*v1beta1.JoinConfiguration)(unsafe.Pointer(in.JoinConfiguration))
-	out.Files = *(*[]File)(unsafe.Pointer(&in.Files))
-	out.DiskSetup = (*DiskSetup)(unsafe.Pointer(in.DiskSetup))
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]File, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha4_File_To_v1alpha3_File(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Files = nil
+	}
+	if in.DiskSetup != nil {
+		in, out := &in.DiskSetup, &out.DiskSetup
+		*out = new(DiskSetup)
+		if err := Convert_v1alpha4_DiskSetup_To_v1alpha3_DiskSetup(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.DiskSetup = nil
+	}
 	out.Mounts = *(*[]MountPoints)(unsafe.Pointer(&in.Mounts))
 	out.PreKubeadmCommands = *(*[]string)(unsafe.Pointer(&in.PreKubeadmCommands))
 	out.PostKubeadmCommands = *(*[]string)(unsafe.Pointer(&in.PostKubeadmCommands))
@@ -419,14 +466,56 @@ func autoConvert_v1alpha4_KubeadmConfigSpec_To_v1alpha3_KubeadmConfigSpec(in *v1
 	out.Format = Format(in.Format)
 	out.Verbosity = (*int32)(unsafe.Pointer(in.Verbosity))
 	out.UseExperimentalRetryJoin = in.UseExperimentalRetryJoin
+	// WARNING: in.GracefulShutdown requires manual conversion: does not exist in peer-type
+	// WARNING: in.WaitForNodeReady requires manual conversion: does not exist in peer-type
+	// WARNING: in.InstallCrictlConfig requires manual conversion: does not exist in peer-type
+	// WARNING: in.PrePullImages requires manual conversion: does not exist in peer-type
+	// WARNING: in.PersistentJournal requires manual conversion: does not exist in peer-type
+	// WARNING: in.LoginBanner requires manual conversion: does not exist in peer-type
+	// WARNING: in.AuditPolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.AuditLogConfig requires manual conversion: does not exist in peer-type
+	// WARNING: in.GrowRootFilesystem requires manual conversion: does not exist in peer-type
+	// WARNING: in.NetworkConfig requires manual conversion: does not exist in peer-type
+	// WARNING: in.SensitiveFields requires manual conversion: does not exist in peer-type
+	// WARNING: in.NodeLocalDNS requires manual conversion: does not exist in peer-type
+	// WARNING: in.SystemdTimers requires manual conversion: does not exist in peer-type
+	// WARNING: in.Packages requires manual conversion: does not exist in peer-type
+	// WARNING: in.SandboxImage requires manual conversion: does not exist in peer-type
+	// WARNING: in.RemountOptions requires manual conversion: does not exist in peer-type
+	// WARNING: in.IgnorePreflightErrors requires manual conversion: does not exist in peer-type
+	// WARNING: in.EtcdDataDir requires manual conversion: does not exist in peer-type
+	// WARNING: in.PublishConfigTo requires manual conversion: does not exist in peer-type
+	// WARNING: in.RotateKubeletServerCertificate requires manual conversion: does not exist in peer-type
+	// WARNING: in.SSHHardening requires manual conversion: does not exist in peer-type
+	// WARNING: in.BootstrapTokenTTL requires manual conversion: does not exist in peer-type
+	// WARNING: in.BootstrapTokenUsages requires manual conversion: does not exist in peer-type
+	// WARNING: in.CloudProvider requires manual conversion: does not exist in peer-type
+	// WARNING: in.SeccompDefault requires manual conversion: does not exist in peer-type
+	// WARNING: in.UserNamespaces requires manual conversion: does not exist in peer-type
+	// WARNING: in.FIPSMode requires manual conversion: does not exist in peer-type
+	// WARNING: in.ReservedResources requires manual conversion: does not exist in peer-type
+	// WARNING: in.Kdump requires manual conversion: does not exist in peer-type
+	// WARNING: in.InstallNodeProblemDetector requires manual conversion: does not exist in peer-type
+	// WARNING: in.StaticPods requires manual conversion: does not exist in peer-type
+	// WARNING: in.RuntimeHandlers requires manual conversion: does not exist in peer-type
+	// WARNING: in.Timezone requires manual conversion: does not exist in peer-type
+	// WARNING: in.MaxPods requires manual conversion: does not exist in peer-type
+	// WARNING: in.ImageGC requires manual conversion: does not exist in peer-type
+	// WARNING: in.UploadBootLogsTo requires manual conversion: does not exist in peer-type
+	// WARNING: in.StartupTaint requires manual conversion: does not exist in peer-type
+	// WARNING: in.KubeletRootDir requires manual conversion: does not exist in peer-type
+	// WARNING: in.HostDNS requires manual conversion: does not exist in peer-type
+	// WARNING: in.DNSManagement requires manual conversion: does not exist in peer-type
+	// WARNING: in.ContainerdMetrics requires manual conversion: does not exist in peer-type
+	// WARNING: in.CgroupV2 requires manual conversion: does not exist in peer-type
+	// WARNING: in.FailSwapOn requires manual conversion: does not exist in peer-type
+	// WARNING: in.AuditdRules requires manual conversion: does not exist in peer-type
+	// WARNING: in.SystemLimits requires manual conversion: does not exist in peer-type
+	// WARNING: in.KubeletServingCertSANs requires manual conversion: does not exist in peer-type
+	// WARNING: in.ContainerdPull requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha4_KubeadmConfigSpec_To_v1alpha3_KubeadmConfigSpec is an autogenerated conversion function.
-func Convert_v1alpha4_KubeadmConfigSpec_To_v1alpha3_KubeadmConfigSpec(in *v1alpha4.KubeadmConfigSpec, out *KubeadmConfigSpec, s conversion.Scope) error {
-	return autoConvert_v1alpha4_KubeadmConfigSpec_To_v1alpha3_KubeadmConfigSpec(in, out, s)
-}
-
 func autoConvert_v1alpha3_KubeadmConfigStatus_To_v1alpha4_KubeadmConfigStatus(in *KubeadmConfigStatus, out *v1alpha4.KubeadmConfigStatus, s conversion.Scope) error {
 	out.Ready = in.Ready
 	out.DataSecretName = (*string)(unsafe.Pointer(in.DataSecretName))
@@ -465,14 +554,10 @@ func autoConvert_v1alpha4_KubeadmConfigStatus_To_v1alpha3_KubeadmConfigStatus(in
 	} else {
 		out.Conditions = nil
 	}
+	// WARNING: in.RenderedFormat requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha4_KubeadmConfigStatus_To_v1alpha3_KubeadmConfigStatus is an autogenerated conversion function.
-func Convert_v1alpha4_KubeadmConfigStatus_To_v1alpha3_KubeadmConfigStatus(in *v1alpha4.KubeadmConfigStatus, out *KubeadmConfigStatus, s conversion.Scope) error {
-	return autoConvert_v1alpha4_KubeadmConfigStatus_To_v1alpha3_KubeadmConfigStatus(in, out, s)
-}
-
 func autoConvert_v1alpha3_KubeadmConfigTemplate_To_v1alpha4_KubeadmConfigTemplate(in *KubeadmConfigTemplate, out *v1alpha4.KubeadmConfigTemplate, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha3_KubeadmConfigTemplateSpec_To_v1alpha4_KubeadmConfigTemplateSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -501,7 +586,17 @@ func Convert_v1alpha4_KubeadmConfigTemplate_To_v1alpha3_KubeadmConfigTemplate(in
 
 func autoConvert_v1alpha3_KubeadmConfigTemplateList_To_v1alpha4_KubeadmConfigTemplateList(in *KubeadmConfigTemplateList, out *v1alpha4.KubeadmConfigTemplateList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]v1alpha4.KubeadmConfigTemplate)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]v1alpha4.KubeadmConfigTemplate, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha3_KubeadmConfigTemplate_To_v1alpha4_KubeadmConfigTemplate(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -512,7 +607,17 @@ func Convert_v1alpha3_KubeadmConfigTemplateList_To_v1alpha4_KubeadmConfigTemplat
 
 func autoConvert_v1alpha4_KubeadmConfigTemplateList_To_v1alpha3_KubeadmConfigTemplateList(in *v1alpha4.KubeadmConfigTemplateList, out *KubeadmConfigTemplateList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]KubeadmConfigTemplate)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KubeadmConfigTemplate, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha4_KubeadmConfigTemplate_To_v1alpha3_KubeadmConfigTemplate(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -609,14 +714,12 @@ func autoConvert_v1alpha4_Partition_To_v1alpha3_Partition(in *v1alpha4.Partition
 	out.Layout = in.Layout
 	out.Overwrite = (*bool)(unsafe.Pointer(in.Overwrite))
 	out.TableType = (*string)(unsafe.Pointer(in.TableType))
+	// WARNING: in.Number requires manual conversion: does not exist in peer-type
+	// WARNING: in.SizeMiB requires manual conversion: does not exist in peer-type
+	// WARNING: in.ConfirmWipe requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha4_Partition_To_v1alpha3_Partition is an autogenerated conversion function.
-func Convert_v1alpha4_Partition_To_v1alpha3_Partition(in *v1alpha4.Partition, out *Partition, s conversion.Scope) error {
-	return autoConvert_v1alpha4_Partition_To_v1alpha3_Partition(in, out, s)
-}
-
 func autoConvert_v1alpha3_SecretFileSource_To_v1alpha4_SecretFileSource(in *SecretFileSource, out *v1alpha4.SecretFileSource, s conversion.Scope) error {
 	out.Name = in.Name
 	out.Key = in.Key
@@ -59,3 +59,15 @@ const (
 	// an error while while retrieving certificates for a joining node.
 	CertificatesCorruptedReason = "CertificatesCorrupted"
 )
+
+const (
+	// SensitiveFieldsAcknowledgedCondition documents whether all the sensitive values set inline in the KubeadmConfig
+	// spec, e.g. user passwords or registry credentials, are acknowledged in its SensitiveFields. Those values end
+	// up in the bootstrap data, but are also readable by anyone allowed to read the KubeadmConfig; the condition
+	// is set only for configs with such values.
+	SensitiveFieldsAcknowledgedCondition clusterv1.ConditionType = "SensitiveFieldsAcknowledged"
+
+	// UnacknowledgedSensitiveFieldsReason (Severity=Warning) documents a KubeadmConfig with sensitive values set inline
+	// which are not acknowledged in its SensitiveFields; they should rather be referenced from Secrets.
+	UnacknowledgedSensitiveFieldsReason = "UnacknowledgedSensitiveFields"
+)
@@ -17,7 +17,9 @@ limitations under the License.
 package v1alpha4
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 )
 
@@ -94,6 +96,566 @@ type KubeadmConfigSpec struct {
 	// For more information, refer to https://github.com/kubernetes-sigs/cluster-api/pull/2763#discussion_r397306055.
 	// +optional
 	UseExperimentalRetryJoin bool `json:"useExperimentalRetryJoin,omitempty"`
+
+	// GracefulShutdown configures the kubelet to delay node shutdown so that pods
+	// can be terminated gracefully, optionally draining the node before it goes down.
+	// +optional
+	GracefulShutdown *GracefulShutdownConfig `json:"gracefulShutdown,omitempty"`
+
+	// WaitForNodeReady, if set, makes bootstrap wait for the node to report Ready
+	// after kubeadm has run, up to the given timeout.
+	// +optional
+	WaitForNodeReady *WaitConfig `json:"waitForNodeReady,omitempty"`
+
+	// InstallCrictlConfig specifies whether /etc/crictl.yaml should be written, pointing
+	// crictl at the runtime socket given by the node registration CRISocket.
+	// +optional
+	InstallCrictlConfig *bool `json:"installCrictlConfig,omitempty"`
+
+	// PrePullImages specifies a list of images to pull through the container runtime
+	// given by the node registration CRISocket, before kubeadm runs.
+	// +optional
+	PrePullImages []string `json:"prePullImages,omitempty"`
+
+	// PersistentJournal specifies whether journald should store its logs on disk,
+	// under /var/log/journal, so that they survive a reboot or crash of the machine.
+	// +optional
+	PersistentJournal *bool `json:"persistentJournal,omitempty"`
+
+	// LoginBanner specifies a banner written to /etc/motd and /etc/issue, and shown
+	// by sshd before login.
+	// +optional
+	LoginBanner *string `json:"loginBanner,omitempty"`
+
+	// AuditPolicy is the audit policy of the API server, written to /etc/kubernetes/audit-policy.yaml
+	// on control plane machines; the API server is configured to use it when initializing the cluster.
+	// +optional
+	AuditPolicy *runtime.RawExtension `json:"auditPolicy,omitempty"`
+
+	// AuditLogConfig configures the audit log of the API server. Requires AuditPolicy.
+	// +optional
+	AuditLogConfig *AuditLogConfig `json:"auditLogConfig,omitempty"`
+
+	// GrowRootFilesystem specifies whether the partition holding the root filesystem, and the
+	// filesystem itself, should be grown to the size of the disk on first boot.
+	// +optional
+	GrowRootFilesystem *bool `json:"growRootFilesystem,omitempty"`
+
+	// NetworkConfig specifies a static network configuration for the network interfaces of the machine,
+	// written as systemd-networkd configuration files.
+	// +optional
+	NetworkConfig []NetworkInterface `json:"networkConfig,omitempty"`
+
+	// SensitiveFields acknowledges sensitive values which are intentionally set inline in this spec, and are thus
+	// readable by anyone allowed to read it, e.g. "users[admin].passwd" or "files[/root/.docker/config.json]".
+	// Sensitive values which are not acknowledged are reported by the SensitiveFieldsAcknowledged condition.
+	// +optional
+	SensitiveFields []string `json:"sensitiveFields,omitempty"`
+
+	// NodeLocalDNS configures the machine to resolve names through a node-local DNS cache.
+	// +optional
+	NodeLocalDNS *NodeLocalDNSConfig `json:"nodeLocalDNS,omitempty"`
+
+	// SystemdTimers specifies commands run periodically on the machine, installed as pairs of
+	// systemd timer and oneshot service units.
+	// +optional
+	SystemdTimers []SystemdTimer `json:"systemdTimers,omitempty"`
+
+	// Packages specifies packages to install on first boot with the package manager of the distribution,
+	// e.g. APT or YUM. Images with an immutable root filesystem, such as Flatcar Container Linux, do not
+	// support package installation.
+	// +optional
+	Packages []string `json:"packages,omitempty"`
+
+	// SandboxImage is the image reference of the pod sandbox (pause) image used by containerd and the kubelet
+	// instead of their default one, e.g. to pull it from a private registry on air-gapped machines.
+	// +optional
+	SandboxImage *string `json:"sandboxImage,omitempty"`
+
+	// RemountOptions specifies filesystems remounted with additional mount options on every boot,
+	// e.g. to enforce nosuid and nodev on hardened machines.
+	// +optional
+	RemountOptions []RemountSpec `json:"remountOptions,omitempty"`
+
+	// IgnorePreflightErrors specifies the kubeadm preflight checks whose errors are reported as warnings,
+	// e.g. "Swap" or "NumCPU", passed to kubeadm init and kubeadm join with --ignore-preflight-errors.
+	// The value "all" ignores the errors of every check.
+	// +optional
+	IgnorePreflightErrors []string `json:"ignorePreflightErrors,omitempty"`
+
+	// EtcdDataDir is the directory where the local etcd member places its data, set as
+	// ClusterConfiguration.Etcd.Local.DataDir. It must be on a filesystem declared in DiskSetup and mounted
+	// by one of the Mounts, e.g. on a disk dedicated to etcd.
+	// +optional
+	EtcdDataDir *string `json:"etcdDataDir,omitempty"`
+
+	// PublishConfigTo specifies a ConfigMap of the workload cluster where a summary of the kubeadm configuration
+	// applied on the machine is published once it has been bootstrapped, for drift detection. Bootstrap tokens
+	// and certificate keys are redacted from the summary.
+	// +optional
+	PublishConfigTo *PublishTarget `json:"publishConfigTo,omitempty"`
+
+	// RotateKubeletServerCertificate specifies whether the kubelet requests its serving certificate from the
+	// cluster, and rotates it as it approaches expiration, instead of using a self-signed one. The certificate
+	// signing requests of the kubelet must be approved, e.g. by a dedicated approver.
+	// +optional
+	RotateKubeletServerCertificate *bool `json:"rotateKubeletServerCertificate,omitempty"`
+
+	// SSHHardening specifies the hardening of the configuration of sshd, written as a drop-in of
+	// /etc/ssh/sshd_config.d, e.g. to comply with security baselines.
+	// +optional
+	SSHHardening *SSHHardeningConfig `json:"sshHardening,omitempty"`
+
+	// BootstrapTokenTTL is how long the bootstrap tokens generated for the machine to join the cluster are valid.
+	// Tokens are refreshed until the infrastructure is ready, and rotated for MachinePools, based on this TTL.
+	// Defaults to the bootstrap token TTL of the controller, 15 minutes unless set with --bootstrap-token-ttl.
+	// Must be at least 1 minute.
+	// +optional
+	BootstrapTokenTTL *metav1.Duration `json:"bootstrapTokenTTL,omitempty"`
+
+	// BootstrapTokenUsages are the usages of the bootstrap tokens generated for the machine to join the cluster,
+	// among "signing" and "authentication". "authentication" is required for the kubelet to join, and "signing"
+	// for the cluster-info ConfigMap to be signed when discovering the cluster with the token.
+	// Defaults to both.
+	// +optional
+	BootstrapTokenUsages []string `json:"bootstrapTokenUsages,omitempty"`
+
+	// CloudProvider specifies the cloud provider of the machine, set as the cloud-provider flag of the kubelet
+	// and, on control plane machines, of the API server and the controller manager, e.g. "external" for an
+	// out-of-tree cloud provider.
+	// +optional
+	CloudProvider *CloudProviderConfig `json:"cloudProvider,omitempty"`
+
+	// SeccompDefault specifies whether the kubelet runs all workloads with the RuntimeDefault seccomp profile
+	// of the container runtime, unless they request another one. Requires Kubernetes v1.22 or later.
+	// +optional
+	SeccompDefault *bool `json:"seccompDefault,omitempty"`
+
+	// UserNamespaces specifies whether the kubelet supports running pods in user namespaces, remapping their
+	// users to unprivileged users of the host when they set hostUsers to false. Requires Kubernetes v1.25 or
+	// later, and a container runtime supporting it, e.g. containerd v2.0 or later.
+	// +optional
+	UserNamespaces *bool `json:"userNamespaces,omitempty"`
+
+	// FIPSMode specifies whether FIPS mode should be enabled on the machine, effective from its next boot,
+	// on operating systems supporting it, e.g. RHEL. SSHHardening must then only allow FIPS approved ciphers
+	// and MACs. Prefer images built with FIPS mode enabled, so that it applies from the first boot.
+	// +optional
+	FIPSMode *bool `json:"fipsMode,omitempty"`
+
+	// ReservedResources specifies the resources the kubelet reserves for the Kubernetes and system daemons,
+	// and the thresholds at which it evicts pods, to prevent the starvation of the node.
+	// +optional
+	ReservedResources *ReservedResourcesConfig `json:"reservedResources,omitempty"`
+
+	// Kdump specifies the memory reserved for a crash kernel, and where kdump saves the dumps of kernel panics,
+	// for their diagnostic. It takes effect from the next boot of the machine.
+	// +optional
+	Kdump *KdumpConfig `json:"kdump,omitempty"`
+
+	// InstallNodeProblemDetector specifies the node problem detector to run on the machine as a systemd unit,
+	// reporting node conditions such as KernelDeadlock, which MachineHealthChecks can watch.
+	// +optional
+	InstallNodeProblemDetector *NPDConfig `json:"installNodeProblemDetector,omitempty"`
+
+	// StaticPods specifies the static pods to run on the machine, e.g. a local registry proxy, whose manifests
+	// are written to /etc/kubernetes/manifests before kubeadm runs.
+	// +optional
+	StaticPods []StaticPod `json:"staticPods,omitempty"`
+
+	// RuntimeHandlers specifies the additional containerd runtime handlers of the machine, e.g. gVisor or Kata
+	// Containers for sandboxed workloads, usable through RuntimeClasses of the same handler name.
+	// +optional
+	RuntimeHandlers []RuntimeHandler `json:"runtimeHandlers,omitempty"`
+
+	// Timezone specifies the timezone of the machine, as a name of the tz database, e.g. "Europe/Berlin",
+	// so that the logs of a fleet of machines are timestamped consistently.
+	// +optional
+	Timezone *string `json:"timezone,omitempty"`
+
+	// MaxPods specifies the maximum number of pods the kubelet runs on the machine, e.g. to run more than the
+	// default 110 pods on dense nodes. It should not exceed the addresses of the pod CIDR allocated to the node.
+	// +optional
+	MaxPods *int32 `json:"maxPods,omitempty"`
+
+	// ImageGC specifies the disk usage thresholds of the image garbage collection of the kubelet, e.g. to collect
+	// unused images earlier on nodes with small disks, before they hit DiskPressure.
+	// +optional
+	ImageGC *ImageGCConfig `json:"imageGC,omitempty"`
+
+	// UploadBootLogsTo specifies where the journal and the cloud-init logs of the machine are uploaded once its
+	// bootstrap completed or failed, for the post-mortem of machines which failed to bootstrap.
+	// +optional
+	UploadBootLogsTo *LogSink `json:"uploadBootLogsTo,omitempty"`
+
+	// StartupTaint specifies a taint the node is registered with by the kubelet, and which is removed once the
+	// postKubeadmCommands ran and the node is Ready, so that no pods are scheduled on it until it is fully configured.
+	// Nodes can't remove their own taints: control plane machines remove it with the admin credentials written by
+	// kubeadm, other machines with the kubeconfig at /etc/kubernetes/startup-taint.conf, e.g. written from a Secret
+	// through files, whose user must be allowed to patch nodes.
+	// +optional
+	StartupTaint *corev1.Taint `json:"startupTaint,omitempty"`
+
+	// KubeletRootDir is the directory where the kubelet places its files, including the ephemeral storage of the pods,
+	// defaulting to /var/lib/kubelet. It must be on a filesystem declared in DiskSetup and mounted by one of the
+	// Mounts, so that the ephemeral storage can't fill the root disk; a non-default directory is passed to the
+	// kubelet with --root-dir.
+	// +optional
+	KubeletRootDir *string `json:"kubeletRootDir,omitempty"`
+
+	// HostDNS specifies the DNS servers and search domains the machine resolves names with, applied as told by
+	// DNSManagement. Not to be confused with ClusterConfiguration.DNS, which configures the DNS addon of the cluster.
+	// +optional
+	HostDNS *HostDNSConfig `json:"hostDNS,omitempty"`
+
+	// DNSManagement specifies how HostDNS is applied: by writing /etc/resolv.conf, for hosts managing it with
+	// resolvconf or by hand, or as a systemd-resolved drop-in, for hosts running systemd-resolved, where
+	// /etc/resolv.conf is a symlink to its stub resolver. Auto, the default, writes both and picks SystemdResolved
+	// when systemd-resolved is running on the machine, ResolvConf otherwise.
+	// +optional
+	DNSManagement DNSManagement `json:"dnsManagement,omitempty"`
+
+	// ContainerdMetrics specifies where containerd exposes its Prometheus metrics, e.g. on a local port scraped by a
+	// node agent of the monitoring stack.
+	// +optional
+	ContainerdMetrics *ContainerdMetricsConfig `json:"containerdMetrics,omitempty"`
+
+	// CgroupV2 specifies whether the machine runs with the unified cgroup (v2) hierarchy, enabled with the
+	// systemd.unified_cgroup_hierarchy=1 kernel arg, effective from its next boot unless it is already set. The kubelet
+	// and containerd are then configured with the systemd cgroup driver, as cgroup v2 requires.
+	// +optional
+	CgroupV2 *bool `json:"cgroupV2,omitempty"`
+
+	// FailSwapOn specifies whether the kubelet fails to start when swap is enabled on the machine, true by default.
+	// Setting it to false lets the kubelet run with swap, enabling the NodeSwap feature gate before Kubernetes v1.30,
+	// along with swap accounting through the swapaccount=1 kernel arg; it requires CgroupV2 and Kubernetes v1.22
+	// or later.
+	// +optional
+	FailSwapOn *bool `json:"failSwapOn,omitempty"`
+
+	// AuditdRules specifies the rules of the Linux audit system loaded on the machine, one per item, e.g.
+	// "-w /etc/kubernetes -p wa -k kubernetes", written to /etc/audit/rules.d/capi.rules and loaded with augenrules
+	// before the kubeadm command runs. The audit system (auditd) must be installed in the image.
+	// +optional
+	AuditdRules []string `json:"auditdRules,omitempty"`
+
+	// SystemLimits specifies the resource limits of the processes of the machine, e.g. to raise the maximum number of
+	// open files for workloads handling many connections.
+	// +optional
+	SystemLimits *SystemLimitsConfig `json:"systemLimits,omitempty"`
+
+	// KubeletServingCertSANs specifies additional subject alternative names, DNS names or IP addresses, of the serving
+	// certificate of the kubelet, for nodes addressed by other names than their hostname. The certificate is
+	// generated for the hostname, the IP addresses and these names before the kubeadm command runs, self-signed as the
+	// one the kubelet generates otherwise. Cannot be set together with RotateKubeletServerCertificate, whose
+	// certificates only name the addresses of the node.
+	// +optional
+	KubeletServingCertSANs []string `json:"kubeletServingCertSANs,omitempty"`
+
+	// ContainerdPull specifies how containerd pulls images, e.g. to pull fewer layers at once when many machines join
+	// at the same time and pull from the same registry.
+	// +optional
+	ContainerdPull *ContainerdPullConfig `json:"containerdPull,omitempty"`
+}
+
+// RuntimeHandler defines a containerd runtime handler.
+type RuntimeHandler struct {
+	// Name of the runtime handler, referenced by the handler of RuntimeClasses, e.g. "runsc".
+	Name string `json:"name"`
+
+	// RuntimeType is the containerd runtime type of the handler, e.g. "io.containerd.runsc.v1" for gVisor
+	// or "io.containerd.kata.v2" for Kata Containers.
+	RuntimeType string `json:"runtimeType"`
+
+	// BinaryPath is the path of the shim binary of the runtime. Defaults to the shim binary named after
+	// RuntimeType, looked up by containerd in its PATH, e.g. containerd-shim-runsc-v1.
+	// +optional
+	BinaryPath string `json:"binaryPath,omitempty"`
+}
+
+// ContainerdMetricsConfig defines where containerd exposes its metrics.
+type ContainerdMetricsConfig struct {
+	// Address is the IP address and port the metrics endpoint of containerd listens on, e.g. "127.0.0.1:1338".
+	Address string `json:"address"`
+}
+
+// ContainerdPullConfig defines how containerd pulls images.
+type ContainerdPullConfig struct {
+	// MaxConcurrentDownloads is the maximum number of layers containerd downloads at once for each image pull.
+	// Defaults to the containerd default, i.e. 3.
+	// +optional
+	MaxConcurrentDownloads *int32 `json:"maxConcurrentDownloads,omitempty"`
+
+	// PullTimeout is how long an image pull may make no progress before containerd cancels it. Requires containerd
+	// v1.7 or later. Defaults to the containerd default, i.e. 1m.
+	// +optional
+	PullTimeout *metav1.Duration `json:"pullTimeout,omitempty"`
+}
+
+// SystemLimitsConfig defines the resource limits of the processes of a machine, set both as the default limits of the
+// systemd services and as the limits of the login sessions.
+type SystemLimitsConfig struct {
+	// NoFile is the maximum number of open files of a process.
+	// +optional
+	NoFile *int64 `json:"noFile,omitempty"`
+
+	// NProc is the maximum number of processes of a user.
+	// +optional
+	NProc *int64 `json:"nProc,omitempty"`
+}
+
+// StaticPod defines a static pod run by the kubelet of a machine.
+type StaticPod struct {
+	// Name of the static pod, which its manifest is written to as /etc/kubernetes/manifests/<name>.yaml.
+	Name string `json:"name"`
+
+	// ManifestFrom is the source of the manifest of the static pod, a Pod in YAML or JSON.
+	ManifestFrom *FileSource `json:"manifestFrom"`
+}
+
+// NPDConfig defines the node problem detector run on a machine.
+type NPDConfig struct {
+	// Image is the image reference of the node problem detector, pulled and run by containerd,
+	// e.g. "registry.k8s.io/node-problem-detector/node-problem-detector:v0.8.10".
+	Image string `json:"image"`
+
+	// ConfigFrom is the source of the system log monitor config of the node problem detector, written to
+	// /etc/node-problem-detector/system-log-monitor.json. Defaults to the kernel monitor config of the image.
+	// +optional
+	ConfigFrom *FileSource `json:"configFrom,omitempty"`
+}
+
+// KdumpConfig defines the kdump configuration of a machine.
+type KdumpConfig struct {
+	// CrashKernel is the value of the crashkernel kernel arg, reserving memory for the crash kernel,
+	// e.g. "256M", "auto" or "1G-4G:192M,4G-64G:256M".
+	CrashKernel string `json:"crashKernel"`
+
+	// Path is the directory where kdump saves the dumps. Defaults to /var/crash.
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// ReservedResourcesConfig defines the resources reserved by the kubelet, and its hard eviction thresholds.
+type ReservedResourcesConfig struct {
+	// KubeReserved are the resources reserved for the Kubernetes daemons, e.g. the kubelet and the container
+	// runtime, by resource name among cpu, memory, ephemeral-storage and pid, e.g. {"cpu": "100m", "memory": "256Mi"}.
+	// +optional
+	KubeReserved map[string]string `json:"kubeReserved,omitempty"`
+
+	// SystemReserved are the resources reserved for the system daemons, e.g. sshd and udev, by resource name
+	// among cpu, memory, ephemeral-storage and pid.
+	// +optional
+	SystemReserved map[string]string `json:"systemReserved,omitempty"`
+
+	// EvictionHard are the thresholds below which the kubelet evicts pods, by eviction signal, as quantities
+	// or percentages, e.g. {"memory.available": "100Mi", "nodefs.available": "10%"}.
+	// +optional
+	EvictionHard map[string]string `json:"evictionHard,omitempty"`
+}
+
+// ImageGCConfig defines the disk usage thresholds of the image garbage collection of the kubelet.
+type ImageGCConfig struct {
+	// HighThresholdPercent is the percentage of disk usage above which the kubelet always runs the image
+	// garbage collection. The kubelet defaults to 85.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	HighThresholdPercent int32 `json:"highThresholdPercent"`
+
+	// LowThresholdPercent is the percentage of disk usage down to which the image garbage collection frees
+	// space; it must be lower than HighThresholdPercent. The kubelet defaults to 80.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	LowThresholdPercent int32 `json:"lowThresholdPercent"`
+}
+
+// AuditLogConfig defines where the API server writes its audit log, and how it is rotated.
+type AuditLogConfig struct {
+	// Path of the audit log file on the host.
+	// Defaults to /var/log/kubernetes/audit/audit.log.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// MaxAge is the maximum number of days to retain old audit log files.
+	// +optional
+	MaxAge *int32 `json:"maxAge,omitempty"`
+
+	// MaxBackup is the maximum number of old audit log files to retain.
+	// +optional
+	MaxBackup *int32 `json:"maxBackup,omitempty"`
+
+	// MaxSize is the maximum size in megabytes of the audit log file before it gets rotated.
+	// +optional
+	MaxSize *int32 `json:"maxSize,omitempty"`
+}
+
+// NetworkInterface defines the static network configuration of a network interface.
+type NetworkInterface struct {
+	// Name of the network interface, e.g. "eth0".
+	Name string `json:"name"`
+
+	// Addresses to assign to the network interface, in CIDR notation, e.g. "192.168.1.10/24".
+	Addresses []string `json:"addresses"`
+
+	// Gateway is the IP address of the default gateway reached through the network interface.
+	// +optional
+	Gateway string `json:"gateway,omitempty"`
+
+	// DNS is a list of IP addresses of the DNS servers to use.
+	// +optional
+	DNS []string `json:"dns,omitempty"`
+
+	// MTU is the maximum transmission unit of the network interface, in bytes.
+	// +kubebuilder:validation:Minimum=68
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	MTU *int32 `json:"mtu,omitempty"`
+
+	// Routes are static routes reached through the network interface, e.g. to a dedicated storage network.
+	// +optional
+	Routes []NetworkRoute `json:"routes,omitempty"`
+}
+
+// NetworkRoute defines a static route of a network interface.
+type NetworkRoute struct {
+	// To is the destination of the route, in CIDR notation, e.g. "10.10.0.0/16".
+	To string `json:"to"`
+
+	// Via is the IP address of the gateway of the route; the destination is reached directly through the
+	// network interface if unset.
+	// +optional
+	Via string `json:"via,omitempty"`
+
+	// Metric is the metric of the route, routes with a lower metric being preferred.
+	// +optional
+	Metric *int32 `json:"metric,omitempty"`
+}
+
+// HostDNSConfig defines the DNS configuration of the machine.
+type HostDNSConfig struct {
+	// Nameservers are the IP addresses of the DNS servers, e.g. "10.0.0.2".
+	// +optional
+	Nameservers []string `json:"nameservers,omitempty"`
+
+	// Search are the search domains completing relative names, e.g. "corp.example.com".
+	// +optional
+	Search []string `json:"search,omitempty"`
+}
+
+// DNSManagement specifies how the DNS configuration of a machine is applied.
+// +kubebuilder:validation:Enum=ResolvConf;SystemdResolved;Auto
+type DNSManagement string
+
+const (
+	// DNSManagementResolvConf writes the DNS configuration to /etc/resolv.conf.
+	DNSManagementResolvConf DNSManagement = "ResolvConf"
+
+	// DNSManagementSystemdResolved writes the DNS configuration as a systemd-resolved drop-in.
+	DNSManagementSystemdResolved DNSManagement = "SystemdResolved"
+
+	// DNSManagementAuto picks the strategy when the machine boots, depending on whether systemd-resolved is running.
+	DNSManagementAuto DNSManagement = "Auto"
+)
+
+// NodeLocalDNSConfig defines the node-local DNS cache used by the machine.
+type NodeLocalDNSConfig struct {
+	// Address is the IP address the node-local DNS cache listens on, e.g. "169.254.20.10".
+	Address string `json:"address"`
+}
+
+// SystemdTimer defines a command run periodically by a systemd timer.
+type SystemdTimer struct {
+	// Name of the systemd timer and service units, without suffix, e.g. "cert-check".
+	Name string `json:"name"`
+
+	// Schedule of the command, in the systemd OnCalendar format, e.g. "daily" or "Mon *-*-* 03:00:00".
+	Schedule string `json:"schedule"`
+
+	// Command run by the service unit, through /bin/sh.
+	Command string `json:"command"`
+}
+
+// RemountSpec defines a filesystem remounted with additional mount options.
+type RemountSpec struct {
+	// Path of the mount point of the filesystem, e.g. "/" or "/usr".
+	Path string `json:"path"`
+
+	// Options are the mount options added to the filesystem, e.g. "nosuid" or "ro".
+	Options []string `json:"options"`
+}
+
+// PublishTarget defines a ConfigMap of the workload cluster where the summary of the applied kubeadm
+// configuration of each machine is published, under the hostname of the machine.
+type PublishTarget struct {
+	// ConfigMapName is the name of the ConfigMap, created if it does not exist.
+	ConfigMapName string `json:"configMapName"`
+
+	// Namespace of the ConfigMap, e.g. "kube-system".
+	Namespace string `json:"namespace"`
+}
+
+// LogSink defines an HTTP endpoint where the boot logs of each machine are uploaded.
+type LogSink struct {
+	// URL under which the logs are uploaded with an HTTP PUT, as a gzipped tarball named after the hostname of the
+	// machine and the outcome of its bootstrap, e.g. <url>/machine-1-failed.tar.gz.
+	URL string `json:"url"`
+
+	// CredentialsFrom is the source of the HTTP headers authenticating the upload, one per line, e.g.
+	// "Authorization: Bearer <token>", written to /etc/cluster-api/boot-logs-credentials.
+	// +optional
+	CredentialsFrom *FileSource `json:"credentialsFrom,omitempty"`
+}
+
+// PermitRootLogin specifies whether root can log in over ssh.
+// +kubebuilder:validation:Enum=yes;no;prohibit-password;forced-commands-only
+type PermitRootLogin string
+
+const (
+	// PermitRootLoginYes allows root to log in with any authentication method.
+	PermitRootLoginYes = PermitRootLogin("yes")
+
+	// PermitRootLoginNo does not allow root to log in.
+	PermitRootLoginNo = PermitRootLogin("no")
+
+	// PermitRootLoginProhibitPassword allows root to log in, but not with a password.
+	PermitRootLoginProhibitPassword = PermitRootLogin("prohibit-password")
+
+	// PermitRootLoginForcedCommandsOnly allows root to log in with a public key, only if a command
+	// is set for the key.
+	PermitRootLoginForcedCommandsOnly = PermitRootLogin("forced-commands-only")
+)
+
+// SSHHardeningConfig defines the hardening of the configuration of sshd. Unset fields keep
+// the value of the image.
+type SSHHardeningConfig struct {
+	// PasswordAuthentication specifies whether sshd allows password authentication.
+	// +optional
+	PasswordAuthentication *bool `json:"passwordAuthentication,omitempty"`
+
+	// PermitRootLogin specifies whether root can log in over ssh.
+	// +optional
+	PermitRootLogin PermitRootLogin `json:"permitRootLogin,omitempty"`
+
+	// Ciphers are the ciphers allowed by sshd, in order of preference, e.g. "chacha20-poly1305@openssh.com".
+	// +optional
+	Ciphers []string `json:"ciphers,omitempty"`
+
+	// MACs are the message authentication code algorithms allowed by sshd, in order of preference,
+	// e.g. "hmac-sha2-512-etm@openssh.com".
+	// +optional
+	MACs []string `json:"macs,omitempty"`
+}
+
+// CloudProviderConfig defines the cloud provider of a machine, and its configuration file.
+type CloudProviderConfig struct {
+	// Name of the cloud provider, e.g. "external" or "aws".
+	Name string `json:"name"`
+
+	// ConfigFrom is the source of the cloud config file, written to /etc/kubernetes/cloud.conf and set as the
+	// cloud-config flag alongside the cloud-provider one. Out-of-tree cloud providers, named "external", do
+	// not require one.
+	// +optional
+	ConfigFrom *FileSource `json:"configFrom,omitempty"`
 }
 
 // KubeadmConfigStatus defines the observed state of KubeadmConfig.
@@ -117,6 +679,10 @@ type KubeadmConfigStatus struct {
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
+	// RenderedFormat is the format of the bootstrap data last rendered into the data secret.
+	// +optional
+	RenderedFormat Format `json:"renderedFormat,omitempty"`
+
 	// Conditions defines current service state of the KubeadmConfig.
 	// +optional
 	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
@@ -191,9 +757,16 @@ type File struct {
 	// +optional
 	Content string `json:"content,omitempty"`
 
-	// ContentFrom is a referenced source of content to populate the file.
+	// ContentFrom is a list of referenced sources of content to populate the file.
+	// The contents of the sources are concatenated, in order, into the file.
+	// +optional
+	ContentFrom []FileSource `json:"contentFrom,omitempty"`
+
+	// Template specifies whether the content of the file is a Go template, which is rendered
+	// before the file is written. The following values are available to the template:
+	// .ControlPlaneEndpoint, the control plane endpoint of the Cluster as "host:port".
 	// +optional
-	ContentFrom *FileSource `json:"contentFrom,omitempty"`
+	Template bool `json:"template,omitempty"`
 }
 
 // FileSource is a union of all possible external source types for file data.
@@ -229,7 +802,8 @@ type User struct {
 	// +optional
 	Groups *string `json:"groups,omitempty"`
 
-	// HomeDir specifies the home directory to use for the user
+	// HomeDir specifies the home directory to use for the user, as an absolute path.
+	// It is created if missing, owned by the user and only accessible by it.
 	// +optional
 	HomeDir *string `json:"homeDir,omitempty"`
 
@@ -237,7 +811,7 @@ type User struct {
 	// +optional
 	Inactive *bool `json:"inactive,omitempty"`
 
-	// Shell specifies the user's shell
+	// Shell specifies the user's shell, as an absolute path. It must be available in the image.
 	// +optional
 	Shell *string `json:"shell,omitempty"`
 
@@ -273,6 +847,24 @@ type NTP struct {
 	Enabled *bool `json:"enabled,omitempty"`
 }
 
+// GracefulShutdownConfig defines input for the kubelet graceful node shutdown.
+type GracefulShutdownConfig struct {
+	// Timeout is the total duration the node delays its shutdown by.
+	// It is passed to the kubelet as --shutdown-grace-period.
+	Timeout metav1.Duration `json:"timeout"`
+
+	// InhibitorUnit specifies whether a systemd unit draining the node
+	// before shutdown should be installed and enabled.
+	// +optional
+	InhibitorUnit bool `json:"inhibitorUnit,omitempty"`
+}
+
+// WaitConfig defines how long bootstrap waits for a condition to be met.
+type WaitConfig struct {
+	// Timeout is the maximum duration to wait for.
+	Timeout metav1.Duration `json:"timeout"`
+}
+
 // DiskSetup defines input for generated disk_setup and fs_setup in cloud-init.
 type DiskSetup struct {
 	// Partitions specifies the list of the partitions to setup.
@@ -298,6 +890,19 @@ type Partition struct {
 	// 'gpt': setups a GPT partition table
 	// +optional
 	TableType *string `json:"tableType,omitempty"`
+	// Number is the number of the partition on the device.
+	// If set, the partition is created explicitly with the size given by SizeMiB
+	// instead of following Layout; multiple entries may then refer to the same device.
+	// +optional
+	Number *int `json:"number,omitempty"`
+	// SizeMiB is the size of the partition in MiB. 0 or -1 means the partition
+	// grows to fill the remaining space on the device. Only valid if Number is set.
+	// +optional
+	SizeMiB *int `json:"sizeMiB,omitempty"`
+	// ConfirmWipe confirms that the existing partitions and filesystems on the device may be destroyed,
+	// which is required when Overwrite is true for the partition, or for a filesystem on the device.
+	// +optional
+	ConfirmWipe bool `json:"confirmWipe,omitempty"`
 }
 
 // Filesystem defines the file systems to be created.
@@ -325,4 +930,5 @@ type Filesystem struct {
 }
 
 // MountPoints defines input for generated mounts in cloud-init.
+// Each entry is [device, mountpoint, type, options, dump, pass], where only device and mountpoint are required.
 type MountPoints []string
@@ -18,16 +18,23 @@ package v1alpha4
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
 )
 
 // These tests are written in BDD-style using Ginkgo framework. Refer to
 // http://onsi.github.io/ginkgo to learn more.
 
 func TestClusterValidate(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+	mbr := "mbr"
+
 	cases := map[string]struct {
 		in        *KubeadmConfig
 		expectErr bool
@@ -56,16 +63,108 @@ func TestClusterValidate(t *testing.T) {
 				Spec: KubeadmConfigSpec{
 					Files: []File{
 						{
-							ContentFrom: &FileSource{
+							ContentFrom: []FileSource{{
 								Secret: SecretFileSource{
 									Name: "foo",
 									Key:  "bar",
 								},
+							}},
+						},
+					},
+				},
+			},
+		},
+		"valid contentFrom with multiple sources": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Files: []File{
+						{
+							ContentFrom: []FileSource{
+								{Secret: SecretFileSource{Name: "foo", Key: "bar"}},
+								{Secret: SecretFileSource{Name: "foo", Key: "baz"}},
+							},
+						},
+					},
+				},
+			},
+		},
+		"invalid contentFrom with multiple sources and base64 encoding": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Files: []File{
+						{
+							Encoding: Base64,
+							ContentFrom: []FileSource{
+								{Secret: SecretFileSource{Name: "foo", Key: "bar"}},
+								{Secret: SecretFileSource{Name: "foo", Key: "baz"}},
 							},
 						},
 					},
 				},
 			},
+			expectErr: true,
+		},
+		"valid templated file": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Files: []File{
+						{
+							Path:     "/etc/kubernetes/kubeconfig",
+							Content:  "server: https://{{ .ControlPlaneEndpoint }}",
+							Template: true,
+						},
+					},
+				},
+			},
+		},
+		"invalid templated file content": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Files: []File{
+						{
+							Path:     "/etc/kubernetes/kubeconfig",
+							Content:  "server: https://{{ .ControlPlaneEndpoint",
+							Template: true,
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid templated file with encoding": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Files: []File{
+						{
+							Path:     "/etc/kubernetes/kubeconfig",
+							Encoding: Base64,
+							Content:  "c2VydmVyOiB7eyAuQ29udHJvbFBsYW5lRW5kcG9pbnQgfX0=",
+							Template: true,
+						},
+					},
+				},
+			},
+			expectErr: true,
 		},
 		"invalid content and contentFrom": {
 			in: &KubeadmConfig{
@@ -76,7 +175,7 @@ func TestClusterValidate(t *testing.T) {
 				Spec: KubeadmConfigSpec{
 					Files: []File{
 						{
-							ContentFrom: &FileSource{},
+							ContentFrom: []FileSource{{}},
 							Content:     "foo",
 						},
 					},
@@ -93,11 +192,11 @@ func TestClusterValidate(t *testing.T) {
 				Spec: KubeadmConfigSpec{
 					Files: []File{
 						{
-							ContentFrom: &FileSource{
+							ContentFrom: []FileSource{{
 								Secret: SecretFileSource{
 									Key: "bar",
 								},
-							},
+							}},
 							Content: "foo",
 						},
 					},
@@ -114,11 +213,11 @@ func TestClusterValidate(t *testing.T) {
 				Spec: KubeadmConfigSpec{
 					Files: []File{
 						{
-							ContentFrom: &FileSource{
+							ContentFrom: []FileSource{{
 								Secret: SecretFileSource{
 									Name: "foo",
 								},
-							},
+							}},
 							Content: "foo",
 						},
 					},
@@ -145,18 +244,2456 @@ func TestClusterValidate(t *testing.T) {
 			},
 			expectErr: true,
 		},
-	}
-
-	for name, tt := range cases {
-		t.Run(name, func(t *testing.T) {
-			g := NewWithT(t)
-			if tt.expectErr {
-				g.Expect(tt.in.ValidateCreate()).NotTo(Succeed())
-				g.Expect(tt.in.ValidateUpdate(nil)).NotTo(Succeed())
-			} else {
-				g.Expect(tt.in.ValidateCreate()).To(Succeed())
-				g.Expect(tt.in.ValidateUpdate(nil)).To(Succeed())
-			}
-		})
-	}
+		"valid graceful shutdown": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					GracefulShutdown: &GracefulShutdownConfig{
+						Timeout:       metav1.Duration{Duration: 30 * time.Second},
+						InhibitorUnit: true,
+					},
+				},
+			},
+		},
+		"invalid graceful shutdown without timeout": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					GracefulShutdown: &GracefulShutdownConfig{},
+				},
+			},
+			expectErr: true,
+		},
+		"valid wait for node ready": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					WaitForNodeReady: &WaitConfig{
+						Timeout: metav1.Duration{Duration: 5 * time.Minute},
+					},
+				},
+			},
+		},
+		"invalid wait for node ready without timeout": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					WaitForNodeReady: &WaitConfig{},
+				},
+			},
+			expectErr: true,
+		},
+		"valid pre-pull images": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					PrePullImages: []string{"nginx:1.21", "k8s.gcr.io/pause:3.4.1", "registry.example.com:5000/team/app@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+				},
+			},
+		},
+		"invalid pre-pull image with uppercase repository": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					PrePullImages: []string{"Nginx:1.21"},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid pre-pull image with shell metacharacters": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					PrePullImages: []string{"nginx:1.21; rm -rf /"},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid empty pre-pull image": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					PrePullImages: []string{""},
+				},
+			},
+			expectErr: true,
+		},
+		"valid mount with device and mountpoint": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Mounts: []MountPoints{
+						{"ephemeral0", "/var/lib/etcd"},
+					},
+				},
+			},
+		},
+		"valid mount with all fields": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Mounts: []MountPoints{
+						{"/dev/sdb1", "/data", "ext4", "defaults,nofail", "0", "2"},
+					},
+				},
+			},
+		},
+		"valid swap mount": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Mounts: []MountPoints{
+						{"swap", "none", "swap", "sw", "0", "0"},
+					},
+				},
+			},
+		},
+		"invalid mount with only a device": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Mounts: []MountPoints{
+						{"ephemeral0"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid mount with too many fields": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Mounts: []MountPoints{
+						{"/dev/sdb1", "/data", "ext4", "defaults", "0", "2", "extra"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid mount with empty device": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Mounts: []MountPoints{
+						{"", "/data"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid mount with whitespace in options": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Mounts: []MountPoints{
+						{"/dev/sdb1", "/data", "ext4", "defaults, nofail"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid mount with relative mountpoint": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Mounts: []MountPoints{
+						{"/dev/sdb1", "data"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid mount with non numeric pass": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Mounts: []MountPoints{
+						{"/dev/sdb1", "/data", "ext4", "defaults", "0", "first"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid partitions where the last one fills the device": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Partitions: []Partition{
+							{Device: "/dev/sdb", Number: intPtr(1), SizeMiB: intPtr(1024)},
+							{Device: "/dev/sdb", Number: intPtr(2), SizeMiB: intPtr(-1)},
+						},
+					},
+				},
+			},
+		},
+		"invalid duplicate partition numbers": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Partitions: []Partition{
+							{Device: "/dev/sdb", Number: intPtr(1), SizeMiB: intPtr(1024)},
+							{Device: "/dev/sdb", Number: intPtr(1), SizeMiB: intPtr(-1)},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid non contiguous partition numbers": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Partitions: []Partition{
+							{Device: "/dev/sdb", Number: intPtr(1), SizeMiB: intPtr(1024)},
+							{Device: "/dev/sdb", Number: intPtr(3), SizeMiB: intPtr(-1)},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid partition filling the device before the last one": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Partitions: []Partition{
+							{Device: "/dev/sdb", Number: intPtr(1), SizeMiB: intPtr(0)},
+							{Device: "/dev/sdb", Number: intPtr(2), SizeMiB: intPtr(1024)},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid partition size without number": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Partitions: []Partition{
+							{Device: "/dev/sdb", Layout: true, SizeMiB: intPtr(1024)},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid numbered partition with mbr table type": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Partitions: []Partition{
+							{Device: "/dev/sdb", Number: intPtr(1), SizeMiB: intPtr(-1), TableType: &mbr},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid audit policy and log configuration": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					AuditPolicy: &runtime.RawExtension{
+						Raw: []byte(`{"apiVersion":"audit.k8s.io/v1","kind":"Policy","rules":[{"level":"Metadata"}]}`),
+					},
+					AuditLogConfig: &AuditLogConfig{
+						Path: "/var/log/audit/kube-apiserver.log",
+					},
+				},
+			},
+		},
+		"invalid audit policy": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					AuditPolicy: &runtime.RawExtension{
+						Raw: []byte("kind: [Policy"),
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid audit log configuration without audit policy": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					AuditLogConfig: &AuditLogConfig{},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid relative audit log path": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					AuditPolicy: &runtime.RawExtension{
+						Raw: []byte(`{"apiVersion":"audit.k8s.io/v1","kind":"Policy"}`),
+					},
+					AuditLogConfig: &AuditLogConfig{
+						Path: "audit.log",
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid network config": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					NetworkConfig: []NetworkInterface{
+						{
+							Name:      "eth0",
+							Addresses: []string{"192.168.1.10/24", "fd00::10/64"},
+							Gateway:   "192.168.1.1",
+							DNS:       []string{"192.168.1.2"},
+						},
+					},
+				},
+			},
+		},
+		"invalid network config without interface name": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					NetworkConfig: []NetworkInterface{
+						{
+							Addresses: []string{"192.168.1.10/24"},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid network config with duplicate interface names": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					NetworkConfig: []NetworkInterface{
+						{
+							Name:      "eth0",
+							Addresses: []string{"192.168.1.10/24"},
+						},
+						{
+							Name:      "eth0",
+							Addresses: []string{"192.168.2.10/24"},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid network config without addresses": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					NetworkConfig: []NetworkInterface{
+						{
+							Name: "eth0",
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid network config with address not in CIDR notation": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					NetworkConfig: []NetworkInterface{
+						{
+							Name:      "eth0",
+							Addresses: []string{"192.168.1.10"},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid network config gateway": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					NetworkConfig: []NetworkInterface{
+						{
+							Name:      "eth0",
+							Addresses: []string{"192.168.1.10/24"},
+							Gateway:   "192.168.1",
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid network config DNS server": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					NetworkConfig: []NetworkInterface{
+						{
+							Name:      "eth0",
+							Addresses: []string{"192.168.1.10/24"},
+							DNS:       []string{"dns.example.com"},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid network config with MTU and routes": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					NetworkConfig: []NetworkInterface{
+						{
+							Name:      "eth0",
+							Addresses: []string{"192.168.1.10/24"},
+							Gateway:   "192.168.1.1",
+						},
+						{
+							Name:      "eth1",
+							Addresses: []string{"10.10.0.10/24"},
+							MTU:       pointer.Int32Ptr(9000),
+							Routes: []NetworkRoute{
+								{To: "10.20.0.0/16", Via: "10.10.0.1", Metric: pointer.Int32Ptr(100)},
+								{To: "10.30.0.0/16"},
+							},
+						},
+					},
+				},
+			},
+		},
+		"invalid network config MTU": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					NetworkConfig: []NetworkInterface{
+						{
+							Name:      "eth0",
+							Addresses: []string{"192.168.1.10/24"},
+							MTU:       pointer.Int32Ptr(67),
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid network config route destination": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					NetworkConfig: []NetworkInterface{
+						{
+							Name:      "eth0",
+							Addresses: []string{"192.168.1.10/24"},
+							Routes:    []NetworkRoute{{To: "10.20.0.0"}},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid network config route gateway": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					NetworkConfig: []NetworkInterface{
+						{
+							Name:      "eth0",
+							Addresses: []string{"192.168.1.10/24"},
+							Routes:    []NetworkRoute{{To: "10.20.0.0/16", Via: "gateway.example.com"}},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid node-local DNS": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					NodeLocalDNS: &NodeLocalDNSConfig{
+						Address: "169.254.20.10",
+					},
+				},
+			},
+		},
+		"valid user home directory and shell": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Users: []User{
+						{
+							Name:    "admin",
+							HomeDir: pointer.StringPtr("/home/admin"),
+							Shell:   pointer.StringPtr("/bin/bash"),
+						},
+					},
+				},
+			},
+		},
+		"relative user home directory": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Users: []User{
+						{
+							Name:    "admin",
+							HomeDir: pointer.StringPtr("home/admin"),
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"user shell with whitespace": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Users: []User{
+						{
+							Name:  "admin",
+							Shell: pointer.StringPtr("/bin/bash -x"),
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid systemd timers": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SystemdTimers: []SystemdTimer{
+						{Name: "cleanup", Schedule: "daily", Command: "crictl rmi --prune"},
+						{Name: "cert-check", Schedule: "Mon..Fri *-*-* 03:00:00", Command: "kubeadm certs check-expiration"},
+						{Name: "hourly-check", Schedule: "*:0/15", Command: "true"},
+					},
+				},
+			},
+		},
+		"invalid systemd timer schedule": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SystemdTimers: []SystemdTimer{
+						{Name: "cleanup", Schedule: "every day", Command: "crictl rmi --prune"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"duplicate systemd timer names": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SystemdTimers: []SystemdTimer{
+						{Name: "cleanup", Schedule: "daily", Command: "crictl rmi --prune"},
+						{Name: "cleanup", Schedule: "weekly", Command: "true"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid systemd timer name": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SystemdTimers: []SystemdTimer{
+						{Name: "clean up", Schedule: "daily", Command: "crictl rmi --prune"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"missing systemd timer command": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SystemdTimers: []SystemdTimer{
+						{Name: "cleanup", Schedule: "daily", Command: ""},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid sandbox image": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SandboxImage: pointer.StringPtr("registry.example.com/pause:3.5"),
+				},
+			},
+		},
+		"invalid sandbox image": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SandboxImage: pointer.StringPtr("registry.example.com/Pause:3.5"),
+				},
+			},
+			expectErr: true,
+		},
+		"valid remount options": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					RemountOptions: []RemountSpec{
+						{Path: "/", Options: []string{"nosuid", "nodev"}},
+						{Path: "/tmp", Options: []string{"noexec", "mode=1777"}},
+					},
+				},
+			},
+		},
+		"invalid remount path": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					RemountOptions: []RemountSpec{
+						{Path: "/usr/", Options: []string{"ro"}},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"duplicate remount paths": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					RemountOptions: []RemountSpec{
+						{Path: "/usr", Options: []string{"ro"}},
+						{Path: "/usr", Options: []string{"nodev"}},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid remount option": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					RemountOptions: []RemountSpec{
+						{Path: "/", Options: []string{"nosuid,nodev"}},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"missing remount options": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					RemountOptions: []RemountSpec{
+						{Path: "/"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid ignore preflight errors": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					IgnorePreflightErrors: []string{"Swap", "NumCPU", "DirAvailable--var-lib-etcd", "UnknownCheck"},
+				},
+			},
+		},
+		"ignore preflight error with a comma": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					IgnorePreflightErrors: []string{"Swap,NumCPU"},
+				},
+			},
+			expectErr: true,
+		},
+		"duplicate ignore preflight errors": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					IgnorePreflightErrors: []string{"Swap", "swap"},
+				},
+			},
+			expectErr: true,
+		},
+		"valid etcd data dir mounted by label": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Filesystems: []Filesystem{{Device: "/dev/sdb", Filesystem: "ext4", Label: "etcd_disk"}},
+					},
+					Mounts:      []MountPoints{{"LABEL=etcd_disk", "/var/lib/etcddisk"}},
+					EtcdDataDir: pointer.StringPtr("/var/lib/etcddisk/etcd"),
+				},
+			},
+		},
+		"valid etcd data dir mounted by device": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Filesystems: []Filesystem{{Device: "/dev/sdb", Filesystem: "ext4", Label: "etcd_disk"}},
+					},
+					Mounts:      []MountPoints{{"/dev/sdb", "/var/lib/etcd"}},
+					EtcdDataDir: pointer.StringPtr("/var/lib/etcd"),
+					ClusterConfiguration: &ClusterConfiguration{
+						Etcd: Etcd{Local: &LocalEtcd{DataDir: "/var/lib/etcd"}},
+					},
+				},
+			},
+		},
+		"etcd data dir not mounted from a declared filesystem": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Filesystems: []Filesystem{{Device: "/dev/sdb", Filesystem: "ext4", Label: "etcd_disk"}},
+					},
+					Mounts:      []MountPoints{{"/dev/sdc", "/var/lib/etcd"}},
+					EtcdDataDir: pointer.StringPtr("/var/lib/etcd"),
+				},
+			},
+			expectErr: true,
+		},
+		"etcd data dir outside of the mount point": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Filesystems: []Filesystem{{Device: "/dev/sdb", Filesystem: "ext4", Label: "etcd_disk"}},
+					},
+					Mounts:      []MountPoints{{"LABEL=etcd_disk", "/var/lib/etcddisk"}},
+					EtcdDataDir: pointer.StringPtr("/var/lib/etcddisk-data"),
+				},
+			},
+			expectErr: true,
+		},
+		"etcd data dir conflicting with the local etcd data dir": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Filesystems: []Filesystem{{Device: "/dev/sdb", Filesystem: "ext4", Label: "etcd_disk"}},
+					},
+					Mounts:      []MountPoints{{"LABEL=etcd_disk", "/var/lib/etcddisk"}},
+					EtcdDataDir: pointer.StringPtr("/var/lib/etcddisk/etcd"),
+					ClusterConfiguration: &ClusterConfiguration{
+						Etcd: Etcd{Local: &LocalEtcd{DataDir: "/var/lib/etcd"}},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"etcd data dir with an external etcd": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Filesystems: []Filesystem{{Device: "/dev/sdb", Filesystem: "ext4", Label: "etcd_disk"}},
+					},
+					Mounts:      []MountPoints{{"LABEL=etcd_disk", "/var/lib/etcddisk"}},
+					EtcdDataDir: pointer.StringPtr("/var/lib/etcddisk/etcd"),
+					ClusterConfiguration: &ClusterConfiguration{
+						Etcd: Etcd{External: &ExternalEtcd{Endpoints: []string{"https://etcd:2379"}}},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"relative etcd data dir": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Filesystems: []Filesystem{{Device: "/dev/sdb", Filesystem: "ext4", Label: "etcd_disk"}},
+					},
+					Mounts:      []MountPoints{{"LABEL=etcd_disk", "/"}},
+					EtcdDataDir: pointer.StringPtr("var/lib/etcd"),
+				},
+			},
+			expectErr: true,
+		},
+		"unconfirmed partition overwrite": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Partitions: []Partition{{Device: "/dev/sdb", Layout: true, Overwrite: pointer.BoolPtr(true)}},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"confirmed partition overwrite": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Partitions: []Partition{{Device: "/dev/sdb", Layout: true, Overwrite: pointer.BoolPtr(true), ConfirmWipe: true}},
+					},
+				},
+			},
+		},
+		"unconfirmed filesystem overwrite": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Partitions:  []Partition{{Device: "/dev/sdb", Layout: true}},
+						Filesystems: []Filesystem{{Device: "/dev/sdb1", Filesystem: "ext4", Label: "data", Overwrite: pointer.BoolPtr(true)}},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"filesystem overwrite confirmed for another device": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Partitions:  []Partition{{Device: "/dev/sdc", Layout: true, ConfirmWipe: true}},
+						Filesystems: []Filesystem{{Device: "/dev/sdb1", Filesystem: "ext4", Label: "data", Overwrite: pointer.BoolPtr(true)}},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"confirmed filesystem overwrite": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Partitions:  []Partition{{Device: "/dev/sdb", Layout: true, ConfirmWipe: true}},
+						Filesystems: []Filesystem{{Device: "/dev/sdb1", Filesystem: "ext4", Label: "data", Overwrite: pointer.BoolPtr(true)}},
+					},
+				},
+			},
+		},
+		"non destructive disk setup": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Partitions:  []Partition{{Device: "/dev/sdb", Layout: true, Overwrite: pointer.BoolPtr(false)}},
+						Filesystems: []Filesystem{{Device: "ephemeral0.1", Filesystem: "ext4", Label: "ephemeral0", ReplaceFS: pointer.StringPtr("ntfs")}},
+					},
+				},
+			},
+		},
+		"valid publish target": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					PublishConfigTo: &PublishTarget{
+						ConfigMapName: "bootstrap-config",
+						Namespace:     "kube-system",
+					},
+				},
+			},
+		},
+		"invalid publish ConfigMap name": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					PublishConfigTo: &PublishTarget{
+						ConfigMapName: "Bootstrap_Config",
+						Namespace:     "kube-system",
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid publish namespace": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					PublishConfigTo: &PublishTarget{
+						ConfigMapName: "bootstrap-config",
+						Namespace:     "kube.system",
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid ssh hardening": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SSHHardening: &SSHHardeningConfig{
+						PasswordAuthentication: pointer.BoolPtr(false),
+						PermitRootLogin:        PermitRootLoginNo,
+						Ciphers:                []string{"chacha20-poly1305@openssh.com", "aes256-ctr"},
+						MACs:                   []string{"hmac-sha2-512-etm@openssh.com", "umac-128@openssh.com"},
+					},
+				},
+			},
+		},
+		"invalid ssh PermitRootLogin": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SSHHardening: &SSHHardeningConfig{
+						PermitRootLogin: "without-password",
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"unsupported ssh cipher": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SSHHardening: &SSHHardeningConfig{
+						Ciphers: []string{"aes256-ctr", "arcfour"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"duplicate ssh MAC": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SSHHardening: &SSHHardeningConfig{
+						MACs: []string{"hmac-sha2-256", "hmac-sha2-256"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid bootstrap token TTL and usages": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					BootstrapTokenTTL:    &metav1.Duration{Duration: time.Hour},
+					BootstrapTokenUsages: []string{"authentication", "signing"},
+				},
+			},
+		},
+		"non-positive bootstrap token TTL": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					BootstrapTokenTTL: &metav1.Duration{Duration: 0},
+				},
+			},
+			expectErr: true,
+		},
+		"bootstrap token TTL shorter than a minute": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					BootstrapTokenTTL: &metav1.Duration{Duration: 30 * time.Second},
+				},
+			},
+			expectErr: true,
+		},
+		"bootstrap token TTL of a minute": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					BootstrapTokenTTL: &metav1.Duration{Duration: time.Minute},
+				},
+			},
+		},
+		"unknown bootstrap token usage": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					BootstrapTokenUsages: []string{"authentication", "single-use"},
+				},
+			},
+			expectErr: true,
+		},
+		"bootstrap token usages without authentication": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					BootstrapTokenUsages: []string{"signing"},
+				},
+			},
+			expectErr: true,
+		},
+		"external cloud provider without cloud config": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					CloudProvider: &CloudProviderConfig{Name: "external"},
+				},
+			},
+		},
+		"cloud provider with cloud config": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					CloudProvider: &CloudProviderConfig{
+						Name:       "openstack",
+						ConfigFrom: &FileSource{Secret: SecretFileSource{Name: "cloud-config", Key: "cloud.conf"}},
+					},
+				},
+			},
+		},
+		"cloud provider without name": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					CloudProvider: &CloudProviderConfig{},
+				},
+			},
+			expectErr: true,
+		},
+		"cloud config without secret key": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					CloudProvider: &CloudProviderConfig{
+						Name:       "external",
+						ConfigFrom: &FileSource{Secret: SecretFileSource{Name: "cloud-config"}},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"FIPS mode with FIPS approved sshd algorithms": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					FIPSMode: pointer.BoolPtr(true),
+					SSHHardening: &SSHHardeningConfig{
+						Ciphers: []string{"aes256-gcm@openssh.com", "aes256-ctr"},
+						MACs:    []string{"hmac-sha2-512-etm@openssh.com"},
+					},
+				},
+			},
+		},
+		"FIPS mode with a non FIPS sshd cipher": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					FIPSMode: pointer.BoolPtr(true),
+					SSHHardening: &SSHHardeningConfig{
+						Ciphers: []string{"aes256-gcm@openssh.com", "chacha20-poly1305@openssh.com"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"FIPS mode with a non FIPS sshd MAC": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					FIPSMode: pointer.BoolPtr(true),
+					SSHHardening: &SSHHardeningConfig{
+						MACs: []string{"umac-128-etm@openssh.com"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"seccomp default and user namespaces with a supported version": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ClusterConfiguration: &ClusterConfiguration{KubernetesVersion: "v1.25.3"},
+					SeccompDefault:       pointer.BoolPtr(true),
+					UserNamespaces:       pointer.BoolPtr(true),
+				},
+			},
+		},
+		"seccomp default and user namespaces without a version": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SeccompDefault: pointer.BoolPtr(true),
+					UserNamespaces: pointer.BoolPtr(true),
+				},
+			},
+		},
+		"seccomp default with an unsupported version": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ClusterConfiguration: &ClusterConfiguration{KubernetesVersion: "v1.21.2"},
+					SeccompDefault:       pointer.BoolPtr(true),
+				},
+			},
+			expectErr: true,
+		},
+		"user namespaces with an unsupported version": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ClusterConfiguration: &ClusterConfiguration{KubernetesVersion: "v1.24.0"},
+					UserNamespaces:       pointer.BoolPtr(true),
+				},
+			},
+			expectErr: true,
+		},
+		"valid reserved resources": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ReservedResources: &ReservedResourcesConfig{
+						KubeReserved:   map[string]string{"cpu": "100m", "memory": "256Mi", "pid": "1000"},
+						SystemReserved: map[string]string{"ephemeral-storage": "1Gi"},
+						EvictionHard:   map[string]string{"memory.available": "100Mi", "nodefs.available": "7.5%"},
+					},
+				},
+			},
+		},
+		"reserved resource with an unknown resource": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ReservedResources: &ReservedResourcesConfig{
+						KubeReserved: map[string]string{"gpu": "1"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"reserved resource with an invalid quantity": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ReservedResources: &ReservedResourcesConfig{
+						SystemReserved: map[string]string{"memory": "lots"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"reserved resource with a negative quantity": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ReservedResources: &ReservedResourcesConfig{
+						KubeReserved: map[string]string{"cpu": "-100m"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"eviction threshold with an unknown signal": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ReservedResources: &ReservedResourcesConfig{
+						EvictionHard: map[string]string{"cpu.available": "10%"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"eviction threshold above 100%": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ReservedResources: &ReservedResourcesConfig{
+						EvictionHard: map[string]string{"nodefs.available": "110%"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid kdump": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Kdump: &KdumpConfig{CrashKernel: "1G-4G:192M,4G-:256M@16M", Path: "/var/crash"},
+				},
+			},
+		},
+		"valid kdump with a high crash kernel": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Kdump: &KdumpConfig{CrashKernel: "512M,high"},
+				},
+			},
+		},
+		"kdump with an invalid crash kernel": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Kdump: &KdumpConfig{CrashKernel: "256MB"},
+				},
+			},
+			expectErr: true,
+		},
+		"kdump with a relative path": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Kdump: &KdumpConfig{CrashKernel: "auto", Path: "var/crash"},
+				},
+			},
+			expectErr: true,
+		},
+		"valid node problem detector": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					InstallNodeProblemDetector: &NPDConfig{
+						Image: "registry.k8s.io/node-problem-detector/node-problem-detector:v0.8.10",
+						ConfigFrom: &FileSource{
+							Secret: SecretFileSource{Name: "npd-config", Key: "system-log-monitor.json"},
+						},
+					},
+				},
+			},
+		},
+		"node problem detector with an invalid image": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					InstallNodeProblemDetector: &NPDConfig{
+						Image: "Node-Problem-Detector:latest",
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"node problem detector with an incomplete config source": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					InstallNodeProblemDetector: &NPDConfig{
+						Image:      "registry.k8s.io/node-problem-detector/node-problem-detector:v0.8.10",
+						ConfigFrom: &FileSource{Secret: SecretFileSource{Name: "npd-config"}},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid static pods": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					StaticPods: []StaticPod{
+						{
+							Name: "registry-proxy",
+							ManifestFrom: &FileSource{
+								Secret: SecretFileSource{Name: "static-pods", Key: "registry-proxy.yaml"},
+							},
+						},
+						{
+							Name: "metrics-agent",
+							ManifestFrom: &FileSource{
+								Secret: SecretFileSource{Name: "static-pods", Key: "metrics-agent.yaml"},
+							},
+						},
+					},
+				},
+			},
+		},
+		"static pods with duplicate names": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					StaticPods: []StaticPod{
+						{
+							Name: "registry-proxy",
+							ManifestFrom: &FileSource{
+								Secret: SecretFileSource{Name: "static-pods", Key: "registry-proxy.yaml"},
+							},
+						},
+						{
+							Name: "registry-proxy",
+							ManifestFrom: &FileSource{
+								Secret: SecretFileSource{Name: "static-pods", Key: "registry-proxy.yaml"},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"static pod with an invalid name": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					StaticPods: []StaticPod{
+						{
+							Name: "Registry_Proxy",
+							ManifestFrom: &FileSource{
+								Secret: SecretFileSource{Name: "static-pods", Key: "Registry_Proxy.yaml"},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"static pod overwriting a kubeadm static pod": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					StaticPods: []StaticPod{
+						{
+							Name: "kube-apiserver",
+							ManifestFrom: &FileSource{
+								Secret: SecretFileSource{Name: "static-pods", Key: "kube-apiserver.yaml"},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"static pod without a manifest source": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					StaticPods: []StaticPod{
+						{Name: "registry-proxy"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"static pod with an incomplete manifest source": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					StaticPods: []StaticPod{
+						{
+							Name:         "registry-proxy",
+							ManifestFrom: &FileSource{Secret: SecretFileSource{Name: "static-pods"}},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid runtime handlers": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					RuntimeHandlers: []RuntimeHandler{
+						{Name: "runsc", RuntimeType: "io.containerd.runsc.v1"},
+						{Name: "kata", RuntimeType: "io.containerd.kata.v2", BinaryPath: "/opt/kata/bin/containerd-shim-kata-v2"},
+					},
+				},
+			},
+		},
+		"runtime handlers with duplicate names": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					RuntimeHandlers: []RuntimeHandler{
+						{Name: "runsc", RuntimeType: "io.containerd.runsc.v1"},
+						{Name: "runsc", RuntimeType: "io.containerd.runsc.v1"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"runtime handler with an invalid name": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					RuntimeHandlers: []RuntimeHandler{
+						{Name: "gVisor", RuntimeType: "io.containerd.runsc.v1"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"runtime handler with an invalid runtime type": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					RuntimeHandlers: []RuntimeHandler{
+						{Name: "runsc", RuntimeType: "runsc"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"runtime handler with a relative binary path": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					RuntimeHandlers: []RuntimeHandler{
+						{Name: "kata", RuntimeType: "io.containerd.kata.v2", BinaryPath: "bin/containerd-shim-kata-v2"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid timezone": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Timezone: pointer.StringPtr("Europe/Berlin"),
+				},
+			},
+			expectErr: false,
+		},
+		"unknown timezone": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Timezone: pointer.StringPtr("Europe/Atlantis"),
+				},
+			},
+			expectErr: true,
+		},
+		"local timezone": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Timezone: pointer.StringPtr("Local"),
+				},
+			},
+			expectErr: true,
+		},
+		"valid max pods": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					MaxPods: pointer.Int32Ptr(250),
+				},
+			},
+			expectErr: false,
+		},
+		"zero max pods": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					MaxPods: pointer.Int32Ptr(0),
+				},
+			},
+			expectErr: true,
+		},
+		"valid image gc thresholds": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ImageGC: &ImageGCConfig{HighThresholdPercent: 70, LowThresholdPercent: 50},
+				},
+			},
+			expectErr: false,
+		},
+		"image gc threshold above 100": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ImageGC: &ImageGCConfig{HighThresholdPercent: 110, LowThresholdPercent: 50},
+				},
+			},
+			expectErr: true,
+		},
+		"image gc high threshold not above the low one": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ImageGC: &ImageGCConfig{HighThresholdPercent: 50, LowThresholdPercent: 50},
+				},
+			},
+			expectErr: true,
+		},
+		"valid boot logs sink": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					UploadBootLogsTo: &LogSink{
+						URL: "https://logs.example.com/boot",
+						CredentialsFrom: &FileSource{
+							Secret: SecretFileSource{Name: "boot-logs", Key: "headers"},
+						},
+					},
+				},
+			},
+			expectErr: false,
+		},
+		"boot logs sink without scheme": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					UploadBootLogsTo: &LogSink{URL: "logs.example.com/boot"},
+				},
+			},
+			expectErr: true,
+		},
+		"boot logs sink with a quote": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					UploadBootLogsTo: &LogSink{URL: "https://logs.example.com/'boot'"},
+				},
+			},
+			expectErr: true,
+		},
+		"boot logs credentials without key": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					UploadBootLogsTo: &LogSink{
+						URL: "https://logs.example.com/boot",
+						CredentialsFrom: &FileSource{
+							Secret: SecretFileSource{Name: "boot-logs"},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid startup taint": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					StartupTaint: &corev1.Taint{Key: "example.com/bootstrapping", Value: "true", Effect: corev1.TaintEffectNoSchedule},
+				},
+			},
+			expectErr: false,
+		},
+		"startup taint with invalid key": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					StartupTaint: &corev1.Taint{Key: "bootstrapping!", Effect: corev1.TaintEffectNoSchedule},
+				},
+			},
+			expectErr: true,
+		},
+		"startup taint without effect": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					StartupTaint: &corev1.Taint{Key: "example.com/bootstrapping"},
+				},
+			},
+			expectErr: true,
+		},
+		"valid kubelet root dir mounted by label": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Filesystems: []Filesystem{{Device: "/dev/sdb", Filesystem: "ext4", Label: "kubelet_disk"}},
+					},
+					Mounts:         []MountPoints{{"LABEL=kubelet_disk", "/var/lib/kubelet"}},
+					KubeletRootDir: pointer.StringPtr("/var/lib/kubelet"),
+				},
+			},
+			expectErr: false,
+		},
+		"kubelet root dir not mounted from a declared filesystem": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Filesystems: []Filesystem{{Device: "/dev/sdb", Filesystem: "ext4", Label: "kubelet_disk"}},
+					},
+					Mounts:         []MountPoints{{"LABEL=kubelet_disk", "/var/lib/kubelet-disk"}},
+					KubeletRootDir: pointer.StringPtr("/var/lib/kubelet"),
+				},
+			},
+			expectErr: true,
+		},
+		"kubelet root dir conflicting with the root-dir kubelet arg": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Filesystems: []Filesystem{{Device: "/dev/sdb", Filesystem: "ext4", Label: "kubelet_disk"}},
+					},
+					Mounts:         []MountPoints{{"LABEL=kubelet_disk", "/var/lib/kubelet-disk"}},
+					KubeletRootDir: pointer.StringPtr("/var/lib/kubelet-disk"),
+					JoinConfiguration: &JoinConfiguration{
+						NodeRegistration: NodeRegistrationOptions{
+							KubeletExtraArgs: map[string]string{"root-dir": "/var/lib/kubelet"},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"kubelet root dir not clean": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					DiskSetup: &DiskSetup{
+						Filesystems: []Filesystem{{Device: "/dev/sdb", Filesystem: "ext4", Label: "kubelet_disk"}},
+					},
+					Mounts:         []MountPoints{{"LABEL=kubelet_disk", "/var/lib/kubelet-disk"}},
+					KubeletRootDir: pointer.StringPtr("/var/lib/kubelet-disk/"),
+				},
+			},
+			expectErr: true,
+		},
+		"valid host DNS": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					HostDNS: &HostDNSConfig{
+						Nameservers: []string{"10.0.0.2", "fd00::2"},
+						Search:      []string{"corp.example.com"},
+					},
+					DNSManagement: DNSManagementResolvConf,
+				},
+			},
+			expectErr: false,
+		},
+		"host DNS with invalid nameserver": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					HostDNS: &HostDNSConfig{
+						Nameservers: []string{"dns.example.com"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"host DNS with invalid search domain": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					HostDNS: &HostDNSConfig{
+						Search: []string{"Corp_Example"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"empty host DNS": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					HostDNS: &HostDNSConfig{},
+				},
+			},
+			expectErr: true,
+		},
+		"valid containerd metrics address": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ContainerdMetrics: &ContainerdMetricsConfig{Address: "127.0.0.1:1338"},
+				},
+			},
+			expectErr: false,
+		},
+		"containerd metrics address without port": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ContainerdMetrics: &ContainerdMetricsConfig{Address: "127.0.0.1"},
+				},
+			},
+			expectErr: true,
+		},
+		"containerd metrics address with hostname": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ContainerdMetrics: &ContainerdMetricsConfig{Address: "localhost:1338"},
+				},
+			},
+			expectErr: true,
+		},
+		"containerd metrics address with invalid port": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ContainerdMetrics: &ContainerdMetricsConfig{Address: "[::1]:70000"},
+				},
+			},
+			expectErr: true,
+		},
+		"valid cgroup v2 with swap": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					CgroupV2:   pointer.BoolPtr(true),
+					FailSwapOn: pointer.BoolPtr(false),
+				},
+			},
+			expectErr: false,
+		},
+		"swap without cgroup v2": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					FailSwapOn: pointer.BoolPtr(false),
+				},
+			},
+			expectErr: true,
+		},
+		"swap on unsupported Kubernetes version": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ClusterConfiguration: &ClusterConfiguration{KubernetesVersion: "v1.21.3"},
+					CgroupV2:             pointer.BoolPtr(true),
+					FailSwapOn:           pointer.BoolPtr(false),
+				},
+			},
+			expectErr: true,
+		},
+		"cgroup v2 with conflicting cgroup driver kubelet arg": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					CgroupV2: pointer.BoolPtr(true),
+					JoinConfiguration: &JoinConfiguration{
+						NodeRegistration: NodeRegistrationOptions{
+							KubeletExtraArgs: map[string]string{"cgroup-driver": "cgroupfs"},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"swap with conflicting fail-swap-on kubelet arg": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					CgroupV2:   pointer.BoolPtr(true),
+					FailSwapOn: pointer.BoolPtr(false),
+					InitConfiguration: &InitConfiguration{
+						NodeRegistration: NodeRegistrationOptions{
+							KubeletExtraArgs: map[string]string{"fail-swap-on": "true"},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid auditd rules": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					AuditdRules: []string{"-w /etc/kubernetes -p wa -k kubernetes", "-a always,exit -F arch=b64 -S execve"},
+				},
+			},
+			expectErr: false,
+		},
+		"empty auditd rule": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					AuditdRules: []string{""},
+				},
+			},
+			expectErr: true,
+		},
+		"auditd rule without option": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					AuditdRules: []string{"w /etc/kubernetes -p wa"},
+				},
+			},
+			expectErr: true,
+		},
+		"multi-line auditd rule": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					AuditdRules: []string{"-w /etc/kubernetes -p wa\n-D"},
+				},
+			},
+			expectErr: true,
+		},
+		"valid system limits": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SystemLimits: &SystemLimitsConfig{NoFile: pointer.Int64Ptr(1048576), NProc: pointer.Int64Ptr(65536)},
+				},
+			},
+			expectErr: false,
+		},
+		"empty system limits": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SystemLimits: &SystemLimitsConfig{},
+				},
+			},
+			expectErr: true,
+		},
+		"zero noFile system limit": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SystemLimits: &SystemLimitsConfig{NoFile: pointer.Int64Ptr(0)},
+				},
+			},
+			expectErr: true,
+		},
+		"negative nProc system limit": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					SystemLimits: &SystemLimitsConfig{NProc: pointer.Int64Ptr(-1)},
+				},
+			},
+			expectErr: true,
+		},
+		"valid kubelet serving cert SANs": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					KubeletServingCertSANs: []string{"node.example.com", "*.nodes.example.com", "10.0.0.10", "fd00::10"},
+				},
+			},
+			expectErr: false,
+		},
+		"kubelet serving cert SAN with invalid name": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					KubeletServingCertSANs: []string{"Node_1"},
+				},
+			},
+			expectErr: true,
+		},
+		"kubelet serving cert SANs with server certificate rotation": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					KubeletServingCertSANs:         []string{"node.example.com"},
+					RotateKubeletServerCertificate: pointer.BoolPtr(true),
+				},
+			},
+			expectErr: true,
+		},
+		"kubelet serving cert SANs with tls-cert-file kubelet arg": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					KubeletServingCertSANs: []string{"node.example.com"},
+					JoinConfiguration: &JoinConfiguration{
+						NodeRegistration: NodeRegistrationOptions{
+							KubeletExtraArgs: map[string]string{"tls-cert-file": "/etc/kubelet.crt"},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid containerd pull": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ContainerdPull: &ContainerdPullConfig{MaxConcurrentDownloads: pointer.Int32Ptr(10), PullTimeout: &metav1.Duration{Duration: 5 * time.Minute}},
+				},
+			},
+			expectErr: false,
+		},
+		"empty containerd pull": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ContainerdPull: &ContainerdPullConfig{},
+				},
+			},
+			expectErr: true,
+		},
+		"containerd pull with zero max concurrent downloads": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ContainerdPull: &ContainerdPullConfig{MaxConcurrentDownloads: pointer.Int32Ptr(0)},
+				},
+			},
+			expectErr: true,
+		},
+		"containerd pull with negative timeout": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					ContainerdPull: &ContainerdPullConfig{PullTimeout: &metav1.Duration{Duration: -time.Minute}},
+				},
+			},
+			expectErr: true,
+		},
+		"valid packages": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Packages: []string{"nfs-common", "open-iscsi=2.0.874-7.1ubuntu6", "libstdc++6"},
+				},
+			},
+		},
+		"invalid package name": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Packages: []string{"nfs-common; rm -rf /"},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid node-local DNS address": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					NodeLocalDNS: &NodeLocalDNSConfig{
+						Address: "169.254.20.10/32",
+					},
+				},
+			},
+			expectErr: true,
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+			if tt.expectErr {
+				g.Expect(tt.in.ValidateCreate()).NotTo(Succeed())
+				g.Expect(tt.in.ValidateUpdate(&KubeadmConfig{})).NotTo(Succeed())
+			} else {
+				g.Expect(tt.in.ValidateCreate()).To(Succeed())
+				g.Expect(tt.in.ValidateUpdate(&KubeadmConfig{})).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestClusterValidateUpdateDiskSetupWipes(t *testing.T) {
+	unconfirmed := &KubeadmConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "baz",
+			Namespace: "default",
+		},
+		Spec: KubeadmConfigSpec{
+			DiskSetup: &DiskSetup{
+				Partitions: []Partition{{Device: "/dev/sdb", Layout: true, Overwrite: pointer.BoolPtr(true)}},
+			},
+		},
+	}
+
+	t.Run("accepts an update keeping the disk setup", func(t *testing.T) {
+		g := NewWithT(t)
+		updated := unconfirmed.DeepCopy()
+		updated.Spec.Packages = []string{"nfs-common"}
+		g.Expect(updated.ValidateUpdate(unconfirmed)).To(Succeed())
+	})
+
+	t.Run("rejects an update changing the disk setup", func(t *testing.T) {
+		g := NewWithT(t)
+		updated := unconfirmed.DeepCopy()
+		updated.Spec.DiskSetup.Partitions = append(updated.Spec.DiskSetup.Partitions, Partition{Device: "/dev/sdc", Layout: true})
+		g.Expect(updated.ValidateUpdate(unconfirmed)).NotTo(Succeed())
+	})
 }
@@ -18,20 +18,228 @@ package v1alpha4
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
 
+	// The tz database is embedded so that timezones are validated independently of the webhook image.
+	_ "time/tzdata"
+
+	"github.com/docker/distribution/reference"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	versionutil "k8s.io/apimachinery/pkg/util/version"
+	bootstrapapi "k8s.io/cluster-bootstrap/token/api"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	ConflictingFileSourceMsg           = "only one of content of contentFrom may be specified for a single file"
+	MissingFileSourceMsg               = "source for file content must be specified if contenFrom is non-nil"
+	MissingSecretNameMsg               = "secret file source must specify non-empty secret name"
+	MissingSecretKeyMsg                = "secret file source must specify non-empty secret key"
+	PathConflictMsg                    = "path property must be unique among all files"
+	InvalidShutdownTimeoutMsg          = "graceful shutdown timeout must be greater than zero"
+	ConflictingFileSourcesEncodingMsg  = "base64 encoded contents cannot be assembled from multiple contentFrom sources"
+	InvalidWaitForNodeReadyTimeoutMsg  = "wait for node ready timeout must be greater than zero"
+	InvalidPartitionNumberMsg          = "partition number must be greater than zero and unique for the device"
+	InvalidPartitionSizeMsg            = "partition size must be positive, or 0 or -1 to grow to fill the device, and requires a partition number"
+	ConflictingPartitionLayoutMsg      = "layout must be false and tableType must be gpt when a partition number is set"
+	NonContiguousPartitionNumbersMsg   = "partition numbers must be contiguous for the device"
+	PartitionFillNotLastMsg            = "only the partition with the highest number on the device may grow to fill it"
+	UnconfirmedWipeMsg                 = "overwriting the partitions or filesystems of a device requires confirmWipe on a partition of the device"
+	MalformedMountPointMsg             = "mount entry must be [device, mountpoint, type, options, dump, pass], with at least device and mountpoint set"
+	InvalidMountPointFieldMsg          = "mount entry fields must not be empty or contain whitespace"
+	InvalidMountPointPathMsg           = "mount entry mountpoint must be an absolute path, or none for swap"
+	InvalidMountPointNumberMsg         = "mount entry dump and pass fields must be non-negative integers"
+	InvalidPrePullImageMsg             = "pre-pull image must be a valid image reference"
+	InvalidAuditPolicyMsg              = "audit policy must be a valid YAML document"
+	MissingAuditPolicyMsg              = "audit log configuration requires an audit policy"
+	InvalidAuditLogPathMsg             = "audit log path must be an absolute path"
+	InvalidFileTemplateMsg             = "templated file content must be a valid Go template"
+	ConflictingFileTemplateEncodingMsg = "templated file contents cannot be encoded"
+	InvalidNetworkInterfaceNameMsg     = "network interface name must be set and unique"
+	InvalidNetworkAddressMsg           = "network interface address must be an IP address in CIDR notation"
+	InvalidNetworkIPMsg                = "network interface gateway and DNS servers must be IP addresses"
+	InvalidNetworkMTUMsg               = "network interface MTU must be between 68 and 65535"
+	InvalidNetworkRouteMsg             = "network route destination must be in CIDR notation, and its gateway an IP address"
+	InvalidNodeLocalDNSAddressMsg      = "node-local DNS address must be an IP address"
+	InvalidUserPathMsg                 = "user home directory and shell must be absolute paths without whitespace"
+	InvalidSystemdTimerNameMsg         = "systemd timer name must be set, unique, and only contain alphanumerics, ':', '_', '.' and '-'"
+	InvalidSystemdTimerScheduleMsg     = "systemd timer schedule must be a valid OnCalendar expression"
+	MissingSystemdTimerCommandMsg      = "systemd timer command must be set"
+	InvalidSandboxImageMsg             = "sandbox image must be a valid image reference"
+	InvalidRemountPathMsg              = "remount path must be a unique, clean absolute path without whitespace"
+	InvalidRemountOptionMsg            = "remount options must be set, and each option must be a mount option such as nosuid or mode=0755"
+	InvalidPackageNameMsg              = "package name must start with an alphanumeric and only contain alphanumerics, '+', '-', '.', ':', '~' and '='"
+	InvalidIgnorePreflightErrorMsg     = "ignored preflight error must be set, unique, and only contain alphanumerics, '_', '.' and '-'"
+	InvalidEtcdDataDirMsg              = "etcd data dir must be a clean absolute path without whitespace"
+	MissingEtcdDataDirMountMsg         = "etcd data dir must be mounted from a filesystem declared in diskSetup, by device or by LABEL="
+	ConflictingEtcdDataDirMsg          = "etcd data dir must match clusterConfiguration.etcd.local.dataDir, and requires a local etcd"
+	InvalidPublishConfigMapNameMsg     = "publish ConfigMap name must be a valid DNS subdomain"
+	InvalidPublishNamespaceMsg         = "publish namespace must be a valid DNS label"
+	InvalidSSHPermitRootLoginMsg       = "sshd PermitRootLogin must be one of yes, no, prohibit-password or forced-commands-only"
+	InvalidSSHCipherMsg                = "sshd cipher must be unique and supported by OpenSSH, e.g. chacha20-poly1305@openssh.com"
+	InvalidSSHMACMsg                   = "sshd MAC must be unique and supported by OpenSSH, e.g. hmac-sha2-512-etm@openssh.com"
+	InvalidBootstrapTokenTTLMsg        = "bootstrap token TTL must be at least 1m"
+	InvalidBootstrapTokenUsageMsg      = "bootstrap token usage must be unique, and one of signing or authentication"
+	MissingBootstrapTokenAuthUsageMsg  = "bootstrap token usages must include authentication for the kubelet to join"
+	InvalidCloudProviderNameMsg        = "cloud provider name must be set, and only contain lowercase alphanumerics and '-', e.g. external"
+	InvalidCloudConfigSourceMsg        = "cloud config source must reference a secret name and key"
+	UnsupportedSeccompDefaultMsg       = "seccomp default requires Kubernetes v1.22 or later"
+	UnsupportedUserNamespacesMsg       = "user namespaces require Kubernetes v1.25 or later"
+	NonFIPSSSHCipherMsg                = "sshd cipher must be FIPS approved when FIPS mode is enabled, e.g. aes256-gcm@openssh.com"
+	NonFIPSSSHMACMsg                   = "sshd MAC must be FIPS approved when FIPS mode is enabled, e.g. hmac-sha2-512-etm@openssh.com"
+	InvalidReservedResourceMsg         = "reserved resource must be one of cpu, memory, ephemeral-storage or pid, with a non-negative quantity"
+	InvalidEvictionThresholdMsg        = "eviction threshold must be for a kubelet eviction signal, e.g. memory.available, with a non-negative quantity or a percentage"
+	InvalidCrashKernelMsg              = "crashkernel must be auto, a size with an optional offset or high/low suffix, or size ranges, e.g. 256M, 256M@16M or 1G-4G:192M,4G-:256M"
+	InvalidKdumpPathMsg                = "kdump path must be a clean absolute path without whitespace"
+	InvalidNPDImageMsg                 = "node problem detector image must be a valid image reference"
+	InvalidNPDConfigSourceMsg          = "node problem detector config source must reference a secret name and key"
+	InvalidStaticPodNameMsg            = "static pod name must be a unique DNS subdomain, other than the kubeadm control plane static pods"
+	InvalidStaticPodManifestSourceMsg  = "static pod manifest source must reference a secret name and key"
+	InvalidRuntimeHandlerNameMsg       = "runtime handler name must be a unique DNS label"
+	InvalidRuntimeTypeMsg              = "runtime type must be a containerd runtime type, e.g. io.containerd.runsc.v1"
+	InvalidRuntimeBinaryPathMsg        = "runtime binary path must be a clean absolute path without whitespace"
+	InvalidTimezoneMsg                 = "timezone must be a name of the tz database, e.g. Europe/Berlin"
+	InvalidMaxPodsMsg                  = "max pods must be greater than zero"
+	InvalidImageGCThresholdMsg         = "image gc threshold must be a percentage between 0 and 100"
+	InvalidImageGCThresholdsMsg        = "image gc high threshold must be greater than the low threshold"
+	InvalidBootLogsURLMsg              = "boot logs URL must be an http or https URL with a host, without whitespace or single quotes"
+	InvalidBootLogsCredentialsMsg      = "boot logs credentials source must reference a secret name and key"
+	InvalidStartupTaintMsg             = "startup taint must have a qualified name as key, a label value as value, and an effect of NoSchedule, PreferNoSchedule or NoExecute"
+	InvalidKubeletRootDirMsg           = "kubelet root dir must be a clean absolute path without whitespace"
+	MissingKubeletRootDirMountMsg      = "kubelet root dir must be mounted from a filesystem declared in diskSetup, by device or by LABEL="
+	ConflictingKubeletRootDirMsg       = "kubelet root dir must match the root-dir kubelet arg of the node registration"
+	InvalidHostDNSNameserverMsg        = "host DNS nameserver must be an IP address"
+	InvalidHostDNSSearchMsg            = "host DNS search domain must be a valid DNS subdomain"
+	EmptyHostDNSMsg                    = "host DNS must have at least one nameserver or search domain"
+	InvalidContainerdMetricsAddressMsg = "containerd metrics address must be an IP address and a port, e.g. 127.0.0.1:1338"
+	MissingCgroupV2ForSwapMsg          = "swap accounting requires cgroupV2 when failSwapOn is false"
+	UnsupportedSwapMsg                 = "swap requires Kubernetes v1.22 or later"
+	ConflictingCgroupKubeletArgMsg     = "kubelet arg must agree with cgroupV2 and failSwapOn"
+	InvalidAuditdRuleMsg               = "auditd rule must be a single line starting with -, e.g. -w /etc/kubernetes -p wa -k kubernetes"
+	InvalidSystemLimitMsg              = "system limit must be positive"
+	EmptySystemLimitsMsg               = "system limits must set at least one of noFile or nProc"
+	InvalidKubeletServingCertSANMsg    = "kubelet serving certificate SAN must be a DNS subdomain, possibly a wildcard one, or an IP address"
+	ConflictingKubeletServingCertMsg   = "kubelet serving certificate SANs cannot be set together with rotateKubeletServerCertificate or the tls-cert-file and tls-private-key-file kubelet args"
+	InvalidContainerdPullMsg           = "containerd pull setting must be positive"
+	EmptyContainerdPullMsg             = "containerd pull must set at least one of maxConcurrentDownloads or pullTimeout"
+)
+
+const (
+	// crashKernelSize matches a memory size of the crashkernel kernel arg, e.g. "256M".
+	crashKernelSize = `[0-9]+[KMGT]`
+	// crashKernelRange matches a range of system memory sizes and the size reserved for them, e.g. "1G-4G:192M".
+	crashKernelRange = `[0-9]+[KMGT]-([0-9]+[KMGT])?:` + crashKernelSize
+)
+
+const (
+	// minNetworkMTU is the smallest MTU an IPv4 network interface must support, per RFC 791.
+	minNetworkMTU = 68
+	// maxNetworkMTU is the largest MTU that fits in the 16 bit length of an IP packet.
+	maxNetworkMTU = 65535
+)
+
+// minBootstrapTokenTTL is the shortest bootstrap token TTL, leaving the machine a chance to join the cluster before
+// its token expires, and the controller to refresh the token without doing so on every reconcile.
+const minBootstrapTokenTTL = time.Minute
+
+var (
+	// minSeccompDefaultVersion is the first Kubernetes version whose kubelet supports the seccomp default.
+	minSeccompDefaultVersion = versionutil.MustParseSemantic("v1.22.0")
+	// minUserNamespacesVersion is the first Kubernetes version whose kubelet supports user namespaces.
+	minUserNamespacesVersion = versionutil.MustParseSemantic("v1.25.0")
+	// minNodeSwapVersion is the first Kubernetes version whose kubelet supports swap.
+	minNodeSwapVersion = versionutil.MustParseSemantic("v1.22.0")
 )
 
 var (
-	ConflictingFileSourceMsg = "only one of content of contentFrom may be specified for a single file"
-	MissingFileSourceMsg     = "source for file content must be specified if contenFrom is non-nil"
-	MissingSecretNameMsg     = "secret file source must specify non-empty secret name"
-	MissingSecretKeyMsg      = "secret file source must specify non-empty secret key"
-	PathConflictMsg          = "path property must be unique among all files"
+	systemdUnitNameRegex = regexp.MustCompile(`^[a-zA-Z0-9:_.\-]+$`)
+
+	// packageNameRegex matches package names as understood by APT and YUM, optionally pinned to a version,
+	// e.g. "nfs-common" or "nfs-common=1:1.3.4-2.5ubuntu3".
+	packageNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9+\-.:~=]*$`)
+
+	// cloudProviderNameRegex matches the name of a cloud provider, e.g. "external" or "aws".
+	cloudProviderNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9\-]*$`)
+
+	// preflightErrorRegex matches the name of a kubeadm preflight check, e.g. "Swap" or "Port-6443".
+	preflightErrorRegex = regexp.MustCompile(`^[a-zA-Z0-9_.\-]+$`)
+
+	// remountOptionRegex matches a single mount option, optionally with a value, e.g. "nosuid" or "mode=0755".
+	remountOptionRegex = regexp.MustCompile(`^[a-z0-9_]+(=[a-zA-Z0-9_.:/-]+)?$`)
+
+	// onCalendarShorthands are the OnCalendar expressions systemd accepts in place of a full timestamp.
+	onCalendarShorthands = map[string]struct{}{
+		"minutely": {}, "hourly": {}, "daily": {}, "weekly": {}, "monthly": {},
+		"quarterly": {}, "semiannually": {}, "yearly": {}, "annually": {},
+	}
+	// sshCiphers are the ciphers supported by OpenSSH, as listed by ssh -Q cipher.
+	sshCiphers = sets.NewString(
+		"3des-cbc", "aes128-cbc", "aes192-cbc", "aes256-cbc", "aes128-ctr", "aes192-ctr", "aes256-ctr",
+		"aes128-gcm@openssh.com", "aes256-gcm@openssh.com", "chacha20-poly1305@openssh.com",
+	)
+
+	// sshMACs are the message authentication code algorithms supported by OpenSSH, as listed by ssh -Q mac.
+	sshMACs = sets.NewString(
+		"hmac-md5", "hmac-md5-96", "hmac-sha1", "hmac-sha1-96", "hmac-sha2-256", "hmac-sha2-512",
+		"umac-64@openssh.com", "umac-128@openssh.com",
+		"hmac-md5-etm@openssh.com", "hmac-md5-96-etm@openssh.com", "hmac-sha1-etm@openssh.com", "hmac-sha1-96-etm@openssh.com",
+		"hmac-sha2-256-etm@openssh.com", "hmac-sha2-512-etm@openssh.com", "umac-64-etm@openssh.com", "umac-128-etm@openssh.com",
+	)
+
+	// fipsSSHCiphers are the ciphers supported by OpenSSH which are FIPS approved.
+	fipsSSHCiphers = sets.NewString(
+		"aes128-ctr", "aes192-ctr", "aes256-ctr", "aes128-gcm@openssh.com", "aes256-gcm@openssh.com",
+	)
+
+	// fipsSSHMACs are the message authentication code algorithms supported by OpenSSH which are FIPS approved.
+	fipsSSHMACs = sets.NewString(
+		"hmac-sha1", "hmac-sha2-256", "hmac-sha2-512",
+		"hmac-sha1-etm@openssh.com", "hmac-sha2-256-etm@openssh.com", "hmac-sha2-512-etm@openssh.com",
+	)
+
+	// reservableResources are the resources the kubelet can reserve for the Kubernetes and system daemons.
+	reservableResources = sets.NewString("cpu", "memory", "ephemeral-storage", "pid")
+
+	// evictionSignals are the signals the kubelet supports hard eviction thresholds for.
+	evictionSignals = sets.NewString(
+		"memory.available", "nodefs.available", "nodefs.inodesFree", "imagefs.available", "imagefs.inodesFree", "pid.available",
+	)
+
+	// crashKernelRegex matches the value of the crashkernel kernel arg: auto, a size with an optional offset or
+	// high/low suffix, or a comma separated list of ranges of system memory sizes with their reserved size,
+	// with an optional offset, e.g. "256M", "256M@16M", "512M,high" or "1G-4G:192M,4G-:256M".
+	crashKernelRegex = regexp.MustCompile(
+		`^(auto|` + crashKernelSize + `(@` + crashKernelSize + `|,high|,low)?|` +
+			crashKernelRange + `(,` + crashKernelRange + `)*(@` + crashKernelSize + `)?)$`,
+	)
+
+	// evictionPercentageRegex matches an eviction threshold given as a percentage, e.g. "10%" or "7.5%".
+	evictionPercentageRegex = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?%$`)
+
+	// runtimeTypeRegex matches a containerd runtime type, which containerd resolves to the name of its shim
+	// binary, e.g. "io.containerd.kata.v2" to containerd-shim-kata-v2.
+	runtimeTypeRegex = regexp.MustCompile(`^[a-z0-9]+(\.[a-z0-9_\-]+)+\.v[0-9]+$`)
+
+	onCalendarWeekdaysRegex = regexp.MustCompile(`^(Mon|Tue|Wed|Thu|Fri|Sat|Sun)((\.\.|,|-)(Mon|Tue|Wed|Thu|Fri|Sat|Sun))*$`)
+	onCalendarDateRegex     = regexp.MustCompile(`^[0-9*,./~]+-[0-9*,./~]+(-[0-9*,./~]+)?$`)
+	onCalendarTimeRegex     = regexp.MustCompile(`^[0-9*,./]+:[0-9*,./]+(:[0-9*,./]+)?$`)
 )
 
 func (c *KubeadmConfig) SetupWebhookWithManager(mgr ctrl.Manager) error {
@@ -46,12 +254,27 @@ var _ webhook.Validator = &KubeadmConfig{}
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
 func (c *KubeadmConfig) ValidateCreate() error {
-	return c.Spec.validate(c.Name)
+	if err := c.Spec.validate(c.Name); err != nil {
+		return err
+	}
+	return c.Spec.validateDiskSetupWipes(c.Name)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
 func (c *KubeadmConfig) ValidateUpdate(old runtime.Object) error {
-	return c.Spec.validate(c.Name)
+	oldConfig, ok := old.(*KubeadmConfig)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a KubeadmConfig but got a %T", old))
+	}
+
+	if err := c.Spec.validate(c.Name); err != nil {
+		return err
+	}
+	// Configs created before wipes had to be confirmed keep being accepted, as long as their disk setup is unchanged.
+	if reflect.DeepEqual(oldConfig.Spec.DiskSetup, c.Spec.DiskSetup) {
+		return nil
+	}
+	return c.Spec.validateDiskSetupWipes(c.Name)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
@@ -66,7 +289,7 @@ func (c *KubeadmConfigSpec) validate(name string) error {
 
 	for i := range c.Files {
 		file := c.Files[i]
-		if file.Content != "" && file.ContentFrom != nil {
+		if file.Content != "" && len(file.ContentFrom) > 0 {
 			allErrs = append(
 				allErrs,
 				field.Invalid(
@@ -79,28 +302,61 @@ func (c *KubeadmConfigSpec) validate(name string) error {
 		// n.b.: if we ever add types besides Secret as a ContentFrom
 		// Source, we must add webhook validation here for one of the
 		// sources being non-nil.
-		if file.ContentFrom != nil {
-			if file.ContentFrom.Secret.Name == "" {
+		for j, source := range file.ContentFrom {
+			if source.Secret.Name == "" {
 				allErrs = append(
 					allErrs,
 					field.Invalid(
-						field.NewPath("spec", "files", fmt.Sprintf("%d", i), "contentFrom", "secret", "name"),
+						field.NewPath("spec", "files", fmt.Sprintf("%d", i), "contentFrom", fmt.Sprintf("%d", j), "secret", "name"),
 						file,
 						MissingSecretNameMsg,
 					),
 				)
 			}
-			if file.ContentFrom.Secret.Key == "" {
+			if source.Secret.Key == "" {
 				allErrs = append(
 					allErrs,
 					field.Invalid(
-						field.NewPath("spec", "files", fmt.Sprintf("%d", i), "contentFrom", "secret", "key"),
+						field.NewPath("spec", "files", fmt.Sprintf("%d", i), "contentFrom", fmt.Sprintf("%d", j), "secret", "key"),
 						file,
 						MissingSecretKeyMsg,
 					),
 				)
 			}
 		}
+		// Concatenating base64 encoded contents does not produce a valid base64 payload.
+		if len(file.ContentFrom) > 1 && (file.Encoding == Base64 || file.Encoding == GzipBase64) {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					field.NewPath("spec", "files", fmt.Sprintf("%d", i), "encoding"),
+					file,
+					ConflictingFileSourcesEncodingMsg,
+				),
+			)
+		}
+		if file.Template {
+			if file.Encoding != "" {
+				allErrs = append(
+					allErrs,
+					field.Invalid(
+						field.NewPath("spec", "files", fmt.Sprintf("%d", i), "encoding"),
+						file,
+						ConflictingFileTemplateEncodingMsg,
+					),
+				)
+			}
+			if _, err := template.New(file.Path).Parse(file.Content); err != nil {
+				allErrs = append(
+					allErrs,
+					field.Invalid(
+						field.NewPath("spec", "files", fmt.Sprintf("%d", i), "content"),
+						file,
+						InvalidFileTemplateMsg,
+					),
+				)
+			}
+		}
 		_, conflict := knownPaths[file.Path]
 		if conflict {
 			allErrs = append(
@@ -115,8 +371,1069 @@ func (c *KubeadmConfigSpec) validate(name string) error {
 		knownPaths[file.Path] = struct{}{}
 	}
 
+	if c.GracefulShutdown != nil && c.GracefulShutdown.Timeout.Duration <= 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				field.NewPath("spec", "gracefulShutdown", "timeout"),
+				c.GracefulShutdown.Timeout.String(),
+				InvalidShutdownTimeoutMsg,
+			),
+		)
+	}
+
+	if c.WaitForNodeReady != nil && c.WaitForNodeReady.Timeout.Duration <= 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				field.NewPath("spec", "waitForNodeReady", "timeout"),
+				c.WaitForNodeReady.Timeout.String(),
+				InvalidWaitForNodeReadyTimeoutMsg,
+			),
+		)
+	}
+
+	if c.BootstrapTokenTTL != nil && c.BootstrapTokenTTL.Duration < minBootstrapTokenTTL {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				field.NewPath("spec", "bootstrapTokenTTL"),
+				c.BootstrapTokenTTL.String(),
+				InvalidBootstrapTokenTTLMsg,
+			),
+		)
+	}
+	allErrs = append(allErrs, validateBootstrapTokenUsages(field.NewPath("spec", "bootstrapTokenUsages"), c.BootstrapTokenUsages)...)
+
+	if c.DiskSetup != nil {
+		allErrs = append(allErrs, validatePartitions(c.DiskSetup.Partitions)...)
+	}
+
+	for i, image := range c.PrePullImages {
+		if _, err := reference.ParseNormalizedNamed(image); err != nil {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					field.NewPath("spec", "prePullImages", fmt.Sprintf("%d", i)),
+					image,
+					fmt.Sprintf("%s: %v", InvalidPrePullImageMsg, err),
+				),
+			)
+		}
+	}
+
+	for i, mount := range c.Mounts {
+		allErrs = append(allErrs, validateMountPoints(field.NewPath("spec", "mounts", fmt.Sprintf("%d", i)), mount)...)
+	}
+
+	if c.AuditPolicy != nil {
+		policy := map[string]interface{}{}
+		if err := yaml.Unmarshal(c.AuditPolicy.Raw, &policy); err != nil || len(policy) == 0 {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					field.NewPath("spec", "auditPolicy"),
+					string(c.AuditPolicy.Raw),
+					InvalidAuditPolicyMsg,
+				),
+			)
+		}
+	}
+
+	if c.AuditLogConfig != nil {
+		if c.AuditPolicy == nil {
+			allErrs = append(
+				allErrs,
+				field.Required(
+					field.NewPath("spec", "auditPolicy"),
+					MissingAuditPolicyMsg,
+				),
+			)
+		}
+		if c.AuditLogConfig.Path != "" && !strings.HasPrefix(c.AuditLogConfig.Path, "/") {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					field.NewPath("spec", "auditLogConfig", "path"),
+					c.AuditLogConfig.Path,
+					InvalidAuditLogPathMsg,
+				),
+			)
+		}
+	}
+
+	for i, user := range c.Users {
+		if user.HomeDir != nil && !isAbsolutePathWithoutWhitespace(*user.HomeDir) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "users").Index(i).Child("homeDir"), *user.HomeDir, InvalidUserPathMsg))
+		}
+		if user.Shell != nil && !isAbsolutePathWithoutWhitespace(*user.Shell) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "users").Index(i).Child("shell"), *user.Shell, InvalidUserPathMsg))
+		}
+	}
+
+	allErrs = append(allErrs, validateNetworkConfig(field.NewPath("spec", "networkConfig"), c.NetworkConfig)...)
+	allErrs = append(allErrs, validateSystemdTimers(field.NewPath("spec", "systemdTimers"), c.SystemdTimers)...)
+	allErrs = append(allErrs, validateRemountOptions(field.NewPath("spec", "remountOptions"), c.RemountOptions)...)
+	allErrs = append(allErrs, validateIgnorePreflightErrors(field.NewPath("spec", "ignorePreflightErrors"), c.IgnorePreflightErrors)...)
+	allErrs = append(allErrs, validateEtcdDataDir(field.NewPath("spec", "etcdDataDir"), c)...)
+	allErrs = append(allErrs, validateSSHHardening(field.NewPath("spec", "sshHardening"), c.SSHHardening)...)
+	allErrs = append(allErrs, validateCloudProvider(field.NewPath("spec", "cloudProvider"), c.CloudProvider)...)
+	allErrs = append(allErrs, validateKubeletSecurityDefaults(field.NewPath("spec"), c)...)
+	allErrs = append(allErrs, validateFIPSMode(field.NewPath("spec", "sshHardening"), c)...)
+	allErrs = append(allErrs, validateReservedResources(field.NewPath("spec", "reservedResources"), c.ReservedResources)...)
+	allErrs = append(allErrs, validateKdump(field.NewPath("spec", "kdump"), c.Kdump)...)
+	allErrs = append(allErrs, validateNodeProblemDetector(field.NewPath("spec", "installNodeProblemDetector"), c.InstallNodeProblemDetector)...)
+	allErrs = append(allErrs, validateStaticPods(field.NewPath("spec", "staticPods"), c.StaticPods)...)
+	allErrs = append(allErrs, validateRuntimeHandlers(field.NewPath("spec", "runtimeHandlers"), c.RuntimeHandlers)...)
+	allErrs = append(allErrs, validateTimezone(field.NewPath("spec", "timezone"), c.Timezone)...)
+
+	if c.MaxPods != nil && *c.MaxPods <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "maxPods"), *c.MaxPods, InvalidMaxPodsMsg))
+	}
+	allErrs = append(allErrs, validateImageGC(field.NewPath("spec", "imageGC"), c.ImageGC)...)
+	allErrs = append(allErrs, validateUploadBootLogsTo(field.NewPath("spec", "uploadBootLogsTo"), c.UploadBootLogsTo)...)
+	allErrs = append(allErrs, validateStartupTaint(field.NewPath("spec", "startupTaint"), c.StartupTaint)...)
+	allErrs = append(allErrs, validateKubeletRootDir(field.NewPath("spec", "kubeletRootDir"), c)...)
+	allErrs = append(allErrs, validateHostDNS(field.NewPath("spec", "hostDNS"), c.HostDNS)...)
+	allErrs = append(allErrs, validateContainerdMetrics(field.NewPath("spec", "containerdMetrics"), c.ContainerdMetrics)...)
+	allErrs = append(allErrs, validateCgroups(field.NewPath("spec"), c)...)
+	allErrs = append(allErrs, validateAuditdRules(field.NewPath("spec", "auditdRules"), c.AuditdRules)...)
+	allErrs = append(allErrs, validateSystemLimits(field.NewPath("spec", "systemLimits"), c.SystemLimits)...)
+	allErrs = append(allErrs, validateKubeletServingCertSANs(field.NewPath("spec", "kubeletServingCertSANs"), c)...)
+	allErrs = append(allErrs, validateContainerdPull(field.NewPath("spec", "containerdPull"), c.ContainerdPull)...)
+
+	if c.SandboxImage != nil {
+		if _, err := reference.ParseNormalizedNamed(*c.SandboxImage); err != nil {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					field.NewPath("spec", "sandboxImage"),
+					*c.SandboxImage,
+					fmt.Sprintf("%s: %v", InvalidSandboxImageMsg, err),
+				),
+			)
+		}
+	}
+
+	for i, pkg := range c.Packages {
+		if !packageNameRegex.MatchString(pkg) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "packages").Index(i), pkg, InvalidPackageNameMsg))
+		}
+	}
+
+	if c.PublishConfigTo != nil {
+		if len(validation.IsDNS1123Subdomain(c.PublishConfigTo.ConfigMapName)) > 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "publishConfigTo", "configMapName"), c.PublishConfigTo.ConfigMapName, InvalidPublishConfigMapNameMsg))
+		}
+		if len(validation.IsDNS1123Label(c.PublishConfigTo.Namespace)) > 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "publishConfigTo", "namespace"), c.PublishConfigTo.Namespace, InvalidPublishNamespaceMsg))
+		}
+	}
+
+	if c.NodeLocalDNS != nil && net.ParseIP(c.NodeLocalDNS.Address) == nil {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				field.NewPath("spec", "nodeLocalDNS", "address"),
+				c.NodeLocalDNS.Address,
+				InvalidNodeLocalDNSAddressMsg,
+			),
+		)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("KubeadmConfig").GroupKind(), name, allErrs)
+}
+
+// isAbsolutePathWithoutWhitespace returns true if the given path is absolute and can be rendered into a
+// command without quoting.
+func isAbsolutePathWithoutWhitespace(path string) bool {
+	return strings.HasPrefix(path, "/") && !strings.ContainsAny(path, " \t\n")
+}
+
+// validateNetworkConfig checks that every network interface has a unique name, at least one
+// address in CIDR notation, that its gateway and DNS servers are IP addresses, and that its MTU
+// and routes are valid.
+func validateNetworkConfig(fldPath *field.Path, interfaces []NetworkInterface) field.ErrorList {
+	var allErrs field.ErrorList
+
+	knownNames := map[string]struct{}{}
+	for i, iface := range interfaces {
+		ifacePath := fldPath.Index(i)
+		if _, conflict := knownNames[iface.Name]; iface.Name == "" || conflict {
+			allErrs = append(allErrs, field.Invalid(ifacePath.Child("name"), iface.Name, InvalidNetworkInterfaceNameMsg))
+		}
+		knownNames[iface.Name] = struct{}{}
+
+		if len(iface.Addresses) == 0 {
+			allErrs = append(allErrs, field.Required(ifacePath.Child("addresses"), InvalidNetworkAddressMsg))
+		}
+		for j, address := range iface.Addresses {
+			if _, _, err := net.ParseCIDR(address); err != nil {
+				allErrs = append(allErrs, field.Invalid(ifacePath.Child("addresses").Index(j), address, InvalidNetworkAddressMsg))
+			}
+		}
+		if iface.Gateway != "" && net.ParseIP(iface.Gateway) == nil {
+			allErrs = append(allErrs, field.Invalid(ifacePath.Child("gateway"), iface.Gateway, InvalidNetworkIPMsg))
+		}
+		for j, dns := range iface.DNS {
+			if net.ParseIP(dns) == nil {
+				allErrs = append(allErrs, field.Invalid(ifacePath.Child("dns").Index(j), dns, InvalidNetworkIPMsg))
+			}
+		}
+		if iface.MTU != nil && (*iface.MTU < minNetworkMTU || *iface.MTU > maxNetworkMTU) {
+			allErrs = append(allErrs, field.Invalid(ifacePath.Child("mtu"), *iface.MTU, InvalidNetworkMTUMsg))
+		}
+		for j, route := range iface.Routes {
+			routePath := ifacePath.Child("routes").Index(j)
+			if _, _, err := net.ParseCIDR(route.To); err != nil {
+				allErrs = append(allErrs, field.Invalid(routePath.Child("to"), route.To, InvalidNetworkRouteMsg))
+			}
+			if route.Via != "" && net.ParseIP(route.Via) == nil {
+				allErrs = append(allErrs, field.Invalid(routePath.Child("via"), route.Via, InvalidNetworkRouteMsg))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validateSystemdTimers checks that every systemd timer has a unique unit name, a command, and a schedule
+// in the OnCalendar format.
+func validateSystemdTimers(fldPath *field.Path, timers []SystemdTimer) field.ErrorList {
+	var allErrs field.ErrorList
+
+	knownNames := map[string]struct{}{}
+	for i, timer := range timers {
+		timerPath := fldPath.Index(i)
+		if _, conflict := knownNames[timer.Name]; !systemdUnitNameRegex.MatchString(timer.Name) || conflict {
+			allErrs = append(allErrs, field.Invalid(timerPath.Child("name"), timer.Name, InvalidSystemdTimerNameMsg))
+		}
+		knownNames[timer.Name] = struct{}{}
+
+		if !isValidOnCalendar(timer.Schedule) {
+			allErrs = append(allErrs, field.Invalid(timerPath.Child("schedule"), timer.Schedule, InvalidSystemdTimerScheduleMsg))
+		}
+		if strings.TrimSpace(timer.Command) == "" {
+			allErrs = append(allErrs, field.Required(timerPath.Child("command"), MissingSystemdTimerCommandMsg))
+		}
+	}
+
+	return allErrs
+}
+
+// validateRemountOptions checks that every remount has a unique, clean absolute path, and at least one option,
+// each of which can be passed as is to mount -o.
+func validateRemountOptions(fldPath *field.Path, remounts []RemountSpec) field.ErrorList {
+	var allErrs field.ErrorList
+
+	knownPaths := map[string]struct{}{}
+	for i, remount := range remounts {
+		remountPath := fldPath.Index(i)
+		_, conflict := knownPaths[remount.Path]
+		if conflict || !isAbsolutePathWithoutWhitespace(remount.Path) || path.Clean(remount.Path) != remount.Path {
+			allErrs = append(allErrs, field.Invalid(remountPath.Child("path"), remount.Path, InvalidRemountPathMsg))
+		}
+		knownPaths[remount.Path] = struct{}{}
+
+		if len(remount.Options) == 0 {
+			allErrs = append(allErrs, field.Required(remountPath.Child("options"), InvalidRemountOptionMsg))
+		}
+		for j, option := range remount.Options {
+			if !remountOptionRegex.MatchString(option) {
+				allErrs = append(allErrs, field.Invalid(remountPath.Child("options").Index(j), option, InvalidRemountOptionMsg))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validateIgnorePreflightErrors checks that every ignored preflight error is a unique check name which can be
+// joined in the value of --ignore-preflight-errors. Check names are compared case insensitively, as kubeadm does.
+// Names of checks unknown to kubeadm are accepted, as they depend on the kubeadm version.
+func validateIgnorePreflightErrors(fldPath *field.Path, names []string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	knownNames := map[string]struct{}{}
+	for i, name := range names {
+		_, conflict := knownNames[strings.ToLower(name)]
+		if conflict || !preflightErrorRegex.MatchString(name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), name, InvalidIgnorePreflightErrorMsg))
+		}
+		knownNames[strings.ToLower(name)] = struct{}{}
+	}
+
+	return allErrs
+}
+
+// validateBootstrapTokenUsages checks that the bootstrap token usages, if any, are unique known usages,
+// including authentication, without which the kubelet can't join.
+func validateBootstrapTokenUsages(fldPath *field.Path, usages []string) field.ErrorList {
+	if len(usages) == 0 {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	known := sets.NewString(bootstrapapi.KnownTokenUsages...)
+	seen := sets.NewString()
+	for i, usage := range usages {
+		if seen.Has(usage) || !known.Has(usage) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), usage, InvalidBootstrapTokenUsageMsg))
+		}
+		seen.Insert(usage)
+	}
+	if !seen.Has("authentication") {
+		allErrs = append(allErrs, field.Invalid(fldPath, usages, MissingBootstrapTokenAuthUsageMsg))
+	}
+
+	return allErrs
+}
+
+// validateSSHHardening checks that the sshd hardening, if any, only sets values supported by OpenSSH, without
+// listing the same cipher or MAC twice.
+func validateSSHHardening(fldPath *field.Path, hardening *SSHHardeningConfig) field.ErrorList {
+	if hardening == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	switch hardening.PermitRootLogin {
+	case "", PermitRootLoginYes, PermitRootLoginNo, PermitRootLoginProhibitPassword, PermitRootLoginForcedCommandsOnly:
+	default:
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("permitRootLogin"), hardening.PermitRootLogin, InvalidSSHPermitRootLoginMsg))
+	}
+
+	allErrs = append(allErrs, validateSSHAlgorithms(fldPath.Child("ciphers"), hardening.Ciphers, sshCiphers, InvalidSSHCipherMsg)...)
+	allErrs = append(allErrs, validateSSHAlgorithms(fldPath.Child("macs"), hardening.MACs, sshMACs, InvalidSSHMACMsg)...)
+
+	return allErrs
+}
+
+// validateSSHAlgorithms checks that every algorithm is a unique one of the supported ones.
+func validateSSHAlgorithms(fldPath *field.Path, algorithms []string, supported sets.String, msg string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	seen := sets.NewString()
+	for i, algorithm := range algorithms {
+		if seen.Has(algorithm) || !supported.Has(algorithm) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), algorithm, msg))
+		}
+		seen.Insert(algorithm)
+	}
+
+	return allErrs
+}
+
+// validateFIPSMode checks that, when FIPS mode is enabled, the ciphers and MACs allowed by sshd, if any, are FIPS
+// approved; sshd would otherwise fail to start with them once the machine is in FIPS mode.
+func validateFIPSMode(fldPath *field.Path, c *KubeadmConfigSpec) field.ErrorList {
+	if c.FIPSMode == nil || !*c.FIPSMode || c.SSHHardening == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	for i, cipher := range c.SSHHardening.Ciphers {
+		if !fipsSSHCiphers.Has(cipher) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("ciphers").Index(i), cipher, NonFIPSSSHCipherMsg))
+		}
+	}
+	for i, mac := range c.SSHHardening.MACs {
+		if !fipsSSHMACs.Has(mac) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("macs").Index(i), mac, NonFIPSSSHMACMsg))
+		}
+	}
+
+	return allErrs
+}
+
+// validateReservedResources checks that the reserved resources, if any, are reservable by the kubelet with
+// non-negative quantities, and that the hard eviction thresholds are for supported signals, with non-negative
+// quantities or percentages of at most 100%.
+func validateReservedResources(fldPath *field.Path, reserved *ReservedResourcesConfig) field.ErrorList {
+	if reserved == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateResourceReservations(fldPath.Child("kubeReserved"), reserved.KubeReserved)...)
+	allErrs = append(allErrs, validateResourceReservations(fldPath.Child("systemReserved"), reserved.SystemReserved)...)
+	for _, signal := range sortedKeys(reserved.EvictionHard) {
+		threshold := reserved.EvictionHard[signal]
+		if !evictionSignals.Has(signal) || !isValidEvictionThreshold(threshold) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("evictionHard").Key(signal), threshold, InvalidEvictionThresholdMsg))
+		}
+	}
+
+	return allErrs
+}
+
+// validateResourceReservations checks that the reservations are for reservable resources, with non-negative quantities.
+func validateResourceReservations(fldPath *field.Path, reservations map[string]string) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, name := range sortedKeys(reservations) {
+		value := reservations[name]
+		if !reservableResources.Has(name) || !isNonNegativeQuantity(value) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(name), value, InvalidReservedResourceMsg))
+		}
+	}
+	return allErrs
+}
+
+// isValidEvictionThreshold returns true if the threshold is a non-negative quantity, or a percentage of at most 100%.
+func isValidEvictionThreshold(threshold string) bool {
+	if !evictionPercentageRegex.MatchString(threshold) {
+		return isNonNegativeQuantity(threshold)
+	}
+	percentage, err := strconv.ParseFloat(strings.TrimSuffix(threshold, "%"), 64)
+	return err == nil && percentage <= 100
+}
+
+// isNonNegativeQuantity returns true if the value is a resource quantity, e.g. "100m" or "1Gi", which is not negative.
+func isNonNegativeQuantity(value string) bool {
+	quantity, err := resource.ParseQuantity(value)
+	return err == nil && quantity.Sign() >= 0
+}
+
+// sortedKeys returns the keys of the map in order, so that validation errors are reported deterministically.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validateKdump checks that the crashkernel kernel arg, if kdump is configured, is well formed, and that the path
+// of the dumps, if any, is a clean absolute path.
+func validateKdump(fldPath *field.Path, kdump *KdumpConfig) field.ErrorList {
+	if kdump == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if !crashKernelRegex.MatchString(kdump.CrashKernel) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("crashKernel"), kdump.CrashKernel, InvalidCrashKernelMsg))
+	}
+	if kdump.Path != "" && (!isAbsolutePathWithoutWhitespace(kdump.Path) || path.Clean(kdump.Path) != kdump.Path) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("path"), kdump.Path, InvalidKdumpPathMsg))
+	}
+
+	return allErrs
+}
+
+// validateNodeProblemDetector checks that the image of the node problem detector, if any, is a valid image
+// reference, and that its config source, if any, references a secret key.
+func validateNodeProblemDetector(fldPath *field.Path, npd *NPDConfig) field.ErrorList {
+	if npd == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if _, err := reference.ParseNormalizedNamed(npd.Image); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("image"), npd.Image, fmt.Sprintf("%s: %v", InvalidNPDImageMsg, err)))
+	}
+	if source := npd.ConfigFrom; source != nil && (source.Secret.Name == "" || source.Secret.Key == "") {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("configFrom", "secret"), source.Secret, InvalidNPDConfigSourceMsg))
+	}
+
+	return allErrs
+}
+
+// kubeadmStaticPods are the names of the static pods kubeadm writes on control plane machines, which static
+// pods must not overwrite.
+var kubeadmStaticPods = sets.NewString("etcd", "kube-apiserver", "kube-controller-manager", "kube-scheduler")
+
+// validateStaticPods checks that every static pod has a unique name usable as the file name of its manifest,
+// and that its manifest source references a secret key. The manifests themselves are only checked by the
+// controller, once resolved.
+func validateStaticPods(fldPath *field.Path, staticPods []StaticPod) field.ErrorList {
+	var allErrs field.ErrorList
+
+	knownNames := sets.NewString()
+	for i, staticPod := range staticPods {
+		podPath := fldPath.Index(i)
+		if len(validation.IsDNS1123Subdomain(staticPod.Name)) > 0 || knownNames.Has(staticPod.Name) || kubeadmStaticPods.Has(staticPod.Name) {
+			allErrs = append(allErrs, field.Invalid(podPath.Child("name"), staticPod.Name, InvalidStaticPodNameMsg))
+		}
+		knownNames.Insert(staticPod.Name)
+
+		if source := staticPod.ManifestFrom; source == nil || source.Secret.Name == "" || source.Secret.Key == "" {
+			allErrs = append(allErrs, field.Invalid(podPath.Child("manifestFrom"), source, InvalidStaticPodManifestSourceMsg))
+		}
+	}
+
+	return allErrs
+}
+
+// validateRuntimeHandlers checks that every runtime handler has a unique name usable by RuntimeClasses, a
+// containerd runtime type, and, if set, an absolute binary path.
+func validateRuntimeHandlers(fldPath *field.Path, handlers []RuntimeHandler) field.ErrorList {
+	var allErrs field.ErrorList
+
+	knownNames := sets.NewString()
+	for i, handler := range handlers {
+		handlerPath := fldPath.Index(i)
+		if len(validation.IsDNS1123Label(handler.Name)) > 0 || knownNames.Has(handler.Name) {
+			allErrs = append(allErrs, field.Invalid(handlerPath.Child("name"), handler.Name, InvalidRuntimeHandlerNameMsg))
+		}
+		knownNames.Insert(handler.Name)
+
+		if !runtimeTypeRegex.MatchString(handler.RuntimeType) {
+			allErrs = append(allErrs, field.Invalid(handlerPath.Child("runtimeType"), handler.RuntimeType, InvalidRuntimeTypeMsg))
+		}
+		if handler.BinaryPath != "" && (!isAbsolutePathWithoutWhitespace(handler.BinaryPath) || path.Clean(handler.BinaryPath) != handler.BinaryPath) {
+			allErrs = append(allErrs, field.Invalid(handlerPath.Child("binaryPath"), handler.BinaryPath, InvalidRuntimeBinaryPathMsg))
+		}
+	}
+
+	return allErrs
+}
+
+// validateTimezone checks that the timezone, if any, is a name of the tz database. "Local" is rejected, as it is
+// not a timezone but the one of the webhook itself.
+func validateTimezone(fldPath *field.Path, timezone *string) field.ErrorList {
+	if timezone == nil {
+		return nil
+	}
+
+	if *timezone == "" || *timezone == "Local" {
+		return field.ErrorList{field.Invalid(fldPath, *timezone, InvalidTimezoneMsg)}
+	}
+	if _, err := time.LoadLocation(*timezone); err != nil {
+		return field.ErrorList{field.Invalid(fldPath, *timezone, InvalidTimezoneMsg)}
+	}
+
+	return nil
+}
+
+// validateImageGC checks that the image garbage collection thresholds, if any, are percentages, the high one
+// being greater than the low one.
+func validateImageGC(fldPath *field.Path, imageGC *ImageGCConfig) field.ErrorList {
+	if imageGC == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if imageGC.HighThresholdPercent < 0 || imageGC.HighThresholdPercent > 100 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("highThresholdPercent"), imageGC.HighThresholdPercent, InvalidImageGCThresholdMsg))
+	}
+	if imageGC.LowThresholdPercent < 0 || imageGC.LowThresholdPercent > 100 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("lowThresholdPercent"), imageGC.LowThresholdPercent, InvalidImageGCThresholdMsg))
+	}
+	if len(allErrs) == 0 && imageGC.HighThresholdPercent <= imageGC.LowThresholdPercent {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("highThresholdPercent"), imageGC.HighThresholdPercent, InvalidImageGCThresholdsMsg))
+	}
+
+	return allErrs
+}
+
+// validateUploadBootLogsTo checks that the boot logs sink, if any, is an http or https URL, which can be quoted in
+// the upload script, and that its credentials source, if any, references a secret key.
+func validateUploadBootLogsTo(fldPath *field.Path, sink *LogSink) field.ErrorList {
+	if sink == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if u, err := url.Parse(sink.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		strings.ContainsAny(sink.URL, " \t\n'") {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("url"), sink.URL, InvalidBootLogsURLMsg))
+	}
+	if source := sink.CredentialsFrom; source != nil && (source.Secret.Name == "" || source.Secret.Key == "") {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("credentialsFrom", "secret"), source.Secret, InvalidBootLogsCredentialsMsg))
+	}
+
+	return allErrs
+}
+
+// validateStartupTaint checks that the startup taint, if any, can be set through the register-with-taints kubelet
+// arg and removed with kubectl.
+func validateStartupTaint(fldPath *field.Path, taint *corev1.Taint) field.ErrorList {
+	if taint == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if len(validation.IsQualifiedName(taint.Key)) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("key"), taint.Key, InvalidStartupTaintMsg))
+	}
+	if len(validation.IsValidLabelValue(taint.Value)) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("value"), taint.Value, InvalidStartupTaintMsg))
+	}
+	switch taint.Effect {
+	case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+	default:
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("effect"), taint.Effect, InvalidStartupTaintMsg))
+	}
+
+	return allErrs
+}
+
+// validateCloudProvider checks that the cloud provider, if any, is named, and that its cloud config source, if any,
+// references a secret key. No provider requires a cloud config, as e.g. "external" ones are configured separately.
+func validateCloudProvider(fldPath *field.Path, cloudProvider *CloudProviderConfig) field.ErrorList {
+	if cloudProvider == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if !cloudProviderNameRegex.MatchString(cloudProvider.Name) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), cloudProvider.Name, InvalidCloudProviderNameMsg))
+	}
+	if source := cloudProvider.ConfigFrom; source != nil && (source.Secret.Name == "" || source.Secret.Key == "") {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("configFrom", "secret"), source.Secret, InvalidCloudConfigSourceMsg))
+	}
+
+	return allErrs
+}
+
+// validateKubeletSecurityDefaults checks that the seccomp default and user namespaces, if enabled, are supported
+// by the Kubernetes version of the cluster configuration. They are not checked when the version is unknown,
+// e.g. when it is only set on the Machine.
+func validateKubeletSecurityDefaults(fldPath *field.Path, c *KubeadmConfigSpec) field.ErrorList {
+	if c.ClusterConfiguration == nil || c.ClusterConfiguration.KubernetesVersion == "" {
+		return nil
+	}
+	version, err := versionutil.ParseSemantic(c.ClusterConfiguration.KubernetesVersion)
+	if err != nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if c.SeccompDefault != nil && *c.SeccompDefault && version.LessThan(minSeccompDefaultVersion) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("seccompDefault"), *c.SeccompDefault, UnsupportedSeccompDefaultMsg))
+	}
+	if c.UserNamespaces != nil && *c.UserNamespaces && version.LessThan(minUserNamespacesVersion) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("userNamespaces"), *c.UserNamespaces, UnsupportedUserNamespacesMsg))
+	}
+
+	return allErrs
+}
+
+// validateEtcdDataDir checks that the etcd data dir, if any, is a clean absolute path agreeing with the local etcd
+// configuration, and that it is on a filesystem declared in the disk setup, mounted on the data dir or on one
+// of its parents.
+func validateEtcdDataDir(fldPath *field.Path, c *KubeadmConfigSpec) field.ErrorList {
+	if c.EtcdDataDir == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	dataDir := *c.EtcdDataDir
+	if !isAbsolutePathWithoutWhitespace(dataDir) || path.Clean(dataDir) != dataDir {
+		allErrs = append(allErrs, field.Invalid(fldPath, dataDir, InvalidEtcdDataDirMsg))
+	}
+
+	if c.ClusterConfiguration != nil {
+		etcd := c.ClusterConfiguration.Etcd
+		if etcd.External != nil || (etcd.Local != nil && etcd.Local.DataDir != "" && etcd.Local.DataDir != dataDir) {
+			allErrs = append(allErrs, field.Invalid(fldPath, dataDir, ConflictingEtcdDataDirMsg))
+		}
+	}
+
+	if !isMountedFromDiskSetup(c, dataDir) {
+		allErrs = append(allErrs, field.Invalid(fldPath, dataDir, MissingEtcdDataDirMountMsg))
+	}
+
+	return allErrs
+}
+
+// validateKubeletRootDir checks that the kubelet root dir, if any, is a clean absolute path agreeing with the
+// root-dir kubelet arg of the node registrations, and that it is on a filesystem declared in the disk setup,
+// mounted on the root dir or on one of its parents.
+func validateKubeletRootDir(fldPath *field.Path, c *KubeadmConfigSpec) field.ErrorList {
+	if c.KubeletRootDir == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	rootDir := *c.KubeletRootDir
+	if !isAbsolutePathWithoutWhitespace(rootDir) || path.Clean(rootDir) != rootDir {
+		allErrs = append(allErrs, field.Invalid(fldPath, rootDir, InvalidKubeletRootDirMsg))
+	}
+
+	var nodeRegistrations []NodeRegistrationOptions
+	if c.InitConfiguration != nil {
+		nodeRegistrations = append(nodeRegistrations, c.InitConfiguration.NodeRegistration)
+	}
+	if c.JoinConfiguration != nil {
+		nodeRegistrations = append(nodeRegistrations, c.JoinConfiguration.NodeRegistration)
+	}
+	for _, nodeRegistration := range nodeRegistrations {
+		if arg, ok := nodeRegistration.KubeletExtraArgs["root-dir"]; ok && arg != rootDir {
+			allErrs = append(allErrs, field.Invalid(fldPath, rootDir, ConflictingKubeletRootDirMsg))
+			break
+		}
+	}
+
+	if !isMountedFromDiskSetup(c, rootDir) {
+		allErrs = append(allErrs, field.Invalid(fldPath, rootDir, MissingKubeletRootDirMountMsg))
+	}
+
+	return allErrs
+}
+
+// validateHostDNS checks that the host DNS configuration, if any, is made of IP addresses of nameservers and of
+// DNS subdomains to search.
+func validateHostDNS(fldPath *field.Path, dns *HostDNSConfig) field.ErrorList {
+	if dns == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if len(dns.Nameservers) == 0 && len(dns.Search) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath, EmptyHostDNSMsg))
+	}
+	for i, nameserver := range dns.Nameservers {
+		if net.ParseIP(nameserver) == nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("nameservers").Index(i), nameserver, InvalidHostDNSNameserverMsg))
+		}
+	}
+	for i, domain := range dns.Search {
+		if len(validation.IsDNS1123Subdomain(domain)) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("search").Index(i), domain, InvalidHostDNSSearchMsg))
+		}
+	}
+
+	return allErrs
+}
+
+// validateContainerdMetrics checks that the metrics address is an IP address and a port, which containerd listens on.
+func validateContainerdMetrics(fldPath *field.Path, metrics *ContainerdMetricsConfig) field.ErrorList {
+	if metrics == nil {
+		return nil
+	}
+
+	host, port, err := net.SplitHostPort(metrics.Address)
+	if err != nil || net.ParseIP(host) == nil {
+		return field.ErrorList{field.Invalid(fldPath.Child("address"), metrics.Address, InvalidContainerdMetricsAddressMsg)}
+	}
+	if portNum, err := strconv.Atoi(port); err != nil || len(validation.IsValidPortNum(portNum)) > 0 {
+		return field.ErrorList{field.Invalid(fldPath.Child("address"), metrics.Address, InvalidContainerdMetricsAddressMsg)}
+	}
+
+	return nil
+}
+
+// validateCgroups checks that swap, if enabled, is accounted with cgroup v2 and supported by the Kubernetes version
+// of the cluster configuration, if known, and that the kubelet args of the node registrations agree with the cgroup
+// driver and the swap setting rendered for the machine.
+func validateCgroups(fldPath *field.Path, c *KubeadmConfigSpec) field.ErrorList {
+	cgroupV2 := c.CgroupV2 != nil && *c.CgroupV2
+	swap := c.FailSwapOn != nil && !*c.FailSwapOn
+
+	var allErrs field.ErrorList
+	if swap && !cgroupV2 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("failSwapOn"), *c.FailSwapOn, MissingCgroupV2ForSwapMsg))
+	}
+	if swap && c.ClusterConfiguration != nil && c.ClusterConfiguration.KubernetesVersion != "" {
+		if version, err := versionutil.ParseSemantic(c.ClusterConfiguration.KubernetesVersion); err == nil && version.LessThan(minNodeSwapVersion) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("failSwapOn"), *c.FailSwapOn, UnsupportedSwapMsg))
+		}
+	}
+
+	var nodeRegistrations []NodeRegistrationOptions
+	if c.InitConfiguration != nil {
+		nodeRegistrations = append(nodeRegistrations, c.InitConfiguration.NodeRegistration)
+	}
+	if c.JoinConfiguration != nil {
+		nodeRegistrations = append(nodeRegistrations, c.JoinConfiguration.NodeRegistration)
+	}
+	for _, nodeRegistration := range nodeRegistrations {
+		if arg, ok := nodeRegistration.KubeletExtraArgs["cgroup-driver"]; ok && cgroupV2 && arg != "systemd" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("cgroupV2"), *c.CgroupV2, fmt.Sprintf("%s: cgroup-driver=%s", ConflictingCgroupKubeletArgMsg, arg)))
+			break
+		}
+	}
+	if c.FailSwapOn != nil {
+		for _, nodeRegistration := range nodeRegistrations {
+			if arg, ok := nodeRegistration.KubeletExtraArgs["fail-swap-on"]; ok && arg != strconv.FormatBool(*c.FailSwapOn) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("failSwapOn"), *c.FailSwapOn, fmt.Sprintf("%s: fail-swap-on=%s", ConflictingCgroupKubeletArgMsg, arg)))
+				break
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validateAuditdRules checks that every auditd rule is a single line starting with an option, as written to the
+// rules file; the options themselves are checked by auditctl when the rules are loaded.
+func validateAuditdRules(fldPath *field.Path, rules []string) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, rule := range rules {
+		if !strings.HasPrefix(strings.TrimSpace(rule), "-") || strings.ContainsAny(rule, "\r\n") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), rule, InvalidAuditdRuleMsg))
+		}
+	}
+
+	return allErrs
+}
+
+// validateSystemLimits checks that the system limits set at least one limit, and that the limits are positive.
+func validateSystemLimits(fldPath *field.Path, limits *SystemLimitsConfig) field.ErrorList {
+	if limits == nil {
+		return nil
+	}
+	if limits.NoFile == nil && limits.NProc == nil {
+		return field.ErrorList{field.Required(fldPath, EmptySystemLimitsMsg)}
+	}
+
+	var allErrs field.ErrorList
+	if limits.NoFile != nil && *limits.NoFile <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("noFile"), *limits.NoFile, InvalidSystemLimitMsg))
+	}
+	if limits.NProc != nil && *limits.NProc <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("nProc"), *limits.NProc, InvalidSystemLimitMsg))
+	}
+
+	return allErrs
+}
+
+// validateKubeletServingCertSANs checks that the kubelet serving certificate SANs are DNS subdomains or IP addresses,
+// and that the kubelet does not get its serving certificate otherwise, i.e. from the cluster or from user provided
+// kubelet args, in which case the certificate generated for the SANs would not be used.
+func validateKubeletServingCertSANs(fldPath *field.Path, c *KubeadmConfigSpec) field.ErrorList {
+	if len(c.KubeletServingCertSANs) == 0 {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	for i, san := range c.KubeletServingCertSANs {
+		if net.ParseIP(san) == nil && len(validation.IsDNS1123Subdomain(san)) > 0 && len(validation.IsWildcardDNS1123Subdomain(san)) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), san, InvalidKubeletServingCertSANMsg))
+		}
+	}
+
+	conflicting := c.RotateKubeletServerCertificate != nil && *c.RotateKubeletServerCertificate
+	var nodeRegistrations []NodeRegistrationOptions
+	if c.InitConfiguration != nil {
+		nodeRegistrations = append(nodeRegistrations, c.InitConfiguration.NodeRegistration)
+	}
+	if c.JoinConfiguration != nil {
+		nodeRegistrations = append(nodeRegistrations, c.JoinConfiguration.NodeRegistration)
+	}
+	for _, nodeRegistration := range nodeRegistrations {
+		_, certFile := nodeRegistration.KubeletExtraArgs["tls-cert-file"]
+		_, keyFile := nodeRegistration.KubeletExtraArgs["tls-private-key-file"]
+		conflicting = conflicting || certFile || keyFile
+	}
+	if conflicting {
+		allErrs = append(allErrs, field.Invalid(fldPath, c.KubeletServingCertSANs, ConflictingKubeletServingCertMsg))
+	}
+
+	return allErrs
+}
+
+// validateContainerdPull checks that the containerd pull settings set at least one setting, and that the settings are
+// positive.
+func validateContainerdPull(fldPath *field.Path, pull *ContainerdPullConfig) field.ErrorList {
+	if pull == nil {
+		return nil
+	}
+	if pull.MaxConcurrentDownloads == nil && pull.PullTimeout == nil {
+		return field.ErrorList{field.Required(fldPath, EmptyContainerdPullMsg)}
+	}
+
+	var allErrs field.ErrorList
+	if pull.MaxConcurrentDownloads != nil && *pull.MaxConcurrentDownloads <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxConcurrentDownloads"), *pull.MaxConcurrentDownloads, InvalidContainerdPullMsg))
+	}
+	if pull.PullTimeout != nil && pull.PullTimeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("pullTimeout"), pull.PullTimeout.String(), InvalidContainerdPullMsg))
+	}
+
+	return allErrs
+}
+
+// isMountedFromDiskSetup returns true if one of the mounts mounts a filesystem declared in the disk setup, by
+// device or by label, on the given directory or on one of its parents.
+func isMountedFromDiskSetup(c *KubeadmConfigSpec, dir string) bool {
+	devices := map[string]struct{}{}
+	if c.DiskSetup != nil {
+		for _, fs := range c.DiskSetup.Filesystems {
+			devices[fs.Device] = struct{}{}
+			if fs.Label != "" && fs.Label != "None" {
+				devices["LABEL="+fs.Label] = struct{}{}
+			}
+		}
+	}
+	for _, mount := range c.Mounts {
+		if len(mount) < 2 {
+			continue
+		}
+		if _, ok := devices[mount[0]]; ok && isPathWithin(dir, mount[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPathWithin returns true if the given path is the given directory or one of its descendants.
+func isPathWithin(p, dir string) bool {
+	dir = path.Clean(dir)
+	return p == dir || dir == "/" || strings.HasPrefix(p, dir+"/")
+}
+
+// isValidOnCalendar returns true if the given schedule is an OnCalendar shorthand, or an OnCalendar timestamp
+// made of an optional weekday, date and time, e.g. "Mon..Fri *-*-* 03:00:00". Timezones are not supported.
+func isValidOnCalendar(schedule string) bool {
+	if _, ok := onCalendarShorthands[schedule]; ok {
+		return true
+	}
+
+	fields := strings.Fields(schedule)
+	if len(fields) == 0 {
+		return false
+	}
+	for _, regex := range []*regexp.Regexp{onCalendarWeekdaysRegex, onCalendarDateRegex, onCalendarTimeRegex} {
+		if len(fields) > 0 && regex.MatchString(fields[0]) {
+			fields = fields[1:]
+		}
+	}
+	return len(fields) == 0
+}
+
+// validateMountPoints checks that a mount entry can be rendered into a well-formed fstab line:
+// [device, mountpoint, type, options, dump, pass], where only device and mountpoint are required.
+func validateMountPoints(path *field.Path, mount MountPoints) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if len(mount) < 2 || len(mount) > 6 {
+		return append(allErrs, field.Invalid(path, mount, MalformedMountPointMsg))
+	}
+
+	for i, f := range mount {
+		if strings.TrimSpace(f) == "" || strings.ContainsAny(strings.TrimSpace(f), " \t\n") {
+			allErrs = append(allErrs, field.Invalid(path.Index(i), f, InvalidMountPointFieldMsg))
+		}
+	}
+
+	if mountPoint := strings.TrimSpace(mount[1]); mountPoint != "none" && !strings.HasPrefix(mountPoint, "/") {
+		allErrs = append(allErrs, field.Invalid(path.Index(1), mount[1], InvalidMountPointPathMsg))
+	}
+
+	for i := 4; i < len(mount); i++ {
+		if n, err := strconv.Atoi(strings.TrimSpace(mount[i])); err != nil || n < 0 {
+			allErrs = append(allErrs, field.Invalid(path.Index(i), mount[i], InvalidMountPointNumberMsg))
+		}
+	}
+
+	return allErrs
+}
+
+// validateDiskSetupWipes checks that the disk setup does not overwrite existing partitions or filesystems
+// without confirmation.
+func (c *KubeadmConfigSpec) validateDiskSetupWipes(name string) error {
+	if c.DiskSetup == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	for _, device := range c.DiskSetup.UnconfirmedWipes() {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "diskSetup"), device, UnconfirmedWipeMsg))
+	}
 	if len(allErrs) == 0 {
 		return nil
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("KubeadmConfig").GroupKind(), name, allErrs)
 }
+
+// UnconfirmedWipes returns the devices whose existing partitions or filesystems would be overwritten without
+// confirmation, i.e. the devices of the partitions and filesystems with Overwrite set to true, unless a partition
+// of the device has ConfirmWipe set to true. A filesystem is on the device of a partition if its device starts
+// with the device of the partition, e.g. /dev/sdb1 for /dev/sdb.
+func (d *DiskSetup) UnconfirmedWipes() []string {
+	isConfirmed := func(device string) bool {
+		for _, p := range d.Partitions {
+			if p.ConfirmWipe && strings.HasPrefix(device, p.Device) {
+				return true
+			}
+		}
+		return false
+	}
+
+	unconfirmed := sets.NewString()
+	for _, p := range d.Partitions {
+		if p.Overwrite != nil && *p.Overwrite && !isConfirmed(p.Device) {
+			unconfirmed.Insert(p.Device)
+		}
+	}
+	for _, fs := range d.Filesystems {
+		if fs.Overwrite != nil && *fs.Overwrite && !isConfirmed(fs.Device) {
+			unconfirmed.Insert(fs.Device)
+		}
+	}
+	return unconfirmed.List()
+}
+
+// validatePartitions checks that explicitly numbered partitions can be laid out on their devices:
+// numbers must be unique and contiguous per device, and only the last one may grow to fill the device.
+func validatePartitions(partitions []Partition) field.ErrorList {
+	var allErrs field.ErrorList
+
+	type numbered struct {
+		index  int
+		number int
+		fill   bool
+	}
+	devices := []string{}
+	byDevice := map[string][]numbered{}
+
+	for i, p := range partitions {
+		path := field.NewPath("spec", "diskSetup", "partitions", fmt.Sprintf("%d", i))
+		if p.SizeMiB != nil && (p.Number == nil || *p.SizeMiB < -1) {
+			allErrs = append(allErrs, field.Invalid(path.Child("sizeMiB"), *p.SizeMiB, InvalidPartitionSizeMsg))
+		}
+		if p.Number == nil {
+			continue
+		}
+		if p.Layout || (p.TableType != nil && *p.TableType != "gpt") {
+			allErrs = append(allErrs, field.Invalid(path, p, ConflictingPartitionLayoutMsg))
+		}
+		if *p.Number < 1 {
+			allErrs = append(allErrs, field.Invalid(path.Child("number"), *p.Number, InvalidPartitionNumberMsg))
+			continue
+		}
+		for _, other := range byDevice[p.Device] {
+			if other.number == *p.Number {
+				allErrs = append(allErrs, field.Invalid(path.Child("number"), *p.Number, InvalidPartitionNumberMsg))
+			}
+		}
+		if _, ok := byDevice[p.Device]; !ok {
+			devices = append(devices, p.Device)
+		}
+		byDevice[p.Device] = append(byDevice[p.Device], numbered{
+			index:  i,
+			number: *p.Number,
+			fill:   p.SizeMiB == nil || *p.SizeMiB <= 0,
+		})
+	}
+
+	for _, device := range devices {
+		entries := byDevice[device]
+		min, max := entries[0].number, entries[0].number
+		for _, e := range entries {
+			if e.number < min {
+				min = e.number
+			}
+			if e.number > max {
+				max = e.number
+			}
+		}
+		if max-min+1 != len(entries) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "diskSetup", "partitions"), device, NonContiguousPartitionNumbersMsg))
+		}
+		for _, e := range entries {
+			if e.fill && e.number != max {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "diskSetup", "partitions", fmt.Sprintf("%d", e.index), "sizeMiB"), device, PartitionFillNotLastMsg))
+			}
+		}
+	}
+
+	return allErrs
+}
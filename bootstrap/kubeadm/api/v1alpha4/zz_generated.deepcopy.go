@@ -68,6 +68,37 @@ func (in *APIServer) DeepCopy() *APIServer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditLogConfig) DeepCopyInto(out *AuditLogConfig) {
+	*out = *in
+	*out = *in
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxBackup != nil {
+		in, out := &in.MaxBackup, &out.MaxBackup
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditLogConfig.
+func (in *AuditLogConfig) DeepCopy() *AuditLogConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditLogConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BootstrapToken) DeepCopyInto(out *BootstrapToken) {
 	*out = *in
@@ -142,6 +173,26 @@ func (in *BootstrapTokenString) DeepCopy() *BootstrapTokenString {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudProviderConfig) DeepCopyInto(out *CloudProviderConfig) {
+	*out = *in
+	if in.ConfigFrom != nil {
+		in, out := &in.ConfigFrom, &out.ConfigFrom
+		*out = new(FileSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudProviderConfig.
+func (in *CloudProviderConfig) DeepCopy() *CloudProviderConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudProviderConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterConfiguration) DeepCopyInto(out *ClusterConfiguration) {
 	*out = *in
@@ -210,6 +261,46 @@ func (in *ClusterStatus) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerdMetricsConfig) DeepCopyInto(out *ContainerdMetricsConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerdMetricsConfig.
+func (in *ContainerdMetricsConfig) DeepCopy() *ContainerdMetricsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerdMetricsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerdPullConfig) DeepCopyInto(out *ContainerdPullConfig) {
+	*out = *in
+	if in.MaxConcurrentDownloads != nil {
+		in, out := &in.MaxConcurrentDownloads, &out.MaxConcurrentDownloads
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PullTimeout != nil {
+		in, out := &in.PullTimeout, &out.PullTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerdPullConfig.
+func (in *ContainerdPullConfig) DeepCopy() *ContainerdPullConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerdPullConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ControlPlaneComponent) DeepCopyInto(out *ControlPlaneComponent) {
 	*out = *in
@@ -362,8 +453,8 @@ func (in *File) DeepCopyInto(out *File) {
 	*out = *in
 	if in.ContentFrom != nil {
 		in, out := &in.ContentFrom, &out.ContentFrom
-		*out = new(FileSource)
-		**out = **in
+		*out = make([]FileSource, len(*in))
+		copy(*out, *in)
 	}
 }
 
@@ -443,6 +534,47 @@ func (in *Filesystem) DeepCopy() *Filesystem {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GracefulShutdownConfig) DeepCopyInto(out *GracefulShutdownConfig) {
+	*out = *in
+	out.Timeout = in.Timeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GracefulShutdownConfig.
+func (in *GracefulShutdownConfig) DeepCopy() *GracefulShutdownConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GracefulShutdownConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostDNSConfig) DeepCopyInto(out *HostDNSConfig) {
+	*out = *in
+	if in.Nameservers != nil {
+		in, out := &in.Nameservers, &out.Nameservers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Search != nil {
+		in, out := &in.Search, &out.Search
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostDNSConfig.
+func (in *HostDNSConfig) DeepCopy() *HostDNSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HostDNSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostPathMount) DeepCopyInto(out *HostPathMount) {
 	*out = *in
@@ -458,6 +590,21 @@ func (in *HostPathMount) DeepCopy() *HostPathMount {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageGCConfig) DeepCopyInto(out *ImageGCConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageGCConfig.
+func (in *ImageGCConfig) DeepCopy() *ImageGCConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageGCConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageMeta) DeepCopyInto(out *ImageMeta) {
 	*out = *in
@@ -553,6 +700,21 @@ func (in *JoinControlPlane) DeepCopy() *JoinControlPlane {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KdumpConfig) DeepCopyInto(out *KdumpConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KdumpConfig.
+func (in *KdumpConfig) DeepCopy() *KdumpConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KdumpConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubeadmConfig) DeepCopyInto(out *KubeadmConfig) {
 	*out = *in
@@ -680,6 +842,242 @@ func (in *KubeadmConfigSpec) DeepCopyInto(out *KubeadmConfigSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.GracefulShutdown != nil {
+		in, out := &in.GracefulShutdown, &out.GracefulShutdown
+		*out = new(GracefulShutdownConfig)
+		**out = **in
+	}
+	if in.WaitForNodeReady != nil {
+		in, out := &in.WaitForNodeReady, &out.WaitForNodeReady
+		*out = new(WaitConfig)
+		**out = **in
+	}
+	if in.InstallCrictlConfig != nil {
+		in, out := &in.InstallCrictlConfig, &out.InstallCrictlConfig
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PrePullImages != nil {
+		in, out := &in.PrePullImages, &out.PrePullImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PersistentJournal != nil {
+		in, out := &in.PersistentJournal, &out.PersistentJournal
+		*out = new(bool)
+		**out = **in
+	}
+	if in.LoginBanner != nil {
+		in, out := &in.LoginBanner, &out.LoginBanner
+		*out = new(string)
+		**out = **in
+	}
+	if in.AuditPolicy != nil {
+		in, out := &in.AuditPolicy, &out.AuditPolicy
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AuditLogConfig != nil {
+		in, out := &in.AuditLogConfig, &out.AuditLogConfig
+		*out = new(AuditLogConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GrowRootFilesystem != nil {
+		in, out := &in.GrowRootFilesystem, &out.GrowRootFilesystem
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NetworkConfig != nil {
+		in, out := &in.NetworkConfig, &out.NetworkConfig
+		*out = make([]NetworkInterface, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SensitiveFields != nil {
+		in, out := &in.SensitiveFields, &out.SensitiveFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeLocalDNS != nil {
+		in, out := &in.NodeLocalDNS, &out.NodeLocalDNS
+		*out = new(NodeLocalDNSConfig)
+		**out = **in
+	}
+	if in.SystemdTimers != nil {
+		in, out := &in.SystemdTimers, &out.SystemdTimers
+		*out = make([]SystemdTimer, len(*in))
+		copy(*out, *in)
+	}
+	if in.Packages != nil {
+		in, out := &in.Packages, &out.Packages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SandboxImage != nil {
+		in, out := &in.SandboxImage, &out.SandboxImage
+		*out = new(string)
+		**out = **in
+	}
+	if in.RemountOptions != nil {
+		in, out := &in.RemountOptions, &out.RemountOptions
+		*out = make([]RemountSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IgnorePreflightErrors != nil {
+		in, out := &in.IgnorePreflightErrors, &out.IgnorePreflightErrors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EtcdDataDir != nil {
+		in, out := &in.EtcdDataDir, &out.EtcdDataDir
+		*out = new(string)
+		**out = **in
+	}
+	if in.PublishConfigTo != nil {
+		in, out := &in.PublishConfigTo, &out.PublishConfigTo
+		*out = new(PublishTarget)
+		**out = **in
+	}
+	if in.RotateKubeletServerCertificate != nil {
+		in, out := &in.RotateKubeletServerCertificate, &out.RotateKubeletServerCertificate
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SSHHardening != nil {
+		in, out := &in.SSHHardening, &out.SSHHardening
+		*out = new(SSHHardeningConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BootstrapTokenTTL != nil {
+		in, out := &in.BootstrapTokenTTL, &out.BootstrapTokenTTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.BootstrapTokenUsages != nil {
+		in, out := &in.BootstrapTokenUsages, &out.BootstrapTokenUsages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CloudProvider != nil {
+		in, out := &in.CloudProvider, &out.CloudProvider
+		*out = new(CloudProviderConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SeccompDefault != nil {
+		in, out := &in.SeccompDefault, &out.SeccompDefault
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UserNamespaces != nil {
+		in, out := &in.UserNamespaces, &out.UserNamespaces
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FIPSMode != nil {
+		in, out := &in.FIPSMode, &out.FIPSMode
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ReservedResources != nil {
+		in, out := &in.ReservedResources, &out.ReservedResources
+		*out = new(ReservedResourcesConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Kdump != nil {
+		in, out := &in.Kdump, &out.Kdump
+		*out = new(KdumpConfig)
+		**out = **in
+	}
+	if in.InstallNodeProblemDetector != nil {
+		in, out := &in.InstallNodeProblemDetector, &out.InstallNodeProblemDetector
+		*out = new(NPDConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StaticPods != nil {
+		in, out := &in.StaticPods, &out.StaticPods
+		*out = make([]StaticPod, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RuntimeHandlers != nil {
+		in, out := &in.RuntimeHandlers, &out.RuntimeHandlers
+		*out = make([]RuntimeHandler, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timezone != nil {
+		in, out := &in.Timezone, &out.Timezone
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaxPods != nil {
+		in, out := &in.MaxPods, &out.MaxPods
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ImageGC != nil {
+		in, out := &in.ImageGC, &out.ImageGC
+		*out = new(ImageGCConfig)
+		**out = **in
+	}
+	if in.UploadBootLogsTo != nil {
+		in, out := &in.UploadBootLogsTo, &out.UploadBootLogsTo
+		*out = new(LogSink)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartupTaint != nil {
+		in, out := &in.StartupTaint, &out.StartupTaint
+		*out = new(corev1.Taint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KubeletRootDir != nil {
+		in, out := &in.KubeletRootDir, &out.KubeletRootDir
+		*out = new(string)
+		**out = **in
+	}
+	if in.HostDNS != nil {
+		in, out := &in.HostDNS, &out.HostDNS
+		*out = new(HostDNSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ContainerdMetrics != nil {
+		in, out := &in.ContainerdMetrics, &out.ContainerdMetrics
+		*out = new(ContainerdMetricsConfig)
+		**out = **in
+	}
+	if in.CgroupV2 != nil {
+		in, out := &in.CgroupV2, &out.CgroupV2
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FailSwapOn != nil {
+		in, out := &in.FailSwapOn, &out.FailSwapOn
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AuditdRules != nil {
+		in, out := &in.AuditdRules, &out.AuditdRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SystemLimits != nil {
+		in, out := &in.SystemLimits, &out.SystemLimits
+		*out = new(SystemLimitsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KubeletServingCertSANs != nil {
+		in, out := &in.KubeletServingCertSANs, &out.KubeletServingCertSANs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ContainerdPull != nil {
+		in, out := &in.ContainerdPull, &out.ContainerdPull
+		*out = new(ContainerdPullConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeadmConfigSpec.
@@ -842,6 +1240,26 @@ func (in *LocalEtcd) DeepCopy() *LocalEtcd {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogSink) DeepCopyInto(out *LogSink) {
+	*out = *in
+	if in.CredentialsFrom != nil {
+		in, out := &in.CredentialsFrom, &out.CredentialsFrom
+		*out = new(FileSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogSink.
+func (in *LogSink) DeepCopy() *LogSink {
+	if in == nil {
+		return nil
+	}
+	out := new(LogSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in MountPoints) DeepCopyInto(out *MountPoints) {
 	{
@@ -861,6 +1279,26 @@ func (in MountPoints) DeepCopy() MountPoints {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NPDConfig) DeepCopyInto(out *NPDConfig) {
+	*out = *in
+	if in.ConfigFrom != nil {
+		in, out := &in.ConfigFrom, &out.ConfigFrom
+		*out = new(FileSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NPDConfig.
+func (in *NPDConfig) DeepCopy() *NPDConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NPDConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NTP) DeepCopyInto(out *NTP) {
 	*out = *in
@@ -886,6 +1324,63 @@ func (in *NTP) DeepCopy() *NTP {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterface) DeepCopyInto(out *NetworkInterface) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MTU != nil {
+		in, out := &in.MTU, &out.MTU
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = make([]NetworkRoute, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterface.
+func (in *NetworkInterface) DeepCopy() *NetworkInterface {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterface)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkRoute) DeepCopyInto(out *NetworkRoute) {
+	*out = *in
+	if in.Metric != nil {
+		in, out := &in.Metric, &out.Metric
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkRoute.
+func (in *NetworkRoute) DeepCopy() *NetworkRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Networking) DeepCopyInto(out *Networking) {
 	*out = *in
@@ -901,6 +1396,21 @@ func (in *Networking) DeepCopy() *Networking {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLocalDNSConfig) DeepCopyInto(out *NodeLocalDNSConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLocalDNSConfig.
+func (in *NodeLocalDNSConfig) DeepCopy() *NodeLocalDNSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLocalDNSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeRegistrationOptions) DeepCopyInto(out *NodeRegistrationOptions) {
 	*out = *in
@@ -943,6 +1453,16 @@ func (in *Partition) DeepCopyInto(out *Partition) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Number != nil {
+		in, out := &in.Number, &out.Number
+		*out = new(int)
+		**out = **in
+	}
+	if in.SizeMiB != nil {
+		in, out := &in.SizeMiB, &out.SizeMiB
+		*out = new(int)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Partition.
@@ -955,6 +1475,123 @@ func (in *Partition) DeepCopy() *Partition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublishTarget) DeepCopyInto(out *PublishTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublishTarget.
+func (in *PublishTarget) DeepCopy() *PublishTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(PublishTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemountSpec) DeepCopyInto(out *RemountSpec) {
+	*out = *in
+	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemountSpec.
+func (in *RemountSpec) DeepCopy() *RemountSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RemountSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReservedResourcesConfig) DeepCopyInto(out *ReservedResourcesConfig) {
+	*out = *in
+	if in.KubeReserved != nil {
+		in, out := &in.KubeReserved, &out.KubeReserved
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SystemReserved != nil {
+		in, out := &in.SystemReserved, &out.SystemReserved
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.EvictionHard != nil {
+		in, out := &in.EvictionHard, &out.EvictionHard
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservedResourcesConfig.
+func (in *ReservedResourcesConfig) DeepCopy() *ReservedResourcesConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ReservedResourcesConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuntimeHandler) DeepCopyInto(out *RuntimeHandler) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuntimeHandler.
+func (in *RuntimeHandler) DeepCopy() *RuntimeHandler {
+	if in == nil {
+		return nil
+	}
+	out := new(RuntimeHandler)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SSHHardeningConfig) DeepCopyInto(out *SSHHardeningConfig) {
+	*out = *in
+	if in.PasswordAuthentication != nil {
+		in, out := &in.PasswordAuthentication, &out.PasswordAuthentication
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Ciphers != nil {
+		in, out := &in.Ciphers, &out.Ciphers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MACs != nil {
+		in, out := &in.MACs, &out.MACs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHHardeningConfig.
+func (in *SSHHardeningConfig) DeepCopy() *SSHHardeningConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SSHHardeningConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretFileSource) DeepCopyInto(out *SecretFileSource) {
 	*out = *in
@@ -970,6 +1607,66 @@ func (in *SecretFileSource) DeepCopy() *SecretFileSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticPod) DeepCopyInto(out *StaticPod) {
+	*out = *in
+	if in.ManifestFrom != nil {
+		in, out := &in.ManifestFrom, &out.ManifestFrom
+		*out = new(FileSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticPod.
+func (in *StaticPod) DeepCopy() *StaticPod {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticPod)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SystemLimitsConfig) DeepCopyInto(out *SystemLimitsConfig) {
+	*out = *in
+	if in.NoFile != nil {
+		in, out := &in.NoFile, &out.NoFile
+		*out = new(int64)
+		**out = **in
+	}
+	if in.NProc != nil {
+		in, out := &in.NProc, &out.NProc
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SystemLimitsConfig.
+func (in *SystemLimitsConfig) DeepCopy() *SystemLimitsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SystemLimitsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SystemdTimer) DeepCopyInto(out *SystemdTimer) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SystemdTimer.
+func (in *SystemdTimer) DeepCopy() *SystemdTimer {
+	if in == nil {
+		return nil
+	}
+	out := new(SystemdTimer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *User) DeepCopyInto(out *User) {
 	*out = *in
@@ -1034,3 +1731,20 @@ func (in *User) DeepCopy() *User {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaitConfig) DeepCopyInto(out *WaitConfig) {
+	*out = *in
+	*out = *in
+	out.Timeout = in.Timeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaitConfig.
+func (in *WaitConfig) DeepCopy() *WaitConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(WaitConfig)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -17,9 +17,15 @@ limitations under the License.
 package controllers
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"net"
+	"path"
+	"sort"
 	"strconv"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -30,6 +36,8 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	versionutil "k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
@@ -51,11 +59,123 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/yaml"
 )
 
 const (
 	// KubeadmConfigControllerName defines the controller used when creating clients.
 	KubeadmConfigControllerName = "kubeadmconfig-controller"
+
+	// kubeletShutdownGracePeriodArg is the kubelet arg enabling the graceful node shutdown.
+	kubeletShutdownGracePeriodArg = "shutdown-grace-period"
+
+	// kubeletPodInfraContainerImageArg is the kubelet arg setting the pod sandbox image.
+	kubeletPodInfraContainerImageArg = "pod-infra-container-image"
+
+	// kubeletRotateServerCertificatesArg is the kubelet arg enabling the rotation of its serving certificate,
+	// the equivalent of serverTLSBootstrap in the kubelet configuration.
+	kubeletRotateServerCertificatesArg = "rotate-server-certificates"
+
+	// kubeletNodeLabelsArg is the kubelet arg setting the labels of the node when registering it.
+	kubeletNodeLabelsArg = "node-labels"
+
+	// auditPolicyPath is where the audit policy is written on control plane machines.
+	auditPolicyPath = "/etc/kubernetes/audit-policy.yaml"
+
+	// defaultAuditLogPath is where the API server writes its audit log, unless configured otherwise.
+	defaultAuditLogPath = "/var/log/kubernetes/audit/audit.log"
+
+	// cloudProviderArg is the kubelet, API server and controller manager arg setting the cloud provider.
+	cloudProviderArg = "cloud-provider"
+
+	// cloudConfigArg is the kubelet, API server and controller manager arg setting the cloud config file.
+	cloudConfigArg = "cloud-config"
+
+	// cloudConfigPath is where the cloud config file is written.
+	cloudConfigPath = "/etc/kubernetes/cloud.conf"
+
+	// staticPodManifestsDir is where the kubelet reads the manifests of static pods from.
+	staticPodManifestsDir = "/etc/kubernetes/manifests"
+
+	// kubeletFeatureGatesArg is the kubelet arg setting its feature gates, as comma separated name=bool pairs.
+	kubeletFeatureGatesArg = "feature-gates"
+
+	// kubeletSeccompDefaultArg is the kubelet arg enabling the RuntimeDefault seccomp profile for all workloads.
+	kubeletSeccompDefaultArg = "seccomp-default"
+
+	// seccompDefaultFeatureGate is the kubelet feature gate required by the seccomp default before Kubernetes v1.25.
+	seccompDefaultFeatureGate = "SeccompDefault"
+
+	// userNamespacesFeatureGate is the kubelet feature gate enabling user namespaces since Kubernetes v1.28;
+	// legacyUserNamespacesFeatureGate is its name before.
+	userNamespacesFeatureGate       = "UserNamespacesSupport"
+	legacyUserNamespacesFeatureGate = "UserNamespacesStatelessPodsSupport"
+
+	// kubeletKubeReservedArg is the kubelet arg reserving resources for the Kubernetes daemons, as comma separated
+	// name=quantity pairs.
+	kubeletKubeReservedArg = "kube-reserved"
+
+	// kubeletSystemReservedArg is the kubelet arg reserving resources for the system daemons, as comma separated
+	// name=quantity pairs.
+	kubeletSystemReservedArg = "system-reserved"
+
+	// kubeletEvictionHardArg is the kubelet arg setting its hard eviction thresholds, as comma separated
+	// signal<threshold pairs.
+	kubeletEvictionHardArg = "eviction-hard"
+
+	// kubeletMaxPodsArg is the kubelet arg setting the maximum number of pods it runs.
+	kubeletMaxPodsArg = "max-pods"
+
+	// kubeletImageGCHighThresholdArg and kubeletImageGCLowThresholdArg are the kubelet args setting the disk usage
+	// percentages above which the image garbage collection runs, and down to which it frees space.
+	kubeletImageGCHighThresholdArg = "image-gc-high-threshold"
+	kubeletImageGCLowThresholdArg  = "image-gc-low-threshold"
+
+	// kubeletRegisterWithTaintsArg is the kubelet arg setting the taints of the node when registering it, as comma
+	// separated key=value:effect triples. It overrides the one kubeadm sets from the taints of the node registration.
+	kubeletRegisterWithTaintsArg = "register-with-taints"
+
+	// kubeletRootDirArg is the kubelet arg setting the directory where it places its files, which defaults to
+	// defaultKubeletRootDir.
+	kubeletRootDirArg     = "root-dir"
+	defaultKubeletRootDir = "/var/lib/kubelet"
+
+	// kubeletCgroupDriverArg is the kubelet arg setting its cgroup driver, which must be systemd with cgroup v2.
+	kubeletCgroupDriverArg = "cgroup-driver"
+	systemdCgroupDriver    = "systemd"
+
+	// kubeletFailSwapOnArg is the kubelet arg making it fail to start when swap is enabled.
+	kubeletFailSwapOnArg = "fail-swap-on"
+
+	// kubeletTLSCertFileArg and kubeletTLSPrivateKeyFileArg are the kubelet args setting its serving certificate and
+	// key, which it generates self-signed otherwise.
+	kubeletTLSCertFileArg       = "tls-cert-file"
+	kubeletTLSPrivateKeyFileArg = "tls-private-key-file"
+
+	// nodeSwapFeatureGate is the kubelet feature gate required to run with swap before Kubernetes v1.30.
+	nodeSwapFeatureGate = "NodeSwap"
+
+	// nodeCIDRMaskSizeArg is the controller manager arg setting the size of the pod CIDR allocated to each node, for
+	// single stack clusters; nodeCIDRMaskSizeIPv4Arg and nodeCIDRMaskSizeIPv6Arg set it per family for dual-stack ones.
+	nodeCIDRMaskSizeArg     = "node-cidr-mask-size"
+	nodeCIDRMaskSizeIPv4Arg = "node-cidr-mask-size-ipv4"
+	nodeCIDRMaskSizeIPv6Arg = "node-cidr-mask-size-ipv6"
+
+	// defaultNodeCIDRMaskSizeIPv4 and defaultNodeCIDRMaskSizeIPv6 are the default sizes of the pod CIDR allocated
+	// to each node by the controller manager.
+	defaultNodeCIDRMaskSizeIPv4 = 24
+	defaultNodeCIDRMaskSizeIPv6 = 64
+)
+
+var (
+	// seccompDefaultEnabledVersion is the first Kubernetes version enabling the seccomp default feature gate by default.
+	seccompDefaultEnabledVersion = versionutil.MustParseSemantic("v1.25.0")
+
+	// userNamespacesSupportVersion is the first Kubernetes version naming the user namespaces feature gate UserNamespacesSupport.
+	userNamespacesSupportVersion = versionutil.MustParseSemantic("v1.28.0")
+
+	// nodeSwapEnabledVersion is the first Kubernetes version enabling the NodeSwap feature gate by default.
+	nodeSwapEnabledVersion = versionutil.MustParseSemantic("v1.30.0")
 )
 
 // InitLocker is a lock that is used around kubeadm init.
@@ -211,6 +331,12 @@ func (r *KubeadmConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}
 	}()
 
+	// Report sensitive values set inline, as they are readable by anyone allowed to read the config.
+	reconcileSensitiveFields(ctx, config)
+
+	// Report ignored preflight errors kubeadm may not know about, as they are passed as is to kubeadm.
+	reconcileIgnorePreflightErrors(ctx, config)
+
 	switch {
 	// Wait for the infrastructure to be ready.
 	case !cluster.Status.InfrastructureReady:
@@ -242,6 +368,12 @@ func (r *KubeadmConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, nil
 	}
 
+	// Templated files may refer to the control plane endpoint, wait for the Cluster to have one.
+	if hasTemplatedFiles(config) && !cluster.Spec.ControlPlaneEndpoint.IsValid() {
+		log.Info("Waiting for Cluster Controller to set Cluster.Spec.ControlPlaneEndpoint")
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
 	// Note: can't use IsFalse here because we need to handle the absence of the condition as well as false.
 	if !conditions.IsTrue(cluster, clusterv1.ControlPlaneInitializedCondition) {
 		return r.handleClusterNotInitialized(ctx, scope)
@@ -279,11 +411,11 @@ func (r *KubeadmConfigReconciler) refreshBootstrapToken(ctx context.Context, con
 	}
 
 	log.Info("Refreshing token until the infrastructure has a chance to consume it")
-	if err := refreshToken(ctx, remoteClient, token); err != nil {
+	if err := refreshToken(ctx, remoteClient, token, tokenTTL(config)); err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "failed to refresh bootstrap token")
 	}
 	return ctrl.Result{
-		RequeueAfter: DefaultTokenTTL / 2,
+		RequeueAfter: tokenTTL(config) / 2,
 	}, nil
 }
 
@@ -296,13 +428,13 @@ func (r *KubeadmConfigReconciler) rotateMachinePoolBootstrapToken(ctx context.Co
 	}
 
 	token := config.Spec.JoinConfiguration.Discovery.BootstrapToken.Token
-	shouldRotate, err := shouldRotate(ctx, remoteClient, token)
+	shouldRotate, err := shouldRotate(ctx, remoteClient, token, tokenTTL(config))
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 	if shouldRotate {
 		log.V(2).Info("Creating new bootstrap token")
-		token, err := createToken(ctx, remoteClient)
+		token, err := createToken(ctx, remoteClient, tokenTTL(config), tokenUsages(config))
 		if err != nil {
 			return ctrl.Result{}, errors.Wrapf(err, "failed to create new bootstrap token")
 		}
@@ -314,7 +446,7 @@ func (r *KubeadmConfigReconciler) rotateMachinePoolBootstrapToken(ctx context.Co
 		return r.joinWorker(ctx, scope)
 	}
 	return ctrl.Result{
-		RequeueAfter: DefaultTokenTTL / 3,
+		RequeueAfter: tokenTTL(config) / 3,
 	}, nil
 }
 
@@ -376,7 +508,12 @@ func (r *KubeadmConfigReconciler) handleClusterNotInitialized(ctx context.Contex
 			},
 		}
 	}
-	initdata, err := kubeadmtypes.MarshalInitConfigurationForVersion(scope.Config.Spec.InitConfiguration, kubernetesVersion)
+
+	// The kubelet args are injected into a copy of the init configuration, only used to render it.
+	initConfiguration := scope.Config.Spec.InitConfiguration.DeepCopy()
+	reconcileKubeletArgs(scope, &initConfiguration.NodeRegistration, kubernetesVersion)
+
+	initdata, err := kubeadmtypes.MarshalInitConfigurationForVersion(initConfiguration, kubernetesVersion)
 	if err != nil {
 		scope.Error(err, "Failed to marshal init configuration")
 		return ctrl.Result{}, err
@@ -394,7 +531,14 @@ func (r *KubeadmConfigReconciler) handleClusterNotInitialized(ctx context.Contex
 	// injects into config.ClusterConfiguration values from top level object
 	r.reconcileTopLevelObjectSettings(ctx, scope.Cluster, machine, scope.Config)
 
-	clusterdata, err := kubeadmtypes.MarshalClusterConfigurationForVersion(scope.Config.Spec.ClusterConfiguration, kubernetesVersion)
+	// The API server, controller manager and etcd settings are injected into a copy of the cluster configuration,
+	// only used to render it.
+	clusterConfiguration := scope.Config.Spec.ClusterConfiguration.DeepCopy()
+	reconcileAuditPolicy(scope.Config, clusterConfiguration)
+	reconcileEtcdDataDir(scope.Config, clusterConfiguration)
+	reconcileCloudProvider(scope.Config, clusterConfiguration)
+
+	clusterdata, err := kubeadmtypes.MarshalClusterConfigurationForVersion(clusterConfiguration, kubernetesVersion)
 	if err != nil {
 		scope.Error(err, "Failed to marshal cluster configuration")
 		return ctrl.Result{}, err
@@ -413,28 +557,16 @@ func (r *KubeadmConfigReconciler) handleClusterNotInitialized(ctx context.Contex
 	}
 	conditions.MarkTrue(scope.Config, bootstrapv1.CertificatesAvailableCondition)
 
-	verbosityFlag := ""
-	if scope.Config.Spec.Verbosity != nil {
-		verbosityFlag = fmt.Sprintf("--v %s", strconv.Itoa(int(*scope.Config.Spec.Verbosity)))
-	}
-
-	files, err := r.resolveFiles(ctx, scope.Config)
+	files, err := r.resolveAllFiles(ctx, scope)
 	if err != nil {
 		conditions.MarkFalse(scope.Config, bootstrapv1.DataSecretAvailableCondition, bootstrapv1.DataSecretGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
 		return ctrl.Result{}, err
 	}
+	userData := baseUserData(scope, &scope.Config.Spec.InitConfiguration.NodeRegistration)
+	userData.AdditionalFiles = files
 
 	cloudInitData, err := cloudinit.NewInitControlPlane(&cloudinit.ControlPlaneInput{
-		BaseUserData: cloudinit.BaseUserData{
-			AdditionalFiles:     files,
-			NTP:                 scope.Config.Spec.NTP,
-			PreKubeadmCommands:  scope.Config.Spec.PreKubeadmCommands,
-			PostKubeadmCommands: scope.Config.Spec.PostKubeadmCommands,
-			Users:               scope.Config.Spec.Users,
-			Mounts:              scope.Config.Spec.Mounts,
-			DiskSetup:           scope.Config.Spec.DiskSetup,
-			KubeadmVerbosity:    verbosityFlag,
-		},
+		BaseUserData:         userData,
 		InitConfiguration:    initdata,
 		ClusterConfiguration: clusterdata,
 		Certificates:         certificates,
@@ -476,7 +608,11 @@ func (r *KubeadmConfigReconciler) joinWorker(ctx context.Context, scope *Scope)
 		return res, nil
 	}
 
-	joinData, err := kubeadmtypes.MarshalJoinConfigurationForVersion(scope.Config.Spec.JoinConfiguration, scope.ConfigOwner.KubernetesVersion())
+	// The kubelet args are injected into a copy of the join configuration, only used to render it.
+	joinConfiguration := scope.Config.Spec.JoinConfiguration.DeepCopy()
+	reconcileKubeletArgs(scope, &joinConfiguration.NodeRegistration, scope.ConfigOwner.KubernetesVersion())
+
+	joinData, err := kubeadmtypes.MarshalJoinConfigurationForVersion(joinConfiguration, scope.ConfigOwner.KubernetesVersion())
 	if err != nil {
 		scope.Error(err, "Failed to marshal join configuration")
 		return ctrl.Result{}, err
@@ -488,29 +624,18 @@ func (r *KubeadmConfigReconciler) joinWorker(ctx context.Context, scope *Scope)
 
 	scope.Info("Creating BootstrapData for the worker node")
 
-	verbosityFlag := ""
-	if scope.Config.Spec.Verbosity != nil {
-		verbosityFlag = fmt.Sprintf("--v %s", strconv.Itoa(int(*scope.Config.Spec.Verbosity)))
-	}
-
-	files, err := r.resolveFiles(ctx, scope.Config)
+	files, err := r.resolveAllFiles(ctx, scope)
 	if err != nil {
 		conditions.MarkFalse(scope.Config, bootstrapv1.DataSecretAvailableCondition, bootstrapv1.DataSecretGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
 		return ctrl.Result{}, err
 	}
+	userData := baseUserData(scope, &scope.Config.Spec.JoinConfiguration.NodeRegistration)
+	userData.AdditionalFiles = files
+	userData.UseExperimentalRetry = scope.Config.Spec.UseExperimentalRetryJoin
+	userData.WaitForNodeReady = scope.Config.Spec.WaitForNodeReady
 
 	cloudJoinData, err := cloudinit.NewNode(&cloudinit.NodeInput{
-		BaseUserData: cloudinit.BaseUserData{
-			AdditionalFiles:      files,
-			NTP:                  scope.Config.Spec.NTP,
-			PreKubeadmCommands:   scope.Config.Spec.PreKubeadmCommands,
-			PostKubeadmCommands:  scope.Config.Spec.PostKubeadmCommands,
-			Users:                scope.Config.Spec.Users,
-			Mounts:               scope.Config.Spec.Mounts,
-			DiskSetup:            scope.Config.Spec.DiskSetup,
-			KubeadmVerbosity:     verbosityFlag,
-			UseExperimentalRetry: scope.Config.Spec.UseExperimentalRetryJoin,
-		},
+		BaseUserData:      userData,
 		JoinConfiguration: joinData,
 	})
 	if err != nil {
@@ -557,7 +682,11 @@ func (r *KubeadmConfigReconciler) joinControlplane(ctx context.Context, scope *S
 		return res, nil
 	}
 
-	joinData, err := kubeadmtypes.MarshalJoinConfigurationForVersion(scope.Config.Spec.JoinConfiguration, scope.ConfigOwner.KubernetesVersion())
+	// The kubelet args are injected into a copy of the join configuration, only used to render it.
+	joinConfiguration := scope.Config.Spec.JoinConfiguration.DeepCopy()
+	reconcileKubeletArgs(scope, &joinConfiguration.NodeRegistration, scope.ConfigOwner.KubernetesVersion())
+
+	joinData, err := kubeadmtypes.MarshalJoinConfigurationForVersion(joinConfiguration, scope.ConfigOwner.KubernetesVersion())
 	if err != nil {
 		scope.Error(err, "Failed to marshal join configuration")
 		return ctrl.Result{}, err
@@ -565,31 +694,20 @@ func (r *KubeadmConfigReconciler) joinControlplane(ctx context.Context, scope *S
 
 	scope.Info("Creating BootstrapData for the join control plane")
 
-	verbosityFlag := ""
-	if scope.Config.Spec.Verbosity != nil {
-		verbosityFlag = fmt.Sprintf("--v %s", strconv.Itoa(int(*scope.Config.Spec.Verbosity)))
-	}
-
-	files, err := r.resolveFiles(ctx, scope.Config)
+	files, err := r.resolveAllFiles(ctx, scope)
 	if err != nil {
 		conditions.MarkFalse(scope.Config, bootstrapv1.DataSecretAvailableCondition, bootstrapv1.DataSecretGenerationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
 		return ctrl.Result{}, err
 	}
+	userData := baseUserData(scope, &scope.Config.Spec.JoinConfiguration.NodeRegistration)
+	userData.AdditionalFiles = files
+	userData.UseExperimentalRetry = scope.Config.Spec.UseExperimentalRetryJoin
+	userData.WaitForNodeReady = scope.Config.Spec.WaitForNodeReady
 
 	cloudJoinData, err := cloudinit.NewJoinControlPlane(&cloudinit.ControlPlaneJoinInput{
 		JoinConfiguration: joinData,
 		Certificates:      certificates,
-		BaseUserData: cloudinit.BaseUserData{
-			AdditionalFiles:      files,
-			NTP:                  scope.Config.Spec.NTP,
-			PreKubeadmCommands:   scope.Config.Spec.PreKubeadmCommands,
-			PostKubeadmCommands:  scope.Config.Spec.PostKubeadmCommands,
-			Users:                scope.Config.Spec.Users,
-			Mounts:               scope.Config.Spec.Mounts,
-			DiskSetup:            scope.Config.Spec.DiskSetup,
-			KubeadmVerbosity:     verbosityFlag,
-			UseExperimentalRetry: scope.Config.Spec.UseExperimentalRetryJoin,
-		},
+		BaseUserData:      userData,
 	})
 	if err != nil {
 		scope.Error(err, "Failed to create a control plane join configuration")
@@ -604,20 +722,112 @@ func (r *KubeadmConfigReconciler) joinControlplane(ctx context.Context, scope *S
 	return ctrl.Result{}, nil
 }
 
+// baseUserData returns the user data shared by the init and join bootstrap data, from the settings of the
+// KubeadmConfig and the given node registration options of its init or join configuration.
+func baseUserData(scope *Scope, nodeRegistration *bootstrapv1.NodeRegistrationOptions) cloudinit.BaseUserData {
+	verbosityFlag := ""
+	if scope.Config.Spec.Verbosity != nil {
+		verbosityFlag = fmt.Sprintf("--v %s", strconv.Itoa(int(*scope.Config.Spec.Verbosity)))
+	}
+
+	return cloudinit.BaseUserData{
+		NTP:                    scope.Config.Spec.NTP,
+		PreKubeadmCommands:     scope.Config.Spec.PreKubeadmCommands,
+		PostKubeadmCommands:    scope.Config.Spec.PostKubeadmCommands,
+		Users:                  scope.Config.Spec.Users,
+		Mounts:                 scope.Config.Spec.Mounts,
+		DiskSetup:              scope.Config.Spec.DiskSetup,
+		KubeadmVerbosity:       verbosityFlag,
+		GracefulShutdown:       scope.Config.Spec.GracefulShutdown,
+		InstallCrictlConfig:    installCrictlConfig(scope.Config),
+		CRISocket:              nodeRegistration.CRISocket,
+		PrePullImages:          scope.Config.Spec.PrePullImages,
+		PersistentJournal:      persistentJournal(scope.Config),
+		LoginBanner:            scope.Config.Spec.LoginBanner,
+		GrowRootFilesystem:     growRootFilesystem(scope.Config),
+		NetworkConfig:          scope.Config.Spec.NetworkConfig,
+		NodeLocalDNS:           scope.Config.Spec.NodeLocalDNS,
+		SystemdTimers:          scope.Config.Spec.SystemdTimers,
+		Packages:               scope.Config.Spec.Packages,
+		SandboxImage:           scope.Config.Spec.SandboxImage,
+		RemountOptions:         scope.Config.Spec.RemountOptions,
+		IgnorePreflightErrors:  scope.Config.Spec.IgnorePreflightErrors,
+		PublishConfigTo:        scope.Config.Spec.PublishConfigTo,
+		NodeLabels:             nodeRegistration.KubeletExtraArgs[kubeletNodeLabelsArg],
+		NodeName:               nodeRegistration.Name,
+		SSHHardening:           scope.Config.Spec.SSHHardening,
+		FIPSMode:               fipsMode(scope.Config),
+		Kdump:                  scope.Config.Spec.Kdump,
+		NodeProblemDetector:    scope.Config.Spec.InstallNodeProblemDetector,
+		RuntimeHandlers:        scope.Config.Spec.RuntimeHandlers,
+		Timezone:               scope.Config.Spec.Timezone,
+		UploadBootLogsTo:       scope.Config.Spec.UploadBootLogsTo,
+		StartupTaint:           scope.Config.Spec.StartupTaint,
+		HostDNS:                scope.Config.Spec.HostDNS,
+		DNSManagement:          scope.Config.Spec.DNSManagement,
+		ContainerdMetrics:      scope.Config.Spec.ContainerdMetrics,
+		CgroupV2:               cgroupV2(scope.Config),
+		SwapAccounting:         swapEnabled(scope.Config),
+		AuditdRules:            scope.Config.Spec.AuditdRules,
+		SystemLimits:           scope.Config.Spec.SystemLimits,
+		KubeletServingCertSANs: scope.Config.Spec.KubeletServingCertSANs,
+		ContainerdPull:         scope.Config.Spec.ContainerdPull,
+	}
+}
+
+// resolveAllFiles returns the files of the bootstrap data: the ones of .Spec.Files, and the ones written for the
+// other settings of the KubeadmConfig.
+func (r *KubeadmConfigReconciler) resolveAllFiles(ctx context.Context, scope *Scope) ([]bootstrapv1.File, error) {
+	files, err := r.resolveFiles(ctx, scope.Config, scope.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	// The audit policy is only read by the API server, which runs on the control plane machines.
+	if scope.ConfigOwner.IsControlPlaneMachine() {
+		if files, err = appendAuditPolicyFile(scope.Config, files); err != nil {
+			return nil, err
+		}
+	}
+	for _, appendFiles := range []func(context.Context, *bootstrapv1.KubeadmConfig, []bootstrapv1.File) ([]bootstrapv1.File, error){
+		r.appendCloudConfigFile,
+		r.appendNodeProblemDetectorConfigFile,
+		r.appendStaticPodFiles,
+		r.appendBootLogsCredentialsFile,
+	} {
+		if files, err = appendFiles(ctx, scope.Config, files); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
 // resolveFiles maps .Spec.Files into cloudinit.Files, resolving any object references
-// along the way.
-func (r *KubeadmConfigReconciler) resolveFiles(ctx context.Context, cfg *bootstrapv1.KubeadmConfig) ([]bootstrapv1.File, error) {
+// along the way. The contents of multiple sources are concatenated in order, and templated
+// contents are rendered with the values of the given Cluster.
+func (r *KubeadmConfigReconciler) resolveFiles(ctx context.Context, cfg *bootstrapv1.KubeadmConfig, cluster *clusterv1.Cluster) ([]bootstrapv1.File, error) {
 	collected := make([]bootstrapv1.File, 0, len(cfg.Spec.Files))
 
 	for i := range cfg.Spec.Files {
 		in := cfg.Spec.Files[i]
-		if in.ContentFrom != nil {
-			data, err := r.resolveSecretFileContent(ctx, cfg.Namespace, in)
-			if err != nil {
-				return nil, errors.Wrapf(err, "failed to resolve file source")
+		if len(in.ContentFrom) > 0 {
+			var content []byte
+			for _, source := range in.ContentFrom {
+				data, err := r.resolveSecretFileContent(ctx, cfg.Namespace, source)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to resolve file source")
+				}
+				content = append(content, data...)
 			}
 			in.ContentFrom = nil
-			in.Content = string(data)
+			in.Content = string(content)
+		}
+		if in.Template {
+			content, err := renderFileTemplate(in, cluster)
+			if err != nil {
+				return nil, err
+			}
+			in.Template = false
+			in.Content = content
 		}
 		collected = append(collected, in)
 	}
@@ -625,19 +835,52 @@ func (r *KubeadmConfigReconciler) resolveFiles(ctx context.Context, cfg *bootstr
 	return collected, nil
 }
 
+// fileTemplateData holds the values available to templated files.
+type fileTemplateData struct {
+	// ControlPlaneEndpoint is the control plane endpoint of the Cluster, as "host:port".
+	ControlPlaneEndpoint string
+}
+
+// hasTemplatedFiles returns whether any of the files of the given config is templated.
+func hasTemplatedFiles(cfg *bootstrapv1.KubeadmConfig) bool {
+	for i := range cfg.Spec.Files {
+		if cfg.Spec.Files[i].Template {
+			return true
+		}
+	}
+	return false
+}
+
+// renderFileTemplate renders the content of a templated file with the values of the given Cluster.
+func renderFileTemplate(file bootstrapv1.File, cluster *clusterv1.Cluster) (string, error) {
+	tm, err := template.New(file.Path).Option("missingkey=error").Parse(file.Content)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse template of file %q", file.Path)
+	}
+
+	var out bytes.Buffer
+	data := fileTemplateData{
+		ControlPlaneEndpoint: cluster.Spec.ControlPlaneEndpoint.String(),
+	}
+	if err := tm.Execute(&out, data); err != nil {
+		return "", errors.Wrapf(err, "failed to render template of file %q", file.Path)
+	}
+	return out.String(), nil
+}
+
 // resolveSecretFileContent returns file content fetched from a referenced secret object.
-func (r *KubeadmConfigReconciler) resolveSecretFileContent(ctx context.Context, ns string, source bootstrapv1.File) ([]byte, error) {
+func (r *KubeadmConfigReconciler) resolveSecretFileContent(ctx context.Context, ns string, source bootstrapv1.FileSource) ([]byte, error) {
 	secret := &corev1.Secret{}
-	key := types.NamespacedName{Namespace: ns, Name: source.ContentFrom.Secret.Name}
+	key := types.NamespacedName{Namespace: ns, Name: source.Secret.Name}
 	if err := r.Client.Get(ctx, key, secret); err != nil {
 		if apierrors.IsNotFound(err) {
 			return nil, errors.Wrapf(err, "secret not found: %s", key)
 		}
 		return nil, errors.Wrapf(err, "failed to retrieve Secret %q", key)
 	}
-	data, ok := secret.Data[source.ContentFrom.Secret.Key]
+	data, ok := secret.Data[source.Secret.Key]
 	if !ok {
-		return nil, errors.Errorf("secret references non-existent secret key: %q", source.ContentFrom.Secret.Key)
+		return nil, errors.Errorf("secret references non-existent secret key: %q", source.Secret.Key)
 	}
 	return data, nil
 }
@@ -770,7 +1013,7 @@ func (r *KubeadmConfigReconciler) reconcileDiscovery(ctx context.Context, cluste
 			return ctrl.Result{}, err
 		}
 
-		token, err := createToken(ctx, remoteClient)
+		token, err := createToken(ctx, remoteClient, tokenTTL(config), tokenUsages(config))
 		if err != nil {
 			return ctrl.Result{}, errors.Wrapf(err, "failed to create new bootstrap token")
 		}
@@ -834,6 +1077,769 @@ func (r *KubeadmConfigReconciler) reconcileTopLevelObjectSettings(ctx context.Co
 	}
 }
 
+// reconcileKubeletArgs injects into the given node registration options the kubelet args required by the settings of
+// the KubeadmConfig, for the given Kubernetes version. They must be a copy of the ones of the KubeadmConfig, only used
+// to render the bootstrap data: saving the args in its spec would change it without user action, and the
+// KubeadmControlPlane would never match the KubeadmConfigs of its machines with its own again, rolling them out
+// endlessly.
+func reconcileKubeletArgs(scope *Scope, nodeRegistration *bootstrapv1.NodeRegistrationOptions, kubernetesVersion string) {
+	reconcileGracefulShutdown(scope.Config, nodeRegistration)
+	reconcileSandboxImage(scope.Config, nodeRegistration)
+	reconcileKubeletServerCertificateRotation(scope.Config, nodeRegistration)
+	reconcileKubeletCloudProvider(scope.Config, nodeRegistration)
+	reconcileKubeletSecurityDefaults(scope.Config, nodeRegistration, kubernetesVersion)
+	reconcileReservedResources(scope.Config, nodeRegistration)
+	reconcileMaxPods(scope, nodeRegistration)
+	reconcileImageGC(scope.Config, nodeRegistration)
+	reconcileStartupTaint(scope.Config, nodeRegistration)
+	reconcileKubeletRootDir(scope.Config, nodeRegistration)
+	reconcileCgroups(scope.Config, nodeRegistration, kubernetesVersion)
+	reconcileKubeletServingCert(scope.Config, nodeRegistration)
+}
+
+// reconcileGracefulShutdown injects into the given node registration options the kubelet args required
+// by the graceful shutdown configuration, if any. User provided kubelet args are respected.
+func reconcileGracefulShutdown(config *bootstrapv1.KubeadmConfig, nodeRegistration *bootstrapv1.NodeRegistrationOptions) {
+	if config.Spec.GracefulShutdown == nil {
+		return
+	}
+
+	if nodeRegistration.KubeletExtraArgs == nil {
+		nodeRegistration.KubeletExtraArgs = map[string]string{}
+	}
+	if _, ok := nodeRegistration.KubeletExtraArgs[kubeletShutdownGracePeriodArg]; !ok {
+		nodeRegistration.KubeletExtraArgs[kubeletShutdownGracePeriodArg] = config.Spec.GracefulShutdown.Timeout.Duration.String()
+	}
+}
+
+// reconcileSandboxImage injects into the given node registration options the kubelet arg setting the
+// sandbox image, if any. User provided kubelet args are respected.
+func reconcileSandboxImage(config *bootstrapv1.KubeadmConfig, nodeRegistration *bootstrapv1.NodeRegistrationOptions) {
+	if config.Spec.SandboxImage == nil {
+		return
+	}
+
+	if nodeRegistration.KubeletExtraArgs == nil {
+		nodeRegistration.KubeletExtraArgs = map[string]string{}
+	}
+	if _, ok := nodeRegistration.KubeletExtraArgs[kubeletPodInfraContainerImageArg]; !ok {
+		nodeRegistration.KubeletExtraArgs[kubeletPodInfraContainerImageArg] = *config.Spec.SandboxImage
+	}
+}
+
+// reconcileKubeletServerCertificateRotation injects into the given node registration options the kubelet arg
+// enabling the rotation of its serving certificate, if requested. User provided kubelet args are respected.
+func reconcileKubeletServerCertificateRotation(config *bootstrapv1.KubeadmConfig, nodeRegistration *bootstrapv1.NodeRegistrationOptions) {
+	if config.Spec.RotateKubeletServerCertificate == nil || !*config.Spec.RotateKubeletServerCertificate {
+		return
+	}
+
+	if nodeRegistration.KubeletExtraArgs == nil {
+		nodeRegistration.KubeletExtraArgs = map[string]string{}
+	}
+	if _, ok := nodeRegistration.KubeletExtraArgs[kubeletRotateServerCertificatesArg]; !ok {
+		nodeRegistration.KubeletExtraArgs[kubeletRotateServerCertificatesArg] = "true"
+	}
+}
+
+// reconcileKubeletCloudProvider injects into the given node registration options the kubelet args setting the
+// cloud provider and its cloud config file, if any. User provided kubelet args are respected.
+func reconcileKubeletCloudProvider(config *bootstrapv1.KubeadmConfig, nodeRegistration *bootstrapv1.NodeRegistrationOptions) {
+	if config.Spec.CloudProvider == nil {
+		return
+	}
+
+	if nodeRegistration.KubeletExtraArgs == nil {
+		nodeRegistration.KubeletExtraArgs = map[string]string{}
+	}
+	for name, value := range cloudProviderArgs(config.Spec.CloudProvider) {
+		if _, ok := nodeRegistration.KubeletExtraArgs[name]; !ok {
+			nodeRegistration.KubeletExtraArgs[name] = value
+		}
+	}
+}
+
+// reconcileKubeletSecurityDefaults injects into the given node registration options the kubelet args enabling the
+// seccomp default and user namespaces, if requested, along with the feature gates they require on the given
+// Kubernetes version; the most recent gates are used when the version is unknown. The container runtime needs no
+// configuration, as the kubelet requests the profile and the user namespaces of each pod. User provided kubelet args
+// and feature gates are respected.
+func reconcileKubeletSecurityDefaults(config *bootstrapv1.KubeadmConfig, nodeRegistration *bootstrapv1.NodeRegistrationOptions, kubernetesVersion string) {
+	seccompDefault := config.Spec.SeccompDefault != nil && *config.Spec.SeccompDefault
+	userNamespaces := config.Spec.UserNamespaces != nil && *config.Spec.UserNamespaces
+	if !seccompDefault && !userNamespaces {
+		return
+	}
+
+	// The version is unknown, i.e. nil, when it can't be parsed.
+	version, _ := versionutil.ParseSemantic(kubernetesVersion)
+
+	if nodeRegistration.KubeletExtraArgs == nil {
+		nodeRegistration.KubeletExtraArgs = map[string]string{}
+	}
+	if seccompDefault {
+		if _, ok := nodeRegistration.KubeletExtraArgs[kubeletSeccompDefaultArg]; !ok {
+			nodeRegistration.KubeletExtraArgs[kubeletSeccompDefaultArg] = "true"
+		}
+		if version != nil && version.LessThan(seccompDefaultEnabledVersion) {
+			enableKubeletFeatureGate(nodeRegistration, seccompDefaultFeatureGate)
+		}
+	}
+	if userNamespaces {
+		if version != nil && version.LessThan(userNamespacesSupportVersion) {
+			enableKubeletFeatureGate(nodeRegistration, legacyUserNamespacesFeatureGate)
+		} else {
+			enableKubeletFeatureGate(nodeRegistration, userNamespacesFeatureGate)
+		}
+	}
+}
+
+// reconcileReservedResources injects into the given node registration options the kubelet args reserving resources
+// for the Kubernetes and system daemons, and setting the hard eviction thresholds, if any. User provided kubelet args
+// are respected.
+func reconcileReservedResources(config *bootstrapv1.KubeadmConfig, nodeRegistration *bootstrapv1.NodeRegistrationOptions) {
+	reserved := config.Spec.ReservedResources
+	if reserved == nil {
+		return
+	}
+
+	args := map[string]string{
+		kubeletKubeReservedArg:   joinKubeletArgPairs(reserved.KubeReserved, "="),
+		kubeletSystemReservedArg: joinKubeletArgPairs(reserved.SystemReserved, "="),
+		kubeletEvictionHardArg:   joinKubeletArgPairs(reserved.EvictionHard, "<"),
+	}
+	for name, value := range args {
+		if value == "" {
+			continue
+		}
+		if nodeRegistration.KubeletExtraArgs == nil {
+			nodeRegistration.KubeletExtraArgs = map[string]string{}
+		}
+		if _, ok := nodeRegistration.KubeletExtraArgs[name]; !ok {
+			nodeRegistration.KubeletExtraArgs[name] = value
+		}
+	}
+}
+
+// reconcileMaxPods injects into the given node registration options the kubelet arg setting the maximum number of
+// pods, if any; a user provided kubelet arg is respected. A warning is logged when the maximum exceeds the addresses
+// of the pod CIDR allocated to the node, as far as they can be derived from the pod CIDRs of the cluster.
+func reconcileMaxPods(scope *Scope, nodeRegistration *bootstrapv1.NodeRegistrationOptions) {
+	maxPods := scope.Config.Spec.MaxPods
+	if maxPods == nil {
+		return
+	}
+
+	if nodeRegistration.KubeletExtraArgs == nil {
+		nodeRegistration.KubeletExtraArgs = map[string]string{}
+	}
+	if _, ok := nodeRegistration.KubeletExtraArgs[kubeletMaxPodsArg]; !ok {
+		nodeRegistration.KubeletExtraArgs[kubeletMaxPodsArg] = strconv.Itoa(int(*maxPods))
+	}
+
+	if capacity, ok := nodePodCIDRCapacity(scope.Cluster, scope.Config.Spec.ClusterConfiguration); ok && int64(*maxPods) > capacity {
+		scope.Info("Warning: maxPods exceeds the addresses of the pod CIDR allocated to the node, pods may fail to get an IP", "maxPods", *maxPods, "podCIDRAddresses", capacity)
+	}
+}
+
+// reconcileImageGC injects into the given node registration options the kubelet args setting the thresholds of the
+// image garbage collection, if any. User provided kubelet args are respected.
+func reconcileImageGC(config *bootstrapv1.KubeadmConfig, nodeRegistration *bootstrapv1.NodeRegistrationOptions) {
+	imageGC := config.Spec.ImageGC
+	if imageGC == nil {
+		return
+	}
+
+	args := map[string]string{
+		kubeletImageGCHighThresholdArg: strconv.Itoa(int(imageGC.HighThresholdPercent)),
+		kubeletImageGCLowThresholdArg:  strconv.Itoa(int(imageGC.LowThresholdPercent)),
+	}
+	if nodeRegistration.KubeletExtraArgs == nil {
+		nodeRegistration.KubeletExtraArgs = map[string]string{}
+	}
+	for name, value := range args {
+		if _, ok := nodeRegistration.KubeletExtraArgs[name]; !ok {
+			nodeRegistration.KubeletExtraArgs[name] = value
+		}
+	}
+}
+
+// reconcileStartupTaint injects into the given node registration options the kubelet arg registering the node with
+// the startup taint, if any, alongside the taints of the node registration, as the arg overrides the one kubeadm sets
+// from them. The taint is appended to a user provided arg.
+func reconcileStartupTaint(config *bootstrapv1.KubeadmConfig, nodeRegistration *bootstrapv1.NodeRegistrationOptions) {
+	startupTaint := config.Spec.StartupTaint
+	if startupTaint == nil {
+		return
+	}
+
+	if nodeRegistration.KubeletExtraArgs == nil {
+		nodeRegistration.KubeletExtraArgs = map[string]string{}
+	}
+	taints, ok := nodeRegistration.KubeletExtraArgs[kubeletRegisterWithTaintsArg]
+	if !ok {
+		registered := make([]string, 0, len(nodeRegistration.Taints))
+		for i := range nodeRegistration.Taints {
+			registered = append(registered, nodeRegistration.Taints[i].ToString())
+		}
+		taints = strings.Join(registered, ",")
+	}
+	for _, taint := range strings.Split(taints, ",") {
+		if strings.TrimSpace(taint) == startupTaint.ToString() {
+			nodeRegistration.KubeletExtraArgs[kubeletRegisterWithTaintsArg] = taints
+			return
+		}
+	}
+
+	if taints != "" {
+		taints += ","
+	}
+	nodeRegistration.KubeletExtraArgs[kubeletRegisterWithTaintsArg] = taints + startupTaint.ToString()
+}
+
+// reconcileKubeletRootDir injects into the given node registration options the kubelet arg setting its root dir,
+// if any and if it is not the default one. The KubeadmConfig webhook ensures it does not conflict with a user
+// provided kubelet arg, and that it is mounted from a filesystem declared in the disk setup.
+func reconcileKubeletRootDir(config *bootstrapv1.KubeadmConfig, nodeRegistration *bootstrapv1.NodeRegistrationOptions) {
+	rootDir := config.Spec.KubeletRootDir
+	if rootDir == nil || *rootDir == defaultKubeletRootDir {
+		return
+	}
+
+	if nodeRegistration.KubeletExtraArgs == nil {
+		nodeRegistration.KubeletExtraArgs = map[string]string{}
+	}
+	if _, ok := nodeRegistration.KubeletExtraArgs[kubeletRootDirArg]; !ok {
+		nodeRegistration.KubeletExtraArgs[kubeletRootDirArg] = *rootDir
+	}
+}
+
+// reconcileCgroups injects into the given node registration options the kubelet args setting the systemd cgroup
+// driver with cgroup v2, and letting the kubelet run with swap, along with the feature gate it requires on the given
+// Kubernetes version, if requested. The kernel args and the containerd configuration are rendered in the bootstrap
+// data. User provided kubelet args are respected, the KubeadmConfig webhook ensures they agree with the settings.
+func reconcileCgroups(config *bootstrapv1.KubeadmConfig, nodeRegistration *bootstrapv1.NodeRegistrationOptions, kubernetesVersion string) {
+	if !cgroupV2(config) && !swapEnabled(config) {
+		return
+	}
+
+	if nodeRegistration.KubeletExtraArgs == nil {
+		nodeRegistration.KubeletExtraArgs = map[string]string{}
+	}
+	if _, ok := nodeRegistration.KubeletExtraArgs[kubeletCgroupDriverArg]; !ok && cgroupV2(config) {
+		nodeRegistration.KubeletExtraArgs[kubeletCgroupDriverArg] = systemdCgroupDriver
+	}
+	if swapEnabled(config) {
+		if _, ok := nodeRegistration.KubeletExtraArgs[kubeletFailSwapOnArg]; !ok {
+			nodeRegistration.KubeletExtraArgs[kubeletFailSwapOnArg] = "false"
+		}
+		// The version is unknown, i.e. nil, when it can't be parsed.
+		if version, _ := versionutil.ParseSemantic(kubernetesVersion); version != nil && version.LessThan(nodeSwapEnabledVersion) {
+			enableKubeletFeatureGate(nodeRegistration, nodeSwapFeatureGate)
+		}
+	}
+}
+
+// reconcileKubeletServingCert injects into the given node registration options the kubelet args setting its serving
+// certificate to the one generated in the bootstrap data for the KubeletServingCertSANs, if any. User provided kubelet
+// args are respected, the KubeadmConfig webhook rejects them along with the KubeletServingCertSANs.
+func reconcileKubeletServingCert(config *bootstrapv1.KubeadmConfig, nodeRegistration *bootstrapv1.NodeRegistrationOptions) {
+	if len(config.Spec.KubeletServingCertSANs) == 0 {
+		return
+	}
+
+	if nodeRegistration.KubeletExtraArgs == nil {
+		nodeRegistration.KubeletExtraArgs = map[string]string{}
+	}
+	args := map[string]string{
+		kubeletTLSCertFileArg:       cloudinit.KubeletServingCertPath,
+		kubeletTLSPrivateKeyFileArg: cloudinit.KubeletServingKeyPath,
+	}
+	for name, value := range args {
+		if _, ok := nodeRegistration.KubeletExtraArgs[name]; !ok {
+			nodeRegistration.KubeletExtraArgs[name] = value
+		}
+	}
+}
+
+// nodePodCIDRCapacity returns the number of addresses of the pod CIDR allocated to each node, the smallest one for
+// dual-stack clusters, and whether it can be derived from the pod CIDRs of the cluster and the node CIDR mask sizes
+// of the controller manager, which default to /24 and /64. Capacities above the range of an int32 are not derived.
+func nodePodCIDRCapacity(cluster *clusterv1.Cluster, clusterConfiguration *bootstrapv1.ClusterConfiguration) (int64, bool) {
+	if cluster == nil || cluster.Spec.ClusterNetwork == nil || cluster.Spec.ClusterNetwork.Pods == nil {
+		return 0, false
+	}
+	var controllerManagerArgs map[string]string
+	if clusterConfiguration != nil {
+		controllerManagerArgs = clusterConfiguration.ControllerManager.ExtraArgs
+	}
+
+	var capacity int64
+	derived := false
+	for _, block := range cluster.Spec.ClusterNetwork.Pods.CIDRBlocks {
+		_, cidr, err := net.ParseCIDR(block)
+		if err != nil {
+			return 0, false
+		}
+		_, bits := cidr.Mask.Size()
+
+		maskSizeArgs, maskSize := []string{nodeCIDRMaskSizeIPv4Arg, nodeCIDRMaskSizeArg}, defaultNodeCIDRMaskSizeIPv4
+		if cidr.IP.To4() == nil {
+			maskSizeArgs, maskSize = []string{nodeCIDRMaskSizeIPv6Arg, nodeCIDRMaskSizeArg}, defaultNodeCIDRMaskSizeIPv6
+		}
+		for _, arg := range maskSizeArgs {
+			if value, ok := controllerManagerArgs[arg]; ok {
+				if maskSize, err = strconv.Atoi(value); err != nil {
+					return 0, false
+				}
+				break
+			}
+		}
+
+		hostBits := bits - maskSize
+		if hostBits < 0 {
+			return 0, false
+		}
+		if hostBits >= 31 {
+			continue
+		}
+		if blockCapacity := int64(1) << hostBits; !derived || blockCapacity < capacity {
+			capacity, derived = blockCapacity, true
+		}
+	}
+	return capacity, derived
+}
+
+// joinKubeletArgPairs joins the given key and value pairs with the given separator into a comma separated kubelet
+// arg value, ordered by key so that the rendered config is stable.
+func joinKubeletArgPairs(pairs map[string]string, separator string) string {
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, key+separator+pairs[key])
+	}
+	return strings.Join(values, ",")
+}
+
+// enableKubeletFeatureGate enables the given feature gate in the kubelet args of the given node registration
+// options, unless it is already set.
+func enableKubeletFeatureGate(nodeRegistration *bootstrapv1.NodeRegistrationOptions, gate string) {
+	featureGates := nodeRegistration.KubeletExtraArgs[kubeletFeatureGatesArg]
+	for _, featureGate := range strings.Split(featureGates, ",") {
+		if strings.TrimSpace(strings.SplitN(featureGate, "=", 2)[0]) == gate {
+			return
+		}
+	}
+
+	if featureGates != "" {
+		featureGates += ","
+	}
+	nodeRegistration.KubeletExtraArgs[kubeletFeatureGatesArg] = featureGates + gate + "=true"
+}
+
+// reconcileCloudProvider injects into the given cluster configuration the API server and controller manager args and
+// volumes setting the cloud provider and its cloud config file, if any. User provided args and volumes are respected.
+func reconcileCloudProvider(config *bootstrapv1.KubeadmConfig, clusterConfiguration *bootstrapv1.ClusterConfiguration) {
+	if config.Spec.CloudProvider == nil || clusterConfiguration == nil {
+		return
+	}
+
+	args := cloudProviderArgs(config.Spec.CloudProvider)
+	for _, component := range []*bootstrapv1.ControlPlaneComponent{
+		&clusterConfiguration.APIServer.ControlPlaneComponent,
+		&clusterConfiguration.ControllerManager,
+	} {
+		if component.ExtraArgs == nil {
+			component.ExtraArgs = map[string]string{}
+		}
+		for name, value := range args {
+			if _, ok := component.ExtraArgs[name]; !ok {
+				component.ExtraArgs[name] = value
+			}
+		}
+
+		// The components run as static pods, so the cloud config must be mounted into them.
+		if config.Spec.CloudProvider.ConfigFrom != nil && !hasHostPathMount(component.ExtraVolumes, "cloud-config") {
+			component.ExtraVolumes = append(component.ExtraVolumes, bootstrapv1.HostPathMount{
+				Name:      "cloud-config",
+				HostPath:  cloudConfigPath,
+				MountPath: cloudConfigPath,
+				ReadOnly:  true,
+				PathType:  corev1.HostPathFile,
+			})
+		}
+	}
+}
+
+// cloudProviderArgs returns the args setting the given cloud provider, and its cloud config file if any.
+func cloudProviderArgs(cloudProvider *bootstrapv1.CloudProviderConfig) map[string]string {
+	args := map[string]string{
+		cloudProviderArg: cloudProvider.Name,
+	}
+	if cloudProvider.ConfigFrom != nil {
+		args[cloudConfigArg] = cloudConfigPath
+	}
+	return args
+}
+
+// appendCloudConfigFile appends to the given files the cloud config file, if the cloud provider has one.
+func (r *KubeadmConfigReconciler) appendCloudConfigFile(ctx context.Context, config *bootstrapv1.KubeadmConfig, files []bootstrapv1.File) ([]bootstrapv1.File, error) {
+	if config.Spec.CloudProvider == nil || config.Spec.CloudProvider.ConfigFrom == nil {
+		return files, nil
+	}
+
+	content, err := r.resolveSecretFileContent(ctx, config.Namespace, *config.Spec.CloudProvider.ConfigFrom)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve the cloud config")
+	}
+	return append(files, bootstrapv1.File{
+		Path:        cloudConfigPath,
+		Owner:       "root:root",
+		Permissions: "0600",
+		Content:     string(content),
+	}), nil
+}
+
+// appendNodeProblemDetectorConfigFile appends to the given files the system log monitor config of the node
+// problem detector, if it has one.
+func (r *KubeadmConfigReconciler) appendNodeProblemDetectorConfigFile(ctx context.Context, config *bootstrapv1.KubeadmConfig, files []bootstrapv1.File) ([]bootstrapv1.File, error) {
+	if config.Spec.InstallNodeProblemDetector == nil || config.Spec.InstallNodeProblemDetector.ConfigFrom == nil {
+		return files, nil
+	}
+
+	content, err := r.resolveSecretFileContent(ctx, config.Namespace, *config.Spec.InstallNodeProblemDetector.ConfigFrom)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve the node problem detector config")
+	}
+	return append(files, bootstrapv1.File{
+		Path:        cloudinit.NodeProblemDetectorConfigPath,
+		Owner:       "root:root",
+		Permissions: "0644",
+		Content:     string(content),
+	}), nil
+}
+
+// appendStaticPodFiles appends to the given files the manifests of the static pods, once checked to be Pods.
+func (r *KubeadmConfigReconciler) appendStaticPodFiles(ctx context.Context, config *bootstrapv1.KubeadmConfig, files []bootstrapv1.File) ([]bootstrapv1.File, error) {
+	for _, staticPod := range config.Spec.StaticPods {
+		if staticPod.ManifestFrom == nil {
+			return nil, errors.Errorf("static pod %q has no manifest source", staticPod.Name)
+		}
+
+		content, err := r.resolveSecretFileContent(ctx, config.Namespace, *staticPod.ManifestFrom)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve the manifest of static pod %q", staticPod.Name)
+		}
+		if err := validateStaticPodManifest(content); err != nil {
+			return nil, errors.Wrapf(err, "invalid manifest of static pod %q", staticPod.Name)
+		}
+
+		files = append(files, bootstrapv1.File{
+			Path:        path.Join(staticPodManifestsDir, staticPod.Name+".yaml"),
+			Owner:       "root:root",
+			Permissions: "0600",
+			Content:     string(content),
+		})
+	}
+	return files, nil
+}
+
+// appendBootLogsCredentialsFile appends to the given files the HTTP headers authenticating the upload of the boot
+// logs, if any.
+func (r *KubeadmConfigReconciler) appendBootLogsCredentialsFile(ctx context.Context, config *bootstrapv1.KubeadmConfig, files []bootstrapv1.File) ([]bootstrapv1.File, error) {
+	if config.Spec.UploadBootLogsTo == nil || config.Spec.UploadBootLogsTo.CredentialsFrom == nil {
+		return files, nil
+	}
+
+	content, err := r.resolveSecretFileContent(ctx, config.Namespace, *config.Spec.UploadBootLogsTo.CredentialsFrom)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve the boot logs credentials")
+	}
+	return append(files, bootstrapv1.File{
+		Path:        cloudinit.BootLogsCredentialsPath,
+		Owner:       "root:root",
+		Permissions: "0600",
+		Content:     string(content),
+	}), nil
+}
+
+// validateStaticPodManifest checks that the given manifest is a v1 Pod in YAML or JSON.
+func validateStaticPodManifest(manifest []byte) error {
+	pod := &corev1.Pod{}
+	if err := yaml.Unmarshal(manifest, pod); err != nil {
+		return errors.Wrap(err, "manifest is not valid YAML")
+	}
+	if pod.APIVersion != "v1" || pod.Kind != "Pod" {
+		return errors.Errorf("manifest must be of apiVersion v1 and kind Pod, got apiVersion %q and kind %q", pod.APIVersion, pod.Kind)
+	}
+	return nil
+}
+
+// reconcileEtcdDataDir injects into the given cluster configuration the data dir of the local etcd, if any. The
+// KubeadmConfig webhook ensures it does not conflict with an external etcd or a data dir set by the user.
+func reconcileEtcdDataDir(config *bootstrapv1.KubeadmConfig, clusterConfiguration *bootstrapv1.ClusterConfiguration) {
+	if config.Spec.EtcdDataDir == nil || clusterConfiguration == nil || clusterConfiguration.Etcd.External != nil {
+		return
+	}
+
+	if clusterConfiguration.Etcd.Local == nil {
+		clusterConfiguration.Etcd.Local = &bootstrapv1.LocalEtcd{}
+	}
+	if clusterConfiguration.Etcd.Local.DataDir == "" {
+		clusterConfiguration.Etcd.Local.DataDir = *config.Spec.EtcdDataDir
+	}
+}
+
+// reconcileAuditPolicy injects into the given cluster configuration the API server args and volumes required
+// by the audit policy and audit log configuration, if any. User provided args and volumes are respected.
+func reconcileAuditPolicy(config *bootstrapv1.KubeadmConfig, clusterConfiguration *bootstrapv1.ClusterConfiguration) {
+	if config.Spec.AuditPolicy == nil || clusterConfiguration == nil {
+		return
+	}
+
+	args := map[string]string{
+		"audit-policy-file": auditPolicyPath,
+		"audit-log-path":    defaultAuditLogPath,
+	}
+	if logConfig := config.Spec.AuditLogConfig; logConfig != nil {
+		if logConfig.Path != "" {
+			args["audit-log-path"] = logConfig.Path
+		}
+		if logConfig.MaxAge != nil {
+			args["audit-log-maxage"] = strconv.Itoa(int(*logConfig.MaxAge))
+		}
+		if logConfig.MaxBackup != nil {
+			args["audit-log-maxbackup"] = strconv.Itoa(int(*logConfig.MaxBackup))
+		}
+		if logConfig.MaxSize != nil {
+			args["audit-log-maxsize"] = strconv.Itoa(int(*logConfig.MaxSize))
+		}
+	}
+
+	apiServer := &clusterConfiguration.APIServer
+	if apiServer.ExtraArgs == nil {
+		apiServer.ExtraArgs = map[string]string{}
+	}
+	for name, value := range args {
+		if _, ok := apiServer.ExtraArgs[name]; !ok {
+			apiServer.ExtraArgs[name] = value
+		}
+	}
+
+	// The API server runs as a static pod, so both the policy and the log directory must be mounted into it.
+	volumes := []bootstrapv1.HostPathMount{
+		{
+			Name:      "audit-policy",
+			HostPath:  apiServer.ExtraArgs["audit-policy-file"],
+			MountPath: apiServer.ExtraArgs["audit-policy-file"],
+			ReadOnly:  true,
+			PathType:  corev1.HostPathFile,
+		},
+	}
+	// An audit log path of "-" means the audit log is written to stdout.
+	if logPath := apiServer.ExtraArgs["audit-log-path"]; logPath != "-" {
+		volumes = append(volumes, bootstrapv1.HostPathMount{
+			Name:      "audit-log",
+			HostPath:  path.Dir(logPath),
+			MountPath: path.Dir(logPath),
+			PathType:  corev1.HostPathDirectoryOrCreate,
+		})
+	}
+	for _, volume := range volumes {
+		if !hasHostPathMount(apiServer.ExtraVolumes, volume.Name) {
+			apiServer.ExtraVolumes = append(apiServer.ExtraVolumes, volume)
+		}
+	}
+}
+
+// hasHostPathMount returns whether a volume with the given name is in the list.
+func hasHostPathMount(volumes []bootstrapv1.HostPathMount, name string) bool {
+	for _, volume := range volumes {
+		if volume.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// appendAuditPolicyFile appends to the given files the audit policy file, if an audit policy is configured.
+func appendAuditPolicyFile(config *bootstrapv1.KubeadmConfig, files []bootstrapv1.File) ([]bootstrapv1.File, error) {
+	if config.Spec.AuditPolicy == nil {
+		return files, nil
+	}
+
+	policy, err := yaml.JSONToYAML(config.Spec.AuditPolicy.Raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render the audit policy")
+	}
+	return append(files, bootstrapv1.File{
+		Path:        auditPolicyPath,
+		Owner:       "root:root",
+		Permissions: "0600",
+		Content:     string(policy),
+	}), nil
+}
+
+// installCrictlConfig returns whether the crictl configuration should be written on the machine.
+func installCrictlConfig(config *bootstrapv1.KubeadmConfig) bool {
+	return config.Spec.InstallCrictlConfig != nil && *config.Spec.InstallCrictlConfig
+}
+
+// persistentJournal returns whether journald should be configured to store its logs on disk.
+func persistentJournal(config *bootstrapv1.KubeadmConfig) bool {
+	return config.Spec.PersistentJournal != nil && *config.Spec.PersistentJournal
+}
+
+// sensitiveFilePathSuffixes are the suffixes of the paths of files commonly holding credentials.
+var sensitiveFilePathSuffixes = []string{
+	"/.docker/config.json",
+	"/var/lib/kubelet/config.json",
+	"/.git-credentials",
+	"/.netrc",
+	".key",
+}
+
+// sensitiveFields returns the sensitive values set inline in the spec of the given config, in the
+// format used by KubeadmConfigSpec.SensitiveFields.
+func sensitiveFields(config *bootstrapv1.KubeadmConfig) []string {
+	var fields []string
+	for _, user := range config.Spec.Users {
+		if user.Passwd != nil && *user.Passwd != "" {
+			fields = append(fields, fmt.Sprintf("users[%s].passwd", user.Name))
+		}
+	}
+	// Contents referenced from Secrets are not readable from the config.
+	for _, file := range config.Spec.Files {
+		if file.Content == "" {
+			continue
+		}
+		for _, suffix := range sensitiveFilePathSuffixes {
+			if strings.HasSuffix(file.Path, suffix) {
+				fields = append(fields, fmt.Sprintf("files[%s]", file.Path))
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// reconcileSensitiveFields sets the SensitiveFieldsAcknowledged condition, reporting the sensitive values set inline
+// in the spec of the given config which are not acknowledged in its SensitiveFields.
+func reconcileSensitiveFields(ctx context.Context, config *bootstrapv1.KubeadmConfig) {
+	log := ctrl.LoggerFrom(ctx)
+
+	fields := sensitiveFields(config)
+	if len(fields) == 0 {
+		conditions.Delete(config, bootstrapv1.SensitiveFieldsAcknowledgedCondition)
+		return
+	}
+
+	acknowledged := sets.NewString(config.Spec.SensitiveFields...)
+	var unacknowledged []string
+	for _, field := range fields {
+		if !acknowledged.Has(field) {
+			unacknowledged = append(unacknowledged, field)
+		}
+	}
+	if len(unacknowledged) == 0 {
+		conditions.MarkTrue(config, bootstrapv1.SensitiveFieldsAcknowledgedCondition)
+		return
+	}
+
+	log.Info("Sensitive fields are set inline, consider referencing them from Secrets", "fields", unacknowledged)
+	conditions.MarkFalse(config, bootstrapv1.SensitiveFieldsAcknowledgedCondition, bootstrapv1.UnacknowledgedSensitiveFieldsReason, clusterv1.ConditionSeverityWarning,
+		"Sensitive fields are set inline and readable by anyone allowed to read this config: %s", strings.Join(unacknowledged, ", "))
+}
+
+// knownPreflightChecks are the names of the kubeadm preflight checks, in lower case as compared by kubeadm.
+var knownPreflightChecks = sets.NewString(
+	"all",
+	"controlplanenodesready",
+	"cri",
+	"externaletcdversion",
+	"hostname",
+	"httpproxy",
+	"httpproxycidr",
+	"imagepull",
+	"isdockersystemdcheck",
+	"isprivilegeduser",
+	"kubeletversion",
+	"kubernetesversion",
+	"mem",
+	"numcpu",
+	"swap",
+	"systemverification",
+)
+
+// knownPreflightCheckPrefixes are the prefixes of the names of the kubeadm preflight checks run for each port,
+// file, directory or executable, e.g. "Port-6443" or "DirAvailable--var-lib-etcd".
+var knownPreflightCheckPrefixes = []string{
+	"diravailable-",
+	"fileavailable-",
+	"filecontent-",
+	"fileexisting-",
+	"port-",
+	"service-",
+}
+
+// unknownPreflightErrors returns the ignored preflight errors of the given config which are not the name of
+// a known kubeadm preflight check.
+func unknownPreflightErrors(config *bootstrapv1.KubeadmConfig) []string {
+	var unknown []string
+	for _, name := range config.Spec.IgnorePreflightErrors {
+		lowerName := strings.ToLower(name)
+		if knownPreflightChecks.Has(lowerName) {
+			continue
+		}
+		known := false
+		for _, prefix := range knownPreflightCheckPrefixes {
+			if strings.HasPrefix(lowerName, prefix) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+// reconcileIgnorePreflightErrors warns about the ignored preflight errors of the given config which are not the
+// name of a known kubeadm preflight check; these are not rejected, as the checks depend on the kubeadm version.
+func reconcileIgnorePreflightErrors(ctx context.Context, config *bootstrapv1.KubeadmConfig) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if unknown := unknownPreflightErrors(config); len(unknown) > 0 {
+		log.Info("Ignoring errors of unknown kubeadm preflight checks, they are passed as is to kubeadm", "checks", unknown)
+	}
+}
+
+// fipsMode returns whether FIPS mode should be enabled on the machine.
+func fipsMode(config *bootstrapv1.KubeadmConfig) bool {
+	return config.Spec.FIPSMode != nil && *config.Spec.FIPSMode
+}
+
+// cgroupV2 returns whether the machine should run with the unified cgroup (v2) hierarchy.
+func cgroupV2(config *bootstrapv1.KubeadmConfig) bool {
+	return config.Spec.CgroupV2 != nil && *config.Spec.CgroupV2
+}
+
+// swapEnabled returns whether the kubelet should run with swap, accounted with cgroup v2.
+func swapEnabled(config *bootstrapv1.KubeadmConfig) bool {
+	return config.Spec.FailSwapOn != nil && !*config.Spec.FailSwapOn
+}
+
+// growRootFilesystem returns whether the root filesystem should be grown to the size of its disk.
+func growRootFilesystem(config *bootstrapv1.KubeadmConfig) bool {
+	return config.Spec.GrowRootFilesystem != nil && *config.Spec.GrowRootFilesystem
+}
+
 // storeBootstrapData creates a new secret with the data passed in as input,
 // sets the reference in the configuration status and ready to true.
 func (r *KubeadmConfigReconciler) storeBootstrapData(ctx context.Context, scope *Scope, data []byte) error {
@@ -874,6 +1880,8 @@ func (r *KubeadmConfigReconciler) storeBootstrapData(ctx context.Context, scope
 		}
 	}
 	scope.Config.Status.DataSecretName = pointer.StringPtr(secret.Name)
+	// The bootstrap data is always rendered as cloud-config, the only supported format.
+	scope.Config.Status.RenderedFormat = bootstrapv1.CloudConfig
 	scope.Config.Status.Ready = true
 	conditions.MarkTrue(scope.Config, bootstrapv1.DataSecretAvailableCondition)
 	return nil
@@ -33,6 +33,7 @@ import (
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/bootstrap/kubeadm/internal/cloudinit"
 	fakeremote "sigs.k8s.io/cluster-api/controllers/remote/fake"
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/feature"
@@ -407,6 +408,7 @@ func TestKubeadmConfigReconciler_Reconcile_GenerateCloudConfigData(t *testing.T)
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(cfg.Status.Ready).To(BeTrue())
 	g.Expect(cfg.Status.DataSecretName).NotTo(BeNil())
+	g.Expect(cfg.Status.RenderedFormat).To(Equal(bootstrapv1.CloudConfig))
 	g.Expect(cfg.Status.ObservedGeneration).NotTo(BeNil())
 	assertHasTrueCondition(g, myclient, request, bootstrapv1.CertificatesAvailableCondition)
 	assertHasTrueCondition(g, myclient, request, bootstrapv1.DataSecretAvailableCondition)
@@ -569,6 +571,7 @@ func TestReconcileIfJoinNodesAndControlPlaneIsReady(t *testing.T) {
 			g.Expect(err).NotTo(HaveOccurred())
 			g.Expect(cfg.Status.Ready).To(BeTrue())
 			g.Expect(cfg.Status.DataSecretName).NotTo(BeNil())
+			g.Expect(cfg.Status.RenderedFormat).To(Equal(bootstrapv1.CloudConfig))
 			g.Expect(cfg.Status.ObservedGeneration).NotTo(BeNil())
 			assertHasTrueCondition(g, myclient, request, bootstrapv1.DataSecretAvailableCondition)
 
@@ -876,6 +879,37 @@ func TestBootstrapTokenTTLExtension(t *testing.T) {
 	}
 }
 
+func TestBootstrapTokenTTLAndUsages(t *testing.T) {
+	g := NewWithT(t)
+
+	config := newKubeadmConfig(newWorkerMachine(newCluster("cluster")), "worker-join-cfg")
+	g.Expect(tokenTTL(config)).To(Equal(DefaultTokenTTL))
+	g.Expect(tokenUsages(config)).To(ConsistOf("signing", "authentication"))
+
+	config.Spec.BootstrapTokenTTL = &metav1.Duration{Duration: time.Hour}
+	config.Spec.BootstrapTokenUsages = []string{"authentication"}
+
+	myclient := helpers.NewFakeClientWithScheme(setupScheme())
+	token, err := createToken(ctx, myclient, tokenTTL(config), tokenUsages(config))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	secret, err := getToken(ctx, myclient, token)
+	g.Expect(err).NotTo(HaveOccurred())
+	expiration, err := time.Parse(time.RFC3339, string(secret.Data[bootstrapapi.BootstrapTokenExpirationKey]))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(expiration).To(BeTemporally("~", time.Now().Add(time.Hour), 5*time.Second))
+	g.Expect(secret.Data).To(HaveKeyWithValue(bootstrapapi.BootstrapTokenUsageAuthentication, []byte("true")))
+	g.Expect(secret.Data).NotTo(HaveKey(bootstrapapi.BootstrapTokenUsageSigningKey))
+
+	// The token is only rotated once past half of the configured TTL.
+	rotate, err := shouldRotate(ctx, myclient, token, tokenTTL(config))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(rotate).To(BeFalse())
+	rotate, err = shouldRotate(ctx, myclient, token, 4*time.Hour)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(rotate).To(BeTrue())
+}
+
 func TestBootstrapTokenRotationMachinePool(t *testing.T) {
 	_ = feature.MutableGates.Set("MachinePool=true")
 	g := NewWithT(t)
@@ -1558,7 +1592,8 @@ func TestKubeadmConfigReconciler_ResolveFiles(t *testing.T) {
 			Name: "source",
 		},
 		Data: map[string][]byte{
-			"key": []byte("foo"),
+			"key":       []byte("foo"),
+			"other-key": []byte("bar\n"),
 		},
 	}
 
@@ -1594,12 +1629,12 @@ func TestKubeadmConfigReconciler_ResolveFiles(t *testing.T) {
 				Spec: bootstrapv1.KubeadmConfigSpec{
 					Files: []bootstrapv1.File{
 						{
-							ContentFrom: &bootstrapv1.FileSource{
+							ContentFrom: []bootstrapv1.FileSource{{
 								Secret: bootstrapv1.SecretFileSource{
 									Name: "source",
 									Key:  "key",
 								},
-							},
+							}},
 							Path:        "/path",
 							Owner:       "root:root",
 							Permissions: "0600",
@@ -1628,12 +1663,12 @@ func TestKubeadmConfigReconciler_ResolveFiles(t *testing.T) {
 							Permissions: "0600",
 						},
 						{
-							ContentFrom: &bootstrapv1.FileSource{
+							ContentFrom: []bootstrapv1.FileSource{{
 								Secret: bootstrapv1.SecretFileSource{
 									Name: "source",
 									Key:  "key",
 								},
-							},
+							}},
 							Path:        "/path",
 							Owner:       "root:root",
 							Permissions: "0600",
@@ -1657,8 +1692,106 @@ func TestKubeadmConfigReconciler_ResolveFiles(t *testing.T) {
 			},
 			objects: []client.Object{testSecret},
 		},
+		"multiple sources should be concatenated in order": {
+			cfg: &bootstrapv1.KubeadmConfig{
+				Spec: bootstrapv1.KubeadmConfigSpec{
+					Files: []bootstrapv1.File{
+						{
+							ContentFrom: []bootstrapv1.FileSource{
+								{
+									Secret: bootstrapv1.SecretFileSource{
+										Name: "source",
+										Key:  "other-key",
+									},
+								},
+								{
+									Secret: bootstrapv1.SecretFileSource{
+										Name: "source",
+										Key:  "key",
+									},
+								},
+							},
+							Path:        "/path",
+							Owner:       "root:root",
+							Permissions: "0600",
+						},
+					},
+				},
+			},
+			expect: []bootstrapv1.File{
+				{
+					Content:     "bar\nfoo",
+					Path:        "/path",
+					Owner:       "root:root",
+					Permissions: "0600",
+				},
+			},
+			objects: []client.Object{testSecret},
+		},
+		"templated content should be rendered": {
+			cfg: &bootstrapv1.KubeadmConfig{
+				Spec: bootstrapv1.KubeadmConfigSpec{
+					Files: []bootstrapv1.File{
+						{
+							Content:     "server: https://{{ .ControlPlaneEndpoint }}",
+							Path:        "/path",
+							Owner:       "root:root",
+							Permissions: "0600",
+							Template:    true,
+						},
+					},
+				},
+			},
+			expect: []bootstrapv1.File{
+				{
+					Content:     "server: https://10.0.0.1:6443",
+					Path:        "/path",
+					Owner:       "root:root",
+					Permissions: "0600",
+				},
+			},
+		},
+		"templated contentFrom should be rendered": {
+			cfg: &bootstrapv1.KubeadmConfig{
+				Spec: bootstrapv1.KubeadmConfigSpec{
+					Files: []bootstrapv1.File{
+						{
+							ContentFrom: []bootstrapv1.FileSource{{
+								Secret: bootstrapv1.SecretFileSource{
+									Name: "template",
+									Key:  "key",
+								},
+							}},
+							Path:        "/path",
+							Owner:       "root:root",
+							Permissions: "0600",
+							Template:    true,
+						},
+					},
+				},
+			},
+			expect: []bootstrapv1.File{
+				{
+					Content:     "server: https://10.0.0.1:6443",
+					Path:        "/path",
+					Owner:       "root:root",
+					Permissions: "0600",
+				},
+			},
+			objects: []client.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "template",
+				},
+				Data: map[string][]byte{
+					"key": []byte("server: https://{{ .ControlPlaneEndpoint }}"),
+				},
+			}},
+		},
 	}
 
+	cluster := newCluster("cluster")
+	cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "10.0.0.1", Port: 6443}
+
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			g := NewWithT(t)
@@ -1675,17 +1808,17 @@ func TestKubeadmConfigReconciler_ResolveFiles(t *testing.T) {
 			// from secrets still are.
 			contentFrom := map[string]bool{}
 			for _, file := range tc.cfg.Spec.Files {
-				if file.ContentFrom != nil {
+				if len(file.ContentFrom) > 0 {
 					contentFrom[file.Path] = true
 				}
 			}
 
-			files, err := k.resolveFiles(ctx, tc.cfg)
+			files, err := k.resolveFiles(ctx, tc.cfg, cluster)
 			g.Expect(err).NotTo(HaveOccurred())
 			g.Expect(files).To(Equal(tc.expect))
 			for _, file := range tc.cfg.Spec.Files {
 				if contentFrom[file.Path] {
-					g.Expect(file.ContentFrom).NotTo(BeNil())
+					g.Expect(file.ContentFrom).NotTo(BeEmpty())
 					g.Expect(file.Content).To(Equal(""))
 				}
 			}
@@ -1693,6 +1826,1180 @@ func TestKubeadmConfigReconciler_ResolveFiles(t *testing.T) {
 	}
 }
 
+// The kubelet args injected from the settings of the KubeadmConfig are rendered in the bootstrap data, but not saved
+// in its spec, which the KubeadmControlPlane compares with its own.
+func TestKubeadmConfigReconciler_Reconcile_DoesNotSaveInjectedKubeletArgs(t *testing.T) {
+	cluster := newCluster("cluster")
+	cluster.Status.InfrastructureReady = true
+	cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "100.105.150.1", Port: 6443}
+
+	cases := map[string]struct {
+		controlPlaneInitialized bool
+		machine                 *clusterv1.Machine
+		configBuilder           func(*clusterv1.Machine, string) *bootstrapv1.KubeadmConfig
+		nodeRegistration        func(*bootstrapv1.KubeadmConfig) *bootstrapv1.NodeRegistrationOptions
+	}{
+		"init control plane": {
+			controlPlaneInitialized: false,
+			machine:                 newControlPlaneMachine(cluster, "control-plane-init-machine"),
+			configBuilder:           newControlPlaneInitKubeadmConfig,
+			nodeRegistration: func(c *bootstrapv1.KubeadmConfig) *bootstrapv1.NodeRegistrationOptions {
+				return &c.Spec.InitConfiguration.NodeRegistration
+			},
+		},
+		"join worker": {
+			controlPlaneInitialized: true,
+			machine:                 newWorkerMachine(cluster),
+			configBuilder: func(machine *clusterv1.Machine, name string) *bootstrapv1.KubeadmConfig {
+				c := newKubeadmConfig(machine, name)
+				c.Spec.JoinConfiguration = &bootstrapv1.JoinConfiguration{}
+				return c
+			},
+			nodeRegistration: func(c *bootstrapv1.KubeadmConfig) *bootstrapv1.NodeRegistrationOptions {
+				return &c.Spec.JoinConfiguration.NodeRegistration
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := cluster.DeepCopy()
+			if tc.controlPlaneInitialized {
+				conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+			}
+			config := tc.configBuilder(tc.machine, "cfg")
+			config.Spec.GracefulShutdown = &bootstrapv1.GracefulShutdownConfig{Timeout: metav1.Duration{Duration: 90 * time.Second}}
+			config.Spec.SandboxImage = pointer.StringPtr("registry.example.com/pause:3.5")
+			config.Spec.RotateKubeletServerCertificate = pointer.BoolPtr(true)
+			config.Spec.CloudProvider = &bootstrapv1.CloudProviderConfig{Name: "external"}
+			config.Spec.SeccompDefault = pointer.BoolPtr(true)
+			config.Spec.ReservedResources = &bootstrapv1.ReservedResourcesConfig{KubeReserved: map[string]string{"cpu": "100m"}}
+			config.Spec.MaxPods = pointer.Int32Ptr(50)
+			config.Spec.ImageGC = &bootstrapv1.ImageGCConfig{HighThresholdPercent: 80, LowThresholdPercent: 60}
+			config.Spec.StartupTaint = &corev1.Taint{Key: "example.com/startup", Effect: corev1.TaintEffectNoSchedule}
+			config.Spec.KubeletRootDir = pointer.StringPtr("/data/kubelet")
+			config.Spec.CgroupV2 = pointer.BoolPtr(true)
+			config.Spec.KubeletServingCertSANs = []string{"node.example.com"}
+			tc.nodeRegistration(config).KubeletExtraArgs = map[string]string{"foo": "bar"}
+
+			objects := []client.Object{cluster, tc.machine, config}
+			objects = append(objects, createSecrets(t, cluster, config)...)
+			myclient := helpers.NewFakeClientWithScheme(setupScheme(), objects...)
+			k := &KubeadmConfigReconciler{
+				Client:             myclient,
+				KubeadmInitLock:    &myInitLocker{},
+				remoteClientGetter: fakeremote.NewClusterClient,
+			}
+
+			_, err := k.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(config)})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			cfg, err := getKubeadmConfig(myclient, config.Name)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cfg.Status.DataSecretName).NotTo(BeNil())
+			g.Expect(tc.nodeRegistration(cfg).KubeletExtraArgs).To(Equal(map[string]string{"foo": "bar"}))
+
+			dataSecret := &corev1.Secret{}
+			g.Expect(myclient.Get(ctx, client.ObjectKey{Namespace: cfg.Namespace, Name: *cfg.Status.DataSecretName}, dataSecret)).To(Succeed())
+			g.Expect(string(dataSecret.Data["value"])).To(ContainSubstring("shutdown-grace-period: 1m30s"))
+			g.Expect(string(dataSecret.Data["value"])).To(ContainSubstring("pod-infra-container-image: registry.example.com/pause:3.5"))
+			g.Expect(string(dataSecret.Data["value"])).To(ContainSubstring(`rotate-server-certificates: "true"`))
+			g.Expect(string(dataSecret.Data["value"])).To(ContainSubstring("cloud-provider: external"))
+			g.Expect(string(dataSecret.Data["value"])).To(ContainSubstring(`seccomp-default: "true"`))
+			g.Expect(string(dataSecret.Data["value"])).To(ContainSubstring("kube-reserved: cpu=100m"))
+			g.Expect(string(dataSecret.Data["value"])).To(ContainSubstring(`max-pods: "50"`))
+			g.Expect(string(dataSecret.Data["value"])).To(ContainSubstring(`image-gc-high-threshold: "80"`))
+			g.Expect(string(dataSecret.Data["value"])).To(ContainSubstring("register-with-taints: example.com/startup:NoSchedule"))
+			g.Expect(string(dataSecret.Data["value"])).To(ContainSubstring("root-dir: /data/kubelet"))
+			g.Expect(string(dataSecret.Data["value"])).To(ContainSubstring("cgroup-driver: systemd"))
+			g.Expect(string(dataSecret.Data["value"])).To(ContainSubstring("tls-cert-file: /etc/kubernetes/pki/kubelet-serving.crt"))
+		})
+	}
+}
+
+// The API server, controller manager and etcd settings injected from the settings of the KubeadmConfig are rendered
+// in the bootstrap data, but not saved in its spec, which the KubeadmControlPlane compares with its own.
+func TestKubeadmConfigReconciler_Reconcile_DoesNotSaveInjectedClusterConfiguration(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("cluster")
+	cluster.Status.InfrastructureReady = true
+	cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "100.105.150.1", Port: 6443}
+	machine := newControlPlaneMachine(cluster, "control-plane-init-machine")
+	config := newControlPlaneInitKubeadmConfig(machine, "cfg")
+	config.Spec.AuditPolicy = &runtime.RawExtension{
+		Raw: []byte(`{"apiVersion":"audit.k8s.io/v1","kind":"Policy","rules":[{"level":"Metadata"}]}`),
+	}
+	config.Spec.EtcdDataDir = pointer.StringPtr("/var/lib/etcddisk/etcd")
+	config.Spec.CloudProvider = &bootstrapv1.CloudProviderConfig{Name: "external"}
+	config.Spec.ClusterConfiguration.APIServer.ExtraArgs = map[string]string{"foo": "bar"}
+
+	objects := []client.Object{cluster, machine, config}
+	objects = append(objects, createSecrets(t, cluster, config)...)
+	myclient := helpers.NewFakeClientWithScheme(setupScheme(), objects...)
+	k := &KubeadmConfigReconciler{
+		Client:             myclient,
+		KubeadmInitLock:    &myInitLocker{},
+		remoteClientGetter: fakeremote.NewClusterClient,
+	}
+
+	_, err := k.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(config)})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cfg, err := getKubeadmConfig(myclient, config.Name)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.Status.DataSecretName).NotTo(BeNil())
+	g.Expect(cfg.Spec.ClusterConfiguration.APIServer.ExtraArgs).To(Equal(map[string]string{"foo": "bar"}))
+	g.Expect(cfg.Spec.ClusterConfiguration.APIServer.ExtraVolumes).To(BeEmpty())
+	g.Expect(cfg.Spec.ClusterConfiguration.ControllerManager.ExtraArgs).To(BeEmpty())
+	g.Expect(cfg.Spec.ClusterConfiguration.Etcd.Local).To(BeNil())
+
+	dataSecret := &corev1.Secret{}
+	g.Expect(myclient.Get(ctx, client.ObjectKey{Namespace: cfg.Namespace, Name: *cfg.Status.DataSecretName}, dataSecret)).To(Succeed())
+	g.Expect(string(dataSecret.Data["value"])).To(ContainSubstring("audit-policy-file: /etc/kubernetes/audit-policy.yaml"))
+	g.Expect(string(dataSecret.Data["value"])).To(ContainSubstring("dataDir: /var/lib/etcddisk/etcd"))
+	g.Expect(string(dataSecret.Data["value"])).To(ContainSubstring("cloud-provider: external"))
+}
+
+func TestKubeadmConfigReconciler_Reconcile_RequeueTemplatedFilesIfControlPlaneEndpointIsMissing(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newCluster("cluster")
+	cluster.Status.InfrastructureReady = true
+
+	controlPlaneInitMachine := newControlPlaneMachine(cluster, "control-plane-init-machine")
+	controlPlaneInitConfig := newControlPlaneInitKubeadmConfig(controlPlaneInitMachine, "control-plane-init-cfg")
+	controlPlaneInitConfig.Spec.Files = []bootstrapv1.File{
+		{
+			Path:     "/etc/kubernetes/endpoint",
+			Content:  "server: https://{{ .ControlPlaneEndpoint }}",
+			Template: true,
+		},
+	}
+
+	objects := []client.Object{
+		cluster,
+		controlPlaneInitMachine,
+		controlPlaneInitConfig,
+	}
+	myclient := helpers.NewFakeClientWithScheme(setupScheme(), objects...)
+
+	k := &KubeadmConfigReconciler{
+		Client:          myclient,
+		KubeadmInitLock: &myInitLocker{},
+	}
+
+	request := ctrl.Request{
+		NamespacedName: client.ObjectKey{
+			Namespace: "default",
+			Name:      "control-plane-init-cfg",
+		},
+	}
+	result, err := k.Reconcile(ctx, request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(10 * time.Second))
+
+	cfg, err := getKubeadmConfig(myclient, "control-plane-init-cfg")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.Status.Ready).To(BeFalse())
+	g.Expect(cfg.Status.DataSecretName).To(BeNil())
+}
+
+func TestKubeadmConfigReconciler_ReconcileGracefulShutdown(t *testing.T) {
+	cases := map[string]struct {
+		gracefulShutdown *bootstrapv1.GracefulShutdownConfig
+		kubeletExtraArgs map[string]string
+		expect           map[string]string
+	}{
+		"kubelet args should not be set without graceful shutdown": {
+			gracefulShutdown: nil,
+			kubeletExtraArgs: nil,
+			expect:           nil,
+		},
+		"kubelet args should be set from the graceful shutdown timeout": {
+			gracefulShutdown: &bootstrapv1.GracefulShutdownConfig{Timeout: metav1.Duration{Duration: 90 * time.Second}},
+			kubeletExtraArgs: map[string]string{"foo": "bar"},
+			expect:           map[string]string{"foo": "bar", "shutdown-grace-period": "1m30s"},
+		},
+		"user provided kubelet args should be respected": {
+			gracefulShutdown: &bootstrapv1.GracefulShutdownConfig{Timeout: metav1.Duration{Duration: 90 * time.Second}},
+			kubeletExtraArgs: map[string]string{"shutdown-grace-period": "5m"},
+			expect:           map[string]string{"shutdown-grace-period": "5m"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			config := &bootstrapv1.KubeadmConfig{
+				Spec: bootstrapv1.KubeadmConfigSpec{
+					GracefulShutdown: tc.gracefulShutdown,
+					JoinConfiguration: &bootstrapv1.JoinConfiguration{
+						NodeRegistration: bootstrapv1.NodeRegistrationOptions{
+							KubeletExtraArgs: tc.kubeletExtraArgs,
+						},
+					},
+				},
+			}
+
+			reconcileGracefulShutdown(config, &config.Spec.JoinConfiguration.NodeRegistration)
+			g.Expect(config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs).To(Equal(tc.expect))
+		})
+	}
+}
+
+func TestKubeadmConfigReconciler_ReconcileSandboxImage(t *testing.T) {
+	cases := map[string]struct {
+		sandboxImage     *string
+		kubeletExtraArgs map[string]string
+		expect           map[string]string
+	}{
+		"kubelet args should not be set without sandbox image": {
+			sandboxImage:     nil,
+			kubeletExtraArgs: nil,
+			expect:           nil,
+		},
+		"kubelet args should be set from the sandbox image": {
+			sandboxImage:     pointer.StringPtr("registry.example.com/pause:3.5"),
+			kubeletExtraArgs: map[string]string{"foo": "bar"},
+			expect:           map[string]string{"foo": "bar", "pod-infra-container-image": "registry.example.com/pause:3.5"},
+		},
+		"user provided kubelet args should be respected": {
+			sandboxImage:     pointer.StringPtr("registry.example.com/pause:3.5"),
+			kubeletExtraArgs: map[string]string{"pod-infra-container-image": "k8s.gcr.io/pause:3.5"},
+			expect:           map[string]string{"pod-infra-container-image": "k8s.gcr.io/pause:3.5"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			config := &bootstrapv1.KubeadmConfig{
+				Spec: bootstrapv1.KubeadmConfigSpec{
+					SandboxImage: tc.sandboxImage,
+					JoinConfiguration: &bootstrapv1.JoinConfiguration{
+						NodeRegistration: bootstrapv1.NodeRegistrationOptions{
+							KubeletExtraArgs: tc.kubeletExtraArgs,
+						},
+					},
+				},
+			}
+
+			reconcileSandboxImage(config, &config.Spec.JoinConfiguration.NodeRegistration)
+			g.Expect(config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs).To(Equal(tc.expect))
+		})
+	}
+}
+
+func TestKubeadmConfigReconciler_ReconcileKubeletServerCertificateRotation(t *testing.T) {
+	cases := map[string]struct {
+		rotate           *bool
+		kubeletExtraArgs map[string]string
+		expect           map[string]string
+	}{
+		"kubelet args should not be set without server certificate rotation": {
+			rotate:           nil,
+			kubeletExtraArgs: nil,
+			expect:           nil,
+		},
+		"kubelet args should not be set when server certificate rotation is disabled": {
+			rotate:           pointer.BoolPtr(false),
+			kubeletExtraArgs: map[string]string{"foo": "bar"},
+			expect:           map[string]string{"foo": "bar"},
+		},
+		"kubelet args should enable server certificate rotation": {
+			rotate:           pointer.BoolPtr(true),
+			kubeletExtraArgs: map[string]string{"foo": "bar"},
+			expect:           map[string]string{"foo": "bar", "rotate-server-certificates": "true"},
+		},
+		"user provided kubelet args should be respected": {
+			rotate:           pointer.BoolPtr(true),
+			kubeletExtraArgs: map[string]string{"rotate-server-certificates": "false"},
+			expect:           map[string]string{"rotate-server-certificates": "false"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			config := &bootstrapv1.KubeadmConfig{
+				Spec: bootstrapv1.KubeadmConfigSpec{
+					RotateKubeletServerCertificate: tc.rotate,
+					JoinConfiguration: &bootstrapv1.JoinConfiguration{
+						NodeRegistration: bootstrapv1.NodeRegistrationOptions{
+							KubeletExtraArgs: tc.kubeletExtraArgs,
+						},
+					},
+				},
+			}
+
+			reconcileKubeletServerCertificateRotation(config, &config.Spec.JoinConfiguration.NodeRegistration)
+			g.Expect(config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs).To(Equal(tc.expect))
+		})
+	}
+}
+
+func TestKubeadmConfigReconciler_ReconcileEtcdDataDir(t *testing.T) {
+	g := NewWithT(t)
+
+	config := newKubeadmConfig(nil, "cfg")
+	config.Spec.DiskSetup = &bootstrapv1.DiskSetup{
+		Partitions:  []bootstrapv1.Partition{{Device: "/dev/sdb", Layout: true}},
+		Filesystems: []bootstrapv1.Filesystem{{Device: "/dev/sdb", Filesystem: "ext4", Label: "etcd_disk"}},
+	}
+	config.Spec.Mounts = []bootstrapv1.MountPoints{{"LABEL=etcd_disk", "/var/lib/etcddisk"}}
+	config.Spec.EtcdDataDir = pointer.StringPtr("/var/lib/etcddisk/etcd")
+	config.Spec.ClusterConfiguration = &bootstrapv1.ClusterConfiguration{}
+	g.Expect(config.ValidateCreate()).To(Succeed())
+
+	reconcileEtcdDataDir(config, config.Spec.ClusterConfiguration)
+
+	// The data dir of the local etcd is on the filesystem mounted from the dedicated disk.
+	g.Expect(config.Spec.ClusterConfiguration.Etcd.Local).NotTo(BeNil())
+	g.Expect(config.Spec.ClusterConfiguration.Etcd.Local.DataDir).To(Equal("/var/lib/etcddisk/etcd"))
+	g.Expect(config.Spec.ClusterConfiguration.Etcd.Local.DataDir).To(HavePrefix(config.Spec.Mounts[0][1] + "/"))
+	g.Expect(config.Spec.Mounts[0][0]).To(Equal("LABEL=" + config.Spec.DiskSetup.Filesystems[0].Label))
+
+	// An external etcd is left untouched.
+	config.Spec.ClusterConfiguration = &bootstrapv1.ClusterConfiguration{
+		Etcd: bootstrapv1.Etcd{External: &bootstrapv1.ExternalEtcd{Endpoints: []string{"https://etcd:2379"}}},
+	}
+	reconcileEtcdDataDir(config, config.Spec.ClusterConfiguration)
+	g.Expect(config.Spec.ClusterConfiguration.Etcd.Local).To(BeNil())
+}
+
+func TestKubeadmConfigReconciler_ReconcileKubeletRootDir(t *testing.T) {
+	g := NewWithT(t)
+
+	config := newKubeadmConfig(nil, "cfg")
+	config.Spec.DiskSetup = &bootstrapv1.DiskSetup{
+		Partitions:  []bootstrapv1.Partition{{Device: "/dev/sdb", Layout: true}},
+		Filesystems: []bootstrapv1.Filesystem{{Device: "/dev/sdb", Filesystem: "ext4", Label: "kubelet_disk"}},
+	}
+	config.Spec.Mounts = []bootstrapv1.MountPoints{{"LABEL=kubelet_disk", "/var/lib/kubelet-disk"}}
+	config.Spec.KubeletRootDir = pointer.StringPtr("/var/lib/kubelet-disk")
+	config.Spec.JoinConfiguration = &bootstrapv1.JoinConfiguration{}
+	g.Expect(config.ValidateCreate()).To(Succeed())
+
+	reconcileKubeletRootDir(config, &config.Spec.JoinConfiguration.NodeRegistration)
+
+	// The kubelet places its files on the filesystem mounted from the dedicated disk.
+	g.Expect(config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs).To(HaveKeyWithValue(kubeletRootDirArg, config.Spec.Mounts[0][1]))
+	g.Expect(config.Spec.Mounts[0][0]).To(Equal("LABEL=" + config.Spec.DiskSetup.Filesystems[0].Label))
+
+	// The default root dir is not passed to the kubelet.
+	config.Spec.Mounts = []bootstrapv1.MountPoints{{"LABEL=kubelet_disk", defaultKubeletRootDir}}
+	config.Spec.KubeletRootDir = pointer.StringPtr(defaultKubeletRootDir)
+	config.Spec.JoinConfiguration = &bootstrapv1.JoinConfiguration{}
+	g.Expect(config.ValidateCreate()).To(Succeed())
+
+	reconcileKubeletRootDir(config, &config.Spec.JoinConfiguration.NodeRegistration)
+	g.Expect(config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs).NotTo(HaveKey(kubeletRootDirArg))
+}
+
+func TestKubeadmConfigReconciler_ReconcileCgroups(t *testing.T) {
+	cases := map[string]struct {
+		cgroupV2          *bool
+		failSwapOn        *bool
+		kubeletExtraArgs  map[string]string
+		kubernetesVersion string
+		expectArgs        map[string]string
+		expectKernelArgs  string
+	}{
+		"kubelet and kernel args should not be set by default": {
+			kubernetesVersion: "v1.28.0",
+			expectArgs:        nil,
+		},
+		"cgroup v2 should set the systemd cgroup driver": {
+			cgroupV2:          pointer.BoolPtr(true),
+			kubernetesVersion: "v1.28.0",
+			expectArgs:        map[string]string{"cgroup-driver": "systemd"},
+			expectKernelArgs:  "systemd.unified_cgroup_hierarchy=1",
+		},
+		"swap should be accounted, and enable the NodeSwap feature gate before v1.30": {
+			cgroupV2:          pointer.BoolPtr(true),
+			failSwapOn:        pointer.BoolPtr(false),
+			kubernetesVersion: "v1.28.0",
+			expectArgs:        map[string]string{"cgroup-driver": "systemd", "fail-swap-on": "false", "feature-gates": "NodeSwap=true"},
+			expectKernelArgs:  "systemd.unified_cgroup_hierarchy=1 swapaccount=1",
+		},
+		"user provided kubelet args should be respected": {
+			cgroupV2:          pointer.BoolPtr(true),
+			failSwapOn:        pointer.BoolPtr(false),
+			kubeletExtraArgs:  map[string]string{"cgroup-driver": "systemd", "fail-swap-on": "false", "feature-gates": "NodeSwap=true"},
+			kubernetesVersion: "v1.28.0",
+			expectArgs:        map[string]string{"cgroup-driver": "systemd", "fail-swap-on": "false", "feature-gates": "NodeSwap=true"},
+			expectKernelArgs:  "systemd.unified_cgroup_hierarchy=1 swapaccount=1",
+		},
+		"swap should not enable the NodeSwap feature gate from v1.30": {
+			cgroupV2:          pointer.BoolPtr(true),
+			failSwapOn:        pointer.BoolPtr(false),
+			kubernetesVersion: "v1.30.0",
+			expectArgs:        map[string]string{"cgroup-driver": "systemd", "fail-swap-on": "false"},
+			expectKernelArgs:  "systemd.unified_cgroup_hierarchy=1 swapaccount=1",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			config := newKubeadmConfig(nil, "cfg")
+			config.Spec.CgroupV2 = tc.cgroupV2
+			config.Spec.FailSwapOn = tc.failSwapOn
+			config.Spec.JoinConfiguration = &bootstrapv1.JoinConfiguration{
+				NodeRegistration: bootstrapv1.NodeRegistrationOptions{KubeletExtraArgs: tc.kubeletExtraArgs},
+			}
+			g.Expect(config.ValidateCreate()).To(Succeed())
+
+			reconcileCgroups(config, &config.Spec.JoinConfiguration.NodeRegistration, tc.kubernetesVersion)
+			g.Expect(config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs).To(Equal(tc.expectArgs))
+
+			// The kernel args rendered in the bootstrap data agree with the kubelet args.
+			out, err := cloudinit.NewNode(&cloudinit.NodeInput{
+				BaseUserData: cloudinit.BaseUserData{
+					CgroupV2:       cgroupV2(config),
+					SwapAccounting: swapEnabled(config),
+				},
+				JoinConfiguration: "my-join-config",
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			if tc.expectKernelArgs == "" {
+				g.Expect(string(out)).NotTo(ContainSubstring("set-cgroup-kernel-args.sh"))
+				return
+			}
+			g.Expect(string(out)).To(ContainSubstring(fmt.Sprintf(`"/usr/local/bin/set-cgroup-kernel-args.sh %s"`, tc.expectKernelArgs)))
+			g.Expect(string(out)).To(ContainSubstring("SystemdCgroup = true"))
+		})
+	}
+}
+
+func TestKubeadmConfigReconciler_ReconcileKubeletServingCert(t *testing.T) {
+	g := NewWithT(t)
+
+	config := newKubeadmConfig(nil, "cfg")
+	config.Spec.KubeletServingCertSANs = []string{"node.example.com", "10.0.0.10"}
+	config.Spec.JoinConfiguration = &bootstrapv1.JoinConfiguration{}
+	g.Expect(config.ValidateCreate()).To(Succeed())
+
+	reconcileKubeletServingCert(config, &config.Spec.JoinConfiguration.NodeRegistration)
+	g.Expect(config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs).To(Equal(map[string]string{
+		"tls-cert-file":        "/etc/kubernetes/pki/kubelet-serving.crt",
+		"tls-private-key-file": "/etc/kubernetes/pki/kubelet-serving.key",
+	}))
+
+	// The certificate served by the kubelet is generated in the bootstrap data for the SANs.
+	out, err := cloudinit.NewNode(&cloudinit.NodeInput{
+		BaseUserData: cloudinit.BaseUserData{
+			KubeletServingCertSANs: config.Spec.KubeletServingCertSANs,
+		},
+		JoinConfiguration: "my-join-config",
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring("DNS:node.example.com,IP:10.0.0.10"))
+	g.Expect(string(out)).To(ContainSubstring(fmt.Sprintf("-keyout %s -out %s",
+		config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs["tls-private-key-file"],
+		config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs["tls-cert-file"])))
+}
+
+func TestKubeadmConfigReconciler_ReconcileSensitiveFields(t *testing.T) {
+	cases := map[string]struct {
+		spec            bootstrapv1.KubeadmConfigSpec
+		expectCondition *clusterv1.Condition
+	}{
+		"config with only public files should not be reported": {
+			spec: bootstrapv1.KubeadmConfigSpec{
+				Files: []bootstrapv1.File{
+					{Path: "/etc/motd", Content: "hello"},
+					{
+						Path: "/root/.docker/config.json",
+						ContentFrom: []bootstrapv1.FileSource{{
+							Secret: bootstrapv1.SecretFileSource{Name: "registry", Key: "config.json"},
+						}},
+					},
+				},
+				Users: []bootstrapv1.User{{Name: "admin"}},
+			},
+			expectCondition: nil,
+		},
+		"config with a user password should be reported": {
+			spec: bootstrapv1.KubeadmConfigSpec{
+				Files: []bootstrapv1.File{
+					{Path: "/root/.docker/config.json", Content: "{}"},
+				},
+				Users: []bootstrapv1.User{{Name: "admin", Passwd: pointer.StringPtr("$6$rounds=4096$salt$hash")}},
+			},
+			expectCondition: &clusterv1.Condition{
+				Type:     bootstrapv1.SensitiveFieldsAcknowledgedCondition,
+				Status:   corev1.ConditionFalse,
+				Severity: clusterv1.ConditionSeverityWarning,
+				Reason:   bootstrapv1.UnacknowledgedSensitiveFieldsReason,
+				Message:  "Sensitive fields are set inline and readable by anyone allowed to read this config: users[admin].passwd, files[/root/.docker/config.json]",
+			},
+		},
+		"config with acknowledged sensitive fields should not be reported": {
+			spec: bootstrapv1.KubeadmConfigSpec{
+				Users:           []bootstrapv1.User{{Name: "admin", Passwd: pointer.StringPtr("$6$rounds=4096$salt$hash")}},
+				SensitiveFields: []string{"users[admin].passwd"},
+			},
+			expectCondition: &clusterv1.Condition{
+				Type:   bootstrapv1.SensitiveFieldsAcknowledgedCondition,
+				Status: corev1.ConditionTrue,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			config := &bootstrapv1.KubeadmConfig{Spec: tc.spec}
+			reconcileSensitiveFields(ctx, config)
+
+			condition := conditions.Get(config, bootstrapv1.SensitiveFieldsAcknowledgedCondition)
+			if tc.expectCondition == nil {
+				g.Expect(condition).To(BeNil())
+				return
+			}
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(tc.expectCondition.Status))
+			g.Expect(condition.Severity).To(Equal(tc.expectCondition.Severity))
+			g.Expect(condition.Reason).To(Equal(tc.expectCondition.Reason))
+			g.Expect(condition.Message).To(Equal(tc.expectCondition.Message))
+		})
+	}
+}
+
+func TestKubeadmConfigReconciler_UnknownPreflightErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	config := newKubeadmConfig(nil, "cfg")
+	g.Expect(unknownPreflightErrors(config)).To(BeEmpty())
+
+	config.Spec.IgnorePreflightErrors = []string{"Swap", "numcpu", "all", "Port-6443", "DirAvailable--var-lib-etcd", "FileExisting-crictl", "NoSuchCheck"}
+	g.Expect(unknownPreflightErrors(config)).To(Equal([]string{"NoSuchCheck"}))
+}
+
+func TestKubeadmConfigReconciler_ReconcileAuditPolicy(t *testing.T) {
+	g := NewWithT(t)
+
+	config := newKubeadmConfig(nil, "cfg")
+	config.Spec.ClusterConfiguration = &bootstrapv1.ClusterConfiguration{
+		APIServer: bootstrapv1.APIServer{
+			ControlPlaneComponent: bootstrapv1.ControlPlaneComponent{
+				ExtraArgs: map[string]string{"audit-log-maxsize": "50"},
+			},
+		},
+	}
+	config.Spec.InitConfiguration = &bootstrapv1.InitConfiguration{}
+	config.Spec.AuditPolicy = &runtime.RawExtension{
+		Raw: []byte(`{"apiVersion":"audit.k8s.io/v1","kind":"Policy","rules":[{"level":"Metadata"}]}`),
+	}
+	config.Spec.AuditLogConfig = &bootstrapv1.AuditLogConfig{
+		Path:    "/var/log/audit/kube-apiserver.log",
+		MaxAge:  pointer.Int32Ptr(30),
+		MaxSize: pointer.Int32Ptr(100),
+	}
+
+	reconcileAuditPolicy(config, config.Spec.ClusterConfiguration)
+	files, err := appendAuditPolicyFile(config, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(files).To(ConsistOf(bootstrapv1.File{
+		Path:        "/etc/kubernetes/audit-policy.yaml",
+		Owner:       "root:root",
+		Permissions: "0600",
+		Content: `apiVersion: audit.k8s.io/v1
+kind: Policy
+rules:
+- level: Metadata
+`,
+	}))
+
+	apiServer := config.Spec.ClusterConfiguration.APIServer
+	g.Expect(apiServer.ExtraArgs).To(Equal(map[string]string{
+		"audit-policy-file": "/etc/kubernetes/audit-policy.yaml",
+		"audit-log-path":    "/var/log/audit/kube-apiserver.log",
+		"audit-log-maxage":  "30",
+		// User provided args are respected.
+		"audit-log-maxsize": "50",
+	}))
+	g.Expect(apiServer.ExtraVolumes).To(ConsistOf(
+		bootstrapv1.HostPathMount{
+			Name:      "audit-policy",
+			HostPath:  "/etc/kubernetes/audit-policy.yaml",
+			MountPath: "/etc/kubernetes/audit-policy.yaml",
+			ReadOnly:  true,
+			PathType:  corev1.HostPathFile,
+		},
+		bootstrapv1.HostPathMount{
+			Name:      "audit-log",
+			HostPath:  "/var/log/audit",
+			MountPath: "/var/log/audit",
+			PathType:  corev1.HostPathDirectoryOrCreate,
+		},
+	))
+
+	// Reconciling again does not duplicate the volumes.
+	reconcileAuditPolicy(config, config.Spec.ClusterConfiguration)
+	g.Expect(config.Spec.ClusterConfiguration.APIServer.ExtraVolumes).To(HaveLen(2))
+}
+
+func TestKubeadmConfigReconciler_ReconcileKubeletSecurityDefaults(t *testing.T) {
+	cases := map[string]struct {
+		seccompDefault    *bool
+		userNamespaces    *bool
+		kubernetesVersion string
+		kubeletExtraArgs  map[string]string
+		expect            map[string]string
+	}{
+		"kubelet args should not be set without security defaults": {
+			kubernetesVersion: "v1.28.0",
+			expect:            nil,
+		},
+		"kubelet args should enable the seccomp default": {
+			seccompDefault:    pointer.BoolPtr(true),
+			kubernetesVersion: "v1.25.0",
+			expect:            map[string]string{"seccomp-default": "true"},
+		},
+		"kubelet args should enable the seccomp default feature gate before v1.25": {
+			seccompDefault:    pointer.BoolPtr(true),
+			kubernetesVersion: "v1.22.1",
+			kubeletExtraArgs:  map[string]string{"feature-gates": "GracefulNodeShutdown=true"},
+			expect:            map[string]string{"seccomp-default": "true", "feature-gates": "GracefulNodeShutdown=true,SeccompDefault=true"},
+		},
+		"kubelet args should enable user namespaces": {
+			userNamespaces:    pointer.BoolPtr(true),
+			kubernetesVersion: "v1.28.0",
+			expect:            map[string]string{"feature-gates": "UserNamespacesSupport=true"},
+		},
+		"kubelet args should enable user namespaces with the legacy feature gate before v1.28": {
+			userNamespaces:    pointer.BoolPtr(true),
+			kubernetesVersion: "v1.26.0",
+			expect:            map[string]string{"feature-gates": "UserNamespacesStatelessPodsSupport=true"},
+		},
+		"kubelet args should use the most recent feature gates without a version": {
+			seccompDefault: pointer.BoolPtr(true),
+			userNamespaces: pointer.BoolPtr(true),
+			expect:         map[string]string{"seccomp-default": "true", "feature-gates": "UserNamespacesSupport=true"},
+		},
+		"user provided kubelet args and feature gates should be respected": {
+			seccompDefault:    pointer.BoolPtr(true),
+			kubernetesVersion: "v1.23.0",
+			kubeletExtraArgs:  map[string]string{"seccomp-default": "false", "feature-gates": "SeccompDefault=false"},
+			expect:            map[string]string{"seccomp-default": "false", "feature-gates": "SeccompDefault=false"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			config := &bootstrapv1.KubeadmConfig{
+				Spec: bootstrapv1.KubeadmConfigSpec{
+					SeccompDefault: tc.seccompDefault,
+					UserNamespaces: tc.userNamespaces,
+					JoinConfiguration: &bootstrapv1.JoinConfiguration{
+						NodeRegistration: bootstrapv1.NodeRegistrationOptions{
+							KubeletExtraArgs: tc.kubeletExtraArgs,
+						},
+					},
+				},
+			}
+
+			reconcileKubeletSecurityDefaults(config, &config.Spec.JoinConfiguration.NodeRegistration, tc.kubernetesVersion)
+			g.Expect(config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs).To(Equal(tc.expect))
+		})
+	}
+}
+
+func TestKubeadmConfigReconciler_ReconcileReservedResources(t *testing.T) {
+	cases := map[string]struct {
+		reservedResources *bootstrapv1.ReservedResourcesConfig
+		kubeletExtraArgs  map[string]string
+		expect            map[string]string
+	}{
+		"kubelet args should not be set without reserved resources": {
+			expect: nil,
+		},
+		"kubelet args should not be set with empty reserved resources": {
+			reservedResources: &bootstrapv1.ReservedResourcesConfig{},
+			expect:            nil,
+		},
+		"kubelet args should reserve resources and set eviction thresholds": {
+			reservedResources: &bootstrapv1.ReservedResourcesConfig{
+				KubeReserved:   map[string]string{"memory": "256Mi", "cpu": "100m"},
+				SystemReserved: map[string]string{"ephemeral-storage": "1Gi"},
+				EvictionHard:   map[string]string{"nodefs.available": "10%", "memory.available": "100Mi"},
+			},
+			kubeletExtraArgs: map[string]string{"cloud-provider": "external"},
+			expect: map[string]string{
+				"cloud-provider":  "external",
+				"kube-reserved":   "cpu=100m,memory=256Mi",
+				"system-reserved": "ephemeral-storage=1Gi",
+				"eviction-hard":   "memory.available<100Mi,nodefs.available<10%",
+			},
+		},
+		"user provided kubelet args should be respected": {
+			reservedResources: &bootstrapv1.ReservedResourcesConfig{
+				KubeReserved: map[string]string{"cpu": "100m"},
+				EvictionHard: map[string]string{"memory.available": "100Mi"},
+			},
+			kubeletExtraArgs: map[string]string{"eviction-hard": "memory.available<500Mi"},
+			expect: map[string]string{
+				"kube-reserved": "cpu=100m",
+				"eviction-hard": "memory.available<500Mi",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			config := &bootstrapv1.KubeadmConfig{
+				Spec: bootstrapv1.KubeadmConfigSpec{
+					ReservedResources: tc.reservedResources,
+					JoinConfiguration: &bootstrapv1.JoinConfiguration{
+						NodeRegistration: bootstrapv1.NodeRegistrationOptions{
+							KubeletExtraArgs: tc.kubeletExtraArgs,
+						},
+					},
+				},
+			}
+
+			reconcileReservedResources(config, &config.Spec.JoinConfiguration.NodeRegistration)
+			g.Expect(config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs).To(Equal(tc.expect))
+		})
+	}
+}
+
+func TestKubeadmConfigReconciler_ReconcileMaxPods(t *testing.T) {
+	g := NewWithT(t)
+
+	config := newKubeadmConfig(nil, "cfg")
+	config.Spec.MaxPods = pointer.Int32Ptr(250)
+	config.Spec.JoinConfiguration = &bootstrapv1.JoinConfiguration{
+		NodeRegistration: bootstrapv1.NodeRegistrationOptions{
+			KubeletExtraArgs: map[string]string{"cloud-provider": "external"},
+		},
+	}
+	scope := &Scope{
+		Logger:  ctrl.Log,
+		Config:  config,
+		Cluster: &clusterv1.Cluster{},
+	}
+
+	reconcileMaxPods(scope, &config.Spec.JoinConfiguration.NodeRegistration)
+	g.Expect(config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs).To(Equal(map[string]string{
+		"cloud-provider": "external",
+		"max-pods":       "250",
+	}))
+
+	// A user provided kubelet arg is respected.
+	config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs["max-pods"] = "200"
+	reconcileMaxPods(scope, &config.Spec.JoinConfiguration.NodeRegistration)
+	g.Expect(config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs["max-pods"]).To(Equal("200"))
+}
+
+func TestKubeadmConfigReconciler_ReconcileImageGC(t *testing.T) {
+	cases := map[string]struct {
+		imageGC          *bootstrapv1.ImageGCConfig
+		kubeletExtraArgs map[string]string
+		expect           map[string]string
+	}{
+		"kubelet args should not be set without image gc thresholds": {
+			expect: nil,
+		},
+		"kubelet args should set the image gc thresholds": {
+			imageGC:          &bootstrapv1.ImageGCConfig{HighThresholdPercent: 70, LowThresholdPercent: 50},
+			kubeletExtraArgs: map[string]string{"cloud-provider": "external"},
+			expect: map[string]string{
+				"cloud-provider":          "external",
+				"image-gc-high-threshold": "70",
+				"image-gc-low-threshold":  "50",
+			},
+		},
+		"user provided kubelet args should be respected": {
+			imageGC:          &bootstrapv1.ImageGCConfig{HighThresholdPercent: 70, LowThresholdPercent: 50},
+			kubeletExtraArgs: map[string]string{"image-gc-low-threshold": "60"},
+			expect: map[string]string{
+				"image-gc-high-threshold": "70",
+				"image-gc-low-threshold":  "60",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			config := &bootstrapv1.KubeadmConfig{
+				Spec: bootstrapv1.KubeadmConfigSpec{
+					ImageGC: tc.imageGC,
+					JoinConfiguration: &bootstrapv1.JoinConfiguration{
+						NodeRegistration: bootstrapv1.NodeRegistrationOptions{
+							KubeletExtraArgs: tc.kubeletExtraArgs,
+						},
+					},
+				},
+			}
+
+			reconcileImageGC(config, &config.Spec.JoinConfiguration.NodeRegistration)
+			g.Expect(config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs).To(Equal(tc.expect))
+		})
+	}
+}
+
+func TestKubeadmConfigReconciler_ReconcileStartupTaint(t *testing.T) {
+	startupTaint := &corev1.Taint{Key: "example.com/bootstrapping", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+
+	cases := map[string]struct {
+		startupTaint     *corev1.Taint
+		taints           []corev1.Taint
+		kubeletExtraArgs map[string]string
+		expect           map[string]string
+	}{
+		"kubelet args should not be set without startup taint": {
+			expect: nil,
+		},
+		"kubelet args should register the node with the startup taint": {
+			startupTaint: startupTaint,
+			expect: map[string]string{
+				"register-with-taints": "example.com/bootstrapping=true:NoSchedule",
+			},
+		},
+		"kubelet args should keep the taints of the node registration": {
+			startupTaint: startupTaint,
+			taints:       []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}},
+			expect: map[string]string{
+				"register-with-taints": "dedicated=gpu:NoSchedule,example.com/bootstrapping=true:NoSchedule",
+			},
+		},
+		"user provided kubelet args should be respected": {
+			startupTaint:     startupTaint,
+			kubeletExtraArgs: map[string]string{"register-with-taints": "dedicated=gpu:NoExecute"},
+			expect: map[string]string{
+				"register-with-taints": "dedicated=gpu:NoExecute,example.com/bootstrapping=true:NoSchedule",
+			},
+		},
+		"startup taint should not be added twice": {
+			startupTaint:     startupTaint,
+			kubeletExtraArgs: map[string]string{"register-with-taints": "example.com/bootstrapping=true:NoSchedule"},
+			expect: map[string]string{
+				"register-with-taints": "example.com/bootstrapping=true:NoSchedule",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			config := &bootstrapv1.KubeadmConfig{
+				Spec: bootstrapv1.KubeadmConfigSpec{
+					StartupTaint: tc.startupTaint,
+					JoinConfiguration: &bootstrapv1.JoinConfiguration{
+						NodeRegistration: bootstrapv1.NodeRegistrationOptions{
+							Taints:           tc.taints,
+							KubeletExtraArgs: tc.kubeletExtraArgs,
+						},
+					},
+				},
+			}
+
+			reconcileStartupTaint(config, &config.Spec.JoinConfiguration.NodeRegistration)
+			g.Expect(config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs).To(Equal(tc.expect))
+
+			// Reconciling again does not change the args.
+			reconcileStartupTaint(config, &config.Spec.JoinConfiguration.NodeRegistration)
+			g.Expect(config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs).To(Equal(tc.expect))
+		})
+	}
+}
+
+func TestNodePodCIDRCapacity(t *testing.T) {
+	cases := map[string]struct {
+		podCIDRs         []string
+		controllerArgs   map[string]string
+		expectCapacity   int64
+		expectDerivation bool
+	}{
+		"no pod CIDRs": {
+			expectDerivation: false,
+		},
+		"default IPv4 node CIDR": {
+			podCIDRs:         []string{"192.168.0.0/16"},
+			expectCapacity:   256,
+			expectDerivation: true,
+		},
+		"custom IPv4 node CIDR": {
+			podCIDRs:         []string{"192.168.0.0/16"},
+			controllerArgs:   map[string]string{"node-cidr-mask-size": "25"},
+			expectCapacity:   128,
+			expectDerivation: true,
+		},
+		"dual-stack node CIDRs": {
+			podCIDRs:         []string{"10.244.0.0/16", "fd00:10:244::/56"},
+			controllerArgs:   map[string]string{"node-cidr-mask-size-ipv4": "23", "node-cidr-mask-size-ipv6": "120"},
+			expectCapacity:   256,
+			expectDerivation: true,
+		},
+		"default IPv6 node CIDR": {
+			podCIDRs:         []string{"fd00:10:244::/56"},
+			expectDerivation: false,
+		},
+		"invalid node CIDR mask size": {
+			podCIDRs:         []string{"192.168.0.0/16"},
+			controllerArgs:   map[string]string{"node-cidr-mask-size": "large"},
+			expectDerivation: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := &clusterv1.Cluster{
+				Spec: clusterv1.ClusterSpec{
+					ClusterNetwork: &clusterv1.ClusterNetwork{
+						Pods: &clusterv1.NetworkRanges{CIDRBlocks: tc.podCIDRs},
+					},
+				},
+			}
+			clusterConfiguration := &bootstrapv1.ClusterConfiguration{
+				ControllerManager: bootstrapv1.ControlPlaneComponent{ExtraArgs: tc.controllerArgs},
+			}
+
+			capacity, ok := nodePodCIDRCapacity(cluster, clusterConfiguration)
+			g.Expect(ok).To(Equal(tc.expectDerivation))
+			g.Expect(capacity).To(Equal(tc.expectCapacity))
+		})
+	}
+}
+
+func TestKubeadmConfigReconciler_ReconcileCloudProvider(t *testing.T) {
+	t.Run("external cloud provider without cloud config", func(t *testing.T) {
+		g := NewWithT(t)
+
+		config := newKubeadmConfig(nil, "cfg")
+		config.Spec.JoinConfiguration = &bootstrapv1.JoinConfiguration{}
+		config.Spec.CloudProvider = &bootstrapv1.CloudProviderConfig{Name: "external"}
+
+		k := &KubeadmConfigReconciler{
+			Client:          helpers.NewFakeClientWithScheme(setupScheme()),
+			KubeadmInitLock: &myInitLocker{},
+		}
+
+		reconcileKubeletCloudProvider(config, &config.Spec.JoinConfiguration.NodeRegistration)
+		g.Expect(config.Spec.JoinConfiguration.NodeRegistration.KubeletExtraArgs).To(Equal(map[string]string{
+			"cloud-provider": "external",
+		}))
+
+		files, err := k.appendCloudConfigFile(ctx, config, nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(files).To(BeEmpty())
+	})
+
+	t.Run("cloud provider with cloud config", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cloudConfig := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "cloud-config",
+			},
+			Data: map[string][]byte{
+				"cloud.conf": []byte("[Global]\nauth-url=https://keystone.example.com\n"),
+			},
+		}
+
+		config := newKubeadmConfig(nil, "cfg")
+		config.Spec.ClusterConfiguration = &bootstrapv1.ClusterConfiguration{
+			ControllerManager: bootstrapv1.ControlPlaneComponent{
+				ExtraArgs: map[string]string{"cloud-provider": "external"},
+			},
+		}
+		config.Spec.InitConfiguration = &bootstrapv1.InitConfiguration{}
+		config.Spec.CloudProvider = &bootstrapv1.CloudProviderConfig{
+			Name: "openstack",
+			ConfigFrom: &bootstrapv1.FileSource{
+				Secret: bootstrapv1.SecretFileSource{Name: "cloud-config", Key: "cloud.conf"},
+			},
+		}
+
+		k := &KubeadmConfigReconciler{
+			Client:          helpers.NewFakeClientWithScheme(setupScheme(), cloudConfig),
+			KubeadmInitLock: &myInitLocker{},
+		}
+
+		reconcileKubeletCloudProvider(config, &config.Spec.InitConfiguration.NodeRegistration)
+		g.Expect(config.Spec.InitConfiguration.NodeRegistration.KubeletExtraArgs).To(Equal(map[string]string{
+			"cloud-provider": "openstack",
+			"cloud-config":   "/etc/kubernetes/cloud.conf",
+		}))
+
+		reconcileCloudProvider(config, config.Spec.ClusterConfiguration)
+		volume := bootstrapv1.HostPathMount{
+			Name:      "cloud-config",
+			HostPath:  "/etc/kubernetes/cloud.conf",
+			MountPath: "/etc/kubernetes/cloud.conf",
+			ReadOnly:  true,
+			PathType:  corev1.HostPathFile,
+		}
+		apiServer := config.Spec.ClusterConfiguration.APIServer
+		g.Expect(apiServer.ExtraArgs).To(Equal(map[string]string{
+			"cloud-provider": "openstack",
+			"cloud-config":   "/etc/kubernetes/cloud.conf",
+		}))
+		g.Expect(apiServer.ExtraVolumes).To(ConsistOf(volume))
+		controllerManager := config.Spec.ClusterConfiguration.ControllerManager
+		g.Expect(controllerManager.ExtraArgs).To(Equal(map[string]string{
+			// User provided args are respected.
+			"cloud-provider": "external",
+			"cloud-config":   "/etc/kubernetes/cloud.conf",
+		}))
+		g.Expect(controllerManager.ExtraVolumes).To(ConsistOf(volume))
+
+		files, err := k.appendCloudConfigFile(ctx, config, nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(files).To(ConsistOf(bootstrapv1.File{
+			Path:        "/etc/kubernetes/cloud.conf",
+			Owner:       "root:root",
+			Permissions: "0600",
+			Content:     "[Global]\nauth-url=https://keystone.example.com\n",
+		}))
+
+		// Reconciling again does not duplicate the volumes.
+		reconcileCloudProvider(config, config.Spec.ClusterConfiguration)
+		g.Expect(config.Spec.ClusterConfiguration.APIServer.ExtraVolumes).To(HaveLen(1))
+		g.Expect(config.Spec.ClusterConfiguration.ControllerManager.ExtraVolumes).To(HaveLen(1))
+	})
+}
+
+func TestKubeadmConfigReconciler_AppendStaticPodFiles(t *testing.T) {
+	manifests := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "static-pods",
+		},
+		Data: map[string][]byte{
+			"registry-proxy.yaml": []byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: registry-proxy\n  namespace: kube-system\nspec:\n  hostNetwork: true\n  containers:\n  - name: registry-proxy\n    image: registry:2\n"),
+			"deployment.yaml":     []byte("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: registry-proxy\n"),
+			"invalid.yaml":        []byte("apiVersion: v1\nkind: Pod\n\tspec: {}\n"),
+		},
+	}
+
+	staticPod := func(name, key string) bootstrapv1.StaticPod {
+		return bootstrapv1.StaticPod{
+			Name: name,
+			ManifestFrom: &bootstrapv1.FileSource{
+				Secret: bootstrapv1.SecretFileSource{Name: "static-pods", Key: key},
+			},
+		}
+	}
+
+	cases := map[string]struct {
+		staticPods []bootstrapv1.StaticPod
+		expect     []bootstrapv1.File
+		expectErr  bool
+	}{
+		"no static pods": {},
+		"static pod manifest is written to the manifests directory": {
+			staticPods: []bootstrapv1.StaticPod{staticPod("registry-proxy", "registry-proxy.yaml")},
+			expect: []bootstrapv1.File{
+				{
+					Path:        "/etc/kubernetes/manifests/registry-proxy.yaml",
+					Owner:       "root:root",
+					Permissions: "0600",
+					Content:     string(manifests.Data["registry-proxy.yaml"]),
+				},
+			},
+		},
+		"static pod manifest of another kind": {
+			staticPods: []bootstrapv1.StaticPod{staticPod("registry-proxy", "deployment.yaml")},
+			expectErr:  true,
+		},
+		"static pod manifest which is not valid YAML": {
+			staticPods: []bootstrapv1.StaticPod{staticPod("registry-proxy", "invalid.yaml")},
+			expectErr:  true,
+		},
+		"static pod manifest missing from the secret": {
+			staticPods: []bootstrapv1.StaticPod{staticPod("registry-proxy", "missing.yaml")},
+			expectErr:  true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			config := newKubeadmConfig(nil, "cfg")
+			config.Spec.StaticPods = tc.staticPods
+
+			k := &KubeadmConfigReconciler{
+				Client:          helpers.NewFakeClientWithScheme(setupScheme(), manifests),
+				KubeadmInitLock: &myInitLocker{},
+			}
+
+			files, err := k.appendStaticPodFiles(ctx, config, nil)
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(files).To(Equal(tc.expect))
+		})
+	}
+}
+
+func TestKubeadmConfigReconciler_AppendBootLogsCredentialsFile(t *testing.T) {
+	g := NewWithT(t)
+
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "boot-logs",
+		},
+		Data: map[string][]byte{
+			"headers": []byte("Authorization: Bearer abcdef\n"),
+		},
+	}
+
+	config := newKubeadmConfig(nil, "cfg")
+	config.Spec.UploadBootLogsTo = &bootstrapv1.LogSink{
+		URL: "https://logs.example.com/boot",
+		CredentialsFrom: &bootstrapv1.FileSource{
+			Secret: bootstrapv1.SecretFileSource{Name: "boot-logs", Key: "headers"},
+		},
+	}
+
+	k := &KubeadmConfigReconciler{
+		Client:          helpers.NewFakeClientWithScheme(setupScheme(), credentials),
+		KubeadmInitLock: &myInitLocker{},
+	}
+
+	files, err := k.appendBootLogsCredentialsFile(ctx, config, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(files).To(ConsistOf(bootstrapv1.File{
+		Path:        "/etc/cluster-api/boot-logs-credentials",
+		Owner:       "root:root",
+		Permissions: "0600",
+		Content:     "Authorization: Bearer abcdef\n",
+	}))
+
+	// Uploading without credentials does not write any file.
+	config.Spec.UploadBootLogsTo.CredentialsFrom = nil
+	files, err = k.appendBootLogsCredentialsFile(ctx, config, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(files).To(BeEmpty())
+}
+
 // test utils
 
 // newCluster return a CAPI cluster object.
@@ -25,6 +25,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	bootstrapapi "k8s.io/cluster-bootstrap/token/api"
 	bootstraputil "k8s.io/cluster-bootstrap/token/util"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -33,8 +34,24 @@ var (
 	DefaultTokenTTL = 15 * time.Minute
 )
 
-// createToken attempts to create a token with the given ID.
-func createToken(ctx context.Context, c client.Client) (string, error) {
+// tokenTTL returns how long the bootstrap tokens of the given config are valid.
+func tokenTTL(config *bootstrapv1.KubeadmConfig) time.Duration {
+	if config.Spec.BootstrapTokenTTL != nil {
+		return config.Spec.BootstrapTokenTTL.Duration
+	}
+	return DefaultTokenTTL
+}
+
+// tokenUsages returns the usages of the bootstrap tokens of the given config.
+func tokenUsages(config *bootstrapv1.KubeadmConfig) []string {
+	if len(config.Spec.BootstrapTokenUsages) > 0 {
+		return config.Spec.BootstrapTokenUsages
+	}
+	return bootstrapapi.KnownTokenUsages
+}
+
+// createToken attempts to create a token with the given TTL and usages.
+func createToken(ctx context.Context, c client.Client, ttl time.Duration, usages []string) (string, error) {
 	token, err := bootstraputil.GenerateBootstrapToken()
 	if err != nil {
 		return "", errors.Wrap(err, "unable to generate bootstrap token")
@@ -55,15 +72,16 @@ func createToken(ctx context.Context, c client.Client) (string, error) {
 		},
 		Type: bootstrapapi.SecretTypeBootstrapToken,
 		Data: map[string][]byte{
-			bootstrapapi.BootstrapTokenIDKey:               []byte(tokenID),
-			bootstrapapi.BootstrapTokenSecretKey:           []byte(tokenSecret),
-			bootstrapapi.BootstrapTokenExpirationKey:       []byte(time.Now().UTC().Add(DefaultTokenTTL).Format(time.RFC3339)),
-			bootstrapapi.BootstrapTokenUsageSigningKey:     []byte("true"),
-			bootstrapapi.BootstrapTokenUsageAuthentication: []byte("true"),
-			bootstrapapi.BootstrapTokenExtraGroupsKey:      []byte("system:bootstrappers:kubeadm:default-node-token"),
-			bootstrapapi.BootstrapTokenDescriptionKey:      []byte("token generated by cluster-api-bootstrap-provider-kubeadm"),
+			bootstrapapi.BootstrapTokenIDKey:          []byte(tokenID),
+			bootstrapapi.BootstrapTokenSecretKey:      []byte(tokenSecret),
+			bootstrapapi.BootstrapTokenExpirationKey:  []byte(time.Now().UTC().Add(ttl).Format(time.RFC3339)),
+			bootstrapapi.BootstrapTokenExtraGroupsKey: []byte("system:bootstrappers:kubeadm:default-node-token"),
+			bootstrapapi.BootstrapTokenDescriptionKey: []byte("token generated by cluster-api-bootstrap-provider-kubeadm"),
 		},
 	}
+	for _, usage := range usages {
+		secretToken.Data[bootstrapapi.BootstrapTokenUsagePrefix+usage] = []byte("true")
+	}
 
 	if err = c.Create(ctx, secretToken); err != nil {
 		return "", err
@@ -92,18 +110,18 @@ func getToken(ctx context.Context, c client.Client, token string) (*v1.Secret, e
 }
 
 // refreshToken extends the TTL for an existing token.
-func refreshToken(ctx context.Context, c client.Client, token string) error {
+func refreshToken(ctx context.Context, c client.Client, token string, ttl time.Duration) error {
 	secret, err := getToken(ctx, c, token)
 	if err != nil {
 		return err
 	}
-	secret.Data[bootstrapapi.BootstrapTokenExpirationKey] = []byte(time.Now().UTC().Add(DefaultTokenTTL).Format(time.RFC3339))
+	secret.Data[bootstrapapi.BootstrapTokenExpirationKey] = []byte(time.Now().UTC().Add(ttl).Format(time.RFC3339))
 
 	return c.Update(ctx, secret)
 }
 
 // shouldRotate returns true if an existing token is past half of its TTL and should to be rotated.
-func shouldRotate(ctx context.Context, c client.Client, token string) (bool, error) {
+func shouldRotate(ctx context.Context, c client.Client, token string, ttl time.Duration) (bool, error) {
 	secret, err := getToken(ctx, c, token)
 	if err != nil {
 		return false, err
@@ -113,5 +131,5 @@ func shouldRotate(ctx context.Context, c client.Client, token string) (bool, err
 	if err != nil {
 		return false, err
 	}
-	return expiration.Before(time.Now().UTC().Add(DefaultTokenTTL / 2)), nil
+	return expiration.Before(time.Now().UTC().Add(ttl / 2)), nil
 }
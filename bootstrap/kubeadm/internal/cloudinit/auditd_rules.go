@@ -0,0 +1,54 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	auditdRulesPath        = "/etc/audit/rules.d/capi.rules"
+	auditdRulesOwner       = "root:root"
+	auditdRulesPermissions = "0600"
+
+	// auditdRulesLoadCommand merges the rules files of /etc/audit/rules.d and loads the result into the kernel;
+	// auditd itself refuses to be restarted by systemctl on most distributions.
+	auditdRulesLoadCommand = "augenrules --load"
+)
+
+// addAuditdRules writes the auditd rules file, and appends the command loading it to the pre kubeadm commands, so
+// that the kubeadm command is audited, if requested.
+func (input *BaseUserData) addAuditdRules() {
+	if len(input.AuditdRules) == 0 {
+		return
+	}
+
+	var b strings.Builder
+	for _, rule := range input.AuditdRules {
+		b.WriteString(strings.TrimSpace(rule))
+		b.WriteString("\n")
+	}
+	input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+		Path:        auditdRulesPath,
+		Owner:       auditdRulesOwner,
+		Permissions: auditdRulesPermissions,
+		Content:     b.String(),
+	})
+	input.PreKubeadmCommands = append(input.PreKubeadmCommands, auditdRulesLoadCommand)
+}
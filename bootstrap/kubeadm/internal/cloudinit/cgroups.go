@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	// cgroupV2KernelArg switches systemd to the unified cgroup (v2) hierarchy; swapAccountingKernelArg enables the
+	// accounting of the swap usage of the cgroups, on kernels not enabling it by default.
+	cgroupV2KernelArg       = "systemd.unified_cgroup_hierarchy=1"
+	swapAccountingKernelArg = "swapaccount=1"
+
+	cgroupKernelArgsScriptPath        = "/usr/local/bin/set-cgroup-kernel-args.sh"
+	cgroupKernelArgsScriptOwner       = "root:root"
+	cgroupKernelArgsScriptPermissions = "0700"
+
+	// cgroupKernelArgsScript sets the kernel args given as arguments which the current boot is missing, with grubby
+	// where available, e.g. on RHEL and its derivatives, or otherwise with a GRUB drop-in, e.g. on Ubuntu. They
+	// only take effect from the next boot, so the script does nothing on machines already booted with them.
+	cgroupKernelArgsScript = `#!/bin/sh
+set -e
+args=""
+for arg in "$@"; do
+  if ! grep -qwF -- "$arg" /proc/cmdline; then
+    args="$args $arg"
+  fi
+done
+if [ -z "$args" ]; then
+  exit 0
+fi
+if command -v grubby >/dev/null 2>&1; then
+  grubby --update-kernel=ALL --args="${args# }"
+elif command -v update-grub >/dev/null 2>&1; then
+  mkdir -p /etc/default/grub.d
+  echo "GRUB_CMDLINE_LINUX_DEFAULT=\"\$GRUB_CMDLINE_LINUX_DEFAULT$args\"" > /etc/default/grub.d/cgroups.cfg
+  update-grub
+else
+  echo "setting the cgroup kernel args is not supported on this operating system" >&2
+fi
+`
+
+	// systemdCgroupConfigPath is a containerd configuration drop-in, loaded like sandboxImageConfigPath.
+	systemdCgroupConfigPath        = "/etc/containerd/conf.d/systemd-cgroup.toml"
+	systemdCgroupConfigOwner       = "root:root"
+	systemdCgroupConfigPermissions = "0644"
+
+	// systemdCgroupConfig configures runc with the systemd cgroup driver, which must match the one of the kubelet.
+	systemdCgroupConfig = `version = 2
+[plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runc.options]
+  SystemdCgroup = true
+`
+)
+
+// addCgroups adds the script setting the kernel args enabling cgroup v2 and swap accounting, and the command running
+// it, along with the containerd configuration drop-in setting the systemd cgroup driver, if requested. The kubelet is
+// configured through its args by the KubeadmConfig controller.
+func (input *BaseUserData) addCgroups() {
+	if !input.CgroupV2 {
+		return
+	}
+
+	kernelArgs := []string{cgroupV2KernelArg}
+	if input.SwapAccounting {
+		kernelArgs = append(kernelArgs, swapAccountingKernelArg)
+	}
+
+	input.WriteFiles = append(input.WriteFiles,
+		bootstrapv1.File{
+			Path:        cgroupKernelArgsScriptPath,
+			Owner:       cgroupKernelArgsScriptOwner,
+			Permissions: cgroupKernelArgsScriptPermissions,
+			Content:     cgroupKernelArgsScript,
+		},
+		bootstrapv1.File{
+			Path:        systemdCgroupConfigPath,
+			Owner:       systemdCgroupConfigOwner,
+			Permissions: systemdCgroupConfigPermissions,
+			Content:     systemdCgroupConfig,
+		},
+	)
+	input.PreKubeadmCommands = append(input.PreKubeadmCommands, cgroupKernelArgsScriptPath+" "+strings.Join(kernelArgs, " "))
+	input.addContainerdRestart()
+}
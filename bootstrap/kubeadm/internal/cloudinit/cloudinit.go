@@ -23,6 +23,7 @@ import (
 	"text/template"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
 )
 
@@ -41,26 +42,73 @@ const (
 
 // BaseUserData is shared across all the various types of files written to disk.
 type BaseUserData struct {
-	Header               string
-	PreKubeadmCommands   []string
-	PostKubeadmCommands  []string
-	AdditionalFiles      []bootstrapv1.File
-	WriteFiles           []bootstrapv1.File
-	Users                []bootstrapv1.User
-	NTP                  *bootstrapv1.NTP
-	DiskSetup            *bootstrapv1.DiskSetup
-	Mounts               []bootstrapv1.MountPoints
-	ControlPlane         bool
-	UseExperimentalRetry bool
-	KubeadmCommand       string
-	KubeadmVerbosity     string
-	SentinelFileCommand  string
+	Header                       string
+	PreKubeadmCommands           []string
+	PostKubeadmCommands          []string
+	AdditionalFiles              []bootstrapv1.File
+	WriteFiles                   []bootstrapv1.File
+	Users                        []bootstrapv1.User
+	NTP                          *bootstrapv1.NTP
+	DiskSetup                    *bootstrapv1.DiskSetup
+	Mounts                       []bootstrapv1.MountPoints
+	ControlPlane                 bool
+	UseExperimentalRetry         bool
+	KubeadmCommand               string
+	KubeadmVerbosity             string
+	SentinelFileCommand          string
+	GracefulShutdown             *bootstrapv1.GracefulShutdownConfig
+	WaitForNodeReady             *bootstrapv1.WaitConfig
+	InstallCrictlConfig          bool
+	CRISocket                    string
+	PrePullImages                []string
+	PersistentJournal            bool
+	LoginBanner                  *string
+	GrowRootFilesystem           bool
+	NetworkConfig                []bootstrapv1.NetworkInterface
+	NodeLocalDNS                 *bootstrapv1.NodeLocalDNSConfig
+	SystemdTimers                []bootstrapv1.SystemdTimer
+	Packages                     []string
+	SandboxImage                 *string
+	RemountOptions               []bootstrapv1.RemountSpec
+	IgnorePreflightErrors        []string
+	KubeadmIgnorePreflightErrors string
+	PublishConfigTo              *bootstrapv1.PublishTarget
+	NodeLabels                   string
+	NodeName                     string
+	SSHHardening                 *bootstrapv1.SSHHardeningConfig
+	FIPSMode                     bool
+	Kdump                        *bootstrapv1.KdumpConfig
+	NodeProblemDetector          *bootstrapv1.NPDConfig
+	RuntimeHandlers              []bootstrapv1.RuntimeHandler
+	Timezone                     *string
+	UploadBootLogsTo             *bootstrapv1.LogSink
+	StartupTaint                 *corev1.Taint
+	HostDNS                      *bootstrapv1.HostDNSConfig
+	DNSManagement                bootstrapv1.DNSManagement
+	ContainerdMetrics            *bootstrapv1.ContainerdMetricsConfig
+	CgroupV2                     bool
+	SwapAccounting               bool
+	AuditdRules                  []string
+	SystemLimits                 *bootstrapv1.SystemLimitsConfig
+	KubeletServingCertSANs       []string
+	ContainerdPull               *bootstrapv1.ContainerdPullConfig
 }
 
 func (input *BaseUserData) prepare() error {
+	if err := input.checkDiskWipes(); err != nil {
+		return err
+	}
 	input.Header = cloudConfigHeader
 	input.WriteFiles = append(input.WriteFiles, input.AdditionalFiles...)
+	if input.ControlPlane {
+		input.addFeatures(adminKubeconfigPath)
+	} else {
+		input.addFeatures(kubeletKubeconfigPath)
+	}
 	input.KubeadmCommand = fmt.Sprintf(standardJoinCommand, input.KubeadmVerbosity)
+	if input.KubeadmIgnorePreflightErrors != "" {
+		input.KubeadmCommand = fmt.Sprintf("%s %s", input.KubeadmCommand, input.KubeadmIgnorePreflightErrors)
+	}
 	if input.UseExperimentalRetry {
 		input.KubeadmCommand = retriableJoinScriptName
 		joinScriptFile, err := generateBootstrapScript(input)
@@ -73,6 +121,38 @@ func (input *BaseUserData) prepare() error {
 	return nil
 }
 
+// addFeatures adds the files and commands of the features shared by the init and join user data, whose commands
+// talking to the API server use the given kubeconfig.
+func (input *BaseUserData) addFeatures(kubeconfigPath string) {
+	input.addGracefulShutdownInhibitor()
+	input.addNodeLabels(kubeconfigPath)
+	input.addWaitForNodeReady()
+	input.addCrictlConfig()
+	input.addPrePullImages()
+	input.addUserHomeDirs()
+	input.addNodeLocalDNS()
+	input.addHostDNS()
+	input.addNetworkConfig()
+	input.addPersistentJournal()
+	input.addLoginBanner()
+	input.addSSHHardening()
+	input.addFIPSMode()
+	input.addKdump()
+	input.addNodeProblemDetector()
+	input.addSystemdTimers()
+	input.addSandboxImage()
+	input.addRuntimeHandlers()
+	input.addContainerdMetrics()
+	input.addContainerdPull()
+	input.addCgroups()
+	input.addAuditdRules()
+	input.addSystemLimits()
+	input.addKubeletServingCert()
+	input.addRemountOptions()
+	input.addWaitForMounts()
+	input.addIgnorePreflightErrors()
+}
+
 func generate(kind string, tpl string, data interface{}) ([]byte, error) {
 	tm := template.New(kind).Funcs(defaultTemplateFuncMap)
 	if _, err := tm.Parse(filesTemplate); err != nil {
@@ -95,6 +175,10 @@ func generate(kind string, tpl string, data interface{}) ([]byte, error) {
 		return nil, errors.Wrap(err, "failed to parse disk setup template")
 	}
 
+	if _, err := tm.Parse(partitionsTemplate); err != nil {
+		return nil, errors.Wrap(err, "failed to parse partitions template")
+	}
+
 	if _, err := tm.Parse(fsSetupTemplate); err != nil {
 		return nil, errors.Wrap(err, "failed to parse fs setup template")
 	}
@@ -103,6 +187,18 @@ func generate(kind string, tpl string, data interface{}) ([]byte, error) {
 		return nil, errors.Wrap(err, "failed to parse mounts template")
 	}
 
+	if _, err := tm.Parse(growRootTemplate); err != nil {
+		return nil, errors.Wrap(err, "failed to parse grow root template")
+	}
+
+	if _, err := tm.Parse(packagesTemplate); err != nil {
+		return nil, errors.Wrap(err, "failed to parse packages template")
+	}
+
+	if _, err := tm.Parse(timezoneTemplate); err != nil {
+		return nil, errors.Wrap(err, "failed to parse timezone template")
+	}
+
 	t, err := tm.Parse(tpl)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to parse %s template", kind)
@@ -17,10 +17,14 @@ limitations under the License.
 package cloudinit
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
 	infrav1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
@@ -179,3 +183,1620 @@ func TestNewInitControlPlaneDiskMounts(t *testing.T) {
 	g.Expect(out).To(ContainSubstring(expectedFSSetup))
 	g.Expect(out).To(ContainSubstring(expectedMounts))
 }
+
+func TestNewNodeDiskWipeConfirmation(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header: "test",
+			DiskSetup: &bootstrapv1.DiskSetup{
+				Partitions: []bootstrapv1.Partition{
+					{Device: "/dev/sdb", Layout: true, Overwrite: pointer.BoolPtr(true)},
+				},
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	_, err := NewNode(nodeinput)
+	g.Expect(err).To(MatchError(ContainSubstring("/dev/sdb")))
+
+	nodeinput.DiskSetup.Partitions[0].ConfirmWipe = true
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+  /dev/sdb:
+    layout: true
+    overwrite: true`))
+}
+
+func TestNewNodeDiskPartitionsFillRemainingSpace(t *testing.T) {
+	g := NewWithT(t)
+
+	intPtr := func(i int) *int { return &i }
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header: "test",
+			DiskSetup: &bootstrapv1.DiskSetup{
+				Partitions: []bootstrapv1.Partition{
+					{
+						Device:  "/dev/sdb",
+						Number:  intPtr(2),
+						SizeMiB: intPtr(-1),
+					},
+					{
+						Device:  "/dev/sdb",
+						Number:  intPtr(1),
+						SizeMiB: intPtr(1024),
+					},
+				},
+				Filesystems: []bootstrapv1.Filesystem{
+					{
+						Device:     "/dev/sdb",
+						Filesystem: "ext4",
+						Label:      "data",
+						Partition:  pointer.StringPtr("2"),
+					},
+				},
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	expectedPartitions := `bootcmd:
+  - "cloud-init-per once partition-sdb-1 sgdisk --new=1:0:+1024M /dev/sdb"
+  - "cloud-init-per once partition-sdb-2 sgdisk --new=2:0:0 /dev/sdb"`
+	expectedFSSetup := `fs_setup:
+  - label: data
+    filesystem: ext4
+    device: /dev/sdb
+    partition: 2`
+
+	g.Expect(out).To(ContainSubstring(expectedPartitions))
+	g.Expect(out).To(ContainSubstring(expectedFSSetup))
+	g.Expect(out).NotTo(ContainSubstring("disk_setup:"))
+}
+
+func TestNewNodeMountsNormalized(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header: "test",
+			Mounts: []bootstrapv1.MountPoints{
+				{" test_disk", "/var/lib/testdir ", "ext4", "defaults,nofail"},
+				{"", "/var/lib/missing-device"},
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	expectedMounts := `mounts:
+  - - test_disk
+    - /var/lib/testdir
+    - ext4
+    - defaults,nofail
+`
+	g.Expect(string(out)).To(ContainSubstring(expectedMounts))
+	g.Expect(string(out)).NotTo(ContainSubstring("missing-device"))
+}
+
+func TestNewNodeGracefulShutdownInhibitor(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:              "test",
+			PostKubeadmCommands: []string{"echo done"},
+			GracefulShutdown: &bootstrapv1.GracefulShutdownConfig{
+				Timeout:       metav1.Duration{Duration: 2 * time.Minute},
+				InhibitorUnit: true,
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(out).To(ContainSubstring("-   path: /etc/systemd/system/kubelet-drain-on-shutdown.service"))
+	g.Expect(out).To(ContainSubstring(`drain "$$(hostname | tr A-Z a-z)" --ignore-daemonsets --delete-emptydir-data --force --timeout=120s || true`))
+	g.Expect(out).To(ContainSubstring("TimeoutStopSec=120"))
+	g.Expect(out).To(ContainSubstring(`  - "systemctl daemon-reload && systemctl enable --now kubelet-drain-on-shutdown.service"
+  - "echo done"`))
+
+	// The node registration name is drained rather than the hostname, rendered by cloud-init when it is a template.
+	nodeinput = &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:   "test",
+			NodeName: "{{ ds.meta_data.local_hostname }}",
+			GracefulShutdown: &bootstrapv1.GracefulShutdownConfig{
+				Timeout:       metav1.Duration{Duration: 2 * time.Minute},
+				InhibitorUnit: true,
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err = NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(out).To(ContainSubstring(`drain "{{ ds.meta_data.local_hostname }}" --ignore-daemonsets`))
+}
+
+func TestNewNodeGracefulShutdownWithoutInhibitor(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header: "test",
+			GracefulShutdown: &bootstrapv1.GracefulShutdownConfig{
+				Timeout: metav1.Duration{Duration: 2 * time.Minute},
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(out).NotTo(ContainSubstring("kubelet-drain-on-shutdown.service"))
+}
+
+func TestNewNodeWaitForNodeReady(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:              "test",
+			PostKubeadmCommands: []string{"echo done"},
+			KubeadmVerbosity:    "--v=10",
+			WaitForNodeReady: &bootstrapv1.WaitConfig{
+				Timeout: metav1.Duration{Duration: 5 * time.Minute},
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	joinCommand := "  - kubeadm join --config /run/kubeadm/kubeadm-join-config.yaml --v=10 && echo success > /run/cluster-api/bootstrap-success.complete\n"
+	waitCommand := `  - "timeout 300s sh -c 'until kubectl --kubeconfig /etc/kubernetes/kubelet.conf get node \"$(hostname | tr A-Z a-z)\"`
+	g.Expect(string(out)).To(ContainSubstring(joinCommand + `  - "echo done"` + "\n" + waitCommand))
+}
+
+func TestNewNodeCrictlConfig(t *testing.T) {
+	tests := []struct {
+		name             string
+		criSocket        string
+		expectedEndpoint string
+	}{
+		{
+			name:             "configured socket path",
+			criSocket:        "/var/run/crio/crio.sock",
+			expectedEndpoint: "unix:///var/run/crio/crio.sock",
+		},
+		{
+			name:             "configured socket URL",
+			criSocket:        "unix:///run/containerd/containerd.sock",
+			expectedEndpoint: "unix:///run/containerd/containerd.sock",
+		},
+		{
+			name:             "default socket",
+			expectedEndpoint: "unix:///var/run/containerd/containerd.sock",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			nodeinput := &NodeInput{
+				BaseUserData: BaseUserData{
+					Header:              "test",
+					InstallCrictlConfig: true,
+					CRISocket:           tt.criSocket,
+				},
+				JoinConfiguration: "my-join-config",
+			}
+
+			out, err := NewNode(nodeinput)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(string(out)).To(ContainSubstring(`-   path: /etc/crictl.yaml
+    owner: root:root
+    permissions: '0644'
+    content: |
+      runtime-endpoint: ` + tt.expectedEndpoint + `
+      image-endpoint: ` + tt.expectedEndpoint))
+		})
+	}
+}
+
+func TestNewInitControlPlaneCrictlConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	cpinput := &ControlPlaneInput{
+		BaseUserData: BaseUserData{
+			Header:              "test",
+			InstallCrictlConfig: true,
+			CRISocket:           "/var/run/crio/crio.sock",
+		},
+		Certificates:         secret.Certificates{},
+		ClusterConfiguration: "my-cluster-config",
+		InitConfiguration:    "my-init-config",
+	}
+
+	out, err := NewInitControlPlane(cpinput)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(string(out)).To(ContainSubstring("-   path: /etc/crictl.yaml"))
+	g.Expect(string(out)).To(ContainSubstring("runtime-endpoint: unix:///var/run/crio/crio.sock"))
+}
+
+func TestNewNodeWithoutCrictlConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:    "test",
+			CRISocket: "/var/run/crio/crio.sock",
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(string(out)).NotTo(ContainSubstring("/etc/crictl.yaml"))
+}
+
+func TestNewNodePrePullImages(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"systemctl restart containerd"},
+			KubeadmVerbosity:   "--v=10",
+			CRISocket:          "/var/run/crio/crio.sock",
+			PrePullImages: []string{
+				"k8s.gcr.io/pause:3.4.1",
+				"docker.io/calico/node:v3.19.1",
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	expectedCommands := `runcmd:
+  - "systemctl restart containerd"
+  - "crictl --image-endpoint unix:///var/run/crio/crio.sock pull k8s.gcr.io/pause:3.4.1"
+  - "crictl --image-endpoint unix:///var/run/crio/crio.sock pull docker.io/calico/node:v3.19.1"
+  - kubeadm join --config /run/kubeadm/kubeadm-join-config.yaml --v=10`
+	g.Expect(string(out)).To(ContainSubstring(expectedCommands))
+}
+
+func TestNewNodePersistentJournal(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"systemctl restart containerd"},
+			KubeadmVerbosity:   "--v=10",
+			PersistentJournal:  true,
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(string(out)).To(ContainSubstring(`-   path: /etc/systemd/journald.conf.d/persistent.conf
+    owner: root:root
+    permissions: '0644'
+    content: |
+      [Journal]
+      Storage=persistent`))
+
+	expectedCommands := `runcmd:
+  - "install -d -m 2755 /var/log/journal"
+  - "systemd-tmpfiles --create --prefix /var/log/journal"
+  - "systemctl restart systemd-journald"
+  - "systemctl restart containerd"
+  - kubeadm join --config /run/kubeadm/kubeadm-join-config.yaml --v=10`
+	g.Expect(string(out)).To(ContainSubstring(expectedCommands))
+}
+
+func TestNewNodeLoginBanner(t *testing.T) {
+	g := NewWithT(t)
+
+	banner := "Authorized access only.\nActivity may be monitored.\n"
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:      "test",
+			LoginBanner: &banner,
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	for _, path := range []string{"/etc/motd", "/etc/issue"} {
+		g.Expect(string(out)).To(ContainSubstring(`-   path: ` + path + `
+    owner: root:root
+    permissions: '0644'
+    content: |
+      Authorized access only.
+      Activity may be monitored.`))
+	}
+	g.Expect(string(out)).To(ContainSubstring(`-   path: /etc/ssh/sshd_config.d/login-banner.conf
+    owner: root:root
+    permissions: '0600'
+    content: |
+      Banner /etc/issue`))
+}
+
+func TestSSHHardening(t *testing.T) {
+	hardening := &bootstrapv1.SSHHardeningConfig{
+		PasswordAuthentication: pointer.BoolPtr(false),
+		PermitRootLogin:        bootstrapv1.PermitRootLoginProhibitPassword,
+		Ciphers:                []string{"chacha20-poly1305@openssh.com", "aes256-gcm@openssh.com"},
+		MACs:                   []string{"hmac-sha2-512-etm@openssh.com"},
+	}
+
+	tests := []struct {
+		name     string
+		generate func(BaseUserData) ([]byte, error)
+	}{
+		{
+			name: "node",
+			generate: func(base BaseUserData) ([]byte, error) {
+				return NewNode(&NodeInput{BaseUserData: base, JoinConfiguration: "my-join-config"})
+			},
+		},
+		{
+			name: "init control plane",
+			generate: func(base BaseUserData) ([]byte, error) {
+				return NewInitControlPlane(&ControlPlaneInput{BaseUserData: base, Certificates: secret.Certificates{}, ClusterConfiguration: "my-cluster-config", InitConfiguration: "my-init-config"})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			out, err := tt.generate(BaseUserData{
+				Header:             "test",
+				PreKubeadmCommands: []string{"echo pre"},
+				SSHHardening:       hardening,
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(string(out)).To(ContainSubstring(`-   path: /etc/ssh/sshd_config.d/00-hardening.conf
+    owner: root:root
+    permissions: '0600'
+    content: |
+      PasswordAuthentication no
+      PermitRootLogin prohibit-password
+      Ciphers chacha20-poly1305@openssh.com,aes256-gcm@openssh.com
+      MACs hmac-sha2-512-etm@openssh.com`))
+			g.Expect(string(out)).To(ContainSubstring(`
+runcmd:
+  - "sshd -t && (systemctl reload sshd || systemctl reload ssh)"
+  - "echo pre"`))
+
+			// Unset fields keep the configuration of the image.
+			out, err = tt.generate(BaseUserData{
+				Header:       "test",
+				SSHHardening: &bootstrapv1.SSHHardeningConfig{PasswordAuthentication: pointer.BoolPtr(true)},
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(string(out)).To(ContainSubstring(`
+    content: |
+      PasswordAuthentication yes
+`))
+			g.Expect(string(out)).NotTo(ContainSubstring("PermitRootLogin"))
+			g.Expect(string(out)).NotTo(ContainSubstring("Ciphers"))
+			g.Expect(string(out)).NotTo(ContainSubstring("MACs"))
+		})
+	}
+}
+
+func TestNewNodeGrowRootFilesystem(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			GrowRootFilesystem: true,
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+growpart:
+  mode: auto
+  devices:
+    - /
+resize_rootfs: true`))
+
+	nodeinput.GrowRootFilesystem = false
+	out, err = NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).NotTo(ContainSubstring("growpart:"))
+	g.Expect(string(out)).NotTo(ContainSubstring("resize_rootfs:"))
+}
+
+func TestNewNodeTimezone(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:   "test",
+			Timezone: pointer.StringPtr("Europe/Berlin"),
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring("\ntimezone: Europe/Berlin\n"))
+
+	nodeinput.Timezone = nil
+	out, err = NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).NotTo(ContainSubstring("timezone:"))
+}
+
+func TestNewNodeSandboxImage(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"echo pre"},
+			SandboxImage:       pointer.StringPtr("registry.example.com/pause:3.5"),
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+-   path: /etc/containerd/conf.d/sandbox-image.toml
+    owner: root:root
+    permissions: '0644'
+    content: |
+      version = 2
+      [plugins."io.containerd.grpc.v1.cri"]
+        sandbox_image = "registry.example.com/pause:3.5"`))
+	g.Expect(string(out)).To(ContainSubstring(`
+runcmd:
+  - "systemctl restart containerd"
+  - "echo pre"`))
+}
+
+func TestNewNodeRuntimeHandlers(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"echo pre"},
+			SandboxImage:       pointer.StringPtr("registry.example.com/pause:3.5"),
+			RuntimeHandlers: []bootstrapv1.RuntimeHandler{
+				{Name: "runsc", RuntimeType: "io.containerd.runsc.v1"},
+				{Name: "kata", RuntimeType: "io.containerd.kata.v2", BinaryPath: "/opt/kata/bin/containerd-shim-kata-v2"},
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+-   path: /etc/containerd/conf.d/runtime-handlers.toml
+    owner: root:root
+    permissions: '0644'
+    content: |
+      version = 2
+      [plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runsc]
+        runtime_type = "io.containerd.runsc.v1"
+      [plugins."io.containerd.grpc.v1.cri".containerd.runtimes.kata]
+        runtime_type = "io.containerd.kata.v2"
+        runtime_path = "/opt/kata/bin/containerd-shim-kata-v2"`))
+	// containerd is restarted once for both drop-ins.
+	g.Expect(string(out)).To(ContainSubstring(`
+runcmd:
+  - "systemctl restart containerd"
+  - "echo pre"`))
+	g.Expect(strings.Count(string(out), "systemctl restart containerd")).To(Equal(1))
+}
+
+func TestNewNodeContainerdMetrics(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"echo pre"},
+			ContainerdMetrics:  &bootstrapv1.ContainerdMetricsConfig{Address: "127.0.0.1:1338"},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+-   path: /etc/containerd/conf.d/metrics.toml
+    owner: root:root
+    permissions: '0644'
+    content: |
+      version = 2
+      [metrics]
+        address = "127.0.0.1:1338"`))
+	g.Expect(string(out)).To(ContainSubstring(`
+runcmd:
+  - "systemctl restart containerd"
+  - "echo pre"`))
+}
+
+func TestNewNodeContainerdPull(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"echo pre"},
+			ContainerdPull: &bootstrapv1.ContainerdPullConfig{
+				MaxConcurrentDownloads: pointer.Int32Ptr(10),
+				PullTimeout:            &metav1.Duration{Duration: 5 * time.Minute},
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+-   path: /etc/containerd/conf.d/pull.toml
+    owner: root:root
+    permissions: '0644'
+    content: |
+      version = 2
+      [plugins."io.containerd.grpc.v1.cri"]
+        max_concurrent_downloads = 10
+        image_pull_progress_timeout = "5m0s"`))
+	g.Expect(string(out)).To(ContainSubstring(`
+runcmd:
+  - "systemctl restart containerd"
+  - "echo pre"`))
+}
+
+func TestNewNodeCgroups(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"echo pre"},
+			CgroupV2:           true,
+			SwapAccounting:     true,
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+-   path: /usr/local/bin/set-cgroup-kernel-args.sh
+    owner: root:root
+    permissions: '0700'`))
+	g.Expect(string(out)).To(ContainSubstring(`
+-   path: /etc/containerd/conf.d/systemd-cgroup.toml
+    owner: root:root
+    permissions: '0644'
+    content: |
+      version = 2
+      [plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runc.options]
+        SystemdCgroup = true`))
+	g.Expect(string(out)).To(ContainSubstring(`
+runcmd:
+  - "systemctl restart containerd"
+  - "echo pre"
+  - "/usr/local/bin/set-cgroup-kernel-args.sh systemd.unified_cgroup_hierarchy=1 swapaccount=1"`))
+
+	// Swap accounting is only enabled along with swap.
+	nodeinput.SwapAccounting = false
+	nodeinput.PreKubeadmCommands = []string{"echo pre"}
+	nodeinput.WriteFiles = nil
+	out, err = NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`"/usr/local/bin/set-cgroup-kernel-args.sh systemd.unified_cgroup_hierarchy=1"`))
+}
+
+func TestNewNodeAuditdRules(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"echo pre"},
+			AuditdRules:        []string{"-w /etc/kubernetes -p wa -k kubernetes", "-a always,exit -F arch=b64 -S execve -k exec"},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+-   path: /etc/audit/rules.d/capi.rules
+    owner: root:root
+    permissions: '0600'
+    content: |
+      -w /etc/kubernetes -p wa -k kubernetes
+      -a always,exit -F arch=b64 -S execve -k exec`))
+	g.Expect(string(out)).To(ContainSubstring(`
+runcmd:
+  - "echo pre"
+  - "augenrules --load"`))
+}
+
+func TestNewNodeSystemLimits(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"echo pre"},
+			SystemLimits: &bootstrapv1.SystemLimitsConfig{
+				NoFile: pointer.Int64Ptr(1048576),
+				NProc:  pointer.Int64Ptr(65536),
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+-   path: /etc/systemd/system.conf.d/capi-limits.conf
+    owner: root:root
+    permissions: '0644'
+    content: |
+      [Manager]
+      DefaultLimitNOFILE=1048576
+      DefaultLimitNPROC=65536`))
+	g.Expect(string(out)).To(ContainSubstring(`
+-   path: /etc/security/limits.d/99-capi.conf
+    owner: root:root
+    permissions: '0644'
+    content: |
+      * - nofile 1048576
+      root - nofile 1048576
+      * - nproc 65536
+      root - nproc 65536`))
+	g.Expect(string(out)).To(ContainSubstring(`
+runcmd:
+  - "echo pre"
+  - "systemctl daemon-reexec"`))
+}
+
+func TestNewNodeKubeletServingCert(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:                 "test",
+			PreKubeadmCommands:     []string{"echo pre"},
+			KubeletServingCertSANs: []string{"node.example.com", "10.0.0.10"},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+-   path: /usr/local/bin/generate-kubelet-serving-cert.sh
+    owner: root:root
+    permissions: '0700'`))
+	g.Expect(string(out)).To(ContainSubstring(`-addext "subjectAltName=${sans},DNS:node.example.com,IP:10.0.0.10"`))
+	g.Expect(string(out)).To(ContainSubstring("-keyout /etc/kubernetes/pki/kubelet-serving.key -out /etc/kubernetes/pki/kubelet-serving.crt"))
+	g.Expect(string(out)).To(ContainSubstring(`
+runcmd:
+  - "echo pre"
+  - "/usr/local/bin/generate-kubelet-serving-cert.sh"`))
+}
+
+func TestNewNodeFIPSMode(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"echo pre"},
+			FIPSMode:           true,
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+-   path: /usr/local/bin/enable-fips-mode.sh
+    owner: root:root
+    permissions: '0700'`))
+	g.Expect(string(out)).To(ContainSubstring("fips-mode-setup --enable"))
+	g.Expect(string(out)).To(ContainSubstring("grubby --update-kernel=ALL --args=fips=1"))
+	g.Expect(string(out)).To(ContainSubstring(`
+runcmd:
+  - "echo pre"
+  - "/usr/local/bin/enable-fips-mode.sh"`))
+}
+
+func TestNewNodeKdump(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"echo pre"},
+			Kdump:              &bootstrapv1.KdumpConfig{CrashKernel: "1G-4G:192M,4G-:256M"},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+-   path: /etc/kdump.conf
+    owner: root:root
+    permissions: '0644'
+    content: |
+      path /var/crash
+      core_collector makedumpfile -l --message-level 1 -d 31`))
+	g.Expect(string(out)).To(ContainSubstring(`
+-   path: /usr/local/bin/enable-kdump.sh
+    owner: root:root
+    permissions: '0700'`))
+	g.Expect(string(out)).To(ContainSubstring(`grubby --update-kernel=ALL --args="crashkernel=$1"`))
+	g.Expect(string(out)).To(ContainSubstring(`systemctl enable "$unit"`))
+	g.Expect(string(out)).To(ContainSubstring(`
+runcmd:
+  - "echo pre"
+  - "/usr/local/bin/enable-kdump.sh 1G-4G:192M,4G-:256M /var/crash"`))
+}
+
+func TestNewNodeNodeProblemDetector(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:              "test",
+			PostKubeadmCommands: []string{"echo post"},
+			AdditionalFiles: []bootstrapv1.File{
+				{Path: NodeProblemDetectorConfigPath, Content: "{}"},
+			},
+			NodeProblemDetector: &bootstrapv1.NPDConfig{
+				Image: "registry.k8s.io/node-problem-detector/node-problem-detector:v0.8.10",
+				ConfigFrom: &bootstrapv1.FileSource{
+					Secret: bootstrapv1.SecretFileSource{Name: "npd-config", Key: "system-log-monitor.json"},
+				},
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+-   path: /etc/systemd/system/node-problem-detector.service
+    owner: root:root
+    permissions: '0644'`))
+	g.Expect(string(out)).To(ContainSubstring("ExecStartPre=/usr/bin/ctr --namespace k8s.io images pull registry.k8s.io/node-problem-detector/node-problem-detector:v0.8.10"))
+	g.Expect(string(out)).To(ContainSubstring("registry.k8s.io/node-problem-detector/node-problem-detector:v0.8.10 node-problem-detector /node-problem-detector"))
+	g.Expect(string(out)).To(ContainSubstring("--mount type=bind,src=/etc/node-problem-detector,dst=/etc/node-problem-detector,options=rbind:ro"))
+	g.Expect(string(out)).To(ContainSubstring("--config.system-log-monitor=/etc/node-problem-detector/system-log-monitor.json"))
+	g.Expect(string(out)).To(ContainSubstring(`
+-   path: /etc/node-problem-detector/system-log-monitor.json`))
+	g.Expect(string(out)).To(ContainSubstring(`
+  - "echo post"
+  - "systemctl daemon-reload"
+  - "systemctl enable --now node-problem-detector.service"`))
+}
+
+func TestNewNodeNodeProblemDetectorDefaultConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header: "test",
+			NodeProblemDetector: &bootstrapv1.NPDConfig{
+				Image: "registry.k8s.io/node-problem-detector/node-problem-detector:v0.8.10",
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring("--config.system-log-monitor=/config/kernel-monitor.json"))
+	g.Expect(string(out)).NotTo(ContainSubstring("src=/etc/node-problem-detector"))
+}
+
+func TestNewNodeRemountOptions(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"echo pre"},
+			RemountOptions: []bootstrapv1.RemountSpec{
+				{Path: "/", Options: []string{"nosuid", "nodev"}},
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+-   path: /etc/systemd/system/remount-.service
+    owner: root:root
+    permissions: '0644'
+    content: |
+      [Unit]
+      Description=Remount / with nosuid,nodev
+      DefaultDependencies=no
+      After=local-fs.target`))
+	g.Expect(string(out)).To(ContainSubstring(`
+      [Service]
+      Type=oneshot
+      RemainAfterExit=true
+      ExecStart=/bin/mount -o remount,nosuid,nodev /`))
+	g.Expect(string(out)).To(ContainSubstring(`
+      [Install]
+      WantedBy=multi-user.target`))
+	g.Expect(string(out)).To(ContainSubstring(`
+runcmd:
+  - "systemctl daemon-reload"
+  - "systemctl enable --now remount-.service"
+  - "echo pre"`))
+}
+
+func TestNewNodeWaitForMounts(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"echo pre"},
+			SandboxImage:       pointer.StringPtr("registry.example.com/pause:3.5"),
+			DiskSetup: &bootstrapv1.DiskSetup{
+				Filesystems: []bootstrapv1.Filesystem{
+					{Device: "/dev/disk/azure/scsi1/lun0", Filesystem: "ext4", Label: "etcd_disk"},
+					{Device: "/dev/disk/azure/scsi1/lun1", Filesystem: "swap", Label: "swap_disk"},
+				},
+			},
+			Mounts: []bootstrapv1.MountPoints{
+				{"LABEL=etcd_disk", "/var/lib/etcd"},
+				{"LABEL=swap_disk", "none", "swap", "sw"},
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	// The mounts are waited for before any other command, including the restart of containerd.
+	g.Expect(string(out)).To(ContainSubstring(`
+runcmd:
+  - "timeout 300s sh -c 'until mountpoint -q /var/lib/etcd; do sleep 1; done'"
+  - "systemctl restart containerd"
+  - "echo pre"`))
+	g.Expect(string(out)).NotTo(ContainSubstring("mountpoint -q none"))
+}
+
+func TestNewNodeWaitForMountsWithoutDiskSetup(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"echo pre"},
+			Mounts: []bootstrapv1.MountPoints{
+				{"/dev/sdb1", "/var/lib/etcd"},
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).NotTo(ContainSubstring("mountpoint -q"))
+}
+
+func TestNewNodePackages(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:   "test",
+			Packages: []string{"nfs-common", "open-iscsi"},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+packages:
+  - nfs-common
+  - open-iscsi`))
+
+	nodeinput.Packages = nil
+	out, err = NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).NotTo(ContainSubstring("packages:"))
+}
+
+func TestNewNodeIgnorePreflightErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:                "test",
+			KubeadmVerbosity:      "--v 5",
+			IgnorePreflightErrors: []string{"Swap", "NumCPU"},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring("kubeadm join --config /run/kubeadm/kubeadm-join-config.yaml --v 5 --ignore-preflight-errors=Swap,NumCPU && "))
+
+	nodeinput = &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:                "test",
+			UseExperimentalRetry:  true,
+			IgnorePreflightErrors: []string{"Swap", "NumCPU"},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+	out, err = NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring("kubeadm join phase preflight --ignore-preflight-errors=DirAvailable--etc-kubernetes-manifests,Swap,NumCPU\n"))
+
+	nodeinput = &NodeInput{
+		BaseUserData: BaseUserData{
+			Header: "test",
+		},
+		JoinConfiguration: "my-join-config",
+	}
+	out, err = NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).NotTo(ContainSubstring("--ignore-preflight-errors"))
+}
+
+func TestNewInitControlPlaneIgnorePreflightErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	cpinput := &ControlPlaneInput{
+		BaseUserData: BaseUserData{
+			Header:                "test",
+			KubeadmVerbosity:      "--v 5",
+			IgnorePreflightErrors: []string{"Swap", "NumCPU"},
+		},
+		Certificates:         secret.Certificates{},
+		ClusterConfiguration: "my-cluster-config",
+		InitConfiguration:    "my-init-config",
+	}
+
+	out, err := NewInitControlPlane(cpinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring("'kubeadm init --config /run/kubeadm/kubeadm.yaml --v 5 --ignore-preflight-errors=Swap,NumCPU && "))
+}
+
+func TestNewNodeNetworkConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"echo pre"},
+			NetworkConfig: []bootstrapv1.NetworkInterface{
+				{
+					Name:      "eth0",
+					Addresses: []string{"192.168.1.10/24", "fd00::10/64"},
+					Gateway:   "192.168.1.1",
+					DNS:       []string{"192.168.1.2", "192.168.1.3"},
+				},
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`-   path: /etc/systemd/network/10-eth0.network
+    owner: root:root
+    permissions: '0644'
+    content: |
+      [Match]
+      Name=eth0`))
+	g.Expect(string(out)).To(ContainSubstring(`
+      [Network]
+      Address=192.168.1.10/24
+      Address=fd00::10/64
+      Gateway=192.168.1.1
+      DNS=192.168.1.2
+      DNS=192.168.1.3`))
+	g.Expect(string(out)).To(ContainSubstring(`runcmd:
+  - "systemctl restart systemd-networkd"
+  - "echo pre"`))
+}
+
+func TestNewNodeNetworkConfigMultipleInterfaces(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header: "test",
+			NetworkConfig: []bootstrapv1.NetworkInterface{
+				{
+					Name:      "eth0",
+					Addresses: []string{"192.168.1.10/24"},
+					Gateway:   "192.168.1.1",
+				},
+				{
+					Name:      "eth1",
+					Addresses: []string{"10.10.0.10/24"},
+					MTU:       pointer.Int32Ptr(9000),
+					Routes: []bootstrapv1.NetworkRoute{
+						{To: "10.20.0.0/16", Via: "10.10.0.1", Metric: pointer.Int32Ptr(100)},
+						{To: "10.30.0.0/16"},
+					},
+				},
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`-   path: /etc/systemd/network/10-eth0.network
+    owner: root:root
+    permissions: '0644'
+    content: |
+      [Match]
+      Name=eth0`))
+	g.Expect(string(out)).To(ContainSubstring(`
+      [Network]
+      Address=192.168.1.10/24
+      Gateway=192.168.1.1`))
+	g.Expect(string(out)).To(ContainSubstring(`-   path: /etc/systemd/network/10-eth1.network
+    owner: root:root
+    permissions: '0644'
+    content: |
+      [Match]
+      Name=eth1`))
+	g.Expect(string(out)).To(ContainSubstring(`
+      [Link]
+      MTUBytes=9000`))
+	g.Expect(string(out)).To(ContainSubstring(`
+      [Network]
+      Address=10.10.0.10/24`))
+	g.Expect(string(out)).To(ContainSubstring(`
+      [Route]
+      Destination=10.20.0.0/16
+      Gateway=10.10.0.1
+      Metric=100`))
+	g.Expect(string(out)).To(ContainSubstring(`
+      [Route]
+      Destination=10.30.0.0/16`))
+	g.Expect(string(out)).To(ContainSubstring(`runcmd:
+  - "systemctl restart systemd-networkd"`))
+}
+
+func TestNewNodeNodeLocalDNS(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"echo pre"},
+			NetworkConfig: []bootstrapv1.NetworkInterface{
+				{
+					Name:      "eth0",
+					Addresses: []string{"192.168.1.10/24"},
+				},
+			},
+			NodeLocalDNS: &bootstrapv1.NodeLocalDNSConfig{
+				Address: "169.254.20.10",
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`-   path: /etc/systemd/resolved.conf.d/node-local-dns.conf
+    owner: root:root
+    permissions: '0644'
+    content: |
+      [Resolve]
+      DNS=169.254.20.10
+      Domains=~.`))
+	g.Expect(string(out)).To(ContainSubstring(`runcmd:
+  - "systemctl restart systemd-networkd"
+  - "systemctl restart systemd-resolved"
+  - "echo pre"`))
+}
+
+func TestNewNodeUserHomeDir(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"echo pre"},
+			Users: []bootstrapv1.User{
+				{
+					Name:    "admin",
+					HomeDir: pointer.StringPtr("/home/admin"),
+					Shell:   pointer.StringPtr("/bin/bash"),
+				},
+				{
+					Name: "nohome",
+				},
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+  - name: admin
+    homedir: /home/admin
+    shell: /bin/bash`))
+	g.Expect(string(out)).To(ContainSubstring(`runcmd:
+  - "mkdir -p /home/admin && chown admin: /home/admin && chmod 0700 /home/admin"
+  - "echo pre"`))
+	g.Expect(string(out)).NotTo(ContainSubstring("nohome:"))
+}
+
+func TestNewNodeSystemdTimers(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:              "test",
+			PostKubeadmCommands: []string{"echo post"},
+			SystemdTimers: []bootstrapv1.SystemdTimer{
+				{
+					Name:     "cert-check",
+					Schedule: "Mon..Fri *-*-* 03:00:00",
+					Command:  "kubeadm certs check-expiration > /var/log/cert-check.log",
+				},
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`-   path: /etc/systemd/system/cert-check.service
+    owner: root:root
+    permissions: '0644'
+    content: |
+      [Unit]
+      Description=cert-check`))
+	g.Expect(string(out)).To(ContainSubstring(`
+      [Service]
+      Type=oneshot
+      ExecStart=/bin/sh -c "kubeadm certs check-expiration > /var/log/cert-check.log"`))
+	g.Expect(string(out)).To(ContainSubstring(`-   path: /etc/systemd/system/cert-check.timer
+    owner: root:root
+    permissions: '0644'
+    content: |
+      [Unit]
+      Description=Run cert-check periodically`))
+	g.Expect(string(out)).To(ContainSubstring(`
+      [Timer]
+      OnCalendar=Mon..Fri *-*-* 03:00:00
+      Persistent=true`))
+	g.Expect(string(out)).To(ContainSubstring(`
+  - "echo post"
+  - "systemctl daemon-reload"
+  - "systemctl enable --now cert-check.timer"`))
+}
+
+func TestNewNodePublishConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:              "test",
+			PostKubeadmCommands: []string{"echo post"},
+			PublishConfigTo: &bootstrapv1.PublishTarget{
+				ConfigMapName: "bootstrap-config",
+				Namespace:     "kube-system",
+			},
+		},
+		JoinConfiguration: `apiVersion: kubeadm.k8s.io/v1beta2
+discovery:
+  bootstrapToken:
+    apiServerEndpoint: 10.0.0.1:6443
+    token: abcdef.0123456789abcdef
+  tlsBootstrapToken: abcdef.0123456789abcdef
+kind: JoinConfiguration`,
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`-   path: /run/cluster-api/kubeadm-config-summary.yaml
+    owner: root:root
+    permissions: '0640'
+    content: |
+      ---
+      apiVersion: kubeadm.k8s.io/v1beta2
+      discovery:
+        bootstrapToken:
+          apiServerEndpoint: 10.0.0.1:6443
+          token: REDACTED
+        tlsBootstrapToken: REDACTED
+      kind: JoinConfiguration`))
+	g.Expect(string(out)).To(ContainSubstring(`
+  - "echo post"
+  - "kubectl --kubeconfig /etc/kubernetes/kubelet.conf create configmap bootstrap-config --namespace kube-system --from-file=\"$(hostname | tr A-Z a-z)\"=/run/cluster-api/kubeadm-config-summary.yaml --dry-run=client -o yaml | kubectl --kubeconfig /etc/kubernetes/kubelet.conf apply --server-side`))
+	// The join configuration itself is not redacted.
+	g.Expect(string(out)).To(ContainSubstring("token: abcdef.0123456789abcdef"))
+}
+
+func TestNewInitControlPlanePublishConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	cpinput := &ControlPlaneInput{
+		BaseUserData: BaseUserData{
+			Header: "test",
+			PublishConfigTo: &bootstrapv1.PublishTarget{
+				ConfigMapName: "control-plane-config",
+				Namespace:     "audit",
+			},
+		},
+		Certificates:         secret.Certificates{},
+		ClusterConfiguration: "my-cluster-config",
+		InitConfiguration: `bootstrapTokens:
+- token: abcdef.0123456789abcdef
+kind: InitConfiguration`,
+	}
+
+	out, err := NewInitControlPlane(cpinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+      ---
+      my-cluster-config
+      ---
+      bootstrapTokens:
+      - token: REDACTED
+      kind: InitConfiguration`))
+	g.Expect(string(out)).To(ContainSubstring(`  - "kubectl --kubeconfig /etc/kubernetes/admin.conf create configmap control-plane-config --namespace audit `))
+}
+
+func TestNewNodeNodeLabels(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:              "test",
+			PostKubeadmCommands: []string{"echo post"},
+			NodeLabels:          "node.cluster.x-k8s.io/pool=blue, example.com/gpu=,example.com/zone=a",
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`
+  - "echo post"
+  - "timeout 300s sh -c 'until kubectl --kubeconfig /etc/kubernetes/kubelet.conf label node \"$(hostname | tr A-Z a-z)\" --overwrite example.com/gpu= example.com/zone=a node.cluster.x-k8s.io/pool=blue; do sleep 5; done'"`))
+
+	// The labels are applied with --overwrite, and in the same order whatever the order of the kubelet arg,
+	// so that the command can be run again and renders the same user data across reconciles.
+	nodeinput = &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:              "test",
+			PostKubeadmCommands: []string{"echo post"},
+			NodeLabels:          "example.com/zone=a,node.cluster.x-k8s.io/pool=blue,example.com/gpu=",
+		},
+		JoinConfiguration: "my-join-config",
+	}
+	again, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(again).To(Equal(out))
+}
+
+func TestNewInitControlPlaneNodeLabels(t *testing.T) {
+	g := NewWithT(t)
+
+	cpinput := &ControlPlaneInput{
+		BaseUserData: BaseUserData{
+			Header:     "test",
+			NodeLabels: "node.cluster.x-k8s.io/pool=control-plane",
+		},
+		Certificates:         secret.Certificates{},
+		ClusterConfiguration: "my-cluster-config",
+		InitConfiguration:    "my-init-config",
+	}
+
+	out, err := NewInitControlPlane(cpinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`kubectl --kubeconfig /etc/kubernetes/admin.conf label node \"$(hostname | tr A-Z a-z)\" --overwrite node.cluster.x-k8s.io/pool=control-plane;`))
+}
+
+func TestNewJoinControlPlaneNodeLabels(t *testing.T) {
+	g := NewWithT(t)
+
+	cpinput := &ControlPlaneJoinInput{
+		BaseUserData: BaseUserData{
+			Header:     "test",
+			NodeLabels: "node.cluster.x-k8s.io/pool=control-plane",
+		},
+		Certificates:      secret.Certificates{},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewJoinControlPlane(cpinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`kubectl --kubeconfig /etc/kubernetes/admin.conf label node \"$(hostname | tr A-Z a-z)\" --overwrite node.cluster.x-k8s.io/pool=control-plane;`))
+}
+
+func TestNewNodeNodeLabelsWithNodeName(t *testing.T) {
+	g := NewWithT(t)
+
+	// The node registration name is used rather than the hostname, rendered by cloud-init when it is a template.
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:     "test",
+			NodeLabels: "node.cluster.x-k8s.io/pool=blue",
+			NodeName:   "{{ ds.meta_data.local_hostname }}",
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`label node \"{{ ds.meta_data.local_hostname }}\" --overwrite node.cluster.x-k8s.io/pool=blue;`))
+	g.Expect(string(out)).NotTo(ContainSubstring("hostname | tr"))
+}
+
+func TestNewNodeWithoutNodeLabels(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header: "test",
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).NotTo(ContainSubstring("label node"))
+}
+
+func TestNewNodeUploadBootLogs(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:              "test",
+			PostKubeadmCommands: []string{"echo post"},
+			UploadBootLogsTo: &bootstrapv1.LogSink{
+				URL: "https://logs.example.com/boot/",
+				CredentialsFrom: &bootstrapv1.FileSource{
+					Secret: bootstrapv1.SecretFileSource{Name: "boot-logs", Key: "headers"},
+				},
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`-   path: /usr/local/bin/upload-boot-logs
+    owner: root:root
+    permissions: '0700'`))
+	g.Expect(string(out)).To(ContainSubstring(`if [ -f /run/cluster-api/bootstrap-success.complete ]; then`))
+	g.Expect(string(out)).To(ContainSubstring(`curl --fail --silent --show-error --retry 3 --header @/etc/cluster-api/boot-logs-credentials --upload-file "${logs}.tar.gz" 'https://logs.example.com/boot'"/$(hostname | tr A-Z a-z)-${outcome}.tar.gz"`))
+	// The upload runs last, after kubeadm and the post kubeadm commands, whether they succeeded or failed: cloud-init
+	// runs all the commands of runcmd, and kubeadm only writes the success sentinel read by the script if it succeeded.
+	g.Expect(string(out)).To(ContainSubstring(`
+  - kubeadm join --config /run/kubeadm/kubeadm-join-config.yaml  && echo success > /run/cluster-api/bootstrap-success.complete
+  - "echo post"
+  - "/usr/local/bin/upload-boot-logs"
+`))
+}
+
+func TestNewNodeUploadBootLogsWithoutCredentials(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:           "test",
+			UploadBootLogsTo: &bootstrapv1.LogSink{URL: "http://10.0.0.1:8080/logs"},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`curl --fail --silent --show-error --retry 3 --upload-file "${logs}.tar.gz" 'http://10.0.0.1:8080/logs'`))
+	g.Expect(string(out)).NotTo(ContainSubstring("boot-logs-credentials"))
+}
+
+func TestNewNodeStartupTaint(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:              "test",
+			PostKubeadmCommands: []string{"echo post"},
+			StartupTaint: &corev1.Taint{
+				Key:    "example.com/bootstrapping",
+				Value:  "true",
+				Effect: corev1.TaintEffectNoSchedule,
+			},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	// The taint is removed after the post kubeadm commands, once the node is Ready, with the dedicated credentials.
+	g.Expect(string(out)).To(ContainSubstring(`
+  - "echo post"
+  - "timeout 300s sh -c 'until kubectl --kubeconfig /etc/kubernetes/kubelet.conf get node \"$(hostname | tr A-Z a-z)\" -o jsonpath=\"{.status.conditions[?(@.type==\\\"Ready\\\")].status}\" | grep -q True; do sleep 5; done' && kubectl --kubeconfig /etc/kubernetes/startup-taint.conf taint node \"$(hostname | tr A-Z a-z)\" example.com/bootstrapping:NoSchedule-"`))
+}
+
+func TestNewInitControlPlaneStartupTaint(t *testing.T) {
+	g := NewWithT(t)
+
+	cpinput := &ControlPlaneInput{
+		BaseUserData: BaseUserData{
+			Header: "test",
+			StartupTaint: &corev1.Taint{
+				Key:    "example.com/bootstrapping",
+				Effect: corev1.TaintEffectNoExecute,
+			},
+		},
+		Certificates:         secret.Certificates{},
+		ClusterConfiguration: "my-cluster-config",
+		InitConfiguration:    "my-init-config",
+	}
+
+	out, err := NewInitControlPlane(cpinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(out)).To(ContainSubstring(`&& kubectl --kubeconfig /etc/kubernetes/admin.conf taint node \"$(hostname | tr A-Z a-z)\" example.com/bootstrapping:NoExecute-"`))
+}
+
+func TestNewNodeHostDNS(t *testing.T) {
+	resolvConf := `
+-   path: /etc/resolv.conf
+    owner: root:root
+    permissions: '0644'
+    content: |
+      search corp.example.com example.com
+      nameserver 10.0.0.2
+      nameserver 10.0.0.3`
+	resolvedConfig := `
+-   path: /etc/systemd/resolved.conf.d/host-dns.conf
+    owner: root:root
+    permissions: '0644'
+    content: |
+      [Resolve]
+      DNS=10.0.0.2 10.0.0.3
+      Domains=corp.example.com example.com`
+	resolvedRestart := `runcmd:
+  - "systemctl restart systemd-resolved"
+  - "echo pre"`
+	resolvConfFallback := `
+-   path: /etc/resolv.conf.host-dns
+    owner: root:root
+    permissions: '0644'
+    content: |
+      search corp.example.com example.com
+      nameserver 10.0.0.2
+      nameserver 10.0.0.3`
+	autoApply := `runcmd:
+  - "if systemctl is-active --quiet systemd-resolved; then systemctl restart systemd-resolved; else cp /etc/resolv.conf.host-dns /etc/resolv.conf; fi"
+  - "echo pre"`
+
+	cases := map[string]struct {
+		strategy     bootstrapv1.DNSManagement
+		expect       []string
+		expectAbsent []string
+	}{
+		"resolv.conf": {
+			strategy:     bootstrapv1.DNSManagementResolvConf,
+			expect:       []string{resolvConf},
+			expectAbsent: []string{resolvedConfig, "systemctl restart systemd-resolved"},
+		},
+		"systemd-resolved": {
+			strategy:     bootstrapv1.DNSManagementSystemdResolved,
+			expect:       []string{resolvedConfig, resolvedRestart},
+			expectAbsent: []string{resolvConf},
+		},
+		"auto picks systemd-resolved when running, resolv.conf otherwise": {
+			strategy:     bootstrapv1.DNSManagementAuto,
+			expect:       []string{resolvedConfig, resolvConfFallback, autoApply},
+			expectAbsent: []string{resolvConf, resolvedRestart},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			nodeinput := &NodeInput{
+				BaseUserData: BaseUserData{
+					Header:             "test",
+					PreKubeadmCommands: []string{"echo pre"},
+					HostDNS: &bootstrapv1.HostDNSConfig{
+						Nameservers: []string{"10.0.0.2", "10.0.0.3"},
+						Search:      []string{"corp.example.com", "example.com"},
+					},
+					DNSManagement: tc.strategy,
+				},
+				JoinConfiguration: "my-join-config",
+			}
+
+			out, err := NewNode(nodeinput)
+			g.Expect(err).NotTo(HaveOccurred())
+			for _, s := range tc.expect {
+				g.Expect(string(out)).To(ContainSubstring(s))
+			}
+			for _, s := range tc.expectAbsent {
+				g.Expect(string(out)).NotTo(ContainSubstring(s))
+			}
+		})
+	}
+}
+
+func TestNewNodeHostDNSWithNodeLocalDNS(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeinput := &NodeInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"echo pre"},
+			NodeLocalDNS:       &bootstrapv1.NodeLocalDNSConfig{Address: "169.254.20.10"},
+			HostDNS:            &bootstrapv1.HostDNSConfig{Search: []string{"corp.example.com"}},
+		},
+		JoinConfiguration: "my-join-config",
+	}
+
+	out, err := NewNode(nodeinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	// systemd-resolved is restarted once for both configurations.
+	g.Expect(strings.Count(string(out), "systemctl restart systemd-resolved")).To(Equal(1))
+	g.Expect(string(out)).To(ContainSubstring("\n      [Resolve]\n      Domains=corp.example.com"))
+}
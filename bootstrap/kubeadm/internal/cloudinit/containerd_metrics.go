@@ -0,0 +1,51 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	// containerdMetricsConfigPath is a containerd configuration drop-in, loaded like sandboxImageConfigPath.
+	containerdMetricsConfigPath        = "/etc/containerd/conf.d/metrics.toml"
+	containerdMetricsConfigOwner       = "root:root"
+	containerdMetricsConfigPermissions = "0644"
+
+	containerdMetricsConfig = `version = 2
+[metrics]
+  address = %q
+`
+)
+
+// addContainerdMetrics adds the containerd configuration drop-in exposing the metrics of containerd, and the command
+// applying it, if requested.
+func (input *BaseUserData) addContainerdMetrics() {
+	if input.ContainerdMetrics == nil {
+		return
+	}
+
+	input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+		Path:        containerdMetricsConfigPath,
+		Owner:       containerdMetricsConfigOwner,
+		Permissions: containerdMetricsConfigPermissions,
+		Content:     fmt.Sprintf(containerdMetricsConfig, input.ContainerdMetrics.Address),
+	})
+	input.addContainerdRestart()
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	// containerdPullConfigPath is a containerd configuration drop-in, loaded like sandboxImageConfigPath.
+	containerdPullConfigPath        = "/etc/containerd/conf.d/pull.toml"
+	containerdPullConfigOwner       = "root:root"
+	containerdPullConfigPermissions = "0644"
+)
+
+// addContainerdPull adds the containerd configuration drop-in tuning the image pulls of the CRI plugin, and the
+// command applying it, if requested.
+func (input *BaseUserData) addContainerdPull() {
+	if input.ContainerdPull == nil {
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("version = 2\n")
+	b.WriteString("[plugins.\"io.containerd.grpc.v1.cri\"]\n")
+	if input.ContainerdPull.MaxConcurrentDownloads != nil {
+		fmt.Fprintf(&b, "  max_concurrent_downloads = %d\n", *input.ContainerdPull.MaxConcurrentDownloads)
+	}
+	if input.ContainerdPull.PullTimeout != nil {
+		fmt.Fprintf(&b, "  image_pull_progress_timeout = %q\n", input.ContainerdPull.PullTimeout.Duration.String())
+	}
+	input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+		Path:        containerdPullConfigPath,
+		Owner:       containerdPullConfigOwner,
+		Permissions: containerdPullConfigPermissions,
+		Content:     b.String(),
+	})
+	input.addContainerdRestart()
+}
@@ -37,13 +37,17 @@ const (
     content: "This placeholder file is used to create the /run/cluster-api sub directory in a way that is compatible with both Linux and Windows (mkdir -p /run/cluster-api does not work with Windows)"
 runcmd:
 {{- template "commands" .PreKubeadmCommands }}
-  - 'kubeadm init --config /run/kubeadm/kubeadm.yaml {{.KubeadmVerbosity}} && {{ .SentinelFileCommand }}'
+  - 'kubeadm init --config /run/kubeadm/kubeadm.yaml {{.KubeadmVerbosity}}{{ with .KubeadmIgnorePreflightErrors }} {{ . }}{{ end }} && {{ .SentinelFileCommand }}'
 {{- template "commands" .PostKubeadmCommands }}
 {{- template "ntp" .NTP }}
+{{- template "timezone" .Timezone }}
 {{- template "users" .Users }}
+{{- template "partitions" .DiskSetup}}
 {{- template "disk_setup" .DiskSetup}}
 {{- template "fs_setup" .DiskSetup}}
 {{- template "mounts" .Mounts}}
+{{- template "grow_root" .GrowRootFilesystem}}
+{{- template "packages" .Packages}}
 `
 )
 
@@ -58,9 +62,16 @@ type ControlPlaneInput struct {
 
 // NewInitControlPlane returns the user data string to be used on a controlplane instance.
 func NewInitControlPlane(input *ControlPlaneInput) ([]byte, error) {
+	if err := input.checkDiskWipes(); err != nil {
+		return nil, err
+	}
 	input.Header = cloudConfigHeader
 	input.WriteFiles = input.Certificates.AsFiles()
 	input.WriteFiles = append(input.WriteFiles, input.AdditionalFiles...)
+	input.addFeatures(adminKubeconfigPath)
+	input.addStartupTaint(adminKubeconfigPath)
+	input.addPublishConfig(adminKubeconfigPath, input.ClusterConfiguration, input.InitConfiguration)
+	input.addUploadBootLogs()
 	input.SentinelFileCommand = sentinelFileCommand
 	userData, err := generate("InitControlplane", controlPlaneCloudInit, input)
 	if err != nil {
@@ -38,10 +38,14 @@ runcmd:
   - {{ .KubeadmCommand }} && {{ .SentinelFileCommand }}
 {{- template "commands" .PostKubeadmCommands }}
 {{- template "ntp" .NTP }}
+{{- template "timezone" .Timezone }}
 {{- template "users" .Users }}
+{{- template "partitions" .DiskSetup}}
 {{- template "disk_setup" .DiskSetup}}
 {{- template "fs_setup" .DiskSetup}}
 {{- template "mounts" .Mounts}}
+{{- template "grow_root" .GrowRootFilesystem}}
+{{- template "packages" .Packages}}
 `
 )
 
@@ -61,6 +65,9 @@ func NewJoinControlPlane(input *ControlPlaneJoinInput) ([]byte, error) {
 	if err := input.prepare(); err != nil {
 		return nil, err
 	}
+	input.addStartupTaint(adminKubeconfigPath)
+	input.addPublishConfig(adminKubeconfigPath, input.JoinConfiguration)
+	input.addUploadBootLogs()
 	userData, err := generate("JoinControlplane", controlPlaneJoinCloudInit, input)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to generate user data for machine joining control plane")
@@ -0,0 +1,63 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	crictlConfigPath        = "/etc/crictl.yaml"
+	crictlConfigOwner       = "root:root"
+	crictlConfigPermissions = "0644"
+
+	// defaultCRISocket is used when the node registration does not set a CRISocket.
+	defaultCRISocket = "/var/run/containerd/containerd.sock"
+
+	crictlConfig = `runtime-endpoint: %[1]s
+image-endpoint: %[1]s
+`
+)
+
+// addCrictlConfig adds the crictl configuration pointing at the node's runtime socket, if requested.
+func (input *BaseUserData) addCrictlConfig() {
+	if !input.InstallCrictlConfig {
+		return
+	}
+
+	input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+		Path:        crictlConfigPath,
+		Owner:       crictlConfigOwner,
+		Permissions: crictlConfigPermissions,
+		Content:     fmt.Sprintf(crictlConfig, crictlEndpoint(input.CRISocket)),
+	})
+}
+
+// crictlEndpoint returns the endpoint crictl should use for the given CRI socket;
+// kubeadm accepts plain socket paths, while crictl requires a URL.
+func crictlEndpoint(criSocket string) string {
+	if criSocket == "" {
+		criSocket = defaultCRISocket
+	}
+	if strings.Contains(criSocket, "://") {
+		return criSocket
+	}
+	return "unix://" + criSocket
+}
@@ -16,10 +16,19 @@ limitations under the License.
 
 package cloudinit
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
 const (
 	diskSetupTemplate = `{{ define "disk_setup" -}}
-{{- if . }}
-disk_setup:{{ range .Partitions }}
+{{- if . }}{{ with LayoutPartitions .Partitions }}
+disk_setup:{{ range . }}
   {{ .Device }}:
     {{- if .TableType }}
     table_type: {{ .TableType }}
@@ -31,5 +40,72 @@ disk_setup:{{ range .Partitions }}
 {{- end -}}
 {{- end -}}
 {{- end -}}
+{{- end -}}
 `
+
+	// partitionsTemplate creates the explicitly numbered partitions in bootcmd, which cloud-init
+	// runs before disk_setup and fs_setup, so that file systems can be created on them.
+	partitionsTemplate = `{{ define "partitions" -}}
+{{- if . }}{{ with PartitionCommands .Partitions }}
+bootcmd:{{ range . }}
+  - {{ printf "%q" . }}
+{{- end -}}
+{{- end -}}
+{{- end -}}
+{{- end -}}
+`
+
+	// partitionCommand creates a GPT partition once per instance, starting at the first free sector.
+	partitionCommand = "cloud-init-per once partition-%s-%d sgdisk --new=%d:0:%s %s"
 )
+
+// checkDiskWipes refuses to render the disk setup if it overwrites the partitions or filesystems of a device
+// without confirmation, e.g. for configs created before the KubeadmConfig webhook rejected them.
+func (input *BaseUserData) checkDiskWipes() error {
+	if input.DiskSetup == nil {
+		return nil
+	}
+	if devices := input.DiskSetup.UnconfirmedWipes(); len(devices) > 0 {
+		return errors.Errorf("refusing to overwrite the partitions or filesystems of %s without confirmWipe", strings.Join(devices, ", "))
+	}
+	return nil
+}
+
+// layoutPartitions returns the partitions which are laid out by cloud-init's disk_setup,
+// i.e. the ones without an explicit partition number.
+func layoutPartitions(partitions []bootstrapv1.Partition) []bootstrapv1.Partition {
+	var res []bootstrapv1.Partition
+	for _, p := range partitions {
+		if p.Number == nil {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// partitionCommands returns the commands creating the explicitly numbered partitions.
+// Partitions are created in number order, so that a partition growing to fill the device
+// is created after the ones preceding it.
+func partitionCommands(partitions []bootstrapv1.Partition) []string {
+	var numbered []bootstrapv1.Partition
+	for _, p := range partitions {
+		if p.Number != nil {
+			numbered = append(numbered, p)
+		}
+	}
+	sort.SliceStable(numbered, func(i, j int) bool {
+		return *numbered[i].Number < *numbered[j].Number
+	})
+
+	commands := make([]string, 0, len(numbered))
+	for _, p := range numbered {
+		// An end of 0 makes sgdisk use the largest available block, i.e. fill the remaining space.
+		end := "0"
+		if p.SizeMiB != nil && *p.SizeMiB > 0 {
+			end = fmt.Sprintf("+%dM", *p.SizeMiB)
+		}
+		name := strings.ReplaceAll(strings.TrimPrefix(p.Device, "/dev/"), "/", "-")
+		commands = append(commands, fmt.Sprintf(partitionCommand, name, *p.Number, *p.Number, end, p.Device))
+	}
+	return commands
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	fipsModeScriptPath        = "/usr/local/bin/enable-fips-mode.sh"
+	fipsModeScriptOwner       = "root:root"
+	fipsModeScriptPermissions = "0700"
+
+	// fipsModeScript enables FIPS mode with fips-mode-setup where available, e.g. on RHEL and its derivatives,
+	// which also switches the system crypto policies to FIPS; it otherwise sets the fips=1 kernel arg with
+	// grubby. Both only take effect from the next boot, so the script does nothing on machines already in
+	// FIPS mode, e.g. booted from a FIPS image.
+	fipsModeScript = `#!/bin/sh
+set -e
+if [ "$(cat /proc/sys/crypto/fips_enabled 2>/dev/null)" = "1" ]; then
+  exit 0
+fi
+if command -v fips-mode-setup >/dev/null 2>&1; then
+  fips-mode-setup --enable
+elif command -v grubby >/dev/null 2>&1; then
+  grubby --update-kernel=ALL --args=fips=1
+else
+  echo "enabling FIPS mode is not supported on this operating system" >&2
+fi
+`
+)
+
+// addFIPSMode adds the script enabling FIPS mode from the next boot, and the command running it, if requested.
+// Operating systems on which FIPS mode can't be enabled this way only log a warning.
+func (input *BaseUserData) addFIPSMode() {
+	if !input.FIPSMode {
+		return
+	}
+
+	input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+		Path:        fipsModeScriptPath,
+		Owner:       fipsModeScriptOwner,
+		Permissions: fipsModeScriptPermissions,
+		Content:     fipsModeScript,
+	})
+	input.PreKubeadmCommands = append(input.PreKubeadmCommands, fipsModeScriptPath)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	gracefulShutdownUnitName        = "kubelet-drain-on-shutdown.service"
+	gracefulShutdownUnitPath        = "/etc/systemd/system/" + gracefulShutdownUnitName
+	gracefulShutdownUnitOwner       = "root:root"
+	gracefulShutdownUnitPermissions = "0644"
+	gracefulShutdownEnableCommand   = "systemctl daemon-reload && systemctl enable --now " + gracefulShutdownUnitName
+
+	// gracefulShutdownUnit is ordered after the kubelet so that, on shutdown, it is stopped
+	// (and therefore drains the node) before the kubelet goes away.
+	gracefulShutdownUnit = `[Unit]
+Description=Drain the Kubernetes node before shutdown
+Wants=kubelet.service
+After=kubelet.service
+
+[Service]
+Type=oneshot
+RemainAfterExit=true
+ExecStart=/bin/true
+ExecStop=/bin/sh -c 'kubectl --kubeconfig /etc/kubernetes/kubelet.conf drain "%s" --ignore-daemonsets --delete-emptydir-data --force --timeout=%ds || true'
+TimeoutStopSec=%d
+
+[Install]
+WantedBy=multi-user.target
+`
+)
+
+// systemdEscaper escapes the specifiers and the variables systemd expands in the command lines of units.
+var systemdEscaper = strings.NewReplacer("%", "%%", "$", "$$")
+
+// addGracefulShutdownInhibitor adds the systemd unit draining the node before shutdown,
+// and the command enabling it, if requested by the graceful shutdown configuration.
+func (input *BaseUserData) addGracefulShutdownInhibitor() {
+	if input.GracefulShutdown == nil || !input.GracefulShutdown.InhibitorUnit {
+		return
+	}
+
+	timeout := int(input.GracefulShutdown.Timeout.Seconds())
+	input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+		Path:        gracefulShutdownUnitPath,
+		Owner:       gracefulShutdownUnitOwner,
+		Permissions: gracefulShutdownUnitPermissions,
+		Content:     fmt.Sprintf(gracefulShutdownUnit, systemdEscaper.Replace(input.nodeName()), timeout, timeout),
+	})
+
+	// The unit is enabled once kubeadm has run, so the kubelet kubeconfig it relies on exists.
+	postKubeadmCommands := make([]string, 0, len(input.PostKubeadmCommands)+1)
+	postKubeadmCommands = append(postKubeadmCommands, gracefulShutdownEnableCommand)
+	input.PostKubeadmCommands = append(postKubeadmCommands, input.PostKubeadmCommands...)
+}
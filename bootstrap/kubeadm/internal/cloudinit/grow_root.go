@@ -0,0 +1,32 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+const (
+	// growRootTemplate grows the partition holding the root filesystem to the size of its disk with growpart,
+	// then grows the filesystem itself; cloud-init picks resize2fs or xfs_growfs depending on its type.
+	growRootTemplate = `{{ define "grow_root" -}}
+{{- if . }}
+growpart:
+  mode: auto
+  devices:
+    - /
+resize_rootfs: true
+{{- end -}}
+{{- end -}}
+`
+)
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	resolvConfPath            = "/etc/resolv.conf"
+	hostDNSResolvConfPath     = "/etc/resolv.conf.host-dns"
+	hostDNSResolvedConfigPath = "/etc/systemd/resolved.conf.d/host-dns.conf"
+	hostDNSConfigOwner        = "root:root"
+	hostDNSConfigPermissions  = "0644"
+
+	// hostDNSAutoCommand applies the DNS configuration as told by the Auto strategy: with the systemd-resolved drop-in
+	// on hosts running systemd-resolved, by replacing /etc/resolv.conf otherwise.
+	hostDNSAutoCommand = "if systemctl is-active --quiet systemd-resolved; then " + resolvedRestartCommand + "; else cp " + hostDNSResolvConfPath + " " + resolvConfPath + "; fi"
+)
+
+// addHostDNS writes the DNS configuration of the host, either to /etc/resolv.conf or as a systemd-resolved drop-in
+// applied by restarting systemd-resolved, as told by the DNS management strategy, if requested. Auto writes both, and
+// picks one when the machine boots depending on whether systemd-resolved is running.
+func (input *BaseUserData) addHostDNS() {
+	if input.HostDNS == nil {
+		return
+	}
+
+	switch input.DNSManagement {
+	case bootstrapv1.DNSManagementResolvConf:
+		input.WriteFiles = append(input.WriteFiles, hostDNSResolvConf(resolvConfPath, input.HostDNS))
+	case bootstrapv1.DNSManagementSystemdResolved:
+		input.addHostDNSResolvedConfig(resolvedRestartCommand)
+	default:
+		// The node-local DNS cache requires systemd-resolved, which it already restarts.
+		if input.NodeLocalDNS != nil {
+			input.addHostDNSResolvedConfig(resolvedRestartCommand)
+			return
+		}
+		input.WriteFiles = append(input.WriteFiles, hostDNSResolvConf(hostDNSResolvConfPath, input.HostDNS))
+		input.addHostDNSResolvedConfig(hostDNSAutoCommand)
+	}
+}
+
+// hostDNSResolvConf returns the file with the given path holding the DNS configuration in the resolv.conf format.
+func hostDNSResolvConf(path string, hostDNS *bootstrapv1.HostDNSConfig) bootstrapv1.File {
+	var b strings.Builder
+	if len(hostDNS.Search) > 0 {
+		fmt.Fprintf(&b, "search %s\n", strings.Join(hostDNS.Search, " "))
+	}
+	for _, nameserver := range hostDNS.Nameservers {
+		fmt.Fprintf(&b, "nameserver %s\n", nameserver)
+	}
+	return bootstrapv1.File{
+		Path:        path,
+		Owner:       hostDNSConfigOwner,
+		Permissions: hostDNSConfigPermissions,
+		Content:     b.String(),
+	}
+}
+
+// addHostDNSResolvedConfig writes the DNS configuration as a systemd-resolved drop-in, applied by the given command.
+func (input *BaseUserData) addHostDNSResolvedConfig(applyCommand string) {
+	var b strings.Builder
+	b.WriteString("[Resolve]\n")
+	if len(input.HostDNS.Nameservers) > 0 {
+		fmt.Fprintf(&b, "DNS=%s\n", strings.Join(input.HostDNS.Nameservers, " "))
+	}
+	if len(input.HostDNS.Search) > 0 {
+		fmt.Fprintf(&b, "Domains=%s\n", strings.Join(input.HostDNS.Search, " "))
+	}
+	input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+		Path:        hostDNSResolvedConfigPath,
+		Owner:       hostDNSConfigOwner,
+		Permissions: hostDNSConfigPermissions,
+		Content:     b.String(),
+	})
+
+	// Names are resolved with the configuration before the other commands, which may need to resolve names;
+	// systemd-resolved is restarted once, also for the node-local DNS cache.
+	for _, command := range input.PreKubeadmCommands {
+		if command == resolvedRestartCommand {
+			return
+		}
+	}
+	input.PreKubeadmCommands = append([]string{applyCommand}, input.PreKubeadmCommands...)
+}
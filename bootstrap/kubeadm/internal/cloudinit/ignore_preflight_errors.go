@@ -0,0 +1,34 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"strings"
+)
+
+const ignorePreflightErrorsFlag = "--ignore-preflight-errors=%s"
+
+// addIgnorePreflightErrors sets the kubeadm flag ignoring the errors of the requested preflight checks, if any.
+// The retriable join script does not use the flag, and appends the checks to the ones it already ignores.
+func (input *BaseUserData) addIgnorePreflightErrors() {
+	if len(input.IgnorePreflightErrors) == 0 {
+		return
+	}
+
+	input.KubeadmIgnorePreflightErrors = fmt.Sprintf(ignorePreflightErrorsFlag, strings.Join(input.IgnorePreflightErrors, ","))
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	defaultKdumpPath       = "/var/crash"
+	kdumpConfigPath        = "/etc/kdump.conf"
+	kdumpConfigOwner       = "root:root"
+	kdumpConfigPermissions = "0644"
+
+	kdumpScriptPath        = "/usr/local/bin/enable-kdump.sh"
+	kdumpScriptOwner       = "root:root"
+	kdumpScriptPermissions = "0700"
+
+	// kdumpConfig is the configuration of the kdump service of RHEL and its derivatives, saving the dumps
+	// in the given path, excluding the pages of the memory not useful for diagnostics.
+	kdumpConfig = `path %s
+core_collector makedumpfile -l --message-level 1 -d 31
+`
+
+	// kdumpScript sets the crashkernel kernel arg, given as first argument, with grubby where available, e.g. on
+	// RHEL and its derivatives, or otherwise with a GRUB drop-in, e.g. on Ubuntu. It then points kdump-tools,
+	// if installed, to the path of the dumps, given as second argument, and enables the kdump service. The
+	// memory of the crash kernel is only reserved from the next boot.
+	kdumpScript = `#!/bin/sh
+set -e
+mkdir -p "$2"
+if command -v grubby >/dev/null 2>&1; then
+  grubby --update-kernel=ALL --args="crashkernel=$1"
+elif command -v update-grub >/dev/null 2>&1; then
+  mkdir -p /etc/default/grub.d
+  echo "GRUB_CMDLINE_LINUX_DEFAULT=\"\$GRUB_CMDLINE_LINUX_DEFAULT crashkernel=$1\"" > /etc/default/grub.d/kdump.cfg
+  update-grub
+else
+  echo "setting the crashkernel kernel arg is not supported on this operating system" >&2
+fi
+if [ -f /etc/default/kdump-tools ]; then
+  sed -i '/^#*KDUMP_COREDIR=/d' /etc/default/kdump-tools
+  echo "KDUMP_COREDIR=\"$2\"" >> /etc/default/kdump-tools
+fi
+for unit in kdump.service kdump-tools.service; do
+  if systemctl cat "$unit" >/dev/null 2>&1; then
+    systemctl enable "$unit"
+  fi
+done
+`
+)
+
+// addKdump adds the kdump configuration, the script setting the crashkernel kernel arg and enabling the
+// kdump service, and the command running it, if requested.
+func (input *BaseUserData) addKdump() {
+	if input.Kdump == nil {
+		return
+	}
+
+	path := input.Kdump.Path
+	if path == "" {
+		path = defaultKdumpPath
+	}
+
+	input.WriteFiles = append(input.WriteFiles,
+		bootstrapv1.File{
+			Path:        kdumpConfigPath,
+			Owner:       kdumpConfigOwner,
+			Permissions: kdumpConfigPermissions,
+			Content:     fmt.Sprintf(kdumpConfig, path),
+		},
+		bootstrapv1.File{
+			Path:        kdumpScriptPath,
+			Owner:       kdumpScriptOwner,
+			Permissions: kdumpScriptPermissions,
+			Content:     kdumpScript,
+		},
+	)
+	input.PreKubeadmCommands = append(input.PreKubeadmCommands, fmt.Sprintf("%s %s %s", kdumpScriptPath, input.Kdump.CrashKernel, path))
+}
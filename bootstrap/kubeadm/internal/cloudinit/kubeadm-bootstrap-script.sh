@@ -120,7 +120,7 @@ function try-or-die-command() {
 }
 # {{ end }}
 
-retry-command kubeadm join phase preflight --ignore-preflight-errors=DirAvailable--etc-kubernetes-manifests
+retry-command kubeadm join phase preflight --ignore-preflight-errors=DirAvailable--etc-kubernetes-manifests{{ range .IgnorePreflightErrors }},{{ . }}{{ end }}
 # {{ if .ControlPlane }}
 retry-command kubeadm join phase control-plane-prepare download-certs
 retry-command kubeadm join phase control-plane-prepare certs
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	// KubeletServingCertPath and KubeletServingKeyPath are where the serving certificate of the kubelet generated for
+	// the KubeletServingCertSANs, and its key, are written.
+	KubeletServingCertPath = "/etc/kubernetes/pki/kubelet-serving.crt"
+	KubeletServingKeyPath  = "/etc/kubernetes/pki/kubelet-serving.key"
+
+	kubeletServingCertScriptPath        = "/usr/local/bin/generate-kubelet-serving-cert.sh"
+	kubeletServingCertScriptOwner       = "root:root"
+	kubeletServingCertScriptPermissions = "0700"
+
+	// kubeletServingCertScript generates the self-signed serving certificate of the kubelet, valid for a year as the one
+	// the kubelet generates otherwise, for the hostname and the IP addresses of the machine, and the given SANs. An
+	// existing certificate is kept, e.g. when cloud-init runs the commands again.
+	kubeletServingCertScript = `#!/bin/sh
+set -e
+if [ -f %[1]s ]; then
+  exit 0
+fi
+host="$(hostname | tr A-Z a-z)"
+sans="DNS:${host}"
+for ip in $(hostname -I); do
+  sans="${sans},IP:${ip}"
+done
+mkdir -p "$(dirname %[1]s)"
+openssl req -x509 -newkey rsa:2048 -nodes -days 365 -subj "/CN=${host}" \
+  -addext "subjectAltName=${sans},%[3]s" \
+  -keyout %[2]s -out %[1]s
+chmod 0600 %[2]s
+`
+)
+
+// kubeletServingCertSANs renders the given SANs as the value of the subjectAltName extension of a certificate.
+func kubeletServingCertSANs(sans []string) string {
+	rendered := make([]string, 0, len(sans))
+	for _, san := range sans {
+		if net.ParseIP(san) != nil {
+			rendered = append(rendered, "IP:"+san)
+			continue
+		}
+		rendered = append(rendered, "DNS:"+san)
+	}
+	return strings.Join(rendered, ",")
+}
+
+// addKubeletServingCert writes the script generating the serving certificate of the kubelet with the additional SANs,
+// and appends it to the pre kubeadm commands, so that the kubelet started by kubeadm serves it, if requested.
+func (input *BaseUserData) addKubeletServingCert() {
+	if len(input.KubeletServingCertSANs) == 0 {
+		return
+	}
+
+	input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+		Path:        kubeletServingCertScriptPath,
+		Owner:       kubeletServingCertScriptOwner,
+		Permissions: kubeletServingCertScriptPermissions,
+		Content:     fmt.Sprintf(kubeletServingCertScript, KubeletServingCertPath, KubeletServingKeyPath, kubeletServingCertSANs(input.KubeletServingCertSANs)),
+	})
+	input.PreKubeadmCommands = append(input.PreKubeadmCommands, kubeletServingCertScriptPath)
+}
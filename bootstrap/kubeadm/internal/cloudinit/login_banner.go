@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	loginBannerMotdPath        = "/etc/motd"
+	loginBannerIssuePath       = "/etc/issue"
+	loginBannerSSHDDropInPath  = "/etc/ssh/sshd_config.d/login-banner.conf"
+	loginBannerOwner           = "root:root"
+	loginBannerPermissions     = "0644"
+	loginBannerSSHDPermissions = "0600"
+
+	// loginBannerSSHDDropIn makes sshd show the pre-login banner; the drop-in is written
+	// by cloud-init before sshd starts, so no reload is needed.
+	loginBannerSSHDDropIn = "Banner " + loginBannerIssuePath + "\n"
+)
+
+// addLoginBanner adds the files showing the login banner on the console and over ssh, if requested.
+func (input *BaseUserData) addLoginBanner() {
+	if input.LoginBanner == nil {
+		return
+	}
+
+	input.WriteFiles = append(input.WriteFiles,
+		bootstrapv1.File{
+			Path:        loginBannerMotdPath,
+			Owner:       loginBannerOwner,
+			Permissions: loginBannerPermissions,
+			Content:     *input.LoginBanner,
+		},
+		bootstrapv1.File{
+			Path:        loginBannerIssuePath,
+			Owner:       loginBannerOwner,
+			Permissions: loginBannerPermissions,
+			Content:     *input.LoginBanner,
+		},
+		bootstrapv1.File{
+			Path:        loginBannerSSHDDropInPath,
+			Owner:       loginBannerOwner,
+			Permissions: loginBannerSSHDPermissions,
+			Content:     loginBannerSSHDDropIn,
+		},
+	)
+}
@@ -16,9 +16,15 @@ limitations under the License.
 
 package cloudinit
 
+import (
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
 const (
 	mountsTemplate = `{{ define "mounts" -}}
-{{- if . }}
+{{- with NormalizeMounts . }}
 mounts:{{ range . }}
   - {{ range . }}- {{ . }}
     {{ end -}}
@@ -27,3 +33,20 @@ mounts:{{ range . }}
 {{- end -}}
 `
 )
+
+// normalizeMounts trims the fields of the mount entries, so that stray whitespace
+// does not end up in fstab, and drops the entries left without device or mountpoint.
+func normalizeMounts(mounts []bootstrapv1.MountPoints) []bootstrapv1.MountPoints {
+	res := make([]bootstrapv1.MountPoints, 0, len(mounts))
+	for _, mount := range mounts {
+		normalized := make(bootstrapv1.MountPoints, 0, len(mount))
+		for _, f := range mount {
+			normalized = append(normalized, strings.TrimSpace(f))
+		}
+		if len(normalized) < 2 || normalized[0] == "" || normalized[1] == "" {
+			continue
+		}
+		res = append(res, normalized)
+	}
+	return res
+}
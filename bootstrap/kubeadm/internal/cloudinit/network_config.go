@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	networkdConfigPathFormat  = "/etc/systemd/network/10-%s.network"
+	networkdConfigOwner       = "root:root"
+	networkdConfigPermissions = "0644"
+
+	// networkdRestartCommand applies the network configuration written by cloud-init.
+	networkdRestartCommand = "systemctl restart systemd-networkd"
+)
+
+// networkdConfig renders the systemd-networkd configuration of a network interface.
+func networkdConfig(iface bootstrapv1.NetworkInterface) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Match]\nName=%s\n\n", iface.Name)
+	if iface.MTU != nil {
+		fmt.Fprintf(&b, "[Link]\nMTUBytes=%d\n\n", *iface.MTU)
+	}
+	b.WriteString("[Network]\n")
+	for _, address := range iface.Addresses {
+		fmt.Fprintf(&b, "Address=%s\n", address)
+	}
+	if iface.Gateway != "" {
+		fmt.Fprintf(&b, "Gateway=%s\n", iface.Gateway)
+	}
+	for _, dns := range iface.DNS {
+		fmt.Fprintf(&b, "DNS=%s\n", dns)
+	}
+	for _, route := range iface.Routes {
+		fmt.Fprintf(&b, "\n[Route]\nDestination=%s\n", route.To)
+		if route.Via != "" {
+			fmt.Fprintf(&b, "Gateway=%s\n", route.Via)
+		}
+		if route.Metric != nil {
+			fmt.Fprintf(&b, "Metric=%d\n", *route.Metric)
+		}
+	}
+	return b.String()
+}
+
+// addNetworkConfig adds a systemd-networkd configuration file for each network interface,
+// and the command applying them, if requested.
+func (input *BaseUserData) addNetworkConfig() {
+	if len(input.NetworkConfig) == 0 {
+		return
+	}
+
+	for _, iface := range input.NetworkConfig {
+		input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+			Path:        fmt.Sprintf(networkdConfigPathFormat, iface.Name),
+			Owner:       networkdConfigOwner,
+			Permissions: networkdConfigPermissions,
+			Content:     networkdConfig(iface),
+		})
+	}
+
+	// The network is configured before the other commands, which may need to reach the network.
+	input.PreKubeadmCommands = append([]string{networkdRestartCommand}, input.PreKubeadmCommands...)
+}
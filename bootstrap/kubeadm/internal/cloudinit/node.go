@@ -34,10 +34,14 @@ runcmd:
   - {{ .KubeadmCommand }} && {{ .SentinelFileCommand }}
 {{- template "commands" .PostKubeadmCommands }}
 {{- template "ntp" .NTP }}
+{{- template "timezone" .Timezone }}
 {{- template "users" .Users }}
+{{- template "partitions" .DiskSetup}}
 {{- template "disk_setup" .DiskSetup}}
 {{- template "fs_setup" .DiskSetup}}
 {{- template "mounts" .Mounts}}
+{{- template "grow_root" .GrowRootFilesystem}}
+{{- template "packages" .Packages}}
 `
 )
 
@@ -54,5 +58,8 @@ func NewNode(input *NodeInput) ([]byte, error) {
 	}
 	input.Header = cloudConfigHeader
 	input.WriteFiles = append(input.WriteFiles, input.AdditionalFiles...)
+	input.addStartupTaint(StartupTaintKubeconfigPath)
+	input.addPublishConfig(kubeletKubeconfigPath, input.JoinConfiguration)
+	input.addUploadBootLogs()
 	return generate("Node", nodeCloudInit, input)
 }
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// nodeLabelsCommand applies the node labels to the given node with the given credentials written by kubeadm, retrying
+// until the node is registered or the timeout expires. Existing values are overwritten, so that the command can be run
+// again safely.
+const nodeLabelsCommand = `timeout 300s sh -c 'until kubectl --kubeconfig %s label node "%s" --overwrite %s; do sleep 5; done'`
+
+// parseNodeLabels returns the labels of the given comma separated list of key=value pairs, as accepted
+// by the kubelet node-labels flag, sorted by key.
+func parseNodeLabels(nodeLabels string) []string {
+	labels := map[string]string{}
+	for _, label := range strings.Split(nodeLabels, ",") {
+		kv := strings.SplitN(strings.TrimSpace(label), "=", 2)
+		if kv[0] == "" {
+			continue
+		}
+		value := ""
+		if len(kv) == 2 {
+			value = kv[1]
+		}
+		labels[kv[0]] = value
+	}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, labels[key]))
+	}
+	return pairs
+}
+
+// addNodeLabels appends the command applying the node labels set with the kubelet node-labels flag to the post
+// kubeadm commands, if any, so that they are also set on the node object and not only when the kubelet registers it.
+// Control plane machines apply them with the admin credentials; the kubelet credentials used on other machines can
+// set the same labels as the node-labels flag, as the kubelet refuses the ones the NodeRestriction admission plugin
+// forbids it to set.
+func (input *BaseUserData) addNodeLabels(kubeconfigPath string) {
+	labels := parseNodeLabels(input.NodeLabels)
+	if len(labels) == 0 {
+		return
+	}
+
+	input.PostKubeadmCommands = append(input.PostKubeadmCommands, fmt.Sprintf(nodeLabelsCommand, kubeconfigPath, input.nodeName(), strings.Join(labels, " ")))
+}
+
+// nodeName returns the name of the node for the commands run on the machine: the name set in the node registration
+// options, which cloud-init may render from a template, or else the lowercased hostname, as defaulted by kubeadm.
+func (input *BaseUserData) nodeName() string {
+	if input.NodeName != "" {
+		return input.NodeName
+	}
+	return "$(hostname | tr A-Z a-z)"
+}
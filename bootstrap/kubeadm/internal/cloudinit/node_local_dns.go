@@ -0,0 +1,51 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	nodeLocalDNSConfigPath        = "/etc/systemd/resolved.conf.d/node-local-dns.conf"
+	nodeLocalDNSConfigOwner       = "root:root"
+	nodeLocalDNSConfigPermissions = "0644"
+
+	// resolvedRestartCommand applies the systemd-resolved configuration written by cloud-init.
+	resolvedRestartCommand = "systemctl restart systemd-resolved"
+)
+
+// addNodeLocalDNS adds a systemd-resolved configuration file making the node-local DNS cache the
+// DNS server of the host, and the command applying it, if requested.
+func (input *BaseUserData) addNodeLocalDNS() {
+	if input.NodeLocalDNS == nil {
+		return
+	}
+
+	input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+		Path:        nodeLocalDNSConfigPath,
+		Owner:       nodeLocalDNSConfigOwner,
+		Permissions: nodeLocalDNSConfigPermissions,
+		// Domains=~. routes all queries to the cache rather than to the per-link DNS servers.
+		Content: fmt.Sprintf("[Resolve]\nDNS=%s\nDomains=~.\n", input.NodeLocalDNS.Address),
+	})
+
+	// Names are resolved through the cache before the other commands, which may need to resolve names.
+	input.PreKubeadmCommands = append([]string{resolvedRestartCommand}, input.PreKubeadmCommands...)
+}
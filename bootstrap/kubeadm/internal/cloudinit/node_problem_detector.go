@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	nodeProblemDetectorUnit          = "node-problem-detector.service"
+	nodeProblemDetectorEnableCommand = "systemctl enable --now " + nodeProblemDetectorUnit
+
+	// NodeProblemDetectorConfigPath is where the system log monitor config of the node problem detector is written,
+	// if any.
+	NodeProblemDetectorConfigPath = "/etc/node-problem-detector/system-log-monitor.json"
+
+	// nodeProblemDetectorDefaultConfigPath is the kernel monitor config shipped in the node problem detector image.
+	nodeProblemDetectorDefaultConfigPath = "/config/kernel-monitor.json"
+
+	// nodeProblemDetectorConfigMount mounts the directory of the config written to NodeProblemDetectorConfigPath.
+	nodeProblemDetectorConfigMount = "  --mount type=bind,src=/etc/node-problem-detector,dst=/etc/node-problem-detector,options=rbind:ro \\\n"
+
+	// nodeProblemDetectorService runs the node problem detector image with containerd, in the k8s.io namespace
+	// so that the kubelet does not garbage collect its image. It reports the node conditions to the API server
+	// with the credentials of the kubelet, which the node problem detector uses in place of in-cluster ones.
+	nodeProblemDetectorService = `[Unit]
+Description=Node problem detector
+Wants=containerd.service kubelet.service
+After=containerd.service kubelet.service
+
+[Service]
+ExecStartPre=/usr/bin/ctr --namespace k8s.io images pull %[1]s
+ExecStartPre=-/usr/bin/ctr --namespace k8s.io containers delete node-problem-detector
+ExecStart=/usr/bin/ctr --namespace k8s.io run --rm --net-host --privileged \
+  --env NODE_NAME=%%H \
+  --mount type=bind,src=/etc/kubernetes,dst=/etc/kubernetes,options=rbind:ro \
+  --mount type=bind,src=/var/lib/kubelet/pki,dst=/var/lib/kubelet/pki,options=rbind:ro \
+  --mount type=bind,src=/var/log,dst=/var/log,options=rbind:ro \
+  --mount type=bind,src=/dev/kmsg,dst=/dev/kmsg,options=rbind:ro \
+%[3]s  %[1]s node-problem-detector /node-problem-detector --logtostderr \
+  --apiserver-override=https://kubernetes.default?inClusterConfig=false&auth=/etc/kubernetes/kubelet.conf \
+  --config.system-log-monitor=%[2]s
+Restart=always
+RestartSec=10
+
+[Install]
+WantedBy=multi-user.target
+`
+)
+
+// addNodeProblemDetector adds the systemd unit running the node problem detector, and the commands starting it
+// once the node has been bootstrapped, if requested. Its config, if any, is written by the caller to
+// NodeProblemDetectorConfigPath.
+func (input *BaseUserData) addNodeProblemDetector() {
+	if input.NodeProblemDetector == nil {
+		return
+	}
+
+	configPath, configMount := nodeProblemDetectorDefaultConfigPath, ""
+	if input.NodeProblemDetector.ConfigFrom != nil {
+		configPath, configMount = NodeProblemDetectorConfigPath, nodeProblemDetectorConfigMount
+	}
+
+	input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+		Path:        fmt.Sprintf(systemdUnitPathFormat, nodeProblemDetectorUnit),
+		Owner:       systemdUnitOwner,
+		Permissions: systemdUnitPermissions,
+		Content:     fmt.Sprintf(nodeProblemDetectorService, input.NodeProblemDetector.Image, configPath, configMount),
+	})
+
+	// The kubelet credentials the node problem detector uses only exist once the node has been bootstrapped.
+	input.PostKubeadmCommands = append(input.PostKubeadmCommands, systemdDaemonReloadCommand, nodeProblemDetectorEnableCommand)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	persistentJournalDirectory         = "/var/log/journal"
+	persistentJournalDropInPath        = "/etc/systemd/journald.conf.d/persistent.conf"
+	persistentJournalDropInOwner       = "root:root"
+	persistentJournalDropInPermissions = "0644"
+
+	persistentJournalDropIn = `[Journal]
+Storage=persistent
+`
+)
+
+// persistentJournalCommands create the journal directory, let systemd-tmpfiles apply the
+// ownership and ACLs journald expects on it, and restart journald to move its logs there.
+var persistentJournalCommands = []string{
+	"install -d -m 2755 " + persistentJournalDirectory,
+	"systemd-tmpfiles --create --prefix " + persistentJournalDirectory,
+	"systemctl restart systemd-journald",
+}
+
+// addPersistentJournal adds the journald drop-in storing logs on disk, and the commands
+// setting up the journal directory, if requested.
+func (input *BaseUserData) addPersistentJournal() {
+	if !input.PersistentJournal {
+		return
+	}
+
+	input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+		Path:        persistentJournalDropInPath,
+		Owner:       persistentJournalDropInOwner,
+		Permissions: persistentJournalDropInPermissions,
+		Content:     persistentJournalDropIn,
+	})
+
+	// The journal is set up before any other command, so that their output is persisted too.
+	preKubeadmCommands := make([]string, 0, len(persistentJournalCommands)+len(input.PreKubeadmCommands))
+	preKubeadmCommands = append(preKubeadmCommands, persistentJournalCommands...)
+	input.PreKubeadmCommands = append(preKubeadmCommands, input.PreKubeadmCommands...)
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+)
+
+// prePullImageCommand pulls an image through the CRI image service, so that it is cached before kubeadm runs.
+const prePullImageCommand = "crictl --image-endpoint %s pull %s"
+
+// addPrePullImages appends the commands pulling the requested images to the pre kubeadm commands.
+// They run after the user provided commands, which may be required to set up the container runtime.
+func (input *BaseUserData) addPrePullImages() {
+	endpoint := crictlEndpoint(input.CRISocket)
+	for _, image := range input.PrePullImages {
+		input.PreKubeadmCommands = append(input.PreKubeadmCommands, fmt.Sprintf(prePullImageCommand, endpoint, image))
+	}
+}
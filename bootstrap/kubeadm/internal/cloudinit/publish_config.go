@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	publishConfigSummaryPath        = "/run/cluster-api/kubeadm-config-summary.yaml"
+	publishConfigSummaryOwner       = "root:root"
+	publishConfigSummaryPermissions = "0640"
+
+	adminKubeconfigPath   = "/etc/kubernetes/admin.conf"
+	kubeletKubeconfigPath = "/etc/kubernetes/kubelet.conf"
+
+	// publishConfigCommand stores the summary under the hostname of the machine, applied server-side with a field
+	// manager per machine so that machines publishing to the same ConfigMap do not remove each other's entries.
+	publishConfigCommand = `kubectl --kubeconfig %[1]s create configmap %[2]s --namespace %[3]s --from-file="$(hostname | tr A-Z a-z)"=%[4]s --dry-run=client -o yaml | kubectl --kubeconfig %[1]s apply --server-side --force-conflicts --field-manager="cluster-api-$(hostname | tr A-Z a-z)" -f -`
+
+	redactedKubeadmValue = "REDACTED"
+)
+
+// redactedKubeadmFieldRegex matches the values of the kubeadm configuration fields holding bootstrap tokens
+// or certificate keys.
+var redactedKubeadmFieldRegex = regexp.MustCompile(`(?m)^([ \t]*(?:-[ \t]+)?(?:token|tlsBootstrapToken|certificateKey):[ \t]*)\S.*$`)
+
+// redactKubeadmConfiguration returns the given kubeadm configuration with its secret values redacted.
+func redactKubeadmConfiguration(configuration string) string {
+	return redactedKubeadmFieldRegex.ReplaceAllString(configuration, "${1}"+redactedKubeadmValue)
+}
+
+// publishConfigCommandFor returns the command publishing the summary of the applied kubeadm configuration
+// to the given target, using the given kubeconfig.
+func publishConfigCommandFor(target *bootstrapv1.PublishTarget, kubeconfig string) string {
+	return fmt.Sprintf(publishConfigCommand, kubeconfig, target.ConfigMapName, target.Namespace, publishConfigSummaryPath)
+}
+
+// addPublishConfig writes a redacted summary of the given kubeadm configurations, and appends the command
+// publishing it with the given kubeconfig to the post kubeadm commands, if requested.
+func (input *BaseUserData) addPublishConfig(kubeconfig string, configurations ...string) {
+	if input.PublishConfigTo == nil {
+		return
+	}
+
+	documents := make([]string, 0, len(configurations))
+	for _, configuration := range configurations {
+		documents = append(documents, "---\n"+strings.TrimSpace(redactKubeadmConfiguration(configuration))+"\n")
+	}
+	input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+		Path:        publishConfigSummaryPath,
+		Owner:       publishConfigSummaryOwner,
+		Permissions: publishConfigSummaryPermissions,
+		Content:     strings.Join(documents, ""),
+	})
+
+	// The summary is only published once the node has been bootstrapped.
+	input.PostKubeadmCommands = append(input.PostKubeadmCommands, publishConfigCommandFor(input.PublishConfigTo, kubeconfig))
+}
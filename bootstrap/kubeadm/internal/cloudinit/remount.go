@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const systemdEnableServiceCommandFormat = "systemctl enable --now %s"
+
+// remountServiceName returns the name of the service unit remounting the given path, e.g.
+// "remount-usr-local.service" for /usr/local, or "remount-.service" for the root filesystem.
+func remountServiceName(path string) string {
+	return "remount-" + strings.ReplaceAll(strings.Trim(path, "/"), "/", "-") + ".service"
+}
+
+// remountService renders the oneshot service unit remounting a filesystem with additional options;
+// it is enabled so that the options are enforced again on every boot.
+func remountService(remount bootstrapv1.RemountSpec) string {
+	options := strings.Join(remount.Options, ",")
+	return fmt.Sprintf("[Unit]\nDescription=Remount %[1]s with %[2]s\nDefaultDependencies=no\nAfter=local-fs.target\n\n"+
+		"[Service]\nType=oneshot\nRemainAfterExit=true\nExecStart=/bin/mount -o remount,%[2]s %[1]s\n\n"+
+		"[Install]\nWantedBy=multi-user.target\n",
+		remount.Path, options)
+}
+
+// addRemountOptions adds the service unit remounting each filesystem with its options, and the commands
+// enabling them, if requested.
+func (input *BaseUserData) addRemountOptions() {
+	if len(input.RemountOptions) == 0 {
+		return
+	}
+
+	commands := []string{systemdDaemonReloadCommand}
+	for _, remount := range input.RemountOptions {
+		name := remountServiceName(remount.Path)
+		input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+			Path:        fmt.Sprintf(systemdUnitPathFormat, name),
+			Owner:       systemdUnitOwner,
+			Permissions: systemdUnitPermissions,
+			Content:     remountService(remount),
+		})
+		commands = append(commands, fmt.Sprintf(systemdEnableServiceCommandFormat, name))
+	}
+
+	// The filesystems are remounted before any other command, so that the options are enforced on the node
+	// before it is bootstrapped.
+	input.PreKubeadmCommands = append(commands, input.PreKubeadmCommands...)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	// runtimeHandlersConfigPath is a containerd configuration drop-in, loaded like sandboxImageConfigPath.
+	runtimeHandlersConfigPath        = "/etc/containerd/conf.d/runtime-handlers.toml"
+	runtimeHandlersConfigOwner       = "root:root"
+	runtimeHandlersConfigPermissions = "0644"
+
+	runtimeHandlerConfig = `[plugins."io.containerd.grpc.v1.cri".containerd.runtimes.%s]
+  runtime_type = %q
+`
+	runtimeHandlerBinaryPathConfig = `  runtime_path = %q
+`
+)
+
+// addRuntimeHandlers adds the containerd configuration drop-in declaring the runtime handlers, and the command
+// applying it, if requested.
+func (input *BaseUserData) addRuntimeHandlers() {
+	if len(input.RuntimeHandlers) == 0 {
+		return
+	}
+
+	var config strings.Builder
+	config.WriteString("version = 2\n")
+	for _, handler := range input.RuntimeHandlers {
+		fmt.Fprintf(&config, runtimeHandlerConfig, handler.Name, handler.RuntimeType)
+		if handler.BinaryPath != "" {
+			fmt.Fprintf(&config, runtimeHandlerBinaryPathConfig, handler.BinaryPath)
+		}
+	}
+
+	input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+		Path:        runtimeHandlersConfigPath,
+		Owner:       runtimeHandlersConfigOwner,
+		Permissions: runtimeHandlersConfigPermissions,
+		Content:     config.String(),
+	})
+	input.addContainerdRestart()
+}
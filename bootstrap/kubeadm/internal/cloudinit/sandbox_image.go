@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	// sandboxImageConfigPath is a containerd configuration drop-in; it is loaded by containerd configurations
+	// importing /etc/containerd/conf.d/*.toml, like the ones of the images built with image-builder.
+	sandboxImageConfigPath        = "/etc/containerd/conf.d/sandbox-image.toml"
+	sandboxImageConfigOwner       = "root:root"
+	sandboxImageConfigPermissions = "0644"
+
+	sandboxImageConfig = `version = 2
+[plugins."io.containerd.grpc.v1.cri"]
+  sandbox_image = %q
+`
+
+	// containerdRestartCommand applies the containerd configuration written by cloud-init.
+	containerdRestartCommand = "systemctl restart containerd"
+)
+
+// addSandboxImage adds the containerd configuration drop-in overriding the sandbox image, and the command
+// applying it, if requested. The kubelet is configured through its args by the KubeadmConfig controller.
+func (input *BaseUserData) addSandboxImage() {
+	if input.SandboxImage == nil {
+		return
+	}
+
+	input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+		Path:        sandboxImageConfigPath,
+		Owner:       sandboxImageConfigOwner,
+		Permissions: sandboxImageConfigPermissions,
+		Content:     fmt.Sprintf(sandboxImageConfig, *input.SandboxImage),
+	})
+
+	input.addContainerdRestart()
+}
+
+// addContainerdRestart adds the command applying the containerd configuration, unless already added.
+func (input *BaseUserData) addContainerdRestart() {
+	if len(input.PreKubeadmCommands) > 0 && input.PreKubeadmCommands[0] == containerdRestartCommand {
+		return
+	}
+
+	// containerd is restarted before the other commands, which may already start pods, e.g. by pre-pulling images.
+	input.PreKubeadmCommands = append([]string{containerdRestartCommand}, input.PreKubeadmCommands...)
+}
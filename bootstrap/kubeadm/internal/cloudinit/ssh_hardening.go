@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	// sshHardeningDropInPath is named to be read before the other drop-ins, e.g. the one written by cloud-init,
+	// as sshd keeps the first value it reads for each keyword.
+	sshHardeningDropInPath        = "/etc/ssh/sshd_config.d/00-hardening.conf"
+	sshHardeningDropInOwner       = "root:root"
+	sshHardeningDropInPermissions = "0600"
+
+	// sshdReloadCommand checks the sshd configuration and reloads it; the service is named ssh on Debian
+	// and Ubuntu, and sshd on other distributions.
+	sshdReloadCommand = "sshd -t && (systemctl reload sshd || systemctl reload ssh)"
+)
+
+// sshHardeningDropIn returns the sshd configuration drop-in setting the given hardening.
+func sshHardeningDropIn(hardening *bootstrapv1.SSHHardeningConfig) string {
+	var b strings.Builder
+	if hardening.PasswordAuthentication != nil {
+		value := "no"
+		if *hardening.PasswordAuthentication {
+			value = "yes"
+		}
+		fmt.Fprintf(&b, "PasswordAuthentication %s\n", value)
+	}
+	if hardening.PermitRootLogin != "" {
+		fmt.Fprintf(&b, "PermitRootLogin %s\n", hardening.PermitRootLogin)
+	}
+	if len(hardening.Ciphers) > 0 {
+		fmt.Fprintf(&b, "Ciphers %s\n", strings.Join(hardening.Ciphers, ","))
+	}
+	if len(hardening.MACs) > 0 {
+		fmt.Fprintf(&b, "MACs %s\n", strings.Join(hardening.MACs, ","))
+	}
+	return b.String()
+}
+
+// addSSHHardening adds the sshd configuration drop-in hardening sshd, and the command applying it, if requested.
+func (input *BaseUserData) addSSHHardening() {
+	if input.SSHHardening == nil {
+		return
+	}
+
+	input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+		Path:        sshHardeningDropInPath,
+		Owner:       sshHardeningDropInOwner,
+		Permissions: sshHardeningDropInPermissions,
+		Content:     sshHardeningDropIn(input.SSHHardening),
+	})
+
+	// sshd may already be running when cloud-init writes the drop-in, so it is reloaded before the other commands.
+	input.PreKubeadmCommands = append([]string{sshdReloadCommand}, input.PreKubeadmCommands...)
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+)
+
+const (
+	// StartupTaintKubeconfigPath is the kubeconfig removing the startup taint on machines other than control plane
+	// ones, as the NodeRestriction admission plugin forbids the kubelet credentials to modify the taints of the node.
+	StartupTaintKubeconfigPath = "/etc/kubernetes/startup-taint.conf"
+
+	// startupTaintCommand waits for the node to become Ready, using the kubelet credentials written by kubeadm, then
+	// removes the startup taint with the given credentials. The taint is kept if the node is not Ready in time.
+	startupTaintCommand = `timeout 300s sh -c 'until kubectl --kubeconfig /etc/kubernetes/kubelet.conf get node "$(hostname | tr A-Z a-z)" -o jsonpath="{.status.conditions[?(@.type==\"Ready\")].status}" | grep -q True; do sleep 5; done' && kubectl --kubeconfig %s taint node "$(hostname | tr A-Z a-z)" %s:%s-`
+)
+
+// addStartupTaint appends the command removing the startup taint, which the kubelet registered the node with, to the
+// post kubeadm commands, if any. It must be added after the commands configuring the node.
+func (input *BaseUserData) addStartupTaint(kubeconfigPath string) {
+	if input.StartupTaint == nil {
+		return
+	}
+
+	input.PostKubeadmCommands = append(input.PostKubeadmCommands, fmt.Sprintf(startupTaintCommand, kubeconfigPath, input.StartupTaint.Key, input.StartupTaint.Effect))
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	systemLimitsSystemdConfigPath = "/etc/systemd/system.conf.d/capi-limits.conf"
+	systemLimitsPAMConfigPath     = "/etc/security/limits.d/99-capi.conf"
+	systemLimitsOwner             = "root:root"
+	systemLimitsPermissions       = "0644"
+
+	// systemdReexecCommand applies the default limits of the systemd manager configuration, which systemctl
+	// daemon-reload does not; they apply to the services started afterwards, e.g. the kubelet started by kubeadm.
+	systemdReexecCommand = "systemctl daemon-reexec"
+)
+
+// addSystemLimits writes the systemd manager configuration drop-in setting the default limits of the services, and the
+// pam_limits configuration setting the limits of the login sessions, and the command applying the former before the
+// kubeadm command runs, if requested.
+func (input *BaseUserData) addSystemLimits() {
+	if input.SystemLimits == nil {
+		return
+	}
+
+	var systemd, pam strings.Builder
+	systemd.WriteString("[Manager]\n")
+	// The * wildcard of pam_limits does not apply to root.
+	if input.SystemLimits.NoFile != nil {
+		fmt.Fprintf(&systemd, "DefaultLimitNOFILE=%d\n", *input.SystemLimits.NoFile)
+		fmt.Fprintf(&pam, "* - nofile %[1]d\nroot - nofile %[1]d\n", *input.SystemLimits.NoFile)
+	}
+	if input.SystemLimits.NProc != nil {
+		fmt.Fprintf(&systemd, "DefaultLimitNPROC=%d\n", *input.SystemLimits.NProc)
+		fmt.Fprintf(&pam, "* - nproc %[1]d\nroot - nproc %[1]d\n", *input.SystemLimits.NProc)
+	}
+	input.WriteFiles = append(input.WriteFiles,
+		bootstrapv1.File{
+			Path:        systemLimitsSystemdConfigPath,
+			Owner:       systemLimitsOwner,
+			Permissions: systemLimitsPermissions,
+			Content:     systemd.String(),
+		},
+		bootstrapv1.File{
+			Path:        systemLimitsPAMConfigPath,
+			Owner:       systemLimitsOwner,
+			Permissions: systemLimitsPermissions,
+			Content:     pam.String(),
+		},
+	)
+	input.PreKubeadmCommands = append(input.PreKubeadmCommands, systemdReexecCommand)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	systemdUnitPathFormat  = "/etc/systemd/system/%s"
+	systemdUnitOwner       = "root:root"
+	systemdUnitPermissions = "0644"
+
+	systemdDaemonReloadCommand      = "systemctl daemon-reload"
+	systemdEnableTimerCommandFormat = "systemctl enable --now %s.timer"
+)
+
+// systemdExecEscaper escapes the characters which systemd would otherwise expand in ExecStart.
+var systemdExecEscaper = strings.NewReplacer("%", "%%", "$", "$$")
+
+// systemdTimerService renders the oneshot service unit running the command of a systemd timer.
+func systemdTimerService(timer bootstrapv1.SystemdTimer) string {
+	return fmt.Sprintf("[Unit]\nDescription=%[1]s\n\n[Service]\nType=oneshot\nExecStart=/bin/sh -c %[2]s\n",
+		timer.Name, strconv.Quote(systemdExecEscaper.Replace(timer.Command)))
+}
+
+// systemdTimerTimer renders the timer unit starting the service unit of a systemd timer.
+func systemdTimerTimer(timer bootstrapv1.SystemdTimer) string {
+	return fmt.Sprintf("[Unit]\nDescription=Run %[1]s periodically\n\n[Timer]\nOnCalendar=%[2]s\nPersistent=true\n\n[Install]\nWantedBy=timers.target\n",
+		timer.Name, timer.Schedule)
+}
+
+// addSystemdTimers adds the service and timer units of each systemd timer, and the commands enabling
+// the timers, if requested.
+func (input *BaseUserData) addSystemdTimers() {
+	if len(input.SystemdTimers) == 0 {
+		return
+	}
+
+	commands := []string{systemdDaemonReloadCommand}
+	for _, timer := range input.SystemdTimers {
+		input.WriteFiles = append(input.WriteFiles,
+			bootstrapv1.File{
+				Path:        fmt.Sprintf(systemdUnitPathFormat, timer.Name+".service"),
+				Owner:       systemdUnitOwner,
+				Permissions: systemdUnitPermissions,
+				Content:     systemdTimerService(timer),
+			},
+			bootstrapv1.File{
+				Path:        fmt.Sprintf(systemdUnitPathFormat, timer.Name+".timer"),
+				Owner:       systemdUnitOwner,
+				Permissions: systemdUnitPermissions,
+				Content:     systemdTimerTimer(timer),
+			},
+		)
+		commands = append(commands, fmt.Sprintf(systemdEnableTimerCommandFormat, timer.Name))
+	}
+
+	// The timers are only started once the node has been bootstrapped.
+	input.PostKubeadmCommands = append(input.PostKubeadmCommands, commands...)
+}
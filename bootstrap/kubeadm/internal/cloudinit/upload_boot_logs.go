@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	// BootLogsCredentialsPath is where the HTTP headers authenticating the upload of the boot logs are written,
+	// one per line.
+	BootLogsCredentialsPath = "/etc/cluster-api/boot-logs-credentials"
+
+	uploadBootLogsScriptPath        = "/usr/local/bin/upload-boot-logs"
+	uploadBootLogsScriptOwner       = "root:root"
+	uploadBootLogsScriptPermissions = "0700"
+
+	// uploadBootLogsScript archives the journal and the cloud-init logs of the current boot, and uploads the archive
+	// under the given URL, named after the hostname and the outcome of the bootstrap, told by the sentinel file.
+	uploadBootLogsScript = `#!/bin/sh
+logs="$(mktemp -d)"
+journalctl --no-pager --boot > "${logs}/journal.log" 2>&1
+cp /var/log/cloud-init.log /var/log/cloud-init-output.log "${logs}/" 2>/dev/null
+outcome=failed
+if [ -f /run/cluster-api/bootstrap-success.complete ]; then
+  outcome=succeeded
+fi
+tar -czf "${logs}.tar.gz" -C "${logs}" .
+curl --fail --silent --show-error --retry 3 %s--upload-file "${logs}.tar.gz" '%s'"/$(hostname | tr A-Z a-z)-${outcome}.tar.gz"
+rm -rf "${logs}" "${logs}.tar.gz"
+`
+)
+
+// addUploadBootLogs writes the script uploading the boot logs to the sink, and appends it to the post kubeadm
+// commands, if requested. It must be added last: cloud-init runs the following commands even if the previous ones
+// failed, so that the logs are uploaded whatever the outcome of the bootstrap, including all of its commands.
+func (input *BaseUserData) addUploadBootLogs() {
+	if input.UploadBootLogsTo == nil {
+		return
+	}
+
+	credentials := ""
+	if input.UploadBootLogsTo.CredentialsFrom != nil {
+		credentials = fmt.Sprintf("--header @%s ", BootLogsCredentialsPath)
+	}
+	input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+		Path:        uploadBootLogsScriptPath,
+		Owner:       uploadBootLogsScriptOwner,
+		Permissions: uploadBootLogsScriptPermissions,
+		Content:     fmt.Sprintf(uploadBootLogsScript, credentials, strings.TrimSuffix(input.UploadBootLogsTo.URL, "/")),
+	})
+	input.PostKubeadmCommands = append(input.PostKubeadmCommands, uploadBootLogsScriptPath)
+}
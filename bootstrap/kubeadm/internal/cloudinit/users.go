@@ -16,6 +16,12 @@ limitations under the License.
 
 package cloudinit
 
+import "fmt"
+
+// userHomeDirCommandFormat ensures the home directory of a user exists, is owned by the user and its primary group,
+// and is only accessible by the user; cloud-init does not fix up home directories which already exist in the image.
+const userHomeDirCommandFormat = "mkdir -p %[2]s && chown %[1]s: %[2]s && chmod 0700 %[2]s"
+
 const (
 	usersTemplate = `{{ define "users" -}}
 {{- if . }}
@@ -58,3 +64,17 @@ users:{{ range . }}
 {{- end -}}
 `
 )
+
+// addUserHomeDirs adds the commands creating the home directory of the users which have one set.
+func (input *BaseUserData) addUserHomeDirs() {
+	var commands []string
+	for _, user := range input.Users {
+		if user.HomeDir == nil || *user.HomeDir == "" {
+			continue
+		}
+		commands = append(commands, fmt.Sprintf(userHomeDirCommandFormat, user.Name, *user.HomeDir))
+	}
+
+	// Home directories are created before the other commands, which may write into them.
+	input.PreKubeadmCommands = append(commands, input.PreKubeadmCommands...)
+}
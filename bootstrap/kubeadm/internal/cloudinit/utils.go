@@ -23,7 +23,10 @@ import (
 
 var (
 	defaultTemplateFuncMap = template.FuncMap{
-		"Indent": templateYAMLIndent,
+		"Indent":            templateYAMLIndent,
+		"LayoutPartitions":  layoutPartitions,
+		"NormalizeMounts":   normalizeMounts,
+		"PartitionCommands": partitionCommands,
 	}
 )
 
@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// waitForMountTimeout bounds the wait for each mountpoint, so that a mount which never becomes ready fails the
+// command instead of blocking the bootstrap forever.
+const waitForMountTimeout = 5 * time.Minute
+
+// waitForMountCommand polls the given path until a filesystem is mounted on it or the timeout (in seconds) expires.
+const waitForMountCommand = `timeout %ds sh -c 'until mountpoint -q %s; do sleep 1; done'`
+
+// addWaitForMounts prepends to the pre kubeadm commands the commands waiting for the mountpoints of the mounts,
+// if filesystems are created by the disk setup. The filesystems may still be being created and mounted when the
+// commands start, and kubeadm would otherwise write to the underlying directories, e.g. of /var/lib/etcd.
+func (input *BaseUserData) addWaitForMounts() {
+	if input.DiskSetup == nil || len(input.DiskSetup.Filesystems) == 0 {
+		return
+	}
+
+	var commands []string
+	for _, mount := range normalizeMounts(input.Mounts) {
+		// Swap entries have no mountpoint to wait for.
+		if !strings.HasPrefix(mount[1], "/") {
+			continue
+		}
+		commands = append(commands, fmt.Sprintf(waitForMountCommand, int(waitForMountTimeout.Seconds()), mount[1]))
+	}
+
+	// The mounts are waited for before any other command, which may already use them, e.g. by restarting
+	// containerd on a dedicated disk.
+	input.PreKubeadmCommands = append(commands, input.PreKubeadmCommands...)
+}
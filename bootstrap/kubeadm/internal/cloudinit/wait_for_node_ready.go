@@ -0,0 +1,35 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+)
+
+// waitForNodeReadyCommand polls the node object, using the kubelet credentials written by kubeadm,
+// until its Ready condition is true or the timeout (in seconds) expires.
+const waitForNodeReadyCommand = `timeout %ds sh -c 'until kubectl --kubeconfig /etc/kubernetes/kubelet.conf get node "$(hostname | tr A-Z a-z)" -o jsonpath="{.status.conditions[?(@.type==\"Ready\")].status}" | grep -q True; do sleep 5; done'`
+
+// addWaitForNodeReady appends the command waiting for the node to become Ready
+// to the post kubeadm commands, if requested.
+func (input *BaseUserData) addWaitForNodeReady() {
+	if input.WaitForNodeReady == nil {
+		return
+	}
+
+	input.PostKubeadmCommands = append(input.PostKubeadmCommands, fmt.Sprintf(waitForNodeReadyCommand, int(input.WaitForNodeReady.Timeout.Seconds())))
+}
@@ -22,6 +22,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -29,11 +30,15 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
@@ -43,26 +48,84 @@ import (
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	"sigs.k8s.io/cluster-api/util/secret"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
+// machineHealthCheckEventSource is the component recording the MachineHealthCheck events.
+const machineHealthCheckEventSource = "machinehealthcheck-controller"
+
+// drainBlockedRecheckInterval is how often the PodDisruptionBudgets blocking the drain of the Node of a Machine
+// whose remediation is deferred are checked again.
+const drainBlockedRecheckInterval = 1 * time.Minute
+
+// defaultClusterOutageThreshold is the ClusterOutageThreshold of MachineHealthChecks which do not set it.
+var defaultClusterOutageThreshold = intstr.FromString("100%")
+
+// defaultStaleNodeStatusThreshold is the StaleNodeStatusThreshold of MachineHealthChecks which do not set it.
+var defaultStaleNodeStatusThreshold = intstr.FromString("50%")
+
+// Event reasons emitted by the MachineHealthCheck controller.
+// These strings are part of the API consumed by users and alerting tools, do not change them.
 const (
-	// Event types.
+	// EventReasonRemediationTriggered is emitted when a Machine was successfully marked for remediation.
+	EventReasonRemediationTriggered string = "MachineMarkedUnhealthy"
+	// EventReasonRemediationSkipped is emitted when remediation is restricted
+	// by the remediation short-circuiting logic (MaxUnhealthy or UnhealthyRange).
+	EventReasonRemediationSkipped string = "RemediationRestricted"
+	// EventReasonDetectedUnhealthy is emitted when the Node of a Machine is detected
+	// unhealthy, but the unhealthy condition timeout has not elapsed yet.
+	EventReasonDetectedUnhealthy string = "DetectedUnhealthy"
+	// EventReasonNodeStartupTimeout is emitted when a Machine did not get a Node
+	// within the MachineHealthCheck NodeStartupTimeout.
+	EventReasonNodeStartupTimeout string = "NodeStartupTimeout"
+	// EventReasonReconcileError is emitted when the MachineHealthCheck failed to reconcile.
+	EventReasonReconcileError string = "ReconcileError"
+	// EventReasonNodeCordoned is emitted when the Node of an unhealthy Machine is cordoned
+	// by the CordonAndWait remediation strategy.
+	EventReasonNodeCordoned string = "NodeCordoned"
+	// EventReasonNodeUncordoned is emitted when the Node of a Machine which recovered
+	// within the CordonAndWait grace period is uncordoned.
+	EventReasonNodeUncordoned string = "NodeUncordoned"
+)
 
+const (
 	// EventRemediationRestricted is emitted in case when machine remediation
 	// is restricted by remediation circuit shorting logic.
-	EventRemediationRestricted string = "RemediationRestricted"
+	//
+	// Deprecated: use EventReasonRemediationSkipped.
+	EventRemediationRestricted = EventReasonRemediationSkipped
+	// EventMachineMarkedUnhealthy is emitted when machine was successfully marked as unhealthy.
+	//
+	// Deprecated: use EventReasonRemediationTriggered.
+	EventMachineMarkedUnhealthy = EventReasonRemediationTriggered
+	// EventDetectedUnhealthy is emitted in case a node associated with a
+	// machine was detected unhealthy.
+	//
+	// Deprecated: use EventReasonDetectedUnhealthy.
+	EventDetectedUnhealthy = EventReasonDetectedUnhealthy
 )
 
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch;delete
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinehealthchecks;machinehealthchecks/status,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch;patch
+
+// HealthEvaluator evaluates the health of a Machine from signals the MachineHealthCheck controller does not know
+// about, e.g. reported by the baseboard management controller of its host.
+type HealthEvaluator interface {
+	// Evaluate returns whether the given Machine is healthy, and the reason why it is not. The Node is nil
+	// if the Machine has no Node yet, or if its Node has gone away.
+	Evaluate(ctx context.Context, machine *clusterv1.Machine, node *corev1.Node) (healthy bool, reason string, err error)
+}
 
 // MachineHealthCheckReconciler reconciles a MachineHealthCheck object.
 type MachineHealthCheckReconciler struct {
@@ -70,16 +133,42 @@ type MachineHealthCheckReconciler struct {
 	Tracker          *remote.ClusterCacheTracker
 	WatchFilterValue string
 
+	// ClusterSelector, if set, restricts the MachineHealthChecks reconciled to the ones of the Clusters it matches,
+	// e.g. to share a management cluster between several controller instances.
+	ClusterSelector labels.Selector
+
+	// HealthEvaluators are consulted alongside the built-in checks of the MachineHealthChecks;
+	// a Machine reported unhealthy by any of them is unhealthy.
+	HealthEvaluators []HealthEvaluator
+
+	// RemediationWebhookURL, if set, is notified with a RemediationNotification every time a Machine is marked
+	// for remediation.
+	RemediationWebhookURL string
+
 	controller controller.Controller
 	recorder   record.EventRecorder
+
+	// nodeProber probes whether the kubelet of a node is reachable through the API server of its cluster,
+	// for MachineHealthChecks with RequireAPIUnreachable; defaults to probeKubelet.
+	nodeProber func(ctx context.Context, cluster *clusterv1.Cluster, nodeName string) (bool, error)
+
+	// clock tells the time of the maintenance windows of the remediation schedules and of the remediations, which
+	// are staggered by it; defaults to the real clock.
+	clock clock.Clock
+
+	// reconciledSinceStartup records the UIDs of the MachineHealthChecks reconciled since the controller started.
+	reconciledSinceStartup sync.Map
 }
 
 func (r *MachineHealthCheckReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
 	controller, err := ctrl.NewControllerManagedBy(mgr).
 		For(&clusterv1.MachineHealthCheck{}).
+		// Deleted Machines are mapped too, so that the status no longer accounts for them as soon as they are gone.
+		// Updates are only mapped when they may change the health of the Machines or the MachineHealthChecks targeting them.
 		Watches(
 			&source.Kind{Type: &clusterv1.Machine{}},
 			handler.EnqueueRequestsFromMapFunc(r.machineToMachineHealthCheck),
+			builder.WithPredicates(predicates.MachineHealthChanged(ctrl.LoggerFrom(ctx))),
 		).
 		WithOptions(options).
 		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
@@ -98,7 +187,7 @@ func (r *MachineHealthCheckReconciler) SetupWithManager(ctx context.Context, mgr
 	}
 
 	r.controller = controller
-	r.recorder = mgr.GetEventRecorderFor("machinehealthcheck-controller")
+	r.recorder = mgr.GetEventRecorderFor(machineHealthCheckEventSource)
 	return nil
 }
 
@@ -129,6 +218,12 @@ func (r *MachineHealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, err
 	}
 
+	// Return early if the Cluster is not managed by this controller.
+	if !r.managesCluster(cluster) {
+		log.V(4).Info("Cluster does not match the cluster selector, skipping")
+		return ctrl.Result{}, nil
+	}
+
 	// Return early if the object or Cluster is paused.
 	if annotations.IsPaused(cluster, m) {
 		log.Info("Reconciliation is paused for this object")
@@ -143,10 +238,15 @@ func (r *MachineHealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.R
 	}
 
 	defer func() {
+		// Refresh the summary shown by kubectl from the status updated by this reconciliation.
+		m.Status.HealthSummary = m.Status.Summary()
+
 		// Always attempt to patch the object and status after each reconciliation.
-		// Patch ObservedGeneration only if the reconciliation completed successfully
+		// Patch ObservedGeneration and LastUpdated only if the reconciliation completed successfully
 		patchOpts := []patch.Option{}
 		if reterr == nil {
+			now := metav1.NewTime(r.now())
+			m.Status.LastUpdated = &now
 			patchOpts = append(patchOpts, patch.WithStatusObservedGeneration{})
 		}
 		if err := patchHelper.Patch(ctx, m, patchOpts...); err != nil {
@@ -160,18 +260,104 @@ func (r *MachineHealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.R
 	}
 	m.Labels[clusterv1.ClusterLabelName] = m.Spec.ClusterName
 
+	// The remediations triggered by this reconciliation are counted from the remediation budget, which may be reset.
+	totalRemediations := m.Status.TotalRemediations
+	if _, ok := m.Annotations[clusterv1.MachineHealthCheckResetRemediationBudgetAnnotation]; ok {
+		totalRemediations = 0
+	}
+
 	result, err := r.reconcile(ctx, log, cluster, m)
 	if err != nil {
 		log.Error(err, "Failed to reconcile MachineHealthCheck")
-		r.recorder.Eventf(m, corev1.EventTypeWarning, "ReconcileError", "%v", err)
+		r.recorder.Eventf(m, corev1.EventTypeWarning, EventReasonReconcileError, "%v", err)
 
 		// Requeue immediately if any errors occurred
 		return ctrl.Result{}, err
 	}
 
+	if err := r.reconcileClusterMachinesHealthy(ctx, cluster, m); err != nil {
+		log.Error(err, "Failed to update the MachinesHealthy condition of the Cluster")
+		return ctrl.Result{}, err
+	}
+
+	// The targets were health checked, unless the reconciliation is waiting for the kubeconfig of the Cluster.
+	if !conditions.Has(m, clusterv1.KubeconfigAvailableCondition) {
+		now := metav1.NewTime(r.now())
+		m.Status.LastSuccessfulReconcile = &now
+	}
+
+	logReconcileSummary(log, m, m.Status.TotalRemediations-totalRemediations)
 	return result, nil
 }
 
+// logReconcileSummary logs a single line summarizing the health of the targets of the MachineHealthCheck, and the
+// number of them flagged for remediation by the reconciliation.
+func logReconcileSummary(logger logr.Logger, m *clusterv1.MachineHealthCheck, flaggedForRemediation int32) {
+	logger.Info("Reconcile summary",
+		"mhc", m.Name,
+		"expected", m.Status.ExpectedMachines,
+		"healthy", m.Status.CurrentHealthy,
+		"unhealthy", m.Status.ExpectedMachines-m.Status.CurrentHealthy,
+		"flaggedForRemediation", flaggedForRemediation,
+		"remediationAllowed", conditions.IsTrue(m, clusterv1.RemediationAllowedCondition),
+	)
+}
+
+// reconcileClusterMachinesHealthy sets the MachinesHealthy condition on the Cluster, summarizing the status of
+// all the MachineHealthChecks of the Cluster.
+func (r *MachineHealthCheckReconciler) reconcileClusterMachinesHealthy(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.MachineHealthCheck) error {
+	mhcList := &clusterv1.MachineHealthCheckList{}
+	if err := r.Client.List(
+		ctx,
+		mhcList,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name},
+	); err != nil {
+		return errors.Wrap(err, "failed to list MachineHealthChecks")
+	}
+
+	// The status of the given MachineHealthCheck has not been patched yet, use the one computed by this reconcile.
+	mhcs := []*clusterv1.MachineHealthCheck{m}
+	for i := range mhcList.Items {
+		mhc := &mhcList.Items[i]
+		if mhc.Name == m.Name || mhc.Spec.ClusterName != cluster.Name || !mhc.DeletionTimestamp.IsZero() {
+			continue
+		}
+		mhcs = append(mhcs, mhc)
+	}
+
+	patchHelper, err := patch.NewHelper(cluster, r.Client)
+	if err != nil {
+		return err
+	}
+	setMachinesHealthyCondition(cluster, mhcs)
+	return patchHelper.Patch(ctx, cluster, patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+		clusterv1.MachinesHealthyCondition,
+	}})
+}
+
+// setMachinesHealthyCondition sets the MachinesHealthy condition on the Cluster, comparing the healthy Machines
+// to the expected Machines across the given MachineHealthChecks.
+func setMachinesHealthyCondition(cluster *clusterv1.Cluster, mhcs []*clusterv1.MachineHealthCheck) {
+	var healthy, expected int32
+	var unhealthy []string
+	for _, mhc := range mhcs {
+		healthy += mhc.Status.CurrentHealthy
+		expected += mhc.Status.ExpectedMachines
+		if mhc.Status.CurrentHealthy < mhc.Status.ExpectedMachines {
+			unhealthy = append(unhealthy, mhc.Name)
+		}
+	}
+
+	if len(unhealthy) == 0 {
+		conditions.MarkTrue(cluster, clusterv1.MachinesHealthyCondition)
+		return
+	}
+	sort.Strings(unhealthy)
+	conditions.MarkFalse(cluster, clusterv1.MachinesHealthyCondition, clusterv1.UnhealthyMachinesReason, clusterv1.ConditionSeverityWarning,
+		"%d of %d machines are healthy; MachineHealthCheck(s) with unhealthy machines: %s", healthy, expected, strings.Join(unhealthy, ", "))
+}
+
 func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger logr.Logger, cluster *clusterv1.Cluster, m *clusterv1.MachineHealthCheck) (ctrl.Result, error) {
 	// Ensure the MachineHealthCheck is owned by the Cluster it belongs to
 	m.OwnerReferences = util.EnsureOwnerRef(m.OwnerReferences, metav1.OwnerReference{
@@ -181,6 +367,20 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 		UID:        cluster.UID,
 	})
 
+	// Without the kubeconfig no remote client can be created; instead of failing on each reconcile, report it and
+	// retry with backoff, leaving the status observed last untouched rather than reporting no healthy Machines.
+	if _, err := secret.Get(ctx, r.Client, util.ObjectKey(cluster), secret.Kubeconfig); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logger.Error(err, "Failed to fetch the Cluster kubeconfig Secret")
+			return ctrl.Result{}, err
+		}
+		logger.Info("Cluster kubeconfig Secret is missing, waiting for it to be created")
+		conditions.MarkFalse(m, clusterv1.KubeconfigAvailableCondition, clusterv1.KubeconfigMissingReason, clusterv1.ConditionSeverityWarning,
+			"Secret %s not found", secret.Name(cluster.Name, secret.Kubeconfig))
+		return ctrl.Result{Requeue: true}, nil
+	}
+	conditions.Delete(m, clusterv1.KubeconfigAvailableCondition)
+
 	// Get the remote cluster cache to use as a client.Reader.
 	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
 	if err != nil {
@@ -193,6 +393,23 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 		return ctrl.Result{}, err
 	}
 
+	// Warn about other MachineHealthChecks which may target the same machines, as they are counted, and possibly
+	// remediated, by each of them; this does not block remediation.
+	overlapping, err := m.OverlappingMachineHealthChecks(ctx, r.Client)
+	if err != nil {
+		logger.Error(err, "Failed to check MachineHealthCheck selectors for overlaps")
+		return ctrl.Result{}, err
+	}
+	if len(overlapping) > 0 {
+		logger.Info("MachineHealthCheck selector overlaps with other MachineHealthChecks", "machineHealthChecks", overlapping)
+		conditions.MarkFalse(m, clusterv1.SelectorExclusiveCondition, clusterv1.SelectorOverlapReason, clusterv1.ConditionSeverityWarning,
+			"Selector overlaps with MachineHealthCheck(s) %s", strings.Join(overlapping, ", "))
+	} else {
+		conditions.Delete(m, clusterv1.SelectorExclusiveCondition)
+	}
+
+	reconcileRemediationBudget(logger, m)
+
 	// fetch all targets
 	logger.V(3).Info("Finding targets")
 	targets, err := r.getTargetsFromMHC(ctx, logger, remoteClient, cluster, m)
@@ -200,18 +417,43 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 		logger.Error(err, "Failed to fetch targets from MachineHealthCheck")
 		return ctrl.Result{}, err
 	}
-	totalTargets := len(targets)
-	m.Status.ExpectedMachines = int32(totalTargets)
-	m.Status.Targets = make([]string, totalTargets)
+	m.Status.Targets = make([]string, len(targets))
 	for i, t := range targets {
 		m.Status.Targets[i] = t.Machine.Name
 	}
 	// do sort to avoid keep changing m.Status as the returned machines are not in order
 	sort.Strings(m.Status.Targets)
 
+	totalTargets := countExpectedTargets(m, targets)
+	m.Status.ExpectedMachines = int32(totalTargets)
+
 	// health check all targets and reconcile mhc status
-	healthy, unhealthy, nextCheckTimes := r.healthCheckTargets(targets, logger, m.Spec.NodeStartupTimeout.Duration)
-	m.Status.CurrentHealthy = int32(len(healthy))
+	healthy, unhealthy, nextCheckTimes := r.healthCheckTargets(ctx, targets, logger, m.Spec.NodeStartupTimeout.Duration)
+	m.Status.CurrentHealthy = int32(countExpectedTargets(m, healthy))
+	m.Status.CurrentSuspect = int32(countExpectedTargets(m, suspectTargets(targets, healthy, unhealthy, r.now())))
+
+	// During a rolling update, the machines of the other MachineSets of the MachineDeployments of the targets may
+	// not be selected; they still count towards the remediation budget of the deployment as a whole.
+	if m.Spec.RemediationBudgetScope == clusterv1.RemediationBudgetScopeMachineDeployment {
+		siblings, err := r.getMachineDeploymentSiblings(ctx, m, targets)
+		if err != nil {
+			logger.Error(err, "Failed to fetch the other machines of the MachineDeployments of the targets")
+			return ctrl.Result{}, err
+		}
+		expectedSiblings, healthySiblings := countMachineDeploymentSiblings(m, siblings)
+		totalTargets += expectedSiblings
+		m.Status.ExpectedMachines += int32(expectedSiblings)
+		m.Status.CurrentHealthy += int32(healthySiblings)
+	}
+	r.clearStaleRemediationConditions(logger, m, healthy)
+	if m.Annotations[clusterv1.MachineHealthCheckListUnhealthyTargetsAnnotation] == "true" {
+		setUnhealthyTargetConditions(m, unhealthy)
+	} else {
+		// Remove the conditions listed before the annotation was removed, if any.
+		setUnhealthyTargetConditions(m, nil)
+	}
+	reconcileAwaitingReplacement(m, healthy, r.now())
+	reconcileUpgradeInProgress(cluster, m)
 
 	var unhealthyLimitKey, unhealthyLimitValue interface{}
 
@@ -221,17 +463,83 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 		return ctrl.Result{}, errors.Wrapf(err, "error checking if remediation is allowed")
 	}
 
+	// Machines across the Cluster going unhealthy at once are more likely the symptom of an outage of the control
+	// plane or of the network than of independent failures, and remediating all of them would make things worse.
+	outageSuspected, outageMessage, err := r.clusterWideOutageSuspected(ctx, cluster, m, targets)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "error checking for a cluster-wide outage")
+	}
+	if outageSuspected {
+		logger.V(3).Info(
+			"Short-circuiting remediation, a cluster-wide outage is suspected",
+			"total target", totalTargets,
+			"unhealthy targets", len(unhealthy),
+		)
+		conditions.Set(m, &clusterv1.Condition{
+			Type:    clusterv1.ClusterWideOutageSuspectedCondition,
+			Status:  corev1.ConditionTrue,
+			Reason:  clusterv1.ClusterOutageThresholdReachedReason,
+			Message: outageMessage,
+		})
+		return r.suppressRemediation(ctx, logger, m, healthy, unhealthy, clusterv1.ClusterOutageThresholdReachedReason, clusterv1.ConditionSeverityWarning, outageMessage)
+	}
+	conditions.Delete(m, clusterv1.ClusterWideOutageSuspectedCondition)
+
+	// Many nodes whose status went stale at once are more likely the symptom of broken reporting of the node status,
+	// e.g. of the node lifecycle controller, than of unhealthy nodes, which remediating their machines would not fix.
+	staleNodeStatus, staleNodeStatusMessage, err := staleNodeStatusSuspected(m, targets, r.now())
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "error checking for stale node status")
+	}
+	if staleNodeStatus {
+		logger.V(3).Info(
+			"Short-circuiting remediation, the reporting of the node status is suspected to be broken",
+			"total target", totalTargets,
+			"unhealthy targets", len(unhealthy),
+		)
+		conditions.Set(m, &clusterv1.Condition{
+			Type:    clusterv1.NodeStatusStaleCondition,
+			Status:  corev1.ConditionTrue,
+			Reason:  clusterv1.StaleNodeStatusThresholdReachedReason,
+			Message: staleNodeStatusMessage,
+		})
+		return r.suppressRemediation(ctx, logger, m, healthy, unhealthy, clusterv1.StaleNodeStatusThresholdReachedReason, clusterv1.ConditionSeverityWarning, staleNodeStatusMessage)
+	}
+	conditions.Delete(m, clusterv1.NodeStatusStaleCondition)
+
+	// Machines are expected to be briefly unhealthy while the Cluster is being upgraded; their health is still
+	// reported, but they are not remediated until the upgrade completes.
+	if remediationPausedForUpgrade(cluster, m) {
+		message := "Remediation is paused while the cluster upgrade is in progress"
+		logger.V(3).Info(
+			"Pausing remediation until the cluster upgrade completes",
+			"total target", totalTargets,
+			"unhealthy targets", len(unhealthy),
+		)
+		return r.suppressRemediation(ctx, logger, m, healthy, unhealthy, clusterv1.UpgradeInProgressReason, clusterv1.ConditionSeverityInfo, message)
+	}
+
 	if !remediationAllowed {
 		var message string
 
-		if m.Spec.UnhealthyRange == nil {
+		// isAllowedRemediation already succeeded, so MinHealthy is known to be valid.
+		belowMinHealthy, _ := isBelowMinHealthy(m)
+		switch {
+		case belowMinHealthy:
+			unhealthyLimitKey = "min healthy"
+			unhealthyLimitValue = m.Spec.MinHealthy
+			message = fmt.Sprintf("Remediation is not allowed, the number of healthy machines is below minHealthy (total: %v, healthy: %v, minHealthy: %v)",
+				totalTargets,
+				m.Status.CurrentHealthy,
+				m.Spec.MinHealthy)
+		case m.Spec.UnhealthyRange == nil:
 			unhealthyLimitKey = "max unhealthy"
 			unhealthyLimitValue = m.Spec.MaxUnhealthy
 			message = fmt.Sprintf("Remediation is not allowed, the number of not started or unhealthy machines exceeds maxUnhealthy (total: %v, unhealthy: %v, maxUnhealthy: %v)",
 				totalTargets,
 				len(unhealthy),
 				m.Spec.MaxUnhealthy)
-		} else {
+		default:
 			unhealthyLimitKey = "unhealthy range"
 			unhealthyLimitValue = *m.Spec.UnhealthyRange
 			message = fmt.Sprintf("Remediation is not allowed, the number of not started or unhealthy machines does not fall within the range (total: %v, unhealthy: %v, unhealthyRange: %v)",
@@ -247,7 +555,8 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 			"unhealthy targets", len(unhealthy),
 		)
 
-		// Remediation not allowed, the number of not started or unhealthy machines either exceeds maxUnhealthy (or) not within unhealthyRange
+		// Remediation not allowed, the number of not started or unhealthy machines either exceeds maxUnhealthy (or) not within unhealthyRange,
+		// or the number of healthy machines is below minHealthy
 		m.Status.RemediationsAllowed = 0
 		conditions.Set(m, &clusterv1.Condition{
 			Type:     clusterv1.RemediationAllowedCondition,
@@ -260,11 +569,12 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 		r.recorder.Eventf(
 			m,
 			corev1.EventTypeWarning,
-			EventRemediationRestricted,
+			EventReasonRemediationSkipped,
 			message,
 		)
 		errList := []error{}
 		for _, t := range append(healthy, unhealthy...) {
+			explainHealthCheck(logger, t, remediationAllowedExplanation(m), r.now())
 			if err := t.patchHelper.Patch(ctx, t.Machine); err != nil {
 				errList = append(errList, errors.Wrapf(err, "failed to patch machine status for machine: %s/%s", t.Machine.Namespace, t.Machine.Name))
 				continue
@@ -287,8 +597,40 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 	m.Status.RemediationsAllowed = remediationCount
 	conditions.MarkTrue(m, clusterv1.RemediationAllowedCondition)
 
+	// Machines in a failure domain which is down are not remediated, but are still patched to report their health.
+	unhealthy, outage, downFailureDomains := splitFailureDomainOutages(m, targets, unhealthy)
+	if len(downFailureDomains) > 0 {
+		logger.V(3).Info("Short-circuiting remediation in failure domains with all targets unhealthy", "failure domains", downFailureDomains)
+		r.recorder.Eventf(
+			m,
+			corev1.EventTypeWarning,
+			EventReasonRemediationSkipped,
+			"Remediation is not allowed in failure domains %v, all of their machines are unhealthy",
+			downFailureDomains,
+		)
+	}
+
+	if err := r.sortUnhealthyTargets(ctx, cluster, m, unhealthy); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to order unhealthy targets for remediation")
+	}
+
+	// Explain the decision to operators, on the targets patched below.
+	for _, t := range append(healthy, unhealthy...) {
+		explainHealthCheck(logger, t, remediationAllowedExplanation(m), r.now())
+	}
+	for _, t := range outage {
+		explainHealthCheck(logger, t, remediationExplanation{
+			Message: fmt.Sprintf("Remediation is not allowed in failure domain %s, all of its machines are unhealthy", t.failureDomain()),
+		}, r.now())
+	}
+
 	errList := r.PatchUnhealthyTargets(ctx, logger, unhealthy, cluster, m)
 	errList = append(errList, r.PatchHealthyTargets(ctx, logger, healthy, cluster, m)...)
+	for _, t := range outage {
+		if err := t.patchHelper.Patch(ctx, t.Machine); err != nil {
+			errList = append(errList, errors.Wrapf(err, "failed to patch machine status for machine: %s/%s", t.Machine.Namespace, t.Machine.Name))
+		}
+	}
 
 	// handle update errors
 	if len(errList) > 0 {
@@ -296,6 +638,32 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 		return reconcile.Result{}, kerrors.NewAggregate(errList)
 	}
 
+	// Ensure targets whose remediation awaits a replacement are remediated once the replacement timeout expires.
+	if conditions.IsTrue(m, clusterv1.AwaitingReplacementCondition) && len(unhealthy) > 0 {
+		nextCheckTimes = append(nextCheckTimes, m.Status.LastRemediationTime.Add(m.Spec.ReplacementTimeout.Duration).Sub(r.now()))
+	}
+
+	// Ensure targets whose remediation is staggered are remediated once the stagger interval has elapsed.
+	if staggerRemaining := remediationStaggerRemaining(m, r.now()); staggerRemaining > 0 && len(unhealthy) > 0 {
+		nextCheckTimes = append(nextCheckTimes, staggerRemaining)
+	}
+
+	// Ensure targets whose remediation is deferred by a maintenance window are remediated once it closes.
+	if maintenanceRemaining := r.remediationDeferredForMaintenance(logger, m); maintenanceRemaining > 0 && len(unhealthy) > 0 {
+		nextCheckTimes = append(nextCheckTimes, maintenanceRemaining)
+	}
+
+	// Ensure targets waiting to recover are remediated once their grace period has elapsed.
+	for _, t := range unhealthy {
+		if remaining, cordoned := remediationGracePeriodRemaining(m, t.Machine, r.now()); cordoned && remaining > 0 {
+			nextCheckTimes = append(nextCheckTimes, remaining)
+		}
+		// Changes to the PodDisruptionBudgets of the workload cluster are not watched.
+		if conditions.IsFalse(t.Machine, clusterv1.DrainAllowedCondition) {
+			nextCheckTimes = append(nextCheckTimes, drainBlockedRecheckInterval)
+		}
+	}
+
 	if minNextCheck := minDuration(nextCheckTimes); minNextCheck > 0 {
 		logger.V(3).Info("Some targets might go unhealthy. Ensuring a requeue happens", "requeueIn", minNextCheck.Truncate(time.Second).String())
 		return ctrl.Result{RequeueAfter: minNextCheck}, nil
@@ -306,6 +674,85 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 	return ctrl.Result{}, nil
 }
 
+// clearStaleRemediationConditions clears the MachineOwnerRemediatedCondition of the healthy targets on the first
+// reconcile of the MachineHealthCheck since the controller started. The condition may have been set during an outage
+// which was over by the time the controller restarted, or restored from an etcd backup, and would otherwise get
+// the owners of these Machines to delete them. The targets are patched by the caller.
+func (r *MachineHealthCheckReconciler) clearStaleRemediationConditions(logger logr.Logger, m *clusterv1.MachineHealthCheck, healthy []healthCheckTarget) {
+	if _, reconciled := r.reconciledSinceStartup.LoadOrStore(m.UID, struct{}{}); reconciled {
+		return
+	}
+
+	for _, t := range healthy {
+		if conditions.IsFalse(t.Machine, clusterv1.MachineOwnerRemediatedCondition) {
+			logger.Info("Clearing stale remediation condition of healthy target", "target", t.string())
+			conditions.Delete(t.Machine, clusterv1.MachineOwnerRemediatedCondition)
+		}
+	}
+}
+
+// maxUnhealthyTargetConditions bounds the number of unhealthy targets listed in the conditions of a
+// MachineHealthCheck, so that large selectors don't balloon the object.
+const maxUnhealthyTargetConditions = 10
+
+// unhealthyTargetConditionType returns the type of the condition listing the given unhealthy Machine on its
+// MachineHealthCheck, e.g. "machine-1/HealthCheckSucceeded".
+func unhealthyTargetConditionType(machineName string) clusterv1.ConditionType {
+	return clusterv1.ConditionType(machineName + "/" + string(clusterv1.MachineHealthCheckSuccededCondition))
+}
+
+// isUnhealthyTargetConditionType returns whether the given condition type lists an unhealthy Machine.
+func isUnhealthyTargetConditionType(conditionType clusterv1.ConditionType) bool {
+	return strings.HasSuffix(string(conditionType), "/"+string(clusterv1.MachineHealthCheckSuccededCondition))
+}
+
+// setUnhealthyTargetConditions lists the unhealthy targets in the conditions of the MachineHealthCheck, so that
+// kubectl describe shows them, with the status, reason and message of their MachineHealthCheckSuccededCondition.
+// At most maxUnhealthyTargetConditions targets are listed, by name; the UnhealthyTargetsListedCondition is set
+// to false when more are unhealthy. The conditions of the targets which are no longer unhealthy are removed.
+// It is only called with the unhealthy targets of MachineHealthChecks with the
+// MachineHealthCheckListUnhealthyTargetsAnnotation, the object growing with each listed condition.
+func setUnhealthyTargetConditions(m *clusterv1.MachineHealthCheck, unhealthy []healthCheckTarget) {
+	sorted := make([]healthCheckTarget, len(unhealthy))
+	copy(sorted, unhealthy)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Machine.Name < sorted[j].Machine.Name
+	})
+
+	listed := sets.NewString()
+	for _, t := range sorted {
+		if listed.Len() == maxUnhealthyTargetConditions {
+			break
+		}
+		condition := conditions.Get(t.Machine, clusterv1.MachineHealthCheckSuccededCondition)
+		if condition == nil {
+			continue
+		}
+		conditionType := unhealthyTargetConditionType(t.Machine.Name)
+		conditions.Set(m, &clusterv1.Condition{
+			Type:     conditionType,
+			Status:   condition.Status,
+			Severity: condition.Severity,
+			Reason:   condition.Reason,
+			Message:  condition.Message,
+		})
+		listed.Insert(string(conditionType))
+	}
+
+	for _, c := range m.GetConditions() {
+		if isUnhealthyTargetConditionType(c.Type) && !listed.Has(string(c.Type)) {
+			conditions.Delete(m, c.Type)
+		}
+	}
+
+	if len(unhealthy) > listed.Len() {
+		conditions.MarkFalse(m, clusterv1.UnhealthyTargetsListedCondition, clusterv1.TooManyUnhealthyTargetsReason, clusterv1.ConditionSeverityInfo,
+			"%d of the %d unhealthy machines are listed in the conditions", listed.Len(), len(unhealthy))
+		return
+	}
+	conditions.Delete(m, clusterv1.UnhealthyTargetsListedCondition)
+}
+
 // PatchHealthyTargets patches healthy machines with MachineHealthCheckSuccededCondition.
 func (r *MachineHealthCheckReconciler) PatchHealthyTargets(ctx context.Context, logger logr.Logger, healthy []healthCheckTarget, cluster *clusterv1.Cluster, m *clusterv1.MachineHealthCheck) []error {
 	errList := []error{}
@@ -329,6 +776,15 @@ func (r *MachineHealthCheckReconciler) PatchHealthyTargets(ctx context.Context,
 			}
 		}
 
+		if err := r.uncordonRecovered(ctx, logger, t, cluster); err != nil {
+			errList = append(errList, err)
+			continue
+		}
+		clearPendingRemediation(logger, t)
+		conditions.Delete(t.Machine, clusterv1.DrainAllowedCondition)
+		// Reverts the AnnotateOnly remediation strategy for a target which is healthy again.
+		delete(t.Machine.Annotations, clusterv1.MachineNeedsRemediationAnnotation)
+
 		if err := t.patchHelper.Patch(ctx, t.Machine); err != nil {
 			logger.Error(err, "failed to patch healthy machine status for machine", "machine", t.Machine.GetName())
 			errList = append(errList, errors.Wrapf(err, "failed to patch healthy machine status for machine: %s/%s", t.Machine.Namespace, t.Machine.Name))
@@ -337,72 +793,104 @@ func (r *MachineHealthCheckReconciler) PatchHealthyTargets(ctx context.Context,
 	return errList
 }
 
+// clearPendingRemediation clears the remediation requested for a target which is healthy again, e.g. as its node
+// became Ready, in the same pass as its MachineHealthCheckSuccededCondition is set, as long as the owner of the
+// Machine is still waiting to remediate it. A remediation which already started is completed by the owner.
+func clearPendingRemediation(logger logr.Logger, t healthCheckTarget) {
+	if !conditions.IsFalse(t.Machine, clusterv1.MachineOwnerRemediatedCondition) ||
+		conditions.GetReason(t.Machine, clusterv1.MachineOwnerRemediatedCondition) != clusterv1.WaitingForRemediationReason {
+		return
+	}
+
+	logger.Info("Target is healthy again, clearing its pending remediation", "target", t.string())
+	conditions.Delete(t.Machine, clusterv1.MachineOwnerRemediatedCondition)
+	delete(t.Machine.Annotations, clusterv1.MachineRemediationReasonAnnotation)
+}
+
 // PatchUnhealthyTargets patches machines with MachineOwnerRemediatedCondition for remediation.
 func (r *MachineHealthCheckReconciler) PatchUnhealthyTargets(ctx context.Context, logger logr.Logger, unhealthy []healthCheckTarget, cluster *clusterv1.Cluster, m *clusterv1.MachineHealthCheck) []error {
 	// mark for remediation
 	errList := []error{}
+	maintenanceRemaining := r.remediationDeferredForMaintenance(logger, m)
 	for _, t := range unhealthy {
 		condition := conditions.Get(t.Machine, clusterv1.MachineHealthCheckSuccededCondition)
 
-		if annotations.IsPaused(cluster, t.Machine) {
-			logger.Info("Machine has failed health check, but machine is paused so skipping remediation", "target", t.string(), "reason", condition.Reason, "message", condition.Message)
-		} else {
-			if m.Spec.RemediationTemplate != nil {
-				// If external remediation request already exists,
-				// return early
-				if r.externalRemediationRequestExists(ctx, m, t.Machine.Name) {
-					return errList
-				}
+		deferred, err := r.deferRemediation(ctx, logger, t, condition, cluster, m, maintenanceRemaining)
+		if err != nil {
+			errList = append(errList, err)
+			continue
+		}
+		if deferred {
+			if err := t.patchHelper.Patch(ctx, t.Machine); err != nil {
+				errList = append(errList, errors.Wrapf(err, "failed to patch unhealthy machine status for machine: %s/%s", t.Machine.Namespace, t.Machine.Name))
+			}
+			continue
+		}
 
-				cloneOwnerRef := &metav1.OwnerReference{
-					APIVersion: clusterv1.GroupVersion.String(),
-					Kind:       "Machine",
-					Name:       t.Machine.Name,
-					UID:        t.Machine.UID,
-				}
+		// flagged is set when the target is newly marked for remediation in this reconcile, so that it is notified once.
+		flagged := false
+		if m.Spec.RemediationStrategy != nil && m.Spec.RemediationStrategy.Type == clusterv1.RemediationStrategyAnnotateOnly {
+			flagged = annotateNeedsRemediation(logger, t, condition, m, r.now())
+		} else if m.Spec.RemediationTemplate != nil {
+			// If external remediation request already exists,
+			// return early
+			if r.externalRemediationRequestExists(ctx, m, t.Machine.Name) {
+				return errList
+			}
 
-				from, err := external.Get(ctx, r.Client, m.Spec.RemediationTemplate, t.Machine.Namespace)
-				if err != nil {
-					conditions.MarkFalse(m, clusterv1.ExternalRemediationTemplateAvailable, clusterv1.ExternalRemediationTemplateNotFound, clusterv1.ConditionSeverityError, err.Error())
-					errList = append(errList, errors.Wrapf(err, "error retrieving remediation template %v %q for machine %q in namespace %q within cluster %q", m.Spec.RemediationTemplate.GroupVersionKind(), m.Spec.RemediationTemplate.Name, t.Machine.Name, t.Machine.Namespace, m.Spec.ClusterName))
-					return errList
-				}
+			cloneOwnerRef := &metav1.OwnerReference{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "Machine",
+				Name:       t.Machine.Name,
+				UID:        t.Machine.UID,
+			}
 
-				generateTemplateInput := &external.GenerateTemplateInput{
-					Template:    from,
-					TemplateRef: m.Spec.RemediationTemplate,
-					Namespace:   t.Machine.Namespace,
-					ClusterName: t.Machine.ClusterName,
-					OwnerRef:    cloneOwnerRef,
-				}
-				to, err := external.GenerateTemplate(generateTemplateInput)
-				if err != nil {
-					errList = append(errList, errors.Wrapf(err, "failed to create template for remediation request %v %q for machine %q in namespace %q within cluster %q", m.Spec.RemediationTemplate.GroupVersionKind(), m.Spec.RemediationTemplate.Name, t.Machine.Name, t.Machine.Namespace, m.Spec.ClusterName))
-					return errList
-				}
+			from, err := external.Get(ctx, r.Client, m.Spec.RemediationTemplate, t.Machine.Namespace)
+			if err != nil {
+				conditions.MarkFalse(m, clusterv1.ExternalRemediationTemplateAvailable, clusterv1.ExternalRemediationTemplateNotFound, clusterv1.ConditionSeverityError, err.Error())
+				errList = append(errList, errors.Wrapf(err, "error retrieving remediation template %v %q for machine %q in namespace %q within cluster %q", m.Spec.RemediationTemplate.GroupVersionKind(), m.Spec.RemediationTemplate.Name, t.Machine.Name, t.Machine.Namespace, m.Spec.ClusterName))
+				return errList
+			}
 
-				// Set the Remediation Request to match the Machine name, the name is used to
-				// guarantee uniqueness between runs. A Machine should only ever have a single
-				// remediation object of a specific GVK created.
-				//
-				// NOTE: This doesn't guarantee uniqueness across different MHC objects watching
-				// the same Machine, users are in charge of setting health checks and remediation properly.
-				to.SetName(t.Machine.Name)
-
-				logger.Info("Target has failed health check, creating an external remediation request", "remediation request name", to.GetName(), "target", t.string(), "reason", condition.Reason, "message", condition.Message)
-				// Create the external clone.
-				if err := r.Client.Create(ctx, to); err != nil {
-					conditions.MarkFalse(m, clusterv1.ExternalRemediationRequestAvailable, clusterv1.ExternalRemediationRequestCreationFailed, clusterv1.ConditionSeverityError, err.Error())
-					errList = append(errList, errors.Wrapf(err, "error creating remediation request for machine %q in namespace %q within cluster %q", t.Machine.Name, t.Machine.Namespace, t.Machine.ClusterName))
-					return errList
-				}
-			} else {
-				logger.Info("Target has failed health check, marking for remediation", "target", t.string(), "reason", condition.Reason, "message", condition.Message)
-				// NOTE: MHC is responsible for creating MachineOwnerRemediatedCondition if missing or to trigger another remediation if the previous one is completed;
-				// instead, if a remediation is in already progress, the remediation owner is responsible for completing the process and MHC should not overwrite the condition.
-				if !conditions.Has(t.Machine, clusterv1.MachineOwnerRemediatedCondition) || conditions.IsTrue(t.Machine, clusterv1.MachineOwnerRemediatedCondition) {
-					conditions.MarkFalse(t.Machine, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "")
-				}
+			generateTemplateInput := &external.GenerateTemplateInput{
+				Template:    from,
+				TemplateRef: m.Spec.RemediationTemplate,
+				Namespace:   t.Machine.Namespace,
+				ClusterName: t.Machine.ClusterName,
+				OwnerRef:    cloneOwnerRef,
+			}
+			to, err := external.GenerateTemplate(generateTemplateInput)
+			if err != nil {
+				errList = append(errList, errors.Wrapf(err, "failed to create template for remediation request %v %q for machine %q in namespace %q within cluster %q", m.Spec.RemediationTemplate.GroupVersionKind(), m.Spec.RemediationTemplate.Name, t.Machine.Name, t.Machine.Namespace, m.Spec.ClusterName))
+				return errList
+			}
+
+			// Set the Remediation Request to match the Machine name, the name is used to
+			// guarantee uniqueness between runs. A Machine should only ever have a single
+			// remediation object of a specific GVK created.
+			//
+			// NOTE: This doesn't guarantee uniqueness across different MHC objects watching
+			// the same Machine, users are in charge of setting health checks and remediation properly.
+			to.SetName(t.Machine.Name)
+
+			logger.Info("Target has failed health check, creating an external remediation request", "remediation request name", to.GetName(), "target", t.string(), "reason", condition.Reason, "message", condition.Message)
+			// Create the external clone.
+			if err := r.Client.Create(ctx, to); err != nil {
+				conditions.MarkFalse(m, clusterv1.ExternalRemediationRequestAvailable, clusterv1.ExternalRemediationRequestCreationFailed, clusterv1.ConditionSeverityError, err.Error())
+				errList = append(errList, errors.Wrapf(err, "error creating remediation request for machine %q in namespace %q within cluster %q", t.Machine.Name, t.Machine.Namespace, t.Machine.ClusterName))
+				return errList
+			}
+			recordRemediation(m, r.now())
+			flagged = true
+		} else {
+			logger.Info("Target has failed health check, marking for remediation", "target", t.string(), "reason", condition.Reason, "message", condition.Message)
+			// NOTE: MHC is responsible for creating MachineOwnerRemediatedCondition if missing or to trigger another remediation if the previous one is completed;
+			// instead, if a remediation is in already progress, the remediation owner is responsible for completing the process and MHC should not overwrite the condition.
+			if !conditions.Has(t.Machine, clusterv1.MachineOwnerRemediatedCondition) || conditions.IsTrue(t.Machine, clusterv1.MachineOwnerRemediatedCondition) {
+				conditions.MarkFalse(t.Machine, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "")
+				annotations.AddAnnotations(t.Machine, map[string]string{clusterv1.MachineRemediationReasonAnnotation: remediationReason(condition)})
+				recordRemediation(m, r.now())
+				flagged = true
 			}
 		}
 
@@ -413,14 +901,657 @@ func (r *MachineHealthCheckReconciler) PatchUnhealthyTargets(ctx context.Context
 		r.recorder.Eventf(
 			t.Machine,
 			corev1.EventTypeNormal,
-			EventMachineMarkedUnhealthy,
+			EventReasonRemediationTriggered,
 			"Machine %v has been marked as unhealthy",
 			t.string(),
 		)
+
+		if !flagged {
+			continue
+		}
+		r.recordNodeEvent(ctx, logger, cluster, t, EventReasonRemediationTriggered, fmt.Sprintf("Machine %v has been marked as unhealthy", t.string()))
+
+		notification := RemediationNotification{
+			Cluster:   cluster.Name,
+			Namespace: t.Machine.Namespace,
+			Machine:   t.Machine.Name,
+			Reason:    remediationReason(condition),
+			Timestamp: metav1.NewTime(r.now()),
+		}
+		if t.Node != nil {
+			notification.Node = t.Node.Name
+		}
+		r.notifyRemediationWebhook(logger, notification)
 	}
 	return errList
 }
 
+// deferRemediation returns true if the remediation of an unhealthy target has to be held off for now, logging why.
+// The target still has to be patched by the caller, to persist its MachineHealthCheckSucceeded condition.
+func (r *MachineHealthCheckReconciler) deferRemediation(ctx context.Context, logger logr.Logger, t healthCheckTarget, condition *clusterv1.Condition, cluster *clusterv1.Cluster, m *clusterv1.MachineHealthCheck, maintenanceRemaining time.Duration) (bool, error) {
+	switch {
+	case t.alertOnly:
+		logger.Info("Target has failed health check on AlertOnly conditions, skipping remediation", "target", t.string(), "reason", condition.Reason, "message", condition.Message)
+		return true, nil
+	case annotations.IsPaused(cluster, t.Machine):
+		logger.Info("Machine has failed health check, but machine is paused so skipping remediation", "target", t.string(), "reason", condition.Reason, "message", condition.Message)
+		return true, nil
+	case remediationBudgetExhausted(m):
+		logger.Info("Machine has failed health check, but the remediation budget is exhausted so skipping remediation", "target", t.string(), "reason", condition.Reason, "message", condition.Message)
+		return true, nil
+	case conditions.IsTrue(m, clusterv1.AwaitingReplacementCondition):
+		logger.Info("Machine has failed health check, but the replacement of the last remediated machine is not healthy yet so deferring remediation", "target", t.string(), "reason", condition.Reason, "message", condition.Message)
+		return true, nil
+	case maintenanceRemaining > 0:
+		logger.Info("Machine has failed health check, but a maintenance window is in progress so deferring remediation", "target", t.string(), "reason", condition.Reason, "message", condition.Message, "timeUntilWindowCloses", maintenanceRemaining.Truncate(time.Second).String())
+		return true, nil
+	}
+	if staggerRemaining := remediationStaggerRemaining(m, r.now()); staggerRemaining > 0 {
+		logger.Info("Machine has failed health check, but another machine was remediated recently so staggering remediation", "target", t.string(), "reason", condition.Reason, "message", condition.Message, "timeUntilNextRemediation", staggerRemaining.Truncate(time.Second).String())
+		return true, nil
+	}
+
+	// The AnnotateOnly strategy leaves the Node alone, so there is nothing to wait for.
+	if m.Spec.RemediationStrategy != nil && m.Spec.RemediationStrategy.Type == clusterv1.RemediationStrategyAnnotateOnly {
+		return false, nil
+	}
+
+	waiting, err := r.cordonAndWait(ctx, logger, t, cluster, m)
+	if err != nil || waiting {
+		return waiting, err
+	}
+	return r.deferRemediationOnBlockingPDB(ctx, logger, t, cluster, m)
+}
+
+// annotateNeedsRemediation implements the AnnotateOnly remediation strategy: the target is annotated as needing
+// remediation, e.g. for a GitOps tool to delete it, but never marked for remediation by its owner.
+// It returns true if the target was not annotated yet.
+func annotateNeedsRemediation(logger logr.Logger, t healthCheckTarget, condition *clusterv1.Condition, m *clusterv1.MachineHealthCheck, now time.Time) bool {
+	if t.Machine.GetAnnotations()[clusterv1.MachineNeedsRemediationAnnotation] == "true" {
+		return false
+	}
+
+	logger.Info("Target has failed health check, annotating it as needing remediation", "target", t.string(), "reason", condition.Reason, "message", condition.Message)
+	annotations.AddAnnotations(t.Machine, map[string]string{
+		clusterv1.MachineNeedsRemediationAnnotation:  "true",
+		clusterv1.MachineRemediationReasonAnnotation: remediationReason(condition),
+	})
+	recordRemediation(m, now)
+	return true
+}
+
+// remediationReason returns the value of the MachineRemediationReasonAnnotation for the given failed
+// MachineHealthCheckSucceeded condition.
+func remediationReason(condition *clusterv1.Condition) string {
+	if condition.Message == "" {
+		return condition.Reason
+	}
+	return fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+}
+
+// recordNodeEvent records an Event on the Node of the target in the workload cluster, so that it shows up
+// when describing the Node. It is recorded once, when the target is marked for remediation. Failures are only
+// logged, as the Event is informational.
+func (r *MachineHealthCheckReconciler) recordNodeEvent(ctx context.Context, logger logr.Logger, cluster *clusterv1.Cluster, t healthCheckTarget, reason, message string) {
+	if t.Node == nil || t.Node.Name == "" {
+		return
+	}
+
+	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		logger.Error(err, "failed to get workload cluster client to record node event", "node", t.Node.Name)
+		return
+	}
+
+	now := metav1.NewTime(r.now())
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("%v.%x", t.Node.Name, now.UnixNano()),
+			// Node events live in the default namespace, as for the ones recorded by the kubelet.
+			Namespace: metav1.NamespaceDefault,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Node",
+			Name:       t.Node.Name,
+			UID:        t.Node.UID,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           corev1.EventTypeNormal,
+		Source:         corev1.EventSource{Component: machineHealthCheckEventSource},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if err := remoteClient.Create(ctx, event); err != nil {
+		logger.Error(err, "failed to record event on node", "node", t.Node.Name)
+	}
+}
+
+// cordonAndWait implements the CordonAndWait remediation strategy: the first time a target is found unhealthy
+// its Node is cordoned, then the target is given the strategy grace period to recover.
+// It returns true as long as the remediation of the target has to be held off.
+func (r *MachineHealthCheckReconciler) cordonAndWait(ctx context.Context, logger logr.Logger, t healthCheckTarget, cluster *clusterv1.Cluster, m *clusterv1.MachineHealthCheck) (bool, error) {
+	// Without a Node there is nothing which could recover, so the target is remediated right away.
+	if m.Spec.RemediationStrategy == nil || m.Spec.RemediationStrategy.Type != clusterv1.RemediationStrategyCordonAndWait || t.Node == nil {
+		return false, nil
+	}
+
+	if remaining, cordoned := remediationGracePeriodRemaining(m, t.Machine, r.now()); cordoned {
+		return remaining > 0, nil
+	}
+
+	if err := r.setNodeUnschedulable(ctx, cluster, t.Node, true); err != nil {
+		return false, errors.Wrapf(err, "failed to cordon node %q of machine: %s/%s", t.Node.Name, t.Machine.Namespace, t.Machine.Name)
+	}
+	annotations.AddAnnotations(t.Machine, map[string]string{
+		clusterv1.MachineRemediationCordonedAnnotation: r.now().UTC().Format(time.RFC3339),
+	})
+
+	gracePeriod := remediationGracePeriod(m)
+	logger.Info("Target has failed health check, cordoning its node and waiting for it to recover", "target", t.string(), "gracePeriod", gracePeriod.String())
+	r.recorder.Eventf(
+		t.Machine,
+		corev1.EventTypeNormal,
+		EventReasonNodeCordoned,
+		"Node of Machine %v has been cordoned, waiting %s for it to recover",
+		t.string(),
+		gracePeriod.String(),
+	)
+	return true, nil
+}
+
+// uncordonRecovered reverts the CordonAndWait remediation strategy for a target which is healthy again.
+func (r *MachineHealthCheckReconciler) uncordonRecovered(ctx context.Context, logger logr.Logger, t healthCheckTarget, cluster *clusterv1.Cluster) error {
+	if _, cordoned := t.Machine.GetAnnotations()[clusterv1.MachineRemediationCordonedAnnotation]; !cordoned {
+		return nil
+	}
+
+	if t.Node != nil {
+		if err := r.setNodeUnschedulable(ctx, cluster, t.Node, false); err != nil {
+			return errors.Wrapf(err, "failed to uncordon node %q of machine: %s/%s", t.Node.Name, t.Machine.Namespace, t.Machine.Name)
+		}
+	}
+	delete(t.Machine.Annotations, clusterv1.MachineRemediationCordonedAnnotation)
+
+	logger.Info("Target has recovered, uncordoning its node", "target", t.string())
+	r.recorder.Eventf(
+		t.Machine,
+		corev1.EventTypeNormal,
+		EventReasonNodeUncordoned,
+		"Machine %v has recovered, its node has been uncordoned",
+		t.string(),
+	)
+	return nil
+}
+
+// setNodeUnschedulable cordons or uncordons the given Node in the workload cluster.
+func (r *MachineHealthCheckReconciler) setNodeUnschedulable(ctx context.Context, cluster *clusterv1.Cluster, node *corev1.Node, unschedulable bool) error {
+	if node.Spec.Unschedulable == unschedulable {
+		return nil
+	}
+
+	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(node.DeepCopy())
+	node.Spec.Unschedulable = unschedulable
+	return remoteClient.Patch(ctx, node, patch)
+}
+
+// deferRemediationOnBlockingPDB implements DeferRemediationOnBlockingPDB: it sets the DrainAllowed condition of the
+// target, and returns true as long as PodDisruptionBudgets would block the drain of its Node.
+func (r *MachineHealthCheckReconciler) deferRemediationOnBlockingPDB(ctx context.Context, logger logr.Logger, t healthCheckTarget, cluster *clusterv1.Cluster, m *clusterv1.MachineHealthCheck) (bool, error) {
+	// Without a Node there is nothing to drain, so the target is remediated right away.
+	if m.Spec.DeferRemediationOnBlockingPDB == nil || !*m.Spec.DeferRemediationOnBlockingPDB || t.Node == nil || t.Node.Name == "" {
+		conditions.Delete(t.Machine, clusterv1.DrainAllowedCondition)
+		return false, nil
+	}
+
+	blocking, err := r.drainBlockingPDBs(ctx, cluster, t.Node.Name)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to check the PodDisruptionBudgets of node %q of machine: %s/%s", t.Node.Name, t.Machine.Namespace, t.Machine.Name)
+	}
+	if len(blocking) == 0 {
+		conditions.Delete(t.Machine, clusterv1.DrainAllowedCondition)
+		return false, nil
+	}
+
+	logger.Info("Target has failed health check, but PodDisruptionBudgets would block the drain of its node, deferring remediation", "target", t.string(), "podDisruptionBudgets", blocking)
+	conditions.MarkFalse(t.Machine, clusterv1.DrainAllowedCondition, clusterv1.DrainBlockedByPDBReason, clusterv1.ConditionSeverityWarning,
+		"Drain of node %s would be blocked by PodDisruptionBudget(s) %s", t.Node.Name, strings.Join(blocking, ", "))
+	return true, nil
+}
+
+// drainBlockingPDBs returns the sorted namespaced names of the PodDisruptionBudgets which allow no disruption of a Pod
+// running on the given Node in the workload cluster, and would thus block its drain.
+func (r *MachineHealthCheckReconciler) drainBlockingPDBs(ctx context.Context, cluster *clusterv1.Cluster, nodeName string) ([]string, error) {
+	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		return nil, err
+	}
+
+	pods := &corev1.PodList{}
+	if err := remoteClient.List(ctx, pods); err != nil {
+		return nil, errors.Wrap(err, "failed to list pods")
+	}
+
+	pdbsByNamespace := map[string][]policyv1beta1.PodDisruptionBudget{}
+	blocking := sets.NewString()
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName != nodeName || !evictedOnDrain(pod) {
+			continue
+		}
+
+		pdbs, ok := pdbsByNamespace[pod.Namespace]
+		if !ok {
+			pdbList := &policyv1beta1.PodDisruptionBudgetList{}
+			if err := remoteClient.List(ctx, pdbList, client.InNamespace(pod.Namespace)); err != nil {
+				return nil, errors.Wrapf(err, "failed to list pod disruption budgets in namespace %q", pod.Namespace)
+			}
+			pdbs = pdbList.Items
+			pdbsByNamespace[pod.Namespace] = pdbs
+		}
+
+		for _, pdb := range pdbs {
+			if pdb.Status.DisruptionsAllowed > 0 {
+				continue
+			}
+			// An empty selector selects no Pod in policy/v1beta1.
+			selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+			if err != nil || selector.Empty() || !selector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			blocking.Insert(pdb.Namespace + "/" + pdb.Name)
+		}
+	}
+	return blocking.List(), nil
+}
+
+// sortUnhealthyTargets sorts the unhealthy targets in the order they should be remediated, as defined by the
+// RemediationOrder of the MachineHealthCheck; this matters when not all of them can be remediated.
+func (r *MachineHealthCheckReconciler) sortUnhealthyTargets(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.MachineHealthCheck, unhealthy []healthCheckTarget) error {
+	unhealthySince := func(t healthCheckTarget) time.Time {
+		return conditions.GetLastTransitionTime(t.Machine, clusterv1.MachineHealthCheckSuccededCondition).Time
+	}
+
+	if m.Spec.RemediationOrder != clusterv1.RemediationOrderLeastCriticalFirst {
+		sort.SliceStable(unhealthy, func(i, j int) bool {
+			return unhealthySince(unhealthy[i]).Before(unhealthySince(unhealthy[j]))
+		})
+		return nil
+	}
+
+	criticalPods, err := r.criticalPodsByNode(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(unhealthy, func(i, j int) bool {
+		ci, cj := criticalPods[unhealthy[i].nodeName()], criticalPods[unhealthy[j].nodeName()]
+		if ci != cj {
+			return ci < cj
+		}
+		return unhealthySince(unhealthy[i]).Before(unhealthySince(unhealthy[j]))
+	})
+	return nil
+}
+
+// criticalPodsByNode returns the number of critical Pods running on each Node of the workload cluster, i.e. Pods
+// with a priority greater than zero which are evicted on drain.
+func (r *MachineHealthCheckReconciler) criticalPodsByNode(ctx context.Context, cluster *clusterv1.Cluster) (map[string]int, error) {
+	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		return nil, err
+	}
+
+	pods := &corev1.PodList{}
+	if err := remoteClient.List(ctx, pods); err != nil {
+		return nil, errors.Wrap(err, "failed to list pods")
+	}
+
+	criticalPods := map[string]int{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" || pod.Spec.Priority == nil || *pod.Spec.Priority <= 0 || !evictedOnDrain(pod) {
+			continue
+		}
+		criticalPods[pod.Spec.NodeName]++
+	}
+	return criticalPods, nil
+}
+
+// evictedOnDrain returns true if the given Pod is evicted when draining its Node, i.e. it is not terminated,
+// and neither a mirror Pod nor a Pod managed by a DaemonSet.
+func evictedOnDrain(pod *corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	if _, mirror := pod.Annotations[corev1.MirrorPodAnnotationKey]; mirror {
+		return false
+	}
+	if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "DaemonSet" {
+		return false
+	}
+	return true
+}
+
+// remediationGracePeriodRemaining returns how long a Machine cordoned by the CordonAndWait remediation
+// strategy is still given to recover, and false if the Machine has not been cordoned.
+func remediationGracePeriodRemaining(m *clusterv1.MachineHealthCheck, machine *clusterv1.Machine, now time.Time) (time.Duration, bool) {
+	value, cordoned := machine.GetAnnotations()[clusterv1.MachineRemediationCordonedAnnotation]
+	if !cordoned {
+		return 0, false
+	}
+
+	cordonedAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		// Consider the grace period elapsed if the annotation has been tampered with.
+		return 0, true
+	}
+
+	return cordonedAt.Add(remediationGracePeriod(m)).Sub(now), true
+}
+
+// remediationGracePeriod returns the grace period of the CordonAndWait remediation strategy, if any.
+func remediationGracePeriod(m *clusterv1.MachineHealthCheck) time.Duration {
+	if m.Spec.RemediationStrategy == nil || m.Spec.RemediationStrategy.GracePeriod == nil {
+		return 0
+	}
+	return m.Spec.RemediationStrategy.GracePeriod.Duration
+}
+
+// suppressRemediation short-circuits the remediation of all the targets for the given reason: the RemediationAllowed
+// condition is set to false, and the targets are patched, with their health check explained, without any of them
+// being marked for remediation. Warnings are always recorded as events, other reasons only if a target is unhealthy.
+func (r *MachineHealthCheckReconciler) suppressRemediation(ctx context.Context, logger logr.Logger, m *clusterv1.MachineHealthCheck, healthy, unhealthy []healthCheckTarget, reason string, severity clusterv1.ConditionSeverity, message string) (ctrl.Result, error) {
+	m.Status.RemediationsAllowed = 0
+	conditions.MarkFalse(m, clusterv1.RemediationAllowedCondition, reason, severity, "%s", message)
+
+	if severity == clusterv1.ConditionSeverityWarning {
+		r.recorder.Event(m, corev1.EventTypeWarning, EventReasonRemediationSkipped, message)
+	} else if len(unhealthy) > 0 {
+		r.recorder.Event(m, corev1.EventTypeNormal, EventReasonRemediationSkipped, message)
+	}
+
+	errList := []error{}
+	for _, t := range append(healthy, unhealthy...) {
+		explainHealthCheck(logger, t, remediationAllowedExplanation(m), r.now())
+		if err := t.patchHelper.Patch(ctx, t.Machine); err != nil {
+			errList = append(errList, errors.Wrapf(err, "failed to patch machine status for machine: %s/%s", t.Machine.Namespace, t.Machine.Name))
+		}
+	}
+	if len(errList) > 0 {
+		return ctrl.Result{}, kerrors.NewAggregate(errList)
+	}
+	return reconcile.Result{Requeue: true}, nil
+}
+
+// reconcileUpgradeInProgress sets the UpgradeInProgress condition while the Cluster is being upgraded, and removes
+// it once the upgrade completes.
+func reconcileUpgradeInProgress(cluster *clusterv1.Cluster, m *clusterv1.MachineHealthCheck) {
+	if _, ok := cluster.GetAnnotations()[clusterv1.ClusterUpgradeInProgressAnnotation]; !ok {
+		conditions.Delete(m, clusterv1.UpgradeInProgressCondition)
+		return
+	}
+	conditions.Set(m, &clusterv1.Condition{
+		Type:    clusterv1.UpgradeInProgressCondition,
+		Status:  corev1.ConditionTrue,
+		Reason:  clusterv1.UpgradeInProgressReason,
+		Message: "The cluster upgrade is in progress",
+	})
+}
+
+// remediationPausedForUpgrade returns whether the MachineHealthCheck pauses remediation because the Cluster is
+// being upgraded.
+func remediationPausedForUpgrade(cluster *clusterv1.Cluster, m *clusterv1.MachineHealthCheck) bool {
+	if m.Spec.PauseDuringUpgrade == nil || !*m.Spec.PauseDuringUpgrade {
+		return false
+	}
+	_, ok := cluster.GetAnnotations()[clusterv1.ClusterUpgradeInProgressAnnotation]
+	return ok
+}
+
+// timeWindowLayout is the layout of the times of day of TimeWindows.
+const timeWindowLayout = "15:04"
+
+// now returns the current time as told by the clock of the reconciler.
+func (r *MachineHealthCheckReconciler) now() time.Time {
+	if r.clock == nil {
+		return time.Now()
+	}
+	return r.clock.Now()
+}
+
+// maintenanceWindowRemaining returns how long until the maintenance window of the given remediation schedule in
+// progress at the given time closes, or zero if none is. Adjoining windows are not merged, the next one is found
+// open once the first closes.
+func maintenanceWindowRemaining(schedule *clusterv1.Schedule, now time.Time) (time.Duration, error) {
+	if schedule == nil {
+		return 0, nil
+	}
+	location, err := time.LoadLocation(schedule.TimeZone)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid remediation schedule time zone %q", schedule.TimeZone)
+	}
+	now = now.In(location)
+
+	var remaining time.Duration
+	for _, window := range schedule.Windows {
+		start, err := time.Parse(timeWindowLayout, window.Start)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid remediation schedule window start %q", window.Start)
+		}
+		end, err := time.Parse(timeWindowLayout, window.End)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid remediation schedule window end %q", window.End)
+		}
+
+		// The window in progress, if any, opened either today or, for a window spanning midnight, yesterday.
+		for _, day := range []int{0, -1} {
+			opens := time.Date(now.Year(), now.Month(), now.Day()+day, start.Hour(), start.Minute(), 0, 0, location)
+			closes := time.Date(now.Year(), now.Month(), now.Day()+day, end.Hour(), end.Minute(), 0, 0, location)
+			if !closes.After(opens) {
+				closes = time.Date(now.Year(), now.Month(), now.Day()+day+1, end.Hour(), end.Minute(), 0, 0, location)
+			}
+			if !now.Before(opens) && now.Before(closes) && closes.Sub(now) > remaining {
+				remaining = closes.Sub(now)
+			}
+		}
+	}
+	return remaining, nil
+}
+
+// remediationDeferredForMaintenance returns how long the remediation of the unhealthy targets of the
+// MachineHealthCheck is deferred because a maintenance window of its remediation schedule is in progress.
+// Remediation is not deferred by invalid schedules, which are only logged.
+func (r *MachineHealthCheckReconciler) remediationDeferredForMaintenance(logger logr.Logger, m *clusterv1.MachineHealthCheck) time.Duration {
+	remaining, err := maintenanceWindowRemaining(m.Spec.RemediationSchedule, r.now())
+	if err != nil {
+		logger.Error(err, "Ignoring the remediation schedule")
+		return 0
+	}
+	return remaining
+}
+
+// clusterWideOutageSuspected returns true, with a message, if the unhealthy Machines across the Cluster reach the
+// ClusterOutageThreshold of the MachineHealthCheck. The health of its targets is the one computed by this reconcile,
+// the health of the other Machines the one last reported by the MachineHealthCheck targeting them, if any.
+func (r *MachineHealthCheckReconciler) clusterWideOutageSuspected(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.MachineHealthCheck, targets []healthCheckTarget) (bool, string, error) {
+	machineList := &clusterv1.MachineList{}
+	if err := r.Client.List(
+		ctx,
+		machineList,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name},
+	); err != nil {
+		return false, "", errors.Wrap(err, "failed to list machines")
+	}
+
+	targetMachines := make(map[string]*clusterv1.Machine, len(targets))
+	for _, t := range targets {
+		targetMachines[t.Machine.Name] = t.Machine
+	}
+
+	var total, unhealthy int
+	for i := range machineList.Items {
+		machine := &machineList.Items[i]
+		if !machine.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if target, ok := targetMachines[machine.Name]; ok {
+			machine = target
+		}
+		total++
+		if conditions.IsFalse(machine, clusterv1.MachineHealthCheckSuccededCondition) {
+			unhealthy++
+		}
+	}
+
+	// With a single Machine, a cluster-wide outage cannot be told apart from the failure of that Machine.
+	if total < 2 {
+		return false, "", nil
+	}
+
+	clusterOutageThreshold := m.Spec.ClusterOutageThreshold
+	if clusterOutageThreshold == nil {
+		clusterOutageThreshold = &defaultClusterOutageThreshold
+	}
+	threshold, err := intstr.GetValueFromIntOrPercent(clusterOutageThreshold, total, true)
+	if err != nil {
+		return false, "", err
+	}
+	if threshold <= 0 || unhealthy < threshold {
+		return false, "", nil
+	}
+	return true, fmt.Sprintf("Remediation is not allowed, a cluster-wide outage is suspected as %v of the %v machines of the cluster are unhealthy (clusterOutageThreshold: %v)",
+		unhealthy,
+		total,
+		clusterOutageThreshold.String()), nil
+}
+
+// staleNodeStatusSuspected returns true, with a message, if the nodes of the targets whose status is stale reach the
+// StaleNodeStatusThreshold of the MachineHealthCheck. It never does unless StaleNodeStatusTimeout is set.
+func staleNodeStatusSuspected(m *clusterv1.MachineHealthCheck, targets []healthCheckTarget, now time.Time) (bool, string, error) {
+	if m.Spec.StaleNodeStatusTimeout == nil {
+		return false, "", nil
+	}
+
+	var total, stale int
+	for _, t := range targets {
+		if t.Node == nil || t.nodeMissing {
+			continue
+		}
+		total++
+		if t.hasStaleNodeStatus(m.Spec.StaleNodeStatusTimeout.Duration, now) {
+			stale++
+		}
+	}
+
+	staleNodeStatusThreshold := m.Spec.StaleNodeStatusThreshold
+	if staleNodeStatusThreshold == nil {
+		staleNodeStatusThreshold = &defaultStaleNodeStatusThreshold
+	}
+	threshold, err := intstr.GetValueFromIntOrPercent(staleNodeStatusThreshold, total, true)
+	if err != nil {
+		return false, "", err
+	}
+	if stale == 0 || stale < threshold {
+		return false, "", nil
+	}
+	return true, fmt.Sprintf("Remediation is not allowed, the reporting of the node status is suspected to be broken as %v of the %v nodes have not reported their status for %v (staleNodeStatusThreshold: %v)",
+		stale,
+		total,
+		m.Spec.StaleNodeStatusTimeout.Duration,
+		staleNodeStatusThreshold.String()), nil
+}
+
+// remediationBudgetExhausted returns whether the MachineHealthCheck has triggered MaxTotalRemediations remediations
+// since its remediation budget was last reset.
+func remediationBudgetExhausted(m *clusterv1.MachineHealthCheck) bool {
+	return m.Spec.MaxTotalRemediations != nil && m.Status.TotalRemediations >= *m.Spec.MaxTotalRemediations
+}
+
+// reconcileRemediationBudget resets the remediation budget of the MachineHealthCheck if requested by an operator,
+// then reports whether it is exhausted.
+func reconcileRemediationBudget(logger logr.Logger, m *clusterv1.MachineHealthCheck) {
+	if _, ok := m.Annotations[clusterv1.MachineHealthCheckResetRemediationBudgetAnnotation]; ok {
+		logger.Info("Resetting the remediation budget", "total remediations", m.Status.TotalRemediations)
+		m.Status.TotalRemediations = 0
+		delete(m.Annotations, clusterv1.MachineHealthCheckResetRemediationBudgetAnnotation)
+	}
+	setRemediationBudgetCondition(m)
+}
+
+// recordRemediation counts a remediation triggered by the MachineHealthCheck at the given time against its
+// remediation budget, and holds back further remediations until its replacement is healthy, if requested.
+func recordRemediation(m *clusterv1.MachineHealthCheck, now time.Time) {
+	m.Status.TotalRemediations++
+	lastRemediationTime := metav1.NewTime(now)
+	m.Status.LastRemediationTime = &lastRemediationTime
+	setRemediationBudgetCondition(m)
+	if m.Spec.ReplacementTimeout != nil {
+		setAwaitingReplacementCondition(m)
+	}
+}
+
+// remediationStaggerRemaining returns how long until the MachineHealthCheck may trigger its next remediation, as
+// staggered by its RemediationStagger after the last one, or 0 if it may now.
+func remediationStaggerRemaining(m *clusterv1.MachineHealthCheck, now time.Time) time.Duration {
+	if m.Spec.RemediationStagger == nil || m.Status.LastRemediationTime == nil {
+		return 0
+	}
+	if remaining := m.Status.LastRemediationTime.Add(m.Spec.RemediationStagger.Duration).Sub(now); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// reconcileAwaitingReplacement sets the AwaitingReplacement condition while the MachineHealthCheck holds back further
+// remediations, i.e. until one of the healthy targets was created since the last remediation, which is then assumed
+// to be the replacement of the remediated machine, or until the ReplacementTimeout expires.
+func reconcileAwaitingReplacement(m *clusterv1.MachineHealthCheck, healthy []healthCheckTarget, now time.Time) {
+	if m.Spec.ReplacementTimeout == nil || m.Status.LastRemediationTime == nil ||
+		!now.Before(m.Status.LastRemediationTime.Add(m.Spec.ReplacementTimeout.Duration)) {
+		conditions.Delete(m, clusterv1.AwaitingReplacementCondition)
+		return
+	}
+	for _, t := range healthy {
+		if t.Node != nil && !t.Machine.CreationTimestamp.Before(m.Status.LastRemediationTime) {
+			conditions.Delete(m, clusterv1.AwaitingReplacementCondition)
+			return
+		}
+	}
+	setAwaitingReplacementCondition(m)
+}
+
+// setAwaitingReplacementCondition sets the AwaitingReplacement condition, telling until when further remediations are
+// held back at most.
+func setAwaitingReplacementCondition(m *clusterv1.MachineHealthCheck) {
+	conditions.Set(m, &clusterv1.Condition{
+		Type:   clusterv1.AwaitingReplacementCondition,
+		Status: corev1.ConditionTrue,
+		Reason: clusterv1.ReplacementNotHealthyReason,
+		Message: fmt.Sprintf("Remediation is held back until the replacement of the machine remediated at %s is healthy, at most until %s",
+			m.Status.LastRemediationTime.UTC().Format(time.RFC3339),
+			m.Status.LastRemediationTime.Add(m.Spec.ReplacementTimeout.Duration).UTC().Format(time.RFC3339)),
+	})
+}
+
+// setRemediationBudgetCondition sets the RemediationBudgetAvailable condition to false while the remediation budget
+// of the MachineHealthCheck is exhausted, and removes it otherwise.
+func setRemediationBudgetCondition(m *clusterv1.MachineHealthCheck) {
+	if !remediationBudgetExhausted(m) {
+		conditions.Delete(m, clusterv1.RemediationBudgetAvailableCondition)
+		return
+	}
+	conditions.MarkFalse(m, clusterv1.RemediationBudgetAvailableCondition, clusterv1.RemediationBudgetExhaustedReason, clusterv1.ConditionSeverityWarning,
+		"%d remediations triggered, the maximum allowed; set the %s annotation to resume remediation", m.Status.TotalRemediations, clusterv1.MachineHealthCheckResetRemediationBudgetAnnotation)
+}
+
 // clusterToMachineHealthCheck maps events from Cluster objects to
 // MachineHealthCheck objects that belong to the Cluster.
 func (r *MachineHealthCheckReconciler) clusterToMachineHealthCheck(o client.Object) []reconcile.Request {
@@ -428,6 +1559,9 @@ func (r *MachineHealthCheckReconciler) clusterToMachineHealthCheck(o client.Obje
 	if !ok {
 		panic(fmt.Sprintf("Expected a Cluster, got %T", o))
 	}
+	if !r.managesCluster(c) {
+		return nil
+	}
 
 	mhcList := &clusterv1.MachineHealthCheckList{}
 	if err := r.Client.List(
@@ -448,6 +1582,12 @@ func (r *MachineHealthCheckReconciler) clusterToMachineHealthCheck(o client.Obje
 	return requests
 }
 
+// managesCluster returns whether the MachineHealthChecks of the given Cluster are reconciled by this controller,
+// i.e. whether the Cluster matches its cluster selector, if any.
+func (r *MachineHealthCheckReconciler) managesCluster(cluster *clusterv1.Cluster) bool {
+	return r.ClusterSelector == nil || r.ClusterSelector.Matches(labels.Set(cluster.Labels))
+}
+
 // machineToMachineHealthCheck maps events from Machine objects to
 // MachineHealthCheck objects that monitor the given machine.
 func (r *MachineHealthCheckReconciler) machineToMachineHealthCheck(o client.Object) []reconcile.Request {
@@ -469,6 +1609,10 @@ func (r *MachineHealthCheckReconciler) machineToMachineHealthCheck(o client.Obje
 	var requests []reconcile.Request
 	for k := range mhcList.Items {
 		mhc := &mhcList.Items[k]
+		// The cluster label could have been changed by users, make sure the MachineHealthCheck targets the Machine's cluster.
+		if mhc.Spec.ClusterName != m.Spec.ClusterName {
+			continue
+		}
 		if hasMatchingLabels(mhc.Spec.Selector, m.Labels) {
 			key := util.ObjectKey(mhc)
 			requests = append(requests, reconcile.Request{NamespacedName: key})
@@ -477,25 +1621,31 @@ func (r *MachineHealthCheckReconciler) machineToMachineHealthCheck(o client.Obje
 	return requests
 }
 
-func (r *MachineHealthCheckReconciler) nodeToMachineHealthCheck(o client.Object) []reconcile.Request {
-	node, ok := o.(*corev1.Node)
-	if !ok {
-		panic(fmt.Sprintf("Expected a corev1.Node, got %T", o))
-	}
+// nodeToMachineHealthCheck returns a handler.MapFunc mapping Nodes of the given workload cluster
+// to the MachineHealthChecks of their Machine. Node names are only unique within a workload cluster,
+// so the Machine is looked up among the ones belonging to that cluster only.
+func (r *MachineHealthCheckReconciler) nodeToMachineHealthCheck(cluster client.ObjectKey) handler.MapFunc {
+	return func(o client.Object) []reconcile.Request {
+		node, ok := o.(*corev1.Node)
+		if !ok {
+			panic(fmt.Sprintf("Expected a corev1.Node, got %T", o))
+		}
 
-	machine, err := r.getMachineFromNode(context.TODO(), node.Name)
-	if machine == nil || err != nil {
-		return nil
-	}
+		machine, err := r.getMachineFromNode(context.TODO(), cluster, node.Name)
+		if machine == nil || err != nil {
+			return nil
+		}
 
-	return r.machineToMachineHealthCheck(machine)
+		return r.machineToMachineHealthCheck(machine)
+	}
 }
 
-func (r *MachineHealthCheckReconciler) getMachineFromNode(ctx context.Context, nodeName string) (*clusterv1.Machine, error) {
+func (r *MachineHealthCheckReconciler) getMachineFromNode(ctx context.Context, cluster client.ObjectKey, nodeName string) (*clusterv1.Machine, error) {
 	machineList := &clusterv1.MachineList{}
 	if err := r.Client.List(
 		ctx,
 		machineList,
+		client.InNamespace(cluster.Namespace),
 		client.MatchingFields{clusterv1.MachineNodeNameIndex: nodeName},
 	); err != nil {
 		return nil, errors.Wrap(err, "failed getting machine list")
@@ -505,12 +1655,16 @@ func (r *MachineHealthCheckReconciler) getMachineFromNode(ctx context.Context, n
 	items := []*clusterv1.Machine{}
 	for i := range machineList.Items {
 		machine := &machineList.Items[i]
+		// Machines of other clusters in the same namespace might refer to a Node with the same name.
+		if machine.Spec.ClusterName != cluster.Name {
+			continue
+		}
 		if machine.Status.NodeRef != nil && machine.Status.NodeRef.Name == nodeName {
 			items = append(items, machine)
 		}
 	}
 	if len(items) != 1 {
-		return nil, errors.Errorf("expecting one machine for node %v, got %v", nodeName, machineNames(items))
+		return nil, errors.Errorf("expecting one machine for node %v in cluster %v, got %v", nodeName, cluster, machineNames(items))
 	}
 	return items[0], nil
 }
@@ -526,16 +1680,26 @@ func (r *MachineHealthCheckReconciler) watchClusterNodes(ctx context.Context, cl
 		Cluster:      util.ObjectKey(cluster),
 		Watcher:      r.controller,
 		Kind:         &corev1.Node{},
-		EventHandler: handler.EnqueueRequestsFromMapFunc(r.nodeToMachineHealthCheck),
+		EventHandler: handler.EnqueueRequestsFromMapFunc(r.nodeToMachineHealthCheck(util.ObjectKey(cluster))),
+		Predicates:   []predicate.Predicate{predicates.NodeHealthChanged(ctrl.LoggerFrom(ctx))},
 	}); err != nil {
 		return err
 	}
 	return nil
 }
 
-// isAllowedRemediation checks the value of the MaxUnhealthy field to determine
+// isAllowedRemediation checks the value of the MinHealthy, UnhealthyRange and MaxUnhealthy fields to determine
 // returns whether remediation should be allowed or not, the remediation count, and error if any.
 func isAllowedRemediation(mhc *clusterv1.MachineHealthCheck) (bool, int32, error) {
+	belowMinHealthy, err := isBelowMinHealthy(mhc)
+	if err != nil {
+		return false, 0, err
+	}
+	// Remediation is not allowed if healthy is below minHealthy
+	if belowMinHealthy {
+		return false, 0, nil
+	}
+
 	var remediationAllowed bool
 	var remediationCount int32
 	if mhc.Spec.UnhealthyRange != nil {
@@ -561,6 +1725,66 @@ func isAllowedRemediation(mhc *clusterv1.MachineHealthCheck) (bool, int32, error
 	return remediationAllowed, remediationCount, nil
 }
 
+// countExpectedTargets returns the number of targets which should be counted towards
+// the MachineHealthCheck's ExpectedMachines, according to its ExpectedMachinesPolicy.
+func countExpectedTargets(mhc *clusterv1.MachineHealthCheck, targets []healthCheckTarget) int {
+	if mhc.Spec.ExpectedMachinesPolicy != clusterv1.ExpectedMachinesPolicyReadyOnly {
+		return len(targets)
+	}
+
+	count := 0
+	for _, t := range targets {
+		// A Machine which has been assigned a NodeRef has been ready at least once;
+		// it keeps counting even if its Node has since gone away.
+		if t.Machine.Status.NodeRef != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// suspectTargets returns the targets which are neither healthy nor unhealthy yet, as their node meets one of the
+// unhealthy conditions, but for less than its timeout at the given time.
+func suspectTargets(targets, healthy, unhealthy []healthCheckTarget, now time.Time) []healthCheckTarget {
+	checked := sets.NewString()
+	for _, t := range healthy {
+		checked.Insert(t.Machine.Name)
+	}
+	for _, t := range unhealthy {
+		checked.Insert(t.Machine.Name)
+	}
+
+	var suspect []healthCheckTarget
+	for i := range targets {
+		if !checked.Has(targets[i].Machine.Name) && targets[i].isSuspect(now) {
+			suspect = append(suspect, targets[i])
+		}
+	}
+	return suspect
+}
+
+// countMachineDeploymentSiblings returns how many of the given machines, which are not targets of the
+// MachineHealthCheck, count towards its ExpectedMachines according to its ExpectedMachinesPolicy, and how many of
+// those are healthy. They are not health checked by this MachineHealthCheck, so they are healthy unless another
+// one reported them unhealthy; the machines being deleted are not counted.
+func countMachineDeploymentSiblings(mhc *clusterv1.MachineHealthCheck, machines []clusterv1.Machine) (int, int) {
+	expected, healthy := 0, 0
+	for i := range machines {
+		machine := &machines[i]
+		if !machine.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if mhc.Spec.ExpectedMachinesPolicy == clusterv1.ExpectedMachinesPolicyReadyOnly && machine.Status.NodeRef == nil {
+			continue
+		}
+		expected++
+		if !conditions.IsFalse(machine, clusterv1.MachineHealthCheckSuccededCondition) {
+			healthy++
+		}
+	}
+	return expected, healthy
+}
+
 // getUnhealthyRange parses an integer range and returns the min and max values
 // Eg. [2-5] will return (2,5,nil).
 func getUnhealthyRange(mhc *clusterv1.MachineHealthCheck) (int, int, error) {
@@ -597,6 +1821,30 @@ func getMaxUnhealthy(mhc *clusterv1.MachineHealthCheck) (int, error) {
 	return maxUnhealthy, nil
 }
 
+func getMinHealthy(mhc *clusterv1.MachineHealthCheck) (int, error) {
+	if mhc.Spec.MinHealthy == nil {
+		return 0, errors.New("spec.minHealthy must be set")
+	}
+	minHealthy, err := intstr.GetValueFromIntOrPercent(mhc.Spec.MinHealthy, int(mhc.Status.ExpectedMachines), true)
+	if err != nil {
+		return 0, err
+	}
+	return minHealthy, nil
+}
+
+// isBelowMinHealthy returns true if MinHealthy is set and fewer machines than
+// the floor are currently healthy.
+func isBelowMinHealthy(mhc *clusterv1.MachineHealthCheck) (bool, error) {
+	if mhc.Spec.MinHealthy == nil {
+		return false, nil
+	}
+	minHealthy, err := getMinHealthy(mhc)
+	if err != nil {
+		return false, err
+	}
+	return int(mhc.Status.CurrentHealthy) < minHealthy, nil
+}
+
 // unhealthyMachineCount calculates the number of presently unhealthy or missing machines
 // ie the delta between the expected number of machines and the current number deemed healthy.
 func unhealthyMachineCount(mhc *clusterv1.MachineHealthCheck) int {
@@ -17,24 +17,32 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 
 	"sort"
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
 	. "github.com/onsi/gomega"
 
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/remote"
@@ -42,8 +50,12 @@ import (
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	"sigs.k8s.io/cluster-api/util/secret"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -231,6 +243,156 @@ func TestMachineHealthCheck_Reconcile(t *testing.T) {
 		}))
 	})
 
+	t.Run("it recomputes the status when a matched Machine is deleted", func(t *testing.T) {
+		g := NewWithT(t)
+		cluster := createNamespaceAndCluster(g)
+
+		mhc := newMachineHealthCheck(cluster.Namespace, cluster.Name)
+
+		g.Expect(testEnv.Create(ctx, mhc)).To(Succeed())
+		defer func(do ...client.Object) {
+			g.Expect(testEnv.Cleanup(ctx, do...)).To(Succeed())
+		}(cluster, mhc)
+
+		// Healthy nodes and machines matching the MHC's label selector.
+		_, machines, cleanup := createMachinesWithNodes(g, cluster,
+			count(1),
+			firstMachineAsControlPlane(),
+			createNodeRefForMachine(true),
+			nodeStatus(corev1.ConditionTrue),
+			machineLabels(mhc.Spec.Selector.MatchLabels),
+		)
+		defer cleanup()
+
+		// A healthy node and machine matching the MHC's label selector, which is deleted below;
+		// only its node is cleaned up, the machine controller takes care of the infra machine.
+		nodesToBeDeleted, machinesToBeDeleted, _ := createMachinesWithNodes(g, cluster,
+			count(1),
+			createNodeRefForMachine(true),
+			nodeStatus(corev1.ConditionTrue),
+			machineLabels(mhc.Spec.Selector.MatchLabels),
+		)
+		defer func() {
+			if err := testEnv.Delete(ctx, nodesToBeDeleted[0]); !apierrors.IsNotFound(err) {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		}()
+		targetMachines := []string{machines[0].Name, machinesToBeDeleted[0].Name}
+		sort.Strings(targetMachines)
+
+		// Make sure the status matches.
+		g.Eventually(func() *clusterv1.MachineHealthCheckStatus {
+			err := testEnv.Get(ctx, util.ObjectKey(mhc), mhc)
+			if err != nil {
+				return nil
+			}
+			return &mhc.Status
+		}, 5*time.Second, 100*time.Millisecond).Should(MatchMachineHealthCheckStatus(&clusterv1.MachineHealthCheckStatus{
+			ExpectedMachines:    2,
+			CurrentHealthy:      2,
+			RemediationsAllowed: 2,
+			ObservedGeneration:  1,
+			Targets:             targetMachines,
+			Conditions: clusterv1.Conditions{
+				{
+					Type:   clusterv1.RemediationAllowedCondition,
+					Status: corev1.ConditionTrue,
+				},
+			},
+		}))
+
+		// Delete one of the machines.
+		g.Expect(testEnv.Delete(ctx, machinesToBeDeleted[0])).To(Succeed())
+
+		// Make sure the status is recomputed as soon as the machine is gone, well before the
+		// periodic resync of the MachineHealthCheck.
+		g.Eventually(func() *clusterv1.MachineHealthCheckStatus {
+			err := testEnv.Get(ctx, util.ObjectKey(mhc), mhc)
+			if err != nil {
+				return nil
+			}
+			return &mhc.Status
+		}, 10*time.Second, 100*time.Millisecond).Should(MatchMachineHealthCheckStatus(&clusterv1.MachineHealthCheckStatus{
+			ExpectedMachines:    1,
+			CurrentHealthy:      1,
+			RemediationsAllowed: 1,
+			ObservedGeneration:  1,
+			Targets:             []string{machines[0].Name},
+			Conditions: clusterv1.Conditions{
+				{
+					Type:   clusterv1.RemediationAllowedCondition,
+					Status: corev1.ConditionTrue,
+				},
+			},
+		}))
+	})
+
+	t.Run("it tracks the generation of the MachineHealthCheck after spec changes", func(t *testing.T) {
+		g := NewWithT(t)
+		cluster := createNamespaceAndCluster(g)
+
+		mhc := newMachineHealthCheck(cluster.Namespace, cluster.Name)
+
+		g.Expect(testEnv.Create(ctx, mhc)).To(Succeed())
+		defer func(do ...client.Object) {
+			g.Expect(testEnv.Cleanup(ctx, do...)).To(Succeed())
+		}(cluster, mhc)
+
+		// Healthy nodes and machines matching the MHC's label selector.
+		_, machines, cleanup := createMachinesWithNodes(g, cluster,
+			count(2),
+			firstMachineAsControlPlane(),
+			createNodeRefForMachine(true),
+			nodeStatus(corev1.ConditionTrue),
+			machineLabels(mhc.Spec.Selector.MatchLabels),
+		)
+		defer cleanup()
+		targetMachines := make([]string, len(machines))
+		for i, m := range machines {
+			targetMachines[i] = m.Name
+		}
+		sort.Strings(targetMachines)
+
+		expectedStatus := &clusterv1.MachineHealthCheckStatus{
+			ExpectedMachines:    2,
+			CurrentHealthy:      2,
+			RemediationsAllowed: 2,
+			ObservedGeneration:  1,
+			Targets:             targetMachines,
+			Conditions: clusterv1.Conditions{
+				{
+					Type:   clusterv1.RemediationAllowedCondition,
+					Status: corev1.ConditionTrue,
+				},
+			},
+		}
+
+		// Make sure the status matches.
+		g.Eventually(func() *clusterv1.MachineHealthCheckStatus {
+			err := testEnv.Get(ctx, util.ObjectKey(mhc), mhc)
+			if err != nil {
+				return nil
+			}
+			return &mhc.Status
+		}).Should(MatchMachineHealthCheckStatus(expectedStatus))
+
+		// Change the spec of the MachineHealthCheck.
+		mhcPatch := client.MergeFrom(mhc.DeepCopy())
+		mhc.Spec.NodeStartupTimeout = &metav1.Duration{Duration: 20 * time.Minute}
+		g.Expect(testEnv.Patch(ctx, mhc, mhcPatch)).To(Succeed())
+
+		// Make sure the new generation is observed, and the status is refreshed.
+		expectedStatus.ObservedGeneration = 2
+		expectedStatus.LastUpdated = mhc.Status.LastUpdated
+		g.Eventually(func() *clusterv1.MachineHealthCheckStatus {
+			err := testEnv.Get(ctx, util.ObjectKey(mhc), mhc)
+			if err != nil {
+				return nil
+			}
+			return &mhc.Status
+		}).Should(MatchMachineHealthCheckStatus(expectedStatus))
+	})
+
 	t.Run("it doesn't mark anything unhealthy when cluster infrastructure is not ready", func(t *testing.T) {
 		g := NewWithT(t)
 		cluster := createNamespaceAndCluster(g)
@@ -389,6 +551,74 @@ func TestMachineHealthCheck_Reconcile(t *testing.T) {
 		}))
 	})
 
+	t.Run("it reports suspect machines whose Node went unhealthy for less than the timeout", func(t *testing.T) {
+		g := NewWithT(t)
+		cluster := createNamespaceAndCluster(g)
+
+		mhc := newMachineHealthCheck(cluster.Namespace, cluster.Name)
+
+		g.Expect(testEnv.Create(ctx, mhc)).To(Succeed())
+		defer func(do ...client.Object) {
+			g.Expect(testEnv.Cleanup(ctx, do...)).To(Succeed())
+		}(cluster, mhc)
+
+		// Healthy nodes and machines.
+		_, machines, cleanup1 := createMachinesWithNodes(g, cluster,
+			count(2),
+			firstMachineAsControlPlane(),
+			createNodeRefForMachine(true),
+			nodeStatus(corev1.ConditionTrue),
+			machineLabels(mhc.Spec.Selector.MatchLabels),
+		)
+		defer cleanup1()
+		// Nodes which just went unhealthy, and their machines.
+		_, suspectMachines, cleanup2 := createMachinesWithNodes(g, cluster,
+			count(1),
+			createNodeRefForMachine(true),
+			nodeStatus(corev1.ConditionUnknown),
+			freshNodeCondition(),
+			machineLabels(mhc.Spec.Selector.MatchLabels),
+		)
+		defer cleanup2()
+		machines = append(machines, suspectMachines...)
+		targetMachines := make([]string, len(machines))
+		for i, m := range machines {
+			targetMachines[i] = m.Name
+		}
+		sort.Strings(targetMachines)
+
+		// Make sure the status matches.
+		g.Eventually(func() *clusterv1.MachineHealthCheckStatus {
+			err := testEnv.Get(ctx, util.ObjectKey(mhc), mhc)
+			if err != nil {
+				return nil
+			}
+			return &mhc.Status
+		}).Should(MatchMachineHealthCheckStatus(&clusterv1.MachineHealthCheckStatus{
+			ExpectedMachines:    3,
+			CurrentHealthy:      2,
+			CurrentSuspect:      1,
+			RemediationsAllowed: 2,
+			ObservedGeneration:  1,
+			Targets:             targetMachines,
+			Conditions: clusterv1.Conditions{
+				{
+					Type:   clusterv1.RemediationAllowedCondition,
+					Status: corev1.ConditionTrue,
+				},
+			},
+		}))
+
+		// The suspect machine is not marked for remediation.
+		g.Consistently(func() bool {
+			machine := &clusterv1.Machine{}
+			if err := testEnv.Get(ctx, util.ObjectKey(suspectMachines[0]), machine); err != nil {
+				return true
+			}
+			return conditions.IsFalse(machine, clusterv1.MachineOwnerRemediatedCondition)
+		}, 2*time.Second).Should(BeFalse())
+	})
+
 	t.Run("it marks unhealthy machines for remediation when there a Machine has a failure reason", func(t *testing.T) {
 		g := NewWithT(t)
 		cluster := createNamespaceAndCluster(g)
@@ -610,6 +840,7 @@ func TestMachineHealthCheck_Reconcile(t *testing.T) {
 		mhc := newMachineHealthCheck(cluster.Namespace, cluster.Name)
 		unhealthyRange := "[1-3]"
 		mhc.Spec.UnhealthyRange = &unhealthyRange
+		mhc.Spec.MaxUnhealthy = nil
 
 		g.Expect(testEnv.Create(ctx, mhc)).To(Succeed())
 		defer func(do ...client.Object) {
@@ -669,6 +900,7 @@ func TestMachineHealthCheck_Reconcile(t *testing.T) {
 		mhc := newMachineHealthCheck(cluster.Namespace, cluster.Name)
 		unhealthyRange := "[3-5]"
 		mhc.Spec.UnhealthyRange = &unhealthyRange
+		mhc.Spec.MaxUnhealthy = nil
 
 		g.Expect(testEnv.Create(ctx, mhc)).To(Succeed())
 		defer func(do ...client.Object) {
@@ -760,20 +992,18 @@ func TestMachineHealthCheck_Reconcile(t *testing.T) {
 		}).Should(Equal(0))
 	})
 
-	t.Run("when a Machine has no Node ref for less than the NodeStartupTimeout", func(t *testing.T) {
+	t.Run("it does not remediate unhealthy machines while a cluster upgrade is in progress", func(t *testing.T) {
 		g := NewWithT(t)
 		cluster := createNamespaceAndCluster(g)
 
-		// After the cluster exists, we have to set the infrastructure ready condition; otherwise, MachineHealthChecks
-		// will never fail when nodeStartupTimeout is exceeded.
-		patchHelper, err := patch.NewHelper(cluster, testEnv.GetClient())
-		g.Expect(err).ToNot(HaveOccurred())
-
-		conditions.MarkTrue(cluster, clusterv1.InfrastructureReadyCondition)
+		// Simulate an upgrade of the cluster.
+		patchHelper, err := patch.NewHelper(cluster, testEnv.Client)
+		g.Expect(err).To(BeNil())
+		cluster.SetAnnotations(map[string]string{clusterv1.ClusterUpgradeInProgressAnnotation: ""})
 		g.Expect(patchHelper.Patch(ctx, cluster)).To(Succeed())
 
 		mhc := newMachineHealthCheck(cluster.Namespace, cluster.Name)
-		mhc.Spec.NodeStartupTimeout = &metav1.Duration{Duration: 5 * time.Hour}
+		mhc.Spec.PauseDuringUpgrade = pointer.BoolPtr(true)
 
 		g.Expect(testEnv.Create(ctx, mhc)).To(Succeed())
 		defer func(do ...client.Object) {
@@ -792,7 +1022,7 @@ func TestMachineHealthCheck_Reconcile(t *testing.T) {
 		// Unhealthy nodes and machines.
 		_, unhealthyMachines, cleanup2 := createMachinesWithNodes(g, cluster,
 			count(1),
-			createNodeRefForMachine(false),
+			createNodeRefForMachine(true),
 			nodeStatus(corev1.ConditionUnknown),
 			machineLabels(mhc.Spec.Selector.MatchLabels),
 		)
@@ -814,19 +1044,27 @@ func TestMachineHealthCheck_Reconcile(t *testing.T) {
 		}).Should(MatchMachineHealthCheckStatus(&clusterv1.MachineHealthCheckStatus{
 			ExpectedMachines:    3,
 			CurrentHealthy:      2,
-			RemediationsAllowed: 2,
+			RemediationsAllowed: 0,
 			ObservedGeneration:  1,
 			Targets:             targetMachines,
 			Conditions: clusterv1.Conditions{
 				{
-					Type:   clusterv1.RemediationAllowedCondition,
-					Status: corev1.ConditionTrue,
+					Type:     clusterv1.RemediationAllowedCondition,
+					Status:   corev1.ConditionFalse,
+					Severity: clusterv1.ConditionSeverityInfo,
+					Reason:   clusterv1.UpgradeInProgressReason,
+					Message:  "Remediation is paused while the cluster upgrade is in progress",
+				},
+				{
+					Type:    clusterv1.UpgradeInProgressCondition,
+					Status:  corev1.ConditionTrue,
+					Reason:  clusterv1.UpgradeInProgressReason,
+					Message: "The cluster upgrade is in progress",
 				},
 			},
 		}))
 
-		// Calculate how many Machines have health check succeeded = false.
-		g.Eventually(func() (unhealthy int) {
+		countMachines := func(matches func(*clusterv1.Machine) bool) int {
 			machines := &clusterv1.MachineList{}
 			err := testEnv.List(ctx, machines, client.MatchingLabels{
 				"selector": mhc.Spec.Selector.MatchLabels["selector"],
@@ -835,41 +1073,62 @@ func TestMachineHealthCheck_Reconcile(t *testing.T) {
 				return -1
 			}
 
+			matching := 0
 			for i := range machines.Items {
-				if conditions.IsFalse(&machines.Items[i], clusterv1.MachineHealthCheckSuccededCondition) {
-					unhealthy++
+				if matches(&machines.Items[i]) {
+					matching++
 				}
 			}
-			return
-		}).Should(Equal(0))
+			return matching
+		}
 
-		// Calculate how many Machines have been remediated.
-		g.Eventually(func() (remediated int) {
-			machines := &clusterv1.MachineList{}
-			err := testEnv.List(ctx, machines, client.MatchingLabels{
-				"selector": mhc.Spec.Selector.MatchLabels["selector"],
+		// The unhealthy Machine is reported, but not marked for remediation.
+		g.Eventually(func() int {
+			return countMachines(func(m *clusterv1.Machine) bool {
+				return conditions.IsFalse(m, clusterv1.MachineHealthCheckSuccededCondition)
 			})
-			if err != nil {
-				return -1
-			}
+		}).Should(Equal(1))
+		g.Consistently(func() int {
+			return countMachines(func(m *clusterv1.Machine) bool {
+				return conditions.Has(m, clusterv1.MachineOwnerRemediatedCondition)
+			})
+		}, 2*time.Second).Should(Equal(0))
 
-			for i := range machines.Items {
-				if conditions.IsTrue(&machines.Items[i], clusterv1.MachineOwnerRemediatedCondition) {
-					remediated++
-				}
+		// Complete the upgrade.
+		g.Expect(testEnv.Get(ctx, util.ObjectKey(cluster), cluster)).To(Succeed())
+		patchHelper, err = patch.NewHelper(cluster, testEnv.Client)
+		g.Expect(err).To(BeNil())
+		cluster.SetAnnotations(nil)
+		g.Expect(patchHelper.Patch(ctx, cluster)).To(Succeed())
+
+		// The unhealthy Machine is now marked for remediation.
+		g.Eventually(func() int {
+			return countMachines(func(m *clusterv1.Machine) bool {
+				return conditions.IsFalse(m, clusterv1.MachineOwnerRemediatedCondition)
+			})
+		}, timeout, 100*time.Millisecond).Should(Equal(1))
+		g.Eventually(func() bool {
+			if err := testEnv.Get(ctx, util.ObjectKey(mhc), mhc); err != nil {
+				return true
 			}
-			return
-		}).Should(Equal(0))
+			return conditions.Has(mhc, clusterv1.UpgradeInProgressCondition)
+		}, timeout, 100*time.Millisecond).Should(BeFalse())
 	})
 
-	t.Run("when a Machine has no Node ref for longer than the NodeStartupTimeout", func(t *testing.T) {
-		// FIXME: Resolve flaky/failing test
-		t.Skip("skipping until made stable")
+	t.Run("when a Machine has no Node ref for less than the NodeStartupTimeout", func(t *testing.T) {
 		g := NewWithT(t)
 		cluster := createNamespaceAndCluster(g)
 
+		// After the cluster exists, we have to set the infrastructure ready condition; otherwise, MachineHealthChecks
+		// will never fail when nodeStartupTimeout is exceeded.
+		patchHelper, err := patch.NewHelper(cluster, testEnv.GetClient())
+		g.Expect(err).ToNot(HaveOccurred())
+
+		conditions.MarkTrue(cluster, clusterv1.InfrastructureReadyCondition)
+		g.Expect(patchHelper.Patch(ctx, cluster)).To(Succeed())
+
 		mhc := newMachineHealthCheck(cluster.Namespace, cluster.Name)
-		mhc.Spec.NodeStartupTimeout = &metav1.Duration{Duration: time.Second}
+		mhc.Spec.NodeStartupTimeout = &metav1.Duration{Duration: 5 * time.Hour}
 
 		g.Expect(testEnv.Create(ctx, mhc)).To(Succeed())
 		defer func(do ...client.Object) {
@@ -894,23 +1153,20 @@ func TestMachineHealthCheck_Reconcile(t *testing.T) {
 		)
 		defer cleanup2()
 		machines = append(machines, unhealthyMachines...)
-
 		targetMachines := make([]string, len(machines))
 		for i, m := range machines {
 			targetMachines[i] = m.Name
 		}
 		sort.Strings(targetMachines)
 
-		// Make sure the MHC status matches. We have two healthy machines and
-		// one unhealthy.
+		// Make sure the status matches.
 		g.Eventually(func() *clusterv1.MachineHealthCheckStatus {
 			err := testEnv.Get(ctx, util.ObjectKey(mhc), mhc)
 			if err != nil {
-				fmt.Printf("error retrieving mhc: %v", err)
 				return nil
 			}
 			return &mhc.Status
-		}, timeout, 100*time.Millisecond).Should(MatchMachineHealthCheckStatus(&clusterv1.MachineHealthCheckStatus{
+		}).Should(MatchMachineHealthCheckStatus(&clusterv1.MachineHealthCheckStatus{
 			ExpectedMachines:    3,
 			CurrentHealthy:      2,
 			RemediationsAllowed: 2,
@@ -931,7 +1187,6 @@ func TestMachineHealthCheck_Reconcile(t *testing.T) {
 				"selector": mhc.Spec.Selector.MatchLabels["selector"],
 			})
 			if err != nil {
-				fmt.Printf("error retrieving list: %v", err)
 				return -1
 			}
 
@@ -941,7 +1196,7 @@ func TestMachineHealthCheck_Reconcile(t *testing.T) {
 				}
 			}
 			return
-		}, timeout, 100*time.Millisecond).Should(Equal(1))
+		}).Should(Equal(0))
 
 		// Calculate how many Machines have been remediated.
 		g.Eventually(func() (remediated int) {
@@ -954,7 +1209,107 @@ func TestMachineHealthCheck_Reconcile(t *testing.T) {
 			}
 
 			for i := range machines.Items {
-				if conditions.Get(&machines.Items[i], clusterv1.MachineOwnerRemediatedCondition) != nil {
+				if conditions.IsTrue(&machines.Items[i], clusterv1.MachineOwnerRemediatedCondition) {
+					remediated++
+				}
+			}
+			return
+		}).Should(Equal(0))
+	})
+
+	t.Run("when a Machine has no Node ref for longer than the NodeStartupTimeout", func(t *testing.T) {
+		// FIXME: Resolve flaky/failing test
+		t.Skip("skipping until made stable")
+		g := NewWithT(t)
+		cluster := createNamespaceAndCluster(g)
+
+		mhc := newMachineHealthCheck(cluster.Namespace, cluster.Name)
+		mhc.Spec.NodeStartupTimeout = &metav1.Duration{Duration: time.Second}
+
+		g.Expect(testEnv.Create(ctx, mhc)).To(Succeed())
+		defer func(do ...client.Object) {
+			g.Expect(testEnv.Cleanup(ctx, do...)).To(Succeed())
+		}(cluster, mhc)
+
+		// Healthy nodes and machines.
+		_, machines, cleanup1 := createMachinesWithNodes(g, cluster,
+			count(2),
+			firstMachineAsControlPlane(),
+			createNodeRefForMachine(true),
+			nodeStatus(corev1.ConditionTrue),
+			machineLabels(mhc.Spec.Selector.MatchLabels),
+		)
+		defer cleanup1()
+		// Unhealthy nodes and machines.
+		_, unhealthyMachines, cleanup2 := createMachinesWithNodes(g, cluster,
+			count(1),
+			createNodeRefForMachine(false),
+			nodeStatus(corev1.ConditionUnknown),
+			machineLabels(mhc.Spec.Selector.MatchLabels),
+		)
+		defer cleanup2()
+		machines = append(machines, unhealthyMachines...)
+
+		targetMachines := make([]string, len(machines))
+		for i, m := range machines {
+			targetMachines[i] = m.Name
+		}
+		sort.Strings(targetMachines)
+
+		// Make sure the MHC status matches. We have two healthy machines and
+		// one unhealthy.
+		g.Eventually(func() *clusterv1.MachineHealthCheckStatus {
+			err := testEnv.Get(ctx, util.ObjectKey(mhc), mhc)
+			if err != nil {
+				fmt.Printf("error retrieving mhc: %v", err)
+				return nil
+			}
+			return &mhc.Status
+		}, timeout, 100*time.Millisecond).Should(MatchMachineHealthCheckStatus(&clusterv1.MachineHealthCheckStatus{
+			ExpectedMachines:    3,
+			CurrentHealthy:      2,
+			RemediationsAllowed: 2,
+			ObservedGeneration:  1,
+			Targets:             targetMachines,
+			Conditions: clusterv1.Conditions{
+				{
+					Type:   clusterv1.RemediationAllowedCondition,
+					Status: corev1.ConditionTrue,
+				},
+			},
+		}))
+
+		// Calculate how many Machines have health check succeeded = false.
+		g.Eventually(func() (unhealthy int) {
+			machines := &clusterv1.MachineList{}
+			err := testEnv.List(ctx, machines, client.MatchingLabels{
+				"selector": mhc.Spec.Selector.MatchLabels["selector"],
+			})
+			if err != nil {
+				fmt.Printf("error retrieving list: %v", err)
+				return -1
+			}
+
+			for i := range machines.Items {
+				if conditions.IsFalse(&machines.Items[i], clusterv1.MachineHealthCheckSuccededCondition) {
+					unhealthy++
+				}
+			}
+			return
+		}, timeout, 100*time.Millisecond).Should(Equal(1))
+
+		// Calculate how many Machines have been remediated.
+		g.Eventually(func() (remediated int) {
+			machines := &clusterv1.MachineList{}
+			err := testEnv.List(ctx, machines, client.MatchingLabels{
+				"selector": mhc.Spec.Selector.MatchLabels["selector"],
+			})
+			if err != nil {
+				return -1
+			}
+
+			for i := range machines.Items {
+				if conditions.Get(&machines.Items[i], clusterv1.MachineOwnerRemediatedCondition) != nil {
 					remediated++
 				}
 			}
@@ -1171,6 +1526,48 @@ func TestMachineHealthCheck_Reconcile(t *testing.T) {
 			}
 			return
 		}).Should(Equal(1))
+
+		// Transition the node back to healthy before its Machine gets remediated.
+		nodePatch = client.MergeFrom(node.DeepCopy())
+		node.Status.Conditions = []corev1.NodeCondition{
+			{
+				Type:               corev1.NodeReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.Now(),
+			},
+		}
+		g.Expect(testEnv.Status().Patch(ctx, node, nodePatch)).To(Succeed())
+
+		// The node watch triggers a reconcile, which sees the Machine healthy again and clears its pending
+		// remediation in the same pass.
+		g.Eventually(func() bool {
+			machine := &clusterv1.Machine{}
+			if err := testEnv.Get(ctx, util.ObjectKey(machines[0]), machine); err != nil {
+				return false
+			}
+			return conditions.IsTrue(machine, clusterv1.MachineHealthCheckSuccededCondition) &&
+				!conditions.Has(machine, clusterv1.MachineOwnerRemediatedCondition)
+		}, 5*time.Second).Should(BeTrue())
+
+		g.Eventually(func() *clusterv1.MachineHealthCheckStatus {
+			err := testEnv.Get(ctx, util.ObjectKey(mhc), mhc)
+			if err != nil {
+				return nil
+			}
+			return &mhc.Status
+		}, 5*time.Second).Should(MatchMachineHealthCheckStatus(&clusterv1.MachineHealthCheckStatus{
+			ExpectedMachines:    1,
+			CurrentHealthy:      1,
+			RemediationsAllowed: 1,
+			ObservedGeneration:  1,
+			Targets:             targetMachines,
+			Conditions: clusterv1.Conditions{
+				{
+					Type:   clusterv1.RemediationAllowedCondition,
+					Status: corev1.ConditionTrue,
+				},
+			},
+		}))
 	})
 
 	t.Run("when in a MachineSet, unhealthy machines should be deleted", func(t *testing.T) {
@@ -1948,6 +2345,36 @@ func TestMachineToMachineHealthCheck(t *testing.T) {
 	}
 }
 
+func TestMachineToMachineHealthCheckOnDelete(t *testing.T) {
+	g := NewWithT(t)
+
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+	namespace := defaultNamespaceName
+	clusterName := "test-cluster"
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+
+	mhc := newMachineHealthCheckWithLabels("mhc1", namespace, clusterName, labels)
+	mhcReq := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: mhc.Namespace, Name: mhc.Name}}
+	machine := newTestMachine("machine1", namespace, clusterName, "node1", labels)
+
+	r := &MachineHealthCheckReconciler{
+		Client: fake.NewClientBuilder().WithObjects(mhc).Build(),
+	}
+
+	// The deletion of a matched Machine must go through the event filter of the controller, and be
+	// mapped to its MachineHealthCheck, so that its status is recomputed promptly.
+	deleteEvent := event.DeleteEvent{Object: machine}
+	g.Expect(predicates.ResourceNotPausedAndHasFilterLabel(log.NullLogger{}, "").Delete(deleteEvent)).To(BeTrue())
+
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+	handler.EnqueueRequestsFromMapFunc(r.machineToMachineHealthCheck).Delete(deleteEvent, queue)
+
+	g.Expect(queue.Len()).To(Equal(1))
+	item, _ := queue.Get()
+	g.Expect(item).To(Equal(mhcReq))
+}
+
 func TestNodeToMachineHealthCheck(t *testing.T) {
 	_ = clusterv1.AddToScheme(scheme.Scheme)
 	fakeClient := fake.NewClientBuilder().Build()
@@ -1970,6 +2397,7 @@ func TestNodeToMachineHealthCheck(t *testing.T) {
 
 	machine1 := newTestMachine("machine1", namespace, clusterName, nodeName, labels)
 	machine2 := newTestMachine("machine2", namespace, clusterName, nodeName, labels)
+	machine3 := newTestMachine("machine3", namespace, "othercluster", nodeName, labels)
 
 	node1 := &corev1.Node{
 		ObjectMeta: metav1.ObjectMeta{
@@ -2026,6 +2454,13 @@ func TestNodeToMachineHealthCheck(t *testing.T) {
 			object:      node1,
 			expected:    []reconcile.Request{},
 		},
+		{
+			name:        "when a Machine of another cluster in the namespace has a Node with the same name",
+			mhcToCreate: []clusterv1.MachineHealthCheck{*mhc1, *mhc3},
+			mToCreate:   []clusterv1.Machine{*machine1, *machine3},
+			object:      node1,
+			expected:    []reconcile.Request{mhc1Req},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -2068,504 +2503,2747 @@ func TestNodeToMachineHealthCheck(t *testing.T) {
 				gs.Eventually(checkStatus).Should(Equal(o.Status))
 			}
 
-			got := r.nodeToMachineHealthCheck(tc.object)
+			got := r.nodeToMachineHealthCheck(client.ObjectKey{Namespace: namespace, Name: clusterName})(tc.object)
 			gs.Expect(got).To(ConsistOf(tc.expected))
 		})
 	}
 }
 
-func TestIsAllowedRemediation(t *testing.T) {
-	testCases := []struct {
-		name               string
-		maxUnhealthy       *intstr.IntOrString
-		expectedMachines   int32
-		currentHealthy     int32
-		allowed            bool
-		observedGeneration int64
-	}{
-		{
-			name:             "when maxUnhealthy is not set",
-			maxUnhealthy:     nil,
-			expectedMachines: int32(3),
-			currentHealthy:   int32(0),
-			allowed:          false,
+func TestNodeToMachineHealthCheckAcrossClusters(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	// Both clusters have a Machine with the same name, whose Node has the same name as well.
+	clusterName := "test-cluster"
+	nodeName := "node1"
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+
+	mhcA := newMachineHealthCheckWithLabels("mhc", "namespace-a", clusterName, labels)
+	mhcB := newMachineHealthCheckWithLabels("mhc", "namespace-b", clusterName, labels)
+	machineA := newTestMachine("machine1", "namespace-a", clusterName, nodeName, labels)
+	machineB := newTestMachine("machine1", "namespace-b", clusterName, nodeName, labels)
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
 		},
-		{
-			name:             "when maxUnhealthy is not an int or percentage",
-			maxUnhealthy:     &intstr.IntOrString{Type: intstr.String, StrVal: "abcdef"},
-			expectedMachines: int32(5),
-			currentHealthy:   int32(2),
-			allowed:          false,
+	}
+
+	r := &MachineHealthCheckReconciler{
+		Client: fake.NewClientBuilder().WithObjects(mhcA, mhcB, machineA, machineB).Build(),
+	}
+
+	g.Expect(r.nodeToMachineHealthCheck(client.ObjectKey{Namespace: "namespace-a", Name: clusterName})(node)).To(ConsistOf(
+		reconcile.Request{NamespacedName: util.ObjectKey(mhcA)},
+	))
+	g.Expect(r.nodeToMachineHealthCheck(client.ObjectKey{Namespace: "namespace-b", Name: clusterName})(node)).To(ConsistOf(
+		reconcile.Request{NamespacedName: util.ObjectKey(mhcB)},
+	))
+	g.Expect(r.nodeToMachineHealthCheck(client.ObjectKey{Namespace: "namespace-c", Name: clusterName})(node)).To(BeEmpty())
+}
+
+func TestMachineHealthCheckReconcileSelectorOverlap(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
 		},
-		{
-			name:             "when maxUnhealthy is an int less than current unhealthy",
-			maxUnhealthy:     &intstr.IntOrString{Type: intstr.Int, IntVal: int32(1)},
-			expectedMachines: int32(3),
-			currentHealthy:   int32(1),
-			allowed:          false,
+	}
+	kubeconfig := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.Name(cluster.Name, secret.Kubeconfig),
+			Namespace: cluster.Namespace,
+		},
+	}
+	mhc := newMachineHealthCheckWithLabels("mhc", cluster.Namespace, cluster.Name, map[string]string{"nodepool": "a"})
+	// Matches a subset of the same machines.
+	overlapping := newMachineHealthCheckWithLabels("overlapping", cluster.Namespace, cluster.Name, map[string]string{"nodepool": "a", "role": "worker"})
+	// Matches a different node pool.
+	disjoint := newMachineHealthCheckWithLabels("disjoint", cluster.Namespace, cluster.Name, map[string]string{"nodepool": "b"})
+
+	r := newFakeMHCReconciler(client.ObjectKeyFromObject(cluster), cluster, kubeconfig, mhc, overlapping, disjoint)
+
+	_, err := r.reconcile(ctx, log.NullLogger{}, cluster, mhc)
+	g.Expect(err).NotTo(HaveOccurred())
+	condition := conditions.Get(mhc, clusterv1.SelectorExclusiveCondition)
+	g.Expect(condition).NotTo(BeNil())
+	g.Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(condition.Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+	g.Expect(condition.Reason).To(Equal(clusterv1.SelectorOverlapReason))
+	g.Expect(condition.Message).To(Equal("Selector overlaps with MachineHealthCheck(s) overlapping"))
+
+	_, err = r.reconcile(ctx, log.NullLogger{}, cluster, disjoint)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(conditions.Has(disjoint, clusterv1.SelectorExclusiveCondition)).To(BeFalse())
+}
+
+func TestReconcileClusterMachinesHealthy(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+	healthy := newMachineHealthCheckWithLabels("healthy", "default", cluster.Name, map[string]string{"nodepool": "a"})
+	healthy.Status.ExpectedMachines = 3
+	healthy.Status.CurrentHealthy = 3
+	unhealthy := newMachineHealthCheckWithLabels("unhealthy", "default", cluster.Name, map[string]string{"nodepool": "b"})
+	unhealthy.Status.ExpectedMachines = 3
+	unhealthy.Status.CurrentHealthy = 1
+	// Belongs to another cluster, must not be counted.
+	otherCluster := newMachineHealthCheckWithLabels("other-cluster", "default", "other-cluster", map[string]string{"nodepool": "a"})
+	otherCluster.Status.ExpectedMachines = 5
+
+	fakeClient := fake.NewClientBuilder().WithObjects(cluster, healthy, unhealthy, otherCluster).Build()
+	r := &MachineHealthCheckReconciler{
+		Client: fakeClient,
+	}
+
+	g.Expect(r.reconcileClusterMachinesHealthy(ctx, cluster, healthy)).To(Succeed())
+
+	updated := &clusterv1.Cluster{}
+	g.Expect(fakeClient.Get(ctx, util.ObjectKey(cluster), updated)).To(Succeed())
+	condition := conditions.Get(updated, clusterv1.MachinesHealthyCondition)
+	g.Expect(condition).NotTo(BeNil())
+	g.Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(condition.Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+	g.Expect(condition.Reason).To(Equal(clusterv1.UnhealthyMachinesReason))
+	g.Expect(condition.Message).To(Equal("4 of 6 machines are healthy; MachineHealthCheck(s) with unhealthy machines: unhealthy"))
+
+	// The unhealthy MachineHealthCheck recovers; its status is not persisted yet, so the one being reconciled is used.
+	recovered := unhealthy.DeepCopy()
+	recovered.Status.CurrentHealthy = 3
+	g.Expect(r.reconcileClusterMachinesHealthy(ctx, updated, recovered)).To(Succeed())
+
+	updated = &clusterv1.Cluster{}
+	g.Expect(fakeClient.Get(ctx, util.ObjectKey(cluster), updated)).To(Succeed())
+	g.Expect(conditions.IsTrue(updated, clusterv1.MachinesHealthyCondition)).To(BeTrue())
+}
+
+func TestMachineHealthCheckReconcileKubeconfigMissing(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+	kubeconfig := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.Name(cluster.Name, secret.Kubeconfig),
+			Namespace: cluster.Namespace,
 		},
+	}
+	mhc := newMachineHealthCheckWithLabels("test-mhc", "default", cluster.Name, map[string]string{"nodepool": "a"})
+	mhc.Status = clusterv1.MachineHealthCheckStatus{
+		ExpectedMachines:    3,
+		CurrentHealthy:      2,
+		RemediationsAllowed: 1,
+		Targets:             []string{"machine-1", "machine-2", "machine-3"},
+	}
+	expectedStatus := mhc.Status.DeepCopy()
+
+	fakeClient := fake.NewClientBuilder().WithObjects(cluster, kubeconfig, mhc).Build()
+	g.Expect(fakeClient.Delete(ctx, kubeconfig)).To(Succeed())
+	r := &MachineHealthCheckReconciler{
+		Client: fakeClient,
+	}
+
+	result, err := r.reconcile(ctx, log.Log, cluster, mhc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.Requeue).To(BeTrue())
+
+	condition := conditions.Get(mhc, clusterv1.KubeconfigAvailableCondition)
+	g.Expect(condition).NotTo(BeNil())
+	g.Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(condition.Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+	g.Expect(condition.Reason).To(Equal(clusterv1.KubeconfigMissingReason))
+	g.Expect(condition.Message).To(Equal("Secret test-cluster-kubeconfig not found"))
+
+	// The status observed last must not be reset while the Machines cannot be health checked.
+	g.Expect(mhc.Status.ExpectedMachines).To(Equal(expectedStatus.ExpectedMachines))
+	g.Expect(mhc.Status.CurrentHealthy).To(Equal(expectedStatus.CurrentHealthy))
+	g.Expect(mhc.Status.RemediationsAllowed).To(Equal(expectedStatus.RemediationsAllowed))
+	g.Expect(mhc.Status.Targets).To(Equal(expectedStatus.Targets))
+}
+
+func TestMachineHealthCheckReconcileClusterWideOutage(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		unhealthyMachines      int
+		clusterOutageThreshold *intstr.IntOrString
+		expectOutage           bool
+	}{
 		{
-			name:             "when maxUnhealthy is an int equal to current unhealthy",
-			maxUnhealthy:     &intstr.IntOrString{Type: intstr.Int, IntVal: int32(2)},
-			expectedMachines: int32(3),
-			currentHealthy:   int32(1),
-			allowed:          true,
+			name:              "when all the machines of the cluster are unhealthy",
+			unhealthyMachines: 3,
+			expectOutage:      true,
 		},
 		{
-			name:             "when maxUnhealthy is an int greater than current unhealthy",
-			maxUnhealthy:     &intstr.IntOrString{Type: intstr.Int, IntVal: int32(3)},
-			expectedMachines: int32(3),
-			currentHealthy:   int32(1),
-			allowed:          true,
+			name:              "when some machines of the cluster are healthy",
+			unhealthyMachines: 2,
+			expectOutage:      false,
 		},
 		{
-			name:             "when maxUnhealthy is a percentage less than current unhealthy",
-			maxUnhealthy:     &intstr.IntOrString{Type: intstr.String, StrVal: "50%"},
-			expectedMachines: int32(5),
-			currentHealthy:   int32(2),
-			allowed:          false,
+			name:                   "when the unhealthy machines reach the clusterOutageThreshold",
+			unhealthyMachines:      2,
+			clusterOutageThreshold: &intstr.IntOrString{Type: intstr.String, StrVal: "60%"},
+			expectOutage:           true,
 		},
 		{
-			name:             "when maxUnhealthy is a percentage equal to current unhealthy",
-			maxUnhealthy:     &intstr.IntOrString{Type: intstr.String, StrVal: "60%"},
-			expectedMachines: int32(5),
-			currentHealthy:   int32(2),
-			allowed:          true,
-		},
-		{
-			name:             "when maxUnhealthy is a percentage greater than current unhealthy",
-			maxUnhealthy:     &intstr.IntOrString{Type: intstr.String, StrVal: "70%"},
-			expectedMachines: int32(5),
-			currentHealthy:   int32(2),
-			allowed:          true,
+			name:                   "when the clusterOutageThreshold is 0",
+			unhealthyMachines:      3,
+			clusterOutageThreshold: &intstr.IntOrString{Type: intstr.Int, IntVal: 0},
+			expectOutage:           false,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			g := NewWithT(t)
+			_ = clusterv1.AddToScheme(scheme.Scheme)
 
-			mhc := &clusterv1.MachineHealthCheck{
-				Spec: clusterv1.MachineHealthCheckSpec{
-					MaxUnhealthy:       tc.maxUnhealthy,
-					NodeStartupTimeout: &metav1.Duration{Duration: 1 * time.Millisecond},
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-cluster",
+					Namespace: "default",
 				},
-				Status: clusterv1.MachineHealthCheckStatus{
-					ExpectedMachines:   tc.expectedMachines,
-					CurrentHealthy:     tc.currentHealthy,
-					ObservedGeneration: tc.observedGeneration,
+			}
+			kubeconfig := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      secret.Name(cluster.Name, secret.Kubeconfig),
+					Namespace: cluster.Namespace,
 				},
 			}
+			labels := map[string]string{"nodepool": "a"}
+			mhc := newMachineHealthCheckWithLabels("test-mhc", cluster.Namespace, cluster.Name, labels)
+			mhc.Spec.ClusterOutageThreshold = tc.clusterOutageThreshold
+
+			// The machines failed, as their FailureReason reports; their nodes do exist.
+			objs := []client.Object{cluster, kubeconfig, mhc}
+			var machines []*clusterv1.Machine
+			for i := 0; i < 3; i++ {
+				nodeName := fmt.Sprintf("node-%d", i)
+				machine := newTestMachine(fmt.Sprintf("machine-%d", i), cluster.Namespace, cluster.Name, nodeName, labels)
+				if i < tc.unhealthyMachines {
+					failureReason := capierrors.UpdateMachineError
+					machine.Status.FailureReason = &failureReason
+				}
+				machines = append(machines, machine)
+				objs = append(objs, machine, newTestNode(nodeName))
+			}
 
-			remediationAllowed, _, _ := isAllowedRemediation(mhc)
-			g.Expect(remediationAllowed).To(Equal(tc.allowed))
+			r := newFakeMHCReconciler(client.ObjectKeyFromObject(cluster), objs...)
+
+			_, err := r.reconcile(ctx, log.NullLogger{}, cluster, mhc)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			remediated := 0
+			for _, machine := range machines {
+				updated := &clusterv1.Machine{}
+				g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(machine), updated)).To(Succeed())
+				if conditions.Has(updated, clusterv1.MachineOwnerRemediatedCondition) {
+					remediated++
+				}
+			}
+
+			if !tc.expectOutage {
+				g.Expect(conditions.Has(mhc, clusterv1.ClusterWideOutageSuspectedCondition)).To(BeFalse())
+				g.Expect(conditions.IsTrue(mhc, clusterv1.RemediationAllowedCondition)).To(BeTrue())
+				g.Expect(remediated).To(Equal(tc.unhealthyMachines))
+				return
+			}
+
+			// Remediation is suppressed for all the machines.
+			g.Expect(remediated).To(Equal(0))
+			g.Expect(mhc.Status.RemediationsAllowed).To(Equal(int32(0)))
+			condition := conditions.Get(mhc, clusterv1.ClusterWideOutageSuspectedCondition)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+			g.Expect(condition.Reason).To(Equal(clusterv1.ClusterOutageThresholdReachedReason))
+			condition = conditions.Get(mhc, clusterv1.RemediationAllowedCondition)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+			g.Expect(condition.Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+			g.Expect(condition.Reason).To(Equal(clusterv1.ClusterOutageThresholdReachedReason))
+			g.Expect(condition.Message).To(ContainSubstring(fmt.Sprintf("%d of the 3 machines of the cluster are unhealthy", tc.unhealthyMachines)))
 		})
 	}
 }
 
-func TestGetMaxUnhealthy(t *testing.T) {
+func TestMachineHealthCheckReconcileStaleNodeStatus(t *testing.T) {
 	testCases := []struct {
-		name                 string
-		maxUnhealthy         *intstr.IntOrString
-		expectedMaxUnhealthy int
-		actualMachineCount   int32
-		expectedErr          error
+		name                     string
+		staleNodes               int
+		staleNodeStatusTimeout   *metav1.Duration
+		staleNodeStatusThreshold *intstr.IntOrString
+		expectSuppressed         bool
 	}{
 		{
-			name:                 "when maxUnhealthy is nil",
-			maxUnhealthy:         nil,
-			expectedMaxUnhealthy: 0,
-			actualMachineCount:   7,
-			expectedErr:          errors.New("spec.maxUnhealthy must be set"),
-		},
-		{
-			name:                 "when maxUnhealthy is not an int or percentage",
-			maxUnhealthy:         &intstr.IntOrString{Type: intstr.String, StrVal: "abcdef"},
-			expectedMaxUnhealthy: 0,
-			actualMachineCount:   3,
-			expectedErr:          errors.New("invalid value for IntOrString: invalid value \"abcdef\": strconv.Atoi: parsing \"abcdef\": invalid syntax"),
+			name:                   "when all the nodes have a stale status",
+			staleNodes:             3,
+			staleNodeStatusTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+			expectSuppressed:       true,
 		},
 		{
-			name:                 "when maxUnhealthy is an int",
-			maxUnhealthy:         &intstr.IntOrString{Type: intstr.Int, IntVal: 3},
-			actualMachineCount:   2,
-			expectedMaxUnhealthy: 3,
-			expectedErr:          nil,
+			name:                   "when a single node has a stale status",
+			staleNodes:             1,
+			staleNodeStatusTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+			expectSuppressed:       false,
 		},
 		{
-			name:                 "when maxUnhealthy is a 40% (of 5)",
-			maxUnhealthy:         &intstr.IntOrString{Type: intstr.String, StrVal: "40%"},
-			actualMachineCount:   5,
-			expectedMaxUnhealthy: 2,
-			expectedErr:          nil,
+			name:                     "when the nodes with a stale status reach the staleNodeStatusThreshold",
+			staleNodes:               1,
+			staleNodeStatusTimeout:   &metav1.Duration{Duration: 10 * time.Minute},
+			staleNodeStatusThreshold: &intstr.IntOrString{Type: intstr.String, StrVal: "30%"},
+			expectSuppressed:         true,
 		},
 		{
-			name:                 "when maxUnhealthy is a 60% (of 7)",
-			maxUnhealthy:         &intstr.IntOrString{Type: intstr.String, StrVal: "60%"},
-			actualMachineCount:   7,
-			expectedMaxUnhealthy: 4,
-			expectedErr:          nil,
+			name:             "when the staleNodeStatusTimeout is not set",
+			staleNodes:       3,
+			expectSuppressed: false,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			g := NewWithT(t)
+			_ = clusterv1.AddToScheme(scheme.Scheme)
 
-			mhc := &clusterv1.MachineHealthCheck{
-				Spec: clusterv1.MachineHealthCheckSpec{
-					MaxUnhealthy: tc.maxUnhealthy,
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-cluster",
+					Namespace: "default",
 				},
-				Status: clusterv1.MachineHealthCheckStatus{
-					ExpectedMachines: tc.actualMachineCount,
+			}
+			conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+			conditions.MarkTrue(cluster, clusterv1.InfrastructureReadyCondition)
+			kubeconfig := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      secret.Name(cluster.Name, secret.Kubeconfig),
+					Namespace: cluster.Namespace,
 				},
 			}
+			labels := map[string]string{"nodepool": "a"}
+			mhc := newMachineHealthCheckWithLabels("test-mhc", cluster.Namespace, cluster.Name, labels)
+			mhc.Spec.ClusterOutageThreshold = &intstr.IntOrString{Type: intstr.Int, IntVal: 0}
+			mhc.Spec.StaleNodeStatusTimeout = tc.staleNodeStatusTimeout
+			mhc.Spec.StaleNodeStatusThreshold = tc.staleNodeStatusThreshold
+
+			// All the nodes have been Unknown for an hour; the status of the first ones was last heartbeated back then.
+			// The times are relative to the clock of the reconciler, which the staleness is evaluated with.
+			fakeClock := clock.NewFakeClock(time.Date(2021, 3, 1, 2, 0, 0, 0, time.UTC))
+			objs := []client.Object{cluster, kubeconfig, mhc}
+			var machines []*clusterv1.Machine
+			for i := 0; i < 3; i++ {
+				nodeName := fmt.Sprintf("node-%d", i)
+				machine := newTestMachine(fmt.Sprintf("machine-%d", i), cluster.Namespace, cluster.Name, nodeName, labels)
+				lastHeartbeat := metav1.NewTime(fakeClock.Now())
+				if i < tc.staleNodes {
+					lastHeartbeat = metav1.NewTime(fakeClock.Now().Add(-time.Hour))
+				}
+				node := newTestNode(nodeName)
+				node.Status.Conditions = []corev1.NodeCondition{
+					{
+						Type:               corev1.NodeReady,
+						Status:             corev1.ConditionUnknown,
+						LastHeartbeatTime:  lastHeartbeat,
+						LastTransitionTime: metav1.NewTime(fakeClock.Now().Add(-time.Hour)),
+					},
+				}
+				machines = append(machines, machine)
+				objs = append(objs, machine, node)
+			}
 
-			maxUnhealthy, err := getMaxUnhealthy(mhc)
-			if tc.expectedErr != nil {
-				g.Expect(err).To(MatchError(tc.expectedErr.Error()))
-			} else {
-				g.Expect(err).ToNot(HaveOccurred())
+			r := newFakeMHCReconciler(client.ObjectKeyFromObject(cluster), objs...)
+			r.clock = fakeClock
+
+			_, err := r.reconcile(ctx, log.NullLogger{}, cluster, mhc)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			remediated := 0
+			for _, machine := range machines {
+				updated := &clusterv1.Machine{}
+				g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(machine), updated)).To(Succeed())
+				g.Expect(conditions.IsFalse(updated, clusterv1.MachineHealthCheckSuccededCondition)).To(BeTrue())
+				if conditions.Has(updated, clusterv1.MachineOwnerRemediatedCondition) {
+					remediated++
+				}
 			}
-			g.Expect(maxUnhealthy).To(Equal(tc.expectedMaxUnhealthy))
-		})
-	}
-}
 
-func ownerReferenceForCluster(ctx context.Context, g *WithT, c *clusterv1.Cluster) metav1.OwnerReference {
-	// Fetch the cluster to populate the UID
-	cc := &clusterv1.Cluster{}
-	g.Expect(testEnv.GetClient().Get(ctx, util.ObjectKey(c), cc)).To(Succeed())
+			if !tc.expectSuppressed {
+				g.Expect(conditions.Has(mhc, clusterv1.NodeStatusStaleCondition)).To(BeFalse())
+				g.Expect(conditions.IsTrue(mhc, clusterv1.RemediationAllowedCondition)).To(BeTrue())
+				g.Expect(remediated).To(Equal(3))
+				return
+			}
 
-	return metav1.OwnerReference{
-		APIVersion: clusterv1.GroupVersion.String(),
-		Kind:       "Cluster",
-		Name:       cc.Name,
-		UID:        cc.UID,
+			// Remediation is suppressed for all the machines, which are still reported as unhealthy.
+			g.Expect(remediated).To(Equal(0))
+			g.Expect(mhc.Status.RemediationsAllowed).To(Equal(int32(0)))
+			condition := conditions.Get(mhc, clusterv1.NodeStatusStaleCondition)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+			g.Expect(condition.Reason).To(Equal(clusterv1.StaleNodeStatusThresholdReachedReason))
+			condition = conditions.Get(mhc, clusterv1.RemediationAllowedCondition)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+			g.Expect(condition.Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+			g.Expect(condition.Reason).To(Equal(clusterv1.StaleNodeStatusThresholdReachedReason))
+			g.Expect(condition.Message).To(ContainSubstring(fmt.Sprintf("%d of the 3 nodes have not reported their status", tc.staleNodes)))
+		})
 	}
 }
 
-// createNamespaceAndCluster creates a namespace in the test environment. It
-// then creates a Cluster and KubeconfigSecret for that cluster in said
-// namespace.
-func createNamespaceAndCluster(g *WithT) *clusterv1.Cluster {
-	ns, err := testEnv.CreateNamespace(ctx, "test-mhc")
-	g.Expect(err).ToNot(HaveOccurred())
+func TestMachineHealthCheckReconcileExplanation(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
 
 	cluster := &clusterv1.Cluster{
 		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: "test-cluster-",
-			Namespace:    ns.Name,
+			Name:      "test-cluster",
+			Namespace: "default",
 		},
 	}
-
-	g.Expect(testEnv.Create(ctx, cluster)).To(Succeed())
-
-	// Make sure the cluster is in the cache before proceeding
-	g.Eventually(func() error {
-		var cl clusterv1.Cluster
-		return testEnv.Get(ctx, util.ObjectKey(cluster), &cl)
-	}, timeout, 100*time.Millisecond).Should(Succeed())
-
-	// This is required for MHC to perform checks
-	patchHelper, err := patch.NewHelper(cluster, testEnv.Client)
-	g.Expect(err).To(BeNil())
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
 	conditions.MarkTrue(cluster, clusterv1.InfrastructureReadyCondition)
-	g.Expect(patchHelper.Patch(ctx, cluster)).To(Succeed())
-
-	// Wait for cluster in cache to be updated post-patch
-	g.Eventually(func() bool {
-		err := testEnv.Get(ctx, util.ObjectKey(cluster), cluster)
-		if err != nil {
-			return false
-		}
-
-		return conditions.IsTrue(cluster, clusterv1.InfrastructureReadyCondition)
-	}, timeout, 100*time.Millisecond).Should(BeTrue())
-
-	g.Expect(testEnv.CreateKubeconfigSecret(ctx, cluster)).To(Succeed())
-
-	return cluster
-}
-
-// newRunningMachine creates a Machine object with a Status.Phase == Running.
-func newRunningMachine(c *clusterv1.Cluster, labels map[string]string) *clusterv1.Machine {
-	return &clusterv1.Machine{
-		TypeMeta: metav1.TypeMeta{
-			APIVersion: clusterv1.GroupVersion.String(),
-			Kind:       "Machine",
-		},
+	kubeconfig := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: "test-mhc-machine-",
-			Namespace:    c.Namespace,
-			Labels:       labels,
-		},
-		Spec: clusterv1.MachineSpec{
-			ClusterName: c.Name,
-			Bootstrap: clusterv1.Bootstrap{
-				DataSecretName: pointer.StringPtr("data-secret-name"),
-			},
-		},
-		Status: clusterv1.MachineStatus{
-			InfrastructureReady: true,
-			BootstrapReady:      true,
-			Phase:               string(clusterv1.MachinePhaseRunning),
-			ObservedGeneration:  1,
+			Name:      secret.Name(cluster.Name, secret.Kubeconfig),
+			Namespace: cluster.Namespace,
 		},
 	}
-}
-
-func newInfraMachine(machine *clusterv1.Machine) (*unstructured.Unstructured, string) {
-	providerID := fmt.Sprintf("test:////%v", uuid.NewUUID())
-	return &unstructured.Unstructured{
-		Object: map[string]interface{}{
-			"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
-			"kind":       "InfrastructureMachine",
-			"metadata": map[string]interface{}{
-				"generateName": "test-mhc-machine-infra-",
-				"namespace":    machine.Namespace,
-			},
-			"spec": map[string]interface{}{
-				"providerID": providerID,
-			},
-		},
-	}, providerID
-}
+	labels := map[string]string{"nodepool": "a"}
+	mhc := newMachineHealthCheckWithLabels("test-mhc", cluster.Namespace, cluster.Name, labels)
+	mhc.Spec.ClusterOutageThreshold = &intstr.IntOrString{Type: intstr.Int, IntVal: 0}
+	mhc.Spec.MaxUnhealthy = &intstr.IntOrString{Type: intstr.Int, IntVal: 1}
+
+	// Two of the three nodes have been Unknown for an hour, exceeding maxUnhealthy.
+	objs := []client.Object{cluster, kubeconfig, mhc}
+	var machines []*clusterv1.Machine
+	for i := 0; i < 3; i++ {
+		nodeName := fmt.Sprintf("node-%d", i)
+		machine := newTestMachine(fmt.Sprintf("machine-%d", i), cluster.Namespace, cluster.Name, nodeName, labels)
+		node := newTestNode(nodeName)
+		if i < 2 {
+			node.Status.Conditions = []corev1.NodeCondition{
+				{
+					Type:               corev1.NodeReady,
+					Status:             corev1.ConditionUnknown,
+					LastHeartbeatTime:  metav1.NewTime(time.Now().Add(-time.Hour)),
+					LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+				},
+			}
+		}
+		machines = append(machines, machine)
+		objs = append(objs, machine, node)
+	}
 
-type machinesWithNodes struct {
-	count                      int
-	nodeStatus                 corev1.ConditionStatus
-	createNodeRefForMachine    bool
-	firstMachineAsControlPlane bool
-	labels                     map[string]string
-	failureReason              string
-	failureMessage             string
-}
+	r := newFakeMHCReconciler(client.ObjectKeyFromObject(cluster), objs...)
 
-type machineWithNodesOption func(m *machinesWithNodes)
+	_, err := r.reconcile(ctx, log.NullLogger{}, cluster, mhc)
+	g.Expect(err).NotTo(HaveOccurred())
 
-func count(n int) machineWithNodesOption {
-	return func(m *machinesWithNodes) {
-		m.count = n
+	explain := func(machine *clusterv1.Machine) healthCheckExplanation {
+		updated := &clusterv1.Machine{}
+		g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(machine), updated)).To(Succeed())
+		g.Expect(updated.Annotations).To(HaveKey(clusterv1.MachineHealthCheckExplanationAnnotation))
+		explanation := healthCheckExplanation{}
+		g.Expect(json.Unmarshal([]byte(updated.Annotations[clusterv1.MachineHealthCheckExplanationAnnotation]), &explanation)).To(Succeed())
+		return explanation
 	}
-}
 
-func firstMachineAsControlPlane() machineWithNodesOption {
-	return func(m *machinesWithNodes) {
-		m.firstMachineAsControlPlane = true
+	// The unhealthy machine is not remediated because of maxUnhealthy, with the condition it timed out on.
+	explanation := explain(machines[0])
+	g.Expect(explanation.MachineHealthCheck).To(Equal(mhc.Name))
+	g.Expect(explanation.Healthy).To(BeFalse())
+	g.Expect(explanation.Reason).To(Equal(clusterv1.UnhealthyNodeConditionReason))
+	g.Expect(explanation.Remediation.Allowed).To(BeFalse())
+	g.Expect(explanation.Remediation.Reason).To(Equal(clusterv1.TooManyUnhealthyReason))
+	g.Expect(explanation.Remediation.Message).To(ContainSubstring("exceeds maxUnhealthy"))
+	g.Expect(explanation.Conditions).To(HaveLen(1))
+	g.Expect(explanation.Conditions[0].Type).To(Equal(corev1.NodeReady))
+	g.Expect(explanation.Conditions[0].Status).To(Equal(corev1.ConditionUnknown))
+	g.Expect(explanation.Conditions[0].Timeout.Duration).To(Equal(5 * time.Minute))
+	g.Expect(explanation.Conditions[0].Since).NotTo(BeNil())
+	g.Expect(explanation.Conditions[0].Since.Time).To(BeTemporally("~", time.Now().Add(-time.Hour), time.Minute))
+	g.Expect(explanation.Conditions[0].TimedOut).To(BeTrue())
+
+	// The healthy machine does not meet the condition.
+	explanation = explain(machines[2])
+	g.Expect(explanation.Healthy).To(BeTrue())
+	g.Expect(explanation.Conditions).To(HaveLen(1))
+	g.Expect(explanation.Conditions[0].Since).To(BeNil())
+	g.Expect(explanation.Conditions[0].TimedOut).To(BeFalse())
+
+	// The explanation does not change with the time of the evaluation, not to patch the machines on every reconcile.
+	annotation := func(machine *clusterv1.Machine) string {
+		updated := &clusterv1.Machine{}
+		g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(machine), updated)).To(Succeed())
+		return updated.Annotations[clusterv1.MachineHealthCheckExplanationAnnotation]
 	}
+	before := annotation(machines[0])
+	r.clock = clock.NewFakeClock(time.Now().Add(10 * time.Minute))
+	_, err = r.reconcile(ctx, log.NullLogger{}, cluster, mhc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(annotation(machines[0])).To(Equal(before))
 }
 
-func nodeStatus(s corev1.ConditionStatus) machineWithNodesOption {
-	return func(m *machinesWithNodes) {
-		m.nodeStatus = s
+func TestRemediationPausedForUpgrade(t *testing.T) {
+	upgrading := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{clusterv1.ClusterUpgradeInProgressAnnotation: ""},
+		},
 	}
-}
 
-func createNodeRefForMachine(b bool) machineWithNodesOption {
-	return func(m *machinesWithNodes) {
-		m.createNodeRefForMachine = b
+	testCases := []struct {
+		name               string
+		cluster            *clusterv1.Cluster
+		pauseDuringUpgrade *bool
+		expected           bool
+	}{
+		{
+			name:               "when the cluster is being upgraded",
+			cluster:            upgrading,
+			pauseDuringUpgrade: pointer.BoolPtr(true),
+			expected:           true,
+		},
+		{
+			name:               "when the cluster is not being upgraded",
+			cluster:            &clusterv1.Cluster{},
+			pauseDuringUpgrade: pointer.BoolPtr(true),
+			expected:           false,
+		},
+		{
+			name:               "when pauseDuringUpgrade is not set",
+			cluster:            upgrading,
+			pauseDuringUpgrade: nil,
+			expected:           false,
+		},
+		{
+			name:               "when pauseDuringUpgrade is false",
+			cluster:            upgrading,
+			pauseDuringUpgrade: pointer.BoolPtr(false),
+			expected:           false,
+		},
 	}
-}
 
-func machineLabels(l map[string]string) machineWithNodesOption {
-	return func(m *machinesWithNodes) {
-		m.labels = l
-	}
-}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
 
-func machineFailureReason(s string) machineWithNodesOption {
-	return func(m *machinesWithNodes) {
-		m.failureReason = s
+			mhc := &clusterv1.MachineHealthCheck{
+				Spec: clusterv1.MachineHealthCheckSpec{
+					PauseDuringUpgrade: tc.pauseDuringUpgrade,
+				},
+			}
+			g.Expect(remediationPausedForUpgrade(tc.cluster, mhc)).To(Equal(tc.expected))
+		})
 	}
 }
 
-func machineFailureMessage(s string) machineWithNodesOption {
-	return func(m *machinesWithNodes) {
-		m.failureMessage = s
+func TestReconcileUpgradeInProgress(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{clusterv1.ClusterUpgradeInProgressAnnotation: ""},
+		},
 	}
+	mhc := &clusterv1.MachineHealthCheck{}
+
+	reconcileUpgradeInProgress(cluster, mhc)
+	condition := conditions.Get(mhc, clusterv1.UpgradeInProgressCondition)
+	g.Expect(condition).NotTo(BeNil())
+	g.Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(condition.Reason).To(Equal(clusterv1.UpgradeInProgressReason))
+
+	// The condition is removed once the upgrade completes.
+	cluster.SetAnnotations(nil)
+	reconcileUpgradeInProgress(cluster, mhc)
+	g.Expect(conditions.Has(mhc, clusterv1.UpgradeInProgressCondition)).To(BeFalse())
 }
 
-func createMachinesWithNodes(
-	g *WithT,
-	c *clusterv1.Cluster,
-	opts ...machineWithNodesOption,
-) ([]*corev1.Node, []*clusterv1.Machine, func()) {
-	o := &machinesWithNodes{}
-	for _, op := range opts {
+func TestIsAllowedRemediation(t *testing.T) {
+	testCases := []struct {
+		name               string
+		maxUnhealthy       *intstr.IntOrString
+		minHealthy         *intstr.IntOrString
+		expectedMachines   int32
+		currentHealthy     int32
+		allowed            bool
+		observedGeneration int64
+	}{
+		{
+			name:             "when maxUnhealthy is not set",
+			maxUnhealthy:     nil,
+			expectedMachines: int32(3),
+			currentHealthy:   int32(0),
+			allowed:          false,
+		},
+		{
+			name:             "when maxUnhealthy is not an int or percentage",
+			maxUnhealthy:     &intstr.IntOrString{Type: intstr.String, StrVal: "abcdef"},
+			expectedMachines: int32(5),
+			currentHealthy:   int32(2),
+			allowed:          false,
+		},
+		{
+			name:             "when maxUnhealthy is an int less than current unhealthy",
+			maxUnhealthy:     &intstr.IntOrString{Type: intstr.Int, IntVal: int32(1)},
+			expectedMachines: int32(3),
+			currentHealthy:   int32(1),
+			allowed:          false,
+		},
+		{
+			name:             "when maxUnhealthy is an int equal to current unhealthy",
+			maxUnhealthy:     &intstr.IntOrString{Type: intstr.Int, IntVal: int32(2)},
+			expectedMachines: int32(3),
+			currentHealthy:   int32(1),
+			allowed:          true,
+		},
+		{
+			name:             "when maxUnhealthy is an int greater than current unhealthy",
+			maxUnhealthy:     &intstr.IntOrString{Type: intstr.Int, IntVal: int32(3)},
+			expectedMachines: int32(3),
+			currentHealthy:   int32(1),
+			allowed:          true,
+		},
+		{
+			name:             "when maxUnhealthy is a percentage less than current unhealthy",
+			maxUnhealthy:     &intstr.IntOrString{Type: intstr.String, StrVal: "50%"},
+			expectedMachines: int32(5),
+			currentHealthy:   int32(2),
+			allowed:          false,
+		},
+		{
+			name:             "when maxUnhealthy is a percentage equal to current unhealthy",
+			maxUnhealthy:     &intstr.IntOrString{Type: intstr.String, StrVal: "60%"},
+			expectedMachines: int32(5),
+			currentHealthy:   int32(2),
+			allowed:          true,
+		},
+		{
+			name:             "when maxUnhealthy is a percentage greater than current unhealthy",
+			maxUnhealthy:     &intstr.IntOrString{Type: intstr.String, StrVal: "70%"},
+			expectedMachines: int32(5),
+			currentHealthy:   int32(2),
+			allowed:          true,
+		},
+		{
+			name:             "when minHealthy is not an int or percentage",
+			maxUnhealthy:     &intstr.IntOrString{Type: intstr.String, StrVal: "100%"},
+			minHealthy:       &intstr.IntOrString{Type: intstr.String, StrVal: "abcdef"},
+			expectedMachines: int32(5),
+			currentHealthy:   int32(4),
+			allowed:          false,
+		},
+		{
+			name:             "when maxUnhealthy allows remediation and current healthy is below minHealthy int",
+			maxUnhealthy:     &intstr.IntOrString{Type: intstr.String, StrVal: "100%"},
+			minHealthy:       &intstr.IntOrString{Type: intstr.Int, IntVal: int32(3)},
+			expectedMachines: int32(5),
+			currentHealthy:   int32(2),
+			allowed:          false,
+		},
+		{
+			name:             "when maxUnhealthy allows remediation and current healthy is equal to minHealthy int",
+			maxUnhealthy:     &intstr.IntOrString{Type: intstr.String, StrVal: "100%"},
+			minHealthy:       &intstr.IntOrString{Type: intstr.Int, IntVal: int32(2)},
+			expectedMachines: int32(5),
+			currentHealthy:   int32(2),
+			allowed:          true,
+		},
+		{
+			name:             "when maxUnhealthy allows remediation and current healthy is below minHealthy percentage rounded up",
+			maxUnhealthy:     &intstr.IntOrString{Type: intstr.String, StrVal: "100%"},
+			minHealthy:       &intstr.IntOrString{Type: intstr.String, StrVal: "50%"},
+			expectedMachines: int32(5),
+			currentHealthy:   int32(2),
+			allowed:          false,
+		},
+		{
+			name:             "when maxUnhealthy allows remediation and current healthy is above minHealthy percentage",
+			maxUnhealthy:     &intstr.IntOrString{Type: intstr.String, StrVal: "100%"},
+			minHealthy:       &intstr.IntOrString{Type: intstr.String, StrVal: "40%"},
+			expectedMachines: int32(5),
+			currentHealthy:   int32(3),
+			allowed:          true,
+		},
+		{
+			name:             "when current healthy is above minHealthy but maxUnhealthy blocks remediation",
+			maxUnhealthy:     &intstr.IntOrString{Type: intstr.Int, IntVal: int32(1)},
+			minHealthy:       &intstr.IntOrString{Type: intstr.Int, IntVal: int32(1)},
+			expectedMachines: int32(5),
+			currentHealthy:   int32(3),
+			allowed:          false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			mhc := &clusterv1.MachineHealthCheck{
+				Spec: clusterv1.MachineHealthCheckSpec{
+					MaxUnhealthy:       tc.maxUnhealthy,
+					MinHealthy:         tc.minHealthy,
+					NodeStartupTimeout: &metav1.Duration{Duration: 1 * time.Millisecond},
+				},
+				Status: clusterv1.MachineHealthCheckStatus{
+					ExpectedMachines:   tc.expectedMachines,
+					CurrentHealthy:     tc.currentHealthy,
+					ObservedGeneration: tc.observedGeneration,
+				},
+			}
+
+			remediationAllowed, _, _ := isAllowedRemediation(mhc)
+			g.Expect(remediationAllowed).To(Equal(tc.allowed))
+		})
+	}
+}
+
+func TestIsAllowedRemediationMachineDeploymentScope(t *testing.T) {
+	g := NewWithT(t)
+
+	namespace := "test-mhc"
+	clusterName := "test-cluster"
+
+	// A MachineDeployment is being rolled out: the MachineHealthCheck only selects the machines of its new
+	// MachineSet, while those of the old one are checked by another MachineHealthCheck.
+	newMachineSet := map[string]string{clusterv1.MachineDeploymentLabelName: "md", clusterv1.MachineSetLabelName: "md-new"}
+	oldMachineSet := map[string]string{clusterv1.MachineDeploymentLabelName: "md", clusterv1.MachineSetLabelName: "md-old"}
+	mhc := newMachineHealthCheckWithLabels("test-mhc", namespace, clusterName, map[string]string{clusterv1.MachineSetLabelName: "md-new"})
+	mhc.Spec.MaxUnhealthy = &intstr.IntOrString{Type: intstr.Int, IntVal: 2}
+
+	var targets []healthCheckTarget
+	for _, name := range []string{"new1", "new2", "new3"} {
+		targets = append(targets, healthCheckTarget{Machine: newTestMachine(name, namespace, clusterName, name, newMachineSet)})
+	}
+	old1 := newTestMachine("old1", namespace, clusterName, "old1", oldMachineSet)
+	old2 := newTestMachine("old2", namespace, clusterName, "old2", oldMachineSet)
+	conditions.MarkFalse(old2, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "")
+	old3 := newTestMachine("old3", namespace, clusterName, "old3", oldMachineSet)
+	conditions.MarkFalse(old3, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "")
+	// Machines being scaled down, or of other MachineDeployments, are not counted.
+	old4 := newTestMachine("old4", namespace, clusterName, "old4", oldMachineSet)
+	old4.Finalizers = []string{clusterv1.MachineFinalizer}
+	old4.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	other := newTestMachine("other", namespace, clusterName, "other", map[string]string{clusterv1.MachineDeploymentLabelName: "other-md"})
+
+	objs := []client.Object{old1, old2, old3, old4, other}
+	for _, t := range targets {
+		objs = append(objs, t.Machine)
+	}
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	r := &MachineHealthCheckReconciler{
+		Client: fake.NewClientBuilder().WithObjects(objs...).Build(),
+	}
+
+	// One of the three machines of the new MachineSet is unhealthy, which the budget of the MachineHealthCheck
+	// alone allows remediating.
+	mhc.Status.ExpectedMachines = 3
+	mhc.Status.CurrentHealthy = 2
+	allowed, remediationCount, err := isAllowedRemediation(mhc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(allowed).To(BeTrue())
+	g.Expect(remediationCount).To(Equal(int32(1)))
+
+	// Combined with the two unhealthy machines of the old MachineSet, the deployment exceeds MaxUnhealthy.
+	siblings, err := r.getMachineDeploymentSiblings(ctx, mhc, targets)
+	g.Expect(err).NotTo(HaveOccurred())
+	siblingNames := []string{}
+	for _, machine := range siblings {
+		siblingNames = append(siblingNames, machine.Name)
+	}
+	g.Expect(siblingNames).To(ConsistOf("old1", "old2", "old3", "old4"))
+
+	expected, healthy := countMachineDeploymentSiblings(mhc, siblings)
+	g.Expect(expected).To(Equal(3))
+	g.Expect(healthy).To(Equal(1))
+
+	mhc.Status.ExpectedMachines += int32(expected)
+	mhc.Status.CurrentHealthy += int32(healthy)
+	allowed, remediationCount, err = isAllowedRemediation(mhc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(allowed).To(BeFalse())
+	g.Expect(remediationCount).To(Equal(int32(-1)))
+}
+
+func TestCountExpectedTargets(t *testing.T) {
+	withNode := func(name string) healthCheckTarget {
+		return healthCheckTarget{Machine: &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: name}},
+		}}
+	}
+	targets := []healthCheckTarget{
+		withNode("healthy-1"),
+		withNode("healthy-2"),
+		withNode("unhealthy"),
+		{Machine: &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "never-ready"}}},
+	}
+	healthy := targets[:2]
+
+	testCases := []struct {
+		name             string
+		policy           clusterv1.ExpectedMachinesPolicy
+		expectedMachines int32
+		allowed          bool
+	}{
+		{
+			name:             "when expectedMachinesPolicy is not set",
+			policy:           "",
+			expectedMachines: 4,
+			allowed:          false,
+		},
+		{
+			name:             "when expectedMachinesPolicy is All",
+			policy:           clusterv1.ExpectedMachinesPolicyAll,
+			expectedMachines: 4,
+			allowed:          false,
+		},
+		{
+			name:             "when expectedMachinesPolicy is ReadyOnly",
+			policy:           clusterv1.ExpectedMachinesPolicyReadyOnly,
+			expectedMachines: 3,
+			allowed:          true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			maxUnhealthy := intstr.FromString("40%")
+			mhc := &clusterv1.MachineHealthCheck{
+				Spec: clusterv1.MachineHealthCheckSpec{
+					MaxUnhealthy:           &maxUnhealthy,
+					ExpectedMachinesPolicy: tc.policy,
+				},
+			}
+			mhc.Status.ExpectedMachines = int32(countExpectedTargets(mhc, targets))
+			mhc.Status.CurrentHealthy = int32(countExpectedTargets(mhc, healthy))
+
+			g.Expect(mhc.Status.ExpectedMachines).To(Equal(tc.expectedMachines))
+			g.Expect(mhc.Status.CurrentHealthy).To(Equal(int32(2)))
+
+			remediationAllowed, _, _ := isAllowedRemediation(mhc)
+			g.Expect(remediationAllowed).To(Equal(tc.allowed))
+		})
+	}
+}
+
+func TestSuspectTargets(t *testing.T) {
+	g := NewWithT(t)
+
+	namespace := "test-mhc"
+	clusterName := "test-cluster"
+	timeoutForMachineToHaveNode := 10 * time.Minute
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      clusterName,
+		},
+	}
+	conditions.MarkTrue(cluster, clusterv1.InfrastructureReadyCondition)
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+
+	mhc := newMachineHealthCheck(namespace, clusterName)
+	newTarget := func(name string, node *corev1.Node) healthCheckTarget {
+		return healthCheckTarget{
+			Cluster: cluster,
+			MHC:     mhc,
+			Machine: newTestMachine(name, namespace, clusterName, name, nil),
+			Node:    node,
+		}
+	}
+	healthy := newTarget("healthy", newTestNode("healthy"))
+	suspect := newTarget("suspect", newTestUnhealthyNode("suspect", corev1.NodeReady, corev1.ConditionUnknown, time.Minute))
+	unhealthy := newTarget("unhealthy", newTestUnhealthyNode("unhealthy", corev1.NodeReady, corev1.ConditionUnknown, 10*time.Minute))
+	targets := []healthCheckTarget{healthy, suspect, unhealthy}
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	r := &MachineHealthCheckReconciler{
+		Client:   fake.NewClientBuilder().WithObjects(healthy.Machine, suspect.Machine, unhealthy.Machine).Build(),
+		recorder: record.NewFakeRecorder(5),
+	}
+	healthyTargets, unhealthyTargets, _ := r.healthCheckTargets(ctx, targets, log.Log, timeoutForMachineToHaveNode)
+
+	// The machine whose node went unhealthy for less than the timeout is neither healthy nor unhealthy yet.
+	g.Expect(healthyTargets).To(ConsistOf(healthy))
+	g.Expect(unhealthyTargets).To(ConsistOf(unhealthy))
+	g.Expect(suspectTargets(targets, healthyTargets, unhealthyTargets, r.now())).To(ConsistOf(suspect))
+
+	// Both the health check and the suspect targets are evaluated with the clock of the reconciler: once the timeout
+	// expires by it, the suspect machine is unhealthy.
+	r.clock = clock.NewFakeClock(time.Now().Add(10 * time.Minute))
+	healthyTargets, unhealthyTargets, _ = r.healthCheckTargets(ctx, targets, log.Log, timeoutForMachineToHaveNode)
+	g.Expect(unhealthyTargets).To(ConsistOf(suspect, unhealthy))
+	g.Expect(suspectTargets(targets, healthyTargets, unhealthyTargets, r.now())).To(BeEmpty())
+}
+
+func TestGetMaxUnhealthy(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		maxUnhealthy         *intstr.IntOrString
+		expectedMaxUnhealthy int
+		actualMachineCount   int32
+		expectedErr          error
+	}{
+		{
+			name:                 "when maxUnhealthy is nil",
+			maxUnhealthy:         nil,
+			expectedMaxUnhealthy: 0,
+			actualMachineCount:   7,
+			expectedErr:          errors.New("spec.maxUnhealthy must be set"),
+		},
+		{
+			name:                 "when maxUnhealthy is not an int or percentage",
+			maxUnhealthy:         &intstr.IntOrString{Type: intstr.String, StrVal: "abcdef"},
+			expectedMaxUnhealthy: 0,
+			actualMachineCount:   3,
+			expectedErr:          errors.New("invalid value for IntOrString: invalid value \"abcdef\": strconv.Atoi: parsing \"abcdef\": invalid syntax"),
+		},
+		{
+			name:                 "when maxUnhealthy is an int",
+			maxUnhealthy:         &intstr.IntOrString{Type: intstr.Int, IntVal: 3},
+			actualMachineCount:   2,
+			expectedMaxUnhealthy: 3,
+			expectedErr:          nil,
+		},
+		{
+			name:                 "when maxUnhealthy is a 40% (of 5)",
+			maxUnhealthy:         &intstr.IntOrString{Type: intstr.String, StrVal: "40%"},
+			actualMachineCount:   5,
+			expectedMaxUnhealthy: 2,
+			expectedErr:          nil,
+		},
+		{
+			name:                 "when maxUnhealthy is a 60% (of 7)",
+			maxUnhealthy:         &intstr.IntOrString{Type: intstr.String, StrVal: "60%"},
+			actualMachineCount:   7,
+			expectedMaxUnhealthy: 4,
+			expectedErr:          nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			mhc := &clusterv1.MachineHealthCheck{
+				Spec: clusterv1.MachineHealthCheckSpec{
+					MaxUnhealthy: tc.maxUnhealthy,
+				},
+				Status: clusterv1.MachineHealthCheckStatus{
+					ExpectedMachines: tc.actualMachineCount,
+				},
+			}
+
+			maxUnhealthy, err := getMaxUnhealthy(mhc)
+			if tc.expectedErr != nil {
+				g.Expect(err).To(MatchError(tc.expectedErr.Error()))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+			g.Expect(maxUnhealthy).To(Equal(tc.expectedMaxUnhealthy))
+		})
+	}
+}
+
+func ownerReferenceForCluster(ctx context.Context, g *WithT, c *clusterv1.Cluster) metav1.OwnerReference {
+	// Fetch the cluster to populate the UID
+	cc := &clusterv1.Cluster{}
+	g.Expect(testEnv.GetClient().Get(ctx, util.ObjectKey(c), cc)).To(Succeed())
+
+	return metav1.OwnerReference{
+		APIVersion: clusterv1.GroupVersion.String(),
+		Kind:       "Cluster",
+		Name:       cc.Name,
+		UID:        cc.UID,
+	}
+}
+
+// createNamespaceAndCluster creates a namespace in the test environment. It
+// then creates a Cluster and KubeconfigSecret for that cluster in said
+// namespace.
+func createNamespaceAndCluster(g *WithT) *clusterv1.Cluster {
+	ns, err := testEnv.CreateNamespace(ctx, "test-mhc")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "test-cluster-",
+			Namespace:    ns.Name,
+		},
+	}
+
+	g.Expect(testEnv.Create(ctx, cluster)).To(Succeed())
+
+	// Make sure the cluster is in the cache before proceeding
+	g.Eventually(func() error {
+		var cl clusterv1.Cluster
+		return testEnv.Get(ctx, util.ObjectKey(cluster), &cl)
+	}, timeout, 100*time.Millisecond).Should(Succeed())
+
+	// This is required for MHC to perform checks
+	patchHelper, err := patch.NewHelper(cluster, testEnv.Client)
+	g.Expect(err).To(BeNil())
+	conditions.MarkTrue(cluster, clusterv1.InfrastructureReadyCondition)
+	g.Expect(patchHelper.Patch(ctx, cluster)).To(Succeed())
+
+	// Wait for cluster in cache to be updated post-patch
+	g.Eventually(func() bool {
+		err := testEnv.Get(ctx, util.ObjectKey(cluster), cluster)
+		if err != nil {
+			return false
+		}
+
+		return conditions.IsTrue(cluster, clusterv1.InfrastructureReadyCondition)
+	}, timeout, 100*time.Millisecond).Should(BeTrue())
+
+	g.Expect(testEnv.CreateKubeconfigSecret(ctx, cluster)).To(Succeed())
+
+	return cluster
+}
+
+// newRunningMachine creates a Machine object with a Status.Phase == Running.
+func newRunningMachine(c *clusterv1.Cluster, labels map[string]string) *clusterv1.Machine {
+	return &clusterv1.Machine{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Machine",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "test-mhc-machine-",
+			Namespace:    c.Namespace,
+			Labels:       labels,
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: c.Name,
+			Bootstrap: clusterv1.Bootstrap{
+				DataSecretName: pointer.StringPtr("data-secret-name"),
+			},
+		},
+		Status: clusterv1.MachineStatus{
+			InfrastructureReady: true,
+			BootstrapReady:      true,
+			Phase:               string(clusterv1.MachinePhaseRunning),
+			ObservedGeneration:  1,
+		},
+	}
+}
+
+func newInfraMachine(machine *clusterv1.Machine) (*unstructured.Unstructured, string) {
+	providerID := fmt.Sprintf("test:////%v", uuid.NewUUID())
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+			"kind":       "InfrastructureMachine",
+			"metadata": map[string]interface{}{
+				"generateName": "test-mhc-machine-infra-",
+				"namespace":    machine.Namespace,
+			},
+			"spec": map[string]interface{}{
+				"providerID": providerID,
+			},
+		},
+	}, providerID
+}
+
+type machinesWithNodes struct {
+	count                      int
+	nodeStatus                 corev1.ConditionStatus
+	createNodeRefForMachine    bool
+	firstMachineAsControlPlane bool
+	labels                     map[string]string
+	failureReason              string
+	failureMessage             string
+	freshNodeCondition         bool
+}
+
+type machineWithNodesOption func(m *machinesWithNodes)
+
+func count(n int) machineWithNodesOption {
+	return func(m *machinesWithNodes) {
+		m.count = n
+	}
+}
+
+func firstMachineAsControlPlane() machineWithNodesOption {
+	return func(m *machinesWithNodes) {
+		m.firstMachineAsControlPlane = true
+	}
+}
+
+func nodeStatus(s corev1.ConditionStatus) machineWithNodesOption {
+	return func(m *machinesWithNodes) {
+		m.nodeStatus = s
+	}
+}
+
+// freshNodeCondition makes the Ready condition of the nodes transition now, instead of ten minutes ago.
+func freshNodeCondition() machineWithNodesOption {
+	return func(m *machinesWithNodes) {
+		m.freshNodeCondition = true
+	}
+}
+
+func createNodeRefForMachine(b bool) machineWithNodesOption {
+	return func(m *machinesWithNodes) {
+		m.createNodeRefForMachine = b
+	}
+}
+
+func machineLabels(l map[string]string) machineWithNodesOption {
+	return func(m *machinesWithNodes) {
+		m.labels = l
+	}
+}
+
+func machineFailureReason(s string) machineWithNodesOption {
+	return func(m *machinesWithNodes) {
+		m.failureReason = s
+	}
+}
+
+func machineFailureMessage(s string) machineWithNodesOption {
+	return func(m *machinesWithNodes) {
+		m.failureMessage = s
+	}
+}
+
+func createMachinesWithNodes(
+	g *WithT,
+	c *clusterv1.Cluster,
+	opts ...machineWithNodesOption,
+) ([]*corev1.Node, []*clusterv1.Machine, func()) {
+	o := &machinesWithNodes{}
+	for _, op := range opts {
 		op(o)
 	}
 
-	var (
-		nodes         []*corev1.Node
-		machines      []*clusterv1.Machine
-		infraMachines []*unstructured.Unstructured
-	)
+	var (
+		nodes         []*corev1.Node
+		machines      []*clusterv1.Machine
+		infraMachines []*unstructured.Unstructured
+	)
+
+	for i := 0; i < o.count; i++ {
+		machine := newRunningMachine(c, o.labels)
+		if i == 0 && o.firstMachineAsControlPlane {
+			if machine.Labels == nil {
+				machine.Labels = make(map[string]string)
+			}
+			machine.Labels[clusterv1.MachineControlPlaneLabelName] = ""
+		}
+		infraMachine, providerID := newInfraMachine(machine)
+		g.Expect(testEnv.Create(ctx, infraMachine)).To(Succeed())
+		infraMachines = append(infraMachines, infraMachine)
+		fmt.Printf("inframachine created: %s\n", infraMachine.GetName())
+		// Patch the status of the InfraMachine and mark it as ready.
+		// NB. Status cannot be set during object creation so we need to patch
+		// it separately.
+		infraMachinePatch := client.MergeFrom(infraMachine.DeepCopy())
+		g.Expect(unstructured.SetNestedField(infraMachine.Object, true, "status", "ready")).To(Succeed())
+		g.Expect(testEnv.Status().Patch(ctx, infraMachine, infraMachinePatch)).To(Succeed())
+
+		machine.Spec.InfrastructureRef = corev1.ObjectReference{
+			APIVersion: infraMachine.GetAPIVersion(),
+			Kind:       infraMachine.GetKind(),
+			Name:       infraMachine.GetName(),
+		}
+		g.Expect(testEnv.Create(ctx, machine)).To(Succeed())
+		fmt.Printf("machine created: %s\n", machine.GetName())
+
+		// Before moving on we want to ensure that the machine has a valid
+		// status. That is, LastUpdated should not be nil.
+		g.Eventually(func() *metav1.Time {
+			k := client.ObjectKey{
+				Name:      machine.GetName(),
+				Namespace: machine.GetNamespace(),
+			}
+			err := testEnv.Get(ctx, k, machine)
+			if err != nil {
+				return nil
+			}
+			return machine.Status.LastUpdated
+		}, timeout, 100*time.Millisecond).ShouldNot(BeNil())
+
+		machinePatchHelper, err := patch.NewHelper(machine, testEnv.Client)
+		g.Expect(err).To(BeNil())
+
+		if o.createNodeRefForMachine {
+			// Create node
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: "test-mhc-node-",
+				},
+				Spec: corev1.NodeSpec{
+					ProviderID: providerID,
+				},
+			}
+
+			g.Expect(testEnv.Create(ctx, node)).To(Succeed())
+			fmt.Printf("node created: %s\n", node.GetName())
+
+			// Patch node status
+			nodePatchHelper, err := patch.NewHelper(node, testEnv.Client)
+			g.Expect(err).To(BeNil())
+
+			transitionTime := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+			if o.freshNodeCondition {
+				transitionTime = metav1.Now()
+			}
+			node.Status.Conditions = []corev1.NodeCondition{
+				{
+					Type:               corev1.NodeReady,
+					Status:             o.nodeStatus,
+					LastTransitionTime: transitionTime,
+				},
+			}
+
+			g.Expect(nodePatchHelper.Patch(ctx, node)).To(Succeed())
+
+			nodes = append(nodes, node)
+
+			machine.Status.NodeRef = &corev1.ObjectReference{
+				Name: node.Name,
+			}
+		}
+
+		if o.failureReason != "" {
+			failureReason := capierrors.MachineStatusError(o.failureReason)
+			machine.Status.FailureReason = &failureReason
+		}
+		if o.failureMessage != "" {
+			machine.Status.FailureMessage = pointer.StringPtr(o.failureMessage)
+		}
+
+		// Adding one second to ensure there is a difference from the
+		// original time so that the patch works. That is, ensure the
+		// precision isn't lost during conversions.
+		lastUp := metav1.NewTime(machine.Status.LastUpdated.Add(time.Second))
+		machine.Status.LastUpdated = &lastUp
+
+		// Patch the machine to record the status changes
+		g.Expect(machinePatchHelper.Patch(ctx, machine)).To(Succeed())
+
+		machines = append(machines, machine)
+	}
+
+	cleanup := func() {
+		fmt.Println("Cleaning up nodes, machines and infra machines.")
+		for _, n := range nodes {
+			if err := testEnv.Delete(ctx, n); !apierrors.IsNotFound(err) {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		}
+		for _, m := range machines {
+			g.Expect(testEnv.Delete(ctx, m)).To(Succeed())
+		}
+		for _, im := range infraMachines {
+			if err := testEnv.Delete(ctx, im); !apierrors.IsNotFound(err) {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		}
+	}
+
+	return nodes, machines, cleanup
+}
+
+func newMachineHealthCheckWithLabels(name, namespace, cluster string, labels map[string]string) *clusterv1.MachineHealthCheck {
+	l := make(map[string]string, len(labels))
+	for k, v := range labels {
+		l[k] = v
+	}
+	l[clusterv1.ClusterLabelName] = cluster
+
+	mhc := newMachineHealthCheck(namespace, cluster)
+	mhc.SetName(name)
+	mhc.Labels = l
+	mhc.Spec.Selector.MatchLabels = l
+
+	return mhc
+}
+
+func newMachineHealthCheck(namespace, clusterName string) *clusterv1.MachineHealthCheck {
+	maxUnhealthy := intstr.FromString("100%")
+	return &clusterv1.MachineHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "test-mhc-",
+			Namespace:    namespace,
+		},
+		Spec: clusterv1.MachineHealthCheckSpec{
+			ClusterName: clusterName,
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"selector": string(uuid.NewUUID()),
+				},
+			},
+			MaxUnhealthy:       &maxUnhealthy,
+			NodeStartupTimeout: &metav1.Duration{Duration: 1 * time.Millisecond},
+			UnhealthyConditions: []clusterv1.UnhealthyCondition{
+				{
+					Type:    corev1.NodeReady,
+					Status:  corev1.ConditionUnknown,
+					Timeout: metav1.Duration{Duration: 5 * time.Minute},
+				},
+			},
+		},
+	}
+}
+
+// newFakeMHCReconciler returns a MachineHealthCheckReconciler whose fake client, holding the given objects, backs both
+// the management cluster and the workload cluster with the given key.
+func newFakeMHCReconciler(cluster client.ObjectKey, objs ...client.Object) *MachineHealthCheckReconciler {
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+	return &MachineHealthCheckReconciler{
+		Client:   fakeClient,
+		recorder: record.NewFakeRecorder(32),
+		Tracker:  remote.NewTestClusterCacheTracker(log.NullLogger{}, fakeClient, scheme.Scheme, cluster, "machinehealthcheck-watchClusterNodes"),
+	}
+}
+
+func TestPatchTargets(t *testing.T) {
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+	g := NewWithT(t)
+
+	namespace := defaultNamespaceName
+	clusterName := "test-cluster"
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+	}
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+
+	mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
+	machine1 := newTestMachine("machine1", namespace, clusterName, "nodeName", labels)
+	machine1.ResourceVersion = "1"
+	conditions.MarkTrue(machine1, clusterv1.MachineHealthCheckSuccededCondition)
+	machine2 := machine1.DeepCopy()
+	machine2.Name = "machine2"
+
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		machine1,
+		machine2,
+		mhc,
+	).Build()
+	recorder := record.NewFakeRecorder(32)
+	r := &MachineHealthCheckReconciler{
+		Client:   cl,
+		recorder: recorder,
+		Tracker:  remote.NewTestClusterCacheTracker(log.NullLogger{}, cl, scheme.Scheme, client.ObjectKey{Name: clusterName, Namespace: namespace}, "machinehealthcheck-watchClusterNodes"),
+	}
+
+	// To make the patch fail, create patchHelper with a different client.
+	fakeMachine := machine1.DeepCopy()
+	fakeMachine.Name = "fake"
+	patchHelper, _ := patch.NewHelper(fakeMachine, fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(fakeMachine).Build())
+	// healthCheckTarget with fake patchHelper, patch should fail on this target.
+	target1 := healthCheckTarget{
+		MHC:         mhc,
+		Machine:     machine1,
+		patchHelper: patchHelper,
+		Node:        &corev1.Node{},
+	}
+
+	// healthCheckTarget with correct patchHelper.
+	patchHelper2, _ := patch.NewHelper(machine2, cl)
+	target3 := healthCheckTarget{
+		MHC:         mhc,
+		Machine:     machine2,
+		patchHelper: patchHelper2,
+		Node:        &corev1.Node{},
+	}
+
+	// Target with wrong patch helper will fail but the other one will be patched.
+	g.Expect(len(r.PatchUnhealthyTargets(context.TODO(), log.NullLogger{}, []healthCheckTarget{target1, target3}, defaultCluster, mhc))).To(BeNumerically(">", 0))
+	g.Expect(cl.Get(ctx, client.ObjectKey{Name: machine2.Name, Namespace: machine2.Namespace}, machine2)).NotTo(HaveOccurred())
+	g.Expect(conditions.Get(machine2, clusterv1.MachineOwnerRemediatedCondition).Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(recorder.Events).To(Receive(HavePrefix(corev1.EventTypeNormal + " " + EventReasonRemediationTriggered + " ")))
+
+	// Target with wrong patch helper will fail but the other one will be patched.
+	g.Expect(len(r.PatchHealthyTargets(context.TODO(), log.NullLogger{}, []healthCheckTarget{target1, target3}, defaultCluster, mhc))).To(BeNumerically(">", 0))
+}
+
+func TestPatchUnhealthyTargetsNodeEvent(t *testing.T) {
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	namespace := defaultNamespaceName
+	clusterName := "test-cluster"
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+	}
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+	mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
+
+	setup := func(g *WithT, trackerKey client.ObjectKey) (*MachineHealthCheckReconciler, client.Client, healthCheckTarget) {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", UID: "node1-uid"}}
+		machine := newTestMachine("machine1", namespace, clusterName, node.Name, labels)
+		conditions.MarkFalse(machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "")
+
+		cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(machine, node, mhc).Build()
+		r := &MachineHealthCheckReconciler{
+			Client:   cl,
+			recorder: record.NewFakeRecorder(32),
+			Tracker:  remote.NewTestClusterCacheTracker(log.NullLogger{}, cl, scheme.Scheme, trackerKey, "machinehealthcheck-watchClusterNodes"),
+		}
+
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+		patchHelper, err := patch.NewHelper(machine, cl)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		return r, cl, healthCheckTarget{
+			MHC:         mhc,
+			Machine:     machine,
+			Node:        node,
+			patchHelper: patchHelper,
+		}
+	}
+
+	t.Run("records an event on the remote node", func(t *testing.T) {
+		g := NewWithT(t)
+		r, cl, target := setup(g, client.ObjectKey{Name: clusterName, Namespace: namespace})
+
+		g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
+
+		events := &corev1.EventList{}
+		g.Expect(cl.List(ctx, events, client.InNamespace(metav1.NamespaceDefault))).To(Succeed())
+		g.Expect(events.Items).To(HaveLen(1))
+		g.Expect(events.Items[0].InvolvedObject.Kind).To(Equal("Node"))
+		g.Expect(events.Items[0].InvolvedObject.Name).To(Equal("node1"))
+		g.Expect(events.Items[0].InvolvedObject.UID).To(BeEquivalentTo("node1-uid"))
+		g.Expect(events.Items[0].Reason).To(Equal(EventReasonRemediationTriggered))
+		g.Expect(events.Items[0].Source.Component).To(Equal(machineHealthCheckEventSource))
+	})
+
+	t.Run("records the event only when the machine is marked for remediation", func(t *testing.T) {
+		g := NewWithT(t)
+		r, cl, target := setup(g, client.ObjectKey{Name: clusterName, Namespace: namespace})
+
+		g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
+
+		patchHelper, err := patch.NewHelper(target.Machine, cl)
+		g.Expect(err).NotTo(HaveOccurred())
+		target.patchHelper = patchHelper
+		g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
+
+		events := &corev1.EventList{}
+		g.Expect(cl.List(ctx, events, client.InNamespace(metav1.NamespaceDefault))).To(Succeed())
+		g.Expect(events.Items).To(HaveLen(1))
+	})
+
+	t.Run("does not fail when the remote cluster cannot be reached", func(t *testing.T) {
+		g := NewWithT(t)
+		r, cl, target := setup(g, client.ObjectKey{Name: "another-cluster", Namespace: namespace})
+
+		g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
+
+		machine := &clusterv1.Machine{}
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(target.Machine), machine)).To(Succeed())
+		g.Expect(conditions.Get(machine, clusterv1.MachineOwnerRemediatedCondition).Status).To(Equal(corev1.ConditionFalse))
+		events := &corev1.EventList{}
+		g.Expect(cl.List(ctx, events, client.InNamespace(metav1.NamespaceDefault))).To(Succeed())
+		g.Expect(events.Items).To(BeEmpty())
+	})
+}
+
+func TestPatchUnhealthyTargetsRemediationReason(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	namespace := defaultNamespaceName
+	clusterName := "test-cluster"
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+	}
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+	mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	machine := newTestMachine("machine1", namespace, clusterName, node.Name, labels)
+	conditions.MarkFalse(machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "Condition Ready on node is reporting status Unknown for more than 5m0s")
+
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(machine, node, mhc).Build()
+	r := &MachineHealthCheckReconciler{
+		Client:   cl,
+		recorder: record.NewFakeRecorder(32),
+		Tracker:  remote.NewTestClusterCacheTracker(log.NullLogger{}, cl, scheme.Scheme, client.ObjectKey{Name: clusterName, Namespace: namespace}, "machinehealthcheck-watchClusterNodes"),
+	}
+
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+	patchHelper, err := patch.NewHelper(machine, cl)
+	g.Expect(err).NotTo(HaveOccurred())
+	target := healthCheckTarget{
+		MHC:         mhc,
+		Machine:     machine,
+		Node:        node,
+		patchHelper: patchHelper,
+	}
+
+	g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
+
+	got := &clusterv1.Machine{}
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), got)).To(Succeed())
+	g.Expect(conditions.IsFalse(got, clusterv1.MachineOwnerRemediatedCondition)).To(BeTrue())
+	g.Expect(got.Annotations).To(HaveKeyWithValue(clusterv1.MachineRemediationReasonAnnotation, "UnhealthyNode: Condition Ready on node is reporting status Unknown for more than 5m0s"))
+}
+
+func TestPatchUnhealthyTargetsRemediationWebhook(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	notifications := make(chan RemediationNotification, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		g.Expect(req.Method).To(Equal(http.MethodPost))
+		g.Expect(req.Header.Get("Content-Type")).To(Equal("application/json"))
+		notification := RemediationNotification{}
+		g.Expect(json.NewDecoder(req.Body).Decode(&notification)).To(Succeed())
+		notifications <- notification
+	}))
+	defer server.Close()
+
+	namespace := defaultNamespaceName
+	clusterName := "test-cluster"
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+	}
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+	mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	machine := newTestMachine("machine1", namespace, clusterName, node.Name, labels)
+	conditions.MarkFalse(machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "Condition Ready on node is reporting status Unknown for more than 5m0s")
+
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(machine, node, mhc).Build()
+	r := &MachineHealthCheckReconciler{
+		Client:                cl,
+		recorder:              record.NewFakeRecorder(32),
+		Tracker:               remote.NewTestClusterCacheTracker(log.NullLogger{}, cl, scheme.Scheme, client.ObjectKey{Name: clusterName, Namespace: namespace}, "machinehealthcheck-watchClusterNodes"),
+		RemediationWebhookURL: server.URL,
+		clock:                 clock.NewFakeClock(time.Date(2021, 3, 1, 2, 0, 0, 0, time.UTC)),
+	}
+
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+	patchHelper, err := patch.NewHelper(machine, cl)
+	g.Expect(err).NotTo(HaveOccurred())
+	target := healthCheckTarget{
+		MHC:         mhc,
+		Machine:     machine,
+		Node:        node,
+		patchHelper: patchHelper,
+	}
+
+	g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
+
+	var notification RemediationNotification
+	g.Eventually(notifications, 5*time.Second).Should(Receive(&notification))
+	g.Expect(notification.Cluster).To(Equal(clusterName))
+	g.Expect(notification.Namespace).To(Equal(namespace))
+	g.Expect(notification.Machine).To(Equal(machine.Name))
+	g.Expect(notification.Node).To(Equal(node.Name))
+	g.Expect(notification.Reason).To(Equal("UnhealthyNode: Condition Ready on node is reporting status Unknown for more than 5m0s"))
+	g.Expect(notification.Timestamp.Time.Equal(r.now())).To(BeTrue())
+
+	// The machine is already marked for remediation, so it is not notified again.
+	patchHelper, err = patch.NewHelper(machine, cl)
+	g.Expect(err).NotTo(HaveOccurred())
+	target.patchHelper = patchHelper
+	g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
+	g.Consistently(notifications, 2*time.Second).ShouldNot(Receive())
+}
+
+func TestMaintenanceWindowRemaining(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		schedule *clusterv1.Schedule
+		now      time.Time
+		expected time.Duration
+	}{
+		{
+			name:     "without schedule",
+			schedule: nil,
+			now:      time.Date(2021, 3, 1, 2, 0, 0, 0, time.UTC),
+			expected: 0,
+		},
+		{
+			name:     "before the window",
+			schedule: &clusterv1.Schedule{Windows: []clusterv1.TimeWindow{{Start: "01:00", End: "03:00"}}},
+			now:      time.Date(2021, 3, 1, 0, 59, 0, 0, time.UTC),
+			expected: 0,
+		},
+		{
+			name:     "during the window",
+			schedule: &clusterv1.Schedule{Windows: []clusterv1.TimeWindow{{Start: "01:00", End: "03:00"}}},
+			now:      time.Date(2021, 3, 1, 2, 30, 0, 0, time.UTC),
+			expected: 30 * time.Minute,
+		},
+		{
+			name:     "when the window closes",
+			schedule: &clusterv1.Schedule{Windows: []clusterv1.TimeWindow{{Start: "01:00", End: "03:00"}}},
+			now:      time.Date(2021, 3, 1, 3, 0, 0, 0, time.UTC),
+			expected: 0,
+		},
+		{
+			name:     "before midnight during a window spanning midnight",
+			schedule: &clusterv1.Schedule{Windows: []clusterv1.TimeWindow{{Start: "23:00", End: "01:00"}}},
+			now:      time.Date(2021, 3, 1, 23, 30, 0, 0, time.UTC),
+			expected: 90 * time.Minute,
+		},
+		{
+			name:     "after midnight during a window spanning midnight",
+			schedule: &clusterv1.Schedule{Windows: []clusterv1.TimeWindow{{Start: "23:00", End: "01:00"}}},
+			now:      time.Date(2021, 3, 2, 0, 30, 0, 0, time.UTC),
+			expected: 30 * time.Minute,
+		},
+		{
+			name: "during a window in another time zone",
+			schedule: &clusterv1.Schedule{
+				TimeZone: "Europe/Berlin",
+				Windows:  []clusterv1.TimeWindow{{Start: "01:00", End: "03:00"}},
+			},
+			now:      time.Date(2021, 3, 1, 1, 0, 0, 0, berlin).UTC(),
+			expected: 2 * time.Hour,
+		},
+		{
+			name: "during overlapping windows",
+			schedule: &clusterv1.Schedule{Windows: []clusterv1.TimeWindow{
+				{Start: "01:00", End: "03:00"},
+				{Start: "02:00", End: "04:00"},
+			}},
+			now:      time.Date(2021, 3, 1, 2, 30, 0, 0, time.UTC),
+			expected: 90 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			remaining, err := maintenanceWindowRemaining(tt.schedule, tt.now)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(remaining).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestPatchUnhealthyTargetsRemediationSchedule(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	namespace := defaultNamespaceName
+	clusterName := "test-cluster"
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+	}
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+	mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
+	mhc.Spec.RemediationSchedule = &clusterv1.Schedule{
+		Windows: []clusterv1.TimeWindow{{Start: "01:00", End: "03:00"}},
+	}
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	machine := newTestMachine("machine1", namespace, clusterName, node.Name, labels)
+	conditions.MarkFalse(machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "Condition Ready on node is reporting status Unknown for more than 5m0s")
+
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(machine, node, mhc).Build()
+	fakeClock := clock.NewFakeClock(time.Date(2021, 3, 1, 2, 0, 0, 0, time.UTC))
+	r := &MachineHealthCheckReconciler{
+		Client:   cl,
+		recorder: record.NewFakeRecorder(32),
+		Tracker:  remote.NewTestClusterCacheTracker(log.NullLogger{}, cl, scheme.Scheme, client.ObjectKey{Name: clusterName, Namespace: namespace}, "machinehealthcheck-watchClusterNodes"),
+		clock:    fakeClock,
+	}
+
+	patchUnhealthyTarget := func() *clusterv1.Machine {
+		m := &clusterv1.Machine{}
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), m)).To(Succeed())
+		patchHelper, err := patch.NewHelper(m, cl)
+		g.Expect(err).NotTo(HaveOccurred())
+		target := healthCheckTarget{
+			MHC:         mhc,
+			Machine:     m,
+			Node:        node,
+			patchHelper: patchHelper,
+		}
+		g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), m)).To(Succeed())
+		return m
+	}
+
+	// The machine is still reported unhealthy during the maintenance window, but not remediated.
+	m := patchUnhealthyTarget()
+	g.Expect(conditions.IsFalse(m, clusterv1.MachineHealthCheckSuccededCondition)).To(BeTrue())
+	g.Expect(conditions.Has(m, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+
+	// The machine is remediated once the maintenance window closes.
+	fakeClock.SetTime(time.Date(2021, 3, 1, 3, 0, 0, 0, time.UTC))
+	m = patchUnhealthyTarget()
+	g.Expect(conditions.IsFalse(m, clusterv1.MachineOwnerRemediatedCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(m, clusterv1.MachineOwnerRemediatedCondition)).To(Equal(clusterv1.WaitingForRemediationReason))
+}
+
+func TestPatchUnhealthyTargetsRemediationStagger(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	namespace := defaultNamespaceName
+	clusterName := "test-cluster"
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+	}
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+	mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
+	mhc.Spec.RemediationStagger = &metav1.Duration{Duration: 5 * time.Minute}
+
+	// MaxUnhealthy allows remediating all three machines at once.
+	machines := []*clusterv1.Machine{
+		newTestMachine("machine1", namespace, clusterName, "node1", labels),
+		newTestMachine("machine2", namespace, clusterName, "node2", labels),
+		newTestMachine("machine3", namespace, clusterName, "node3", labels),
+	}
+	objs := []client.Object{mhc}
+	for _, machine := range machines {
+		conditions.MarkFalse(machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "")
+		objs = append(objs, machine, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: machine.Status.NodeRef.Name}})
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+	start := time.Date(2021, 3, 1, 2, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(start)
+	r := &MachineHealthCheckReconciler{
+		Client:   cl,
+		recorder: record.NewFakeRecorder(32),
+		Tracker:  remote.NewTestClusterCacheTracker(log.NullLogger{}, cl, scheme.Scheme, client.ObjectKey{Name: clusterName, Namespace: namespace}, "machinehealthcheck-watchClusterNodes"),
+		clock:    fakeClock,
+	}
+
+	// patchUnhealthyTargets patches all the unhealthy machines, and returns the names of the ones flagged for
+	// remediation so far.
+	patchUnhealthyTargets := func() []string {
+		targets := []healthCheckTarget{}
+		for _, machine := range machines {
+			m := &clusterv1.Machine{}
+			g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), m)).To(Succeed())
+			patchHelper, err := patch.NewHelper(m, cl)
+			g.Expect(err).NotTo(HaveOccurred())
+			targets = append(targets, healthCheckTarget{
+				MHC:         mhc,
+				Machine:     m,
+				Node:        &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: m.Status.NodeRef.Name}},
+				patchHelper: patchHelper,
+			})
+		}
+		g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, targets, defaultCluster, mhc)).To(BeEmpty())
+
+		flagged := []string{}
+		for _, machine := range machines {
+			m := &clusterv1.Machine{}
+			g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), m)).To(Succeed())
+			if conditions.IsFalse(m, clusterv1.MachineOwnerRemediatedCondition) {
+				flagged = append(flagged, m.Name)
+			}
+		}
+		return flagged
+	}
+
+	// Only the first machine is flagged, the next remediation is due after the stagger interval.
+	g.Expect(patchUnhealthyTargets()).To(Equal([]string{"machine1"}))
+	g.Expect(mhc.Status.LastRemediationTime.Time).To(Equal(start))
+	g.Expect(remediationStaggerRemaining(mhc, fakeClock.Now())).To(Equal(5 * time.Minute))
+
+	// No machine is flagged before the stagger interval has elapsed.
+	fakeClock.Step(4 * time.Minute)
+	g.Expect(patchUnhealthyTargets()).To(Equal([]string{"machine1"}))
+	g.Expect(remediationStaggerRemaining(mhc, fakeClock.Now())).To(Equal(time.Minute))
+
+	// The machines are then flagged one at a time, spaced by the stagger interval.
+	fakeClock.Step(time.Minute)
+	g.Expect(patchUnhealthyTargets()).To(Equal([]string{"machine1", "machine2"}))
+	g.Expect(mhc.Status.LastRemediationTime.Time).To(Equal(start.Add(5 * time.Minute)))
+
+	fakeClock.Step(5 * time.Minute)
+	g.Expect(patchUnhealthyTargets()).To(Equal([]string{"machine1", "machine2", "machine3"}))
+	g.Expect(mhc.Status.LastRemediationTime.Time).To(Equal(start.Add(10 * time.Minute)))
+	g.Expect(mhc.Status.TotalRemediations).To(Equal(int32(3)))
+}
+
+func TestClearStaleRemediationConditions(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	namespace := defaultNamespaceName
+	clusterName := "test-cluster"
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+	mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
+	mhc.UID = "mhc-uid"
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+	}
+
+	node := newTestNode("node1")
+	node.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}
+	machine := newTestMachine("machine1", namespace, clusterName, node.Name, labels)
+	// The remediation condition was set during an outage which is over.
+	conditions.MarkFalse(machine, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "")
+
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(machine, node, mhc).Build()
+	r := &MachineHealthCheckReconciler{
+		Client:   cl,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	checkHealthyTarget := func() *clusterv1.Machine {
+		m := &clusterv1.Machine{}
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), m)).To(Succeed())
+		patchHelper, err := patch.NewHelper(m, cl)
+		g.Expect(err).NotTo(HaveOccurred())
+		target := healthCheckTarget{
+			Cluster:     cluster,
+			MHC:         mhc,
+			Machine:     m,
+			Node:        node,
+			patchHelper: patchHelper,
+		}
+		healthy, unhealthy, _ := r.healthCheckTargets(ctx, []healthCheckTarget{target}, log.NullLogger{}, mhc.Spec.NodeStartupTimeout.Duration)
+		g.Expect(healthy).To(HaveLen(1))
+		g.Expect(unhealthy).To(BeEmpty())
+
+		r.clearStaleRemediationConditions(log.NullLogger{}, mhc, healthy)
+		g.Expect(patchHelper.Patch(ctx, m)).To(Succeed())
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), m)).To(Succeed())
+		return m
+	}
+
+	// The stale remediation condition of the healthy machine is cleared on the first reconcile.
+	m := checkHealthyTarget()
+	g.Expect(conditions.IsTrue(m, clusterv1.MachineHealthCheckSuccededCondition)).To(BeTrue())
+	g.Expect(conditions.Has(m, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+
+	// Remediation conditions are left untouched on later reconciles.
+	conditions.MarkFalse(m, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "")
+	g.Expect(cl.Status().Update(ctx, m)).To(Succeed())
+	m = checkHealthyTarget()
+	g.Expect(conditions.IsFalse(m, clusterv1.MachineOwnerRemediatedCondition)).To(BeTrue())
+}
+
+func TestSetUnhealthyTargetConditions(t *testing.T) {
+	g := NewWithT(t)
+
+	newUnhealthyTarget := func(name string) healthCheckTarget {
+		machine := newTestMachine(name, defaultNamespaceName, "test-cluster", "", map[string]string{})
+		conditions.MarkFalse(machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.NodeNotFoundReason, clusterv1.ConditionSeverityError, "")
+		return healthCheckTarget{Machine: machine}
+	}
+
+	mhc := newMachineHealthCheck(defaultNamespaceName, "test-cluster")
+
+	// Each unhealthy target of a small set gets its own condition.
+	setUnhealthyTargetConditions(mhc, []healthCheckTarget{newUnhealthyTarget("machine-b"), newUnhealthyTarget("machine-a")})
+	for _, name := range []string{"machine-a", "machine-b"} {
+		c := conditions.Get(mhc, clusterv1.ConditionType(name+"/HealthCheckSucceeded"))
+		g.Expect(c).NotTo(BeNil())
+		g.Expect(c.Status).To(Equal(corev1.ConditionFalse))
+		g.Expect(c.Reason).To(Equal(clusterv1.NodeNotFoundReason))
+		g.Expect(c.Severity).To(Equal(clusterv1.ConditionSeverityError))
+	}
+	g.Expect(conditions.Has(mhc, clusterv1.UnhealthyTargetsListedCondition)).To(BeFalse())
+
+	// The conditions of targets which are no longer unhealthy are removed.
+	setUnhealthyTargetConditions(mhc, []healthCheckTarget{newUnhealthyTarget("machine-b")})
+	g.Expect(conditions.Has(mhc, "machine-a/HealthCheckSucceeded")).To(BeFalse())
+	g.Expect(conditions.Has(mhc, "machine-b/HealthCheckSucceeded")).To(BeTrue())
+
+	// At most maxUnhealthyTargetConditions targets are listed, the first ones by name.
+	unhealthy := []healthCheckTarget{}
+	for i := maxUnhealthyTargetConditions + 1; i >= 0; i-- {
+		unhealthy = append(unhealthy, newUnhealthyTarget(fmt.Sprintf("machine-%02d", i)))
+	}
+	setUnhealthyTargetConditions(mhc, unhealthy)
+	g.Expect(conditions.Has(mhc, "machine-b/HealthCheckSucceeded")).To(BeFalse())
+	g.Expect(conditions.Has(mhc, "machine-00/HealthCheckSucceeded")).To(BeTrue())
+	g.Expect(conditions.Has(mhc, clusterv1.ConditionType(fmt.Sprintf("machine-%02d/HealthCheckSucceeded", maxUnhealthyTargetConditions)))).To(BeFalse())
+	g.Expect(conditions.IsFalse(mhc, clusterv1.UnhealthyTargetsListedCondition)).To(BeTrue())
+	g.Expect(conditions.GetMessage(mhc, clusterv1.UnhealthyTargetsListedCondition)).To(Equal("10 of the 12 unhealthy machines are listed in the conditions"))
+
+	// The overflow condition is removed once all unhealthy targets are listed again.
+	setUnhealthyTargetConditions(mhc, nil)
+	g.Expect(mhc.GetConditions()).To(BeEmpty())
+}
+
+func TestPatchHealthyTargetsClearsPendingRemediation(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	namespace := defaultNamespaceName
+	clusterName := "test-cluster"
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+	mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+	}
+
+	// The node of the machines recovered before their owner remediated them.
+	node := newTestNode("node1")
+	node.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}
+	waitingMachine := newTestMachine("machine1", namespace, clusterName, node.Name, labels)
+	conditions.MarkFalse(waitingMachine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "")
+	conditions.MarkFalse(waitingMachine, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "")
+	waitingMachine.Annotations = map[string]string{clusterv1.MachineRemediationReasonAnnotation: clusterv1.UnhealthyNodeConditionReason}
+	failedMachine := newTestMachine("machine2", namespace, clusterName, node.Name, labels)
+	conditions.MarkFalse(failedMachine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "")
+	conditions.MarkFalse(failedMachine, clusterv1.MachineOwnerRemediatedCondition, clusterv1.RemediationFailedReason, clusterv1.ConditionSeverityError, "")
+
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(waitingMachine, failedMachine, node, mhc).Build()
+	r := &MachineHealthCheckReconciler{
+		Client:   cl,
+		recorder: record.NewFakeRecorder(32),
+	}
+	// Only the first reconcile since startup clears any remediation condition of healthy targets.
+	r.reconciledSinceStartup.Store(mhc.UID, struct{}{})
+
+	targets := []healthCheckTarget{}
+	for _, machine := range []*clusterv1.Machine{waitingMachine, failedMachine} {
+		m := &clusterv1.Machine{}
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), m)).To(Succeed())
+		patchHelper, err := patch.NewHelper(m, cl)
+		g.Expect(err).NotTo(HaveOccurred())
+		targets = append(targets, healthCheckTarget{
+			Cluster:     cluster,
+			MHC:         mhc,
+			Machine:     m,
+			Node:        node,
+			patchHelper: patchHelper,
+		})
+	}
 
-	for i := 0; i < o.count; i++ {
-		machine := newRunningMachine(c, o.labels)
-		if i == 0 && o.firstMachineAsControlPlane {
-			if machine.Labels == nil {
-				machine.Labels = make(map[string]string)
+	healthy, unhealthy, _ := r.healthCheckTargets(ctx, targets, log.NullLogger{}, mhc.Spec.NodeStartupTimeout.Duration)
+	g.Expect(healthy).To(HaveLen(2))
+	g.Expect(unhealthy).To(BeEmpty())
+	r.clearStaleRemediationConditions(log.NullLogger{}, mhc, healthy)
+	g.Expect(r.PatchHealthyTargets(ctx, log.NullLogger{}, healthy, cluster, mhc)).To(BeEmpty())
+
+	// The pending remediation is cleared in the same pass as the machine is seen healthy again.
+	m := &clusterv1.Machine{}
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(waitingMachine), m)).To(Succeed())
+	g.Expect(conditions.IsTrue(m, clusterv1.MachineHealthCheckSuccededCondition)).To(BeTrue())
+	g.Expect(conditions.Has(m, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+	g.Expect(m.Annotations).NotTo(HaveKey(clusterv1.MachineRemediationReasonAnnotation))
+
+	// A remediation which already started is left to the owner.
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(failedMachine), m)).To(Succeed())
+	g.Expect(conditions.IsTrue(m, clusterv1.MachineHealthCheckSuccededCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(m, clusterv1.MachineOwnerRemediatedCondition)).To(Equal(clusterv1.RemediationFailedReason))
+}
+
+func TestMachineHealthCheckReconcileUnhealthyTargetConditions(t *testing.T) {
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+	conditions.MarkTrue(cluster, clusterv1.InfrastructureReadyCondition)
+	kubeconfig := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.Name(cluster.Name, secret.Kubeconfig),
+			Namespace: cluster.Namespace,
+		},
+	}
+	labels := map[string]string{"nodepool": "a"}
+
+	reconcileMHC := func(g *WithT, annotations map[string]string) *clusterv1.MachineHealthCheck {
+		mhc := newMachineHealthCheckWithLabels("test-mhc", cluster.Namespace, cluster.Name, labels)
+		mhc.Annotations = annotations
+		mhc.Spec.ClusterOutageThreshold = &intstr.IntOrString{Type: intstr.Int, IntVal: 0}
+		mhc.Spec.MaxUnhealthy = &intstr.IntOrString{Type: intstr.Int, IntVal: 1}
+
+		// Two of the three nodes have been Unknown for an hour.
+		objs := []client.Object{cluster.DeepCopy(), kubeconfig.DeepCopy(), mhc}
+		for i := 0; i < 3; i++ {
+			nodeName := fmt.Sprintf("node-%d", i)
+			machine := newTestMachine(fmt.Sprintf("machine-%d", i), cluster.Namespace, cluster.Name, nodeName, labels)
+			node := newTestNode(nodeName)
+			if i < 2 {
+				node.Status.Conditions = []corev1.NodeCondition{
+					{
+						Type:               corev1.NodeReady,
+						Status:             corev1.ConditionUnknown,
+						LastHeartbeatTime:  metav1.NewTime(time.Now().Add(-time.Hour)),
+						LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+					},
+				}
 			}
-			machine.Labels[clusterv1.MachineControlPlaneLabelName] = ""
+			objs = append(objs, machine, node)
 		}
-		infraMachine, providerID := newInfraMachine(machine)
-		g.Expect(testEnv.Create(ctx, infraMachine)).To(Succeed())
-		infraMachines = append(infraMachines, infraMachine)
-		fmt.Printf("inframachine created: %s\n", infraMachine.GetName())
-		// Patch the status of the InfraMachine and mark it as ready.
-		// NB. Status cannot be set during object creation so we need to patch
-		// it separately.
-		infraMachinePatch := client.MergeFrom(infraMachine.DeepCopy())
-		g.Expect(unstructured.SetNestedField(infraMachine.Object, true, "status", "ready")).To(Succeed())
-		g.Expect(testEnv.Status().Patch(ctx, infraMachine, infraMachinePatch)).To(Succeed())
 
-		machine.Spec.InfrastructureRef = corev1.ObjectReference{
-			APIVersion: infraMachine.GetAPIVersion(),
-			Kind:       infraMachine.GetKind(),
-			Name:       infraMachine.GetName(),
-		}
-		g.Expect(testEnv.Create(ctx, machine)).To(Succeed())
-		fmt.Printf("machine created: %s\n", machine.GetName())
+		r := newFakeMHCReconciler(client.ObjectKeyFromObject(cluster), objs...)
+
+		_, err := r.reconcile(ctx, log.NullLogger{}, cluster, mhc)
+		g.Expect(err).NotTo(HaveOccurred())
+		return mhc
+	}
+
+	targetConditions := func(mhc *clusterv1.MachineHealthCheck) clusterv1.Conditions {
+		listed := clusterv1.Conditions{}
+		for _, c := range mhc.GetConditions() {
+			if isUnhealthyTargetConditionType(c.Type) {
+				listed = append(listed, c)
+			}
+		}
+		return listed
+	}
+
+	t.Run("lists the unhealthy targets with the annotation", func(t *testing.T) {
+		g := NewWithT(t)
+
+		mhc := reconcileMHC(g, map[string]string{clusterv1.MachineHealthCheckListUnhealthyTargetsAnnotation: "true"})
+
+		listed := targetConditions(mhc)
+		g.Expect(listed).To(HaveLen(2))
+		for i, name := range []string{"machine-0", "machine-1"} {
+			g.Expect(listed[i].Type).To(Equal(clusterv1.ConditionType(name + "/HealthCheckSucceeded")))
+			g.Expect(listed[i].Status).To(Equal(corev1.ConditionFalse))
+			g.Expect(listed[i].Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+			g.Expect(listed[i].Reason).To(Equal(clusterv1.UnhealthyNodeConditionReason))
+		}
+		g.Expect(conditions.Has(mhc, clusterv1.UnhealthyTargetsListedCondition)).To(BeFalse())
+	})
+
+	t.Run("does not list the unhealthy targets without the annotation", func(t *testing.T) {
+		g := NewWithT(t)
+
+		mhc := reconcileMHC(g, nil)
+
+		g.Expect(targetConditions(mhc)).To(BeEmpty())
+		g.Expect(conditions.Has(mhc, clusterv1.UnhealthyTargetsListedCondition)).To(BeFalse())
+	})
+}
+
+func TestPostRemediationNotificationRetries(t *testing.T) {
+	g := NewWithT(t)
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	g.Expect(postRemediationNotification(ctx, server.URL, RemediationNotification{Machine: "machine1"})).To(Succeed())
+	g.Expect(attempts).To(Equal(2))
+}
+
+func TestPatchUnhealthyTargetsDeferRemediationOnBlockingPDB(t *testing.T) {
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	namespace := defaultNamespaceName
+	clusterName := "test-cluster"
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+	}
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+	mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
+	mhc.Spec.DeferRemediationOnBlockingPDB = pointer.BoolPtr(true)
+
+	newPDB := func(disruptionsAllowed int32) *policyv1beta1.PodDisruptionBudget {
+		return &policyv1beta1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"},
+			Spec: policyv1beta1.PodDisruptionBudgetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+			Status: policyv1beta1.PodDisruptionBudgetStatus{DisruptionsAllowed: disruptionsAllowed},
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		pdb            *policyv1beta1.PodDisruptionBudget
+		podNodeName    string
+		expectDeferred bool
+	}{
+		{
+			name:           "when a PodDisruptionBudget allowing no disruption selects a pod on the node",
+			pdb:            newPDB(0),
+			podNodeName:    "node1",
+			expectDeferred: true,
+		},
+		{
+			name:           "when the PodDisruptionBudget allows disruptions",
+			pdb:            newPDB(1),
+			podNodeName:    "node1",
+			expectDeferred: false,
+		},
+		{
+			name:           "when the selected pod runs on another node",
+			pdb:            newPDB(0),
+			podNodeName:    "node2",
+			expectDeferred: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "apps", Labels: map[string]string{"app": "web"}},
+				Spec:       corev1.PodSpec{NodeName: tc.podNodeName},
+			}
+			machine := newTestMachine("machine1", namespace, clusterName, node.Name, labels)
+			conditions.MarkFalse(machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "")
+
+			r := newFakeMHCReconciler(client.ObjectKey{Name: clusterName, Namespace: namespace}, machine, node, pod, tc.pdb, mhc)
+			g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+			patchHelper, err := patch.NewHelper(machine, r.Client)
+			g.Expect(err).NotTo(HaveOccurred())
+			target := healthCheckTarget{
+				MHC:         mhc,
+				Machine:     machine,
+				Node:        node,
+				patchHelper: patchHelper,
+			}
+
+			g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
+
+			updated := &clusterv1.Machine{}
+			g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(machine), updated)).To(Succeed())
+			if !tc.expectDeferred {
+				g.Expect(conditions.Has(updated, clusterv1.DrainAllowedCondition)).To(BeFalse())
+				g.Expect(conditions.IsFalse(updated, clusterv1.MachineOwnerRemediatedCondition)).To(BeTrue())
+				return
+			}
+			g.Expect(conditions.Has(updated, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+			condition := conditions.Get(updated, clusterv1.DrainAllowedCondition)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+			g.Expect(condition.Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+			g.Expect(condition.Reason).To(Equal(clusterv1.DrainBlockedByPDBReason))
+			g.Expect(condition.Message).To(Equal("Drain of node node1 would be blocked by PodDisruptionBudget(s) apps/web"))
+		})
+	}
+}
+
+func TestEvictedOnDrain(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pod      *corev1.Pod
+		expected bool
+	}{
+		{
+			name:     "when the pod is running",
+			pod:      &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+			expected: true,
+		},
+		{
+			name:     "when the pod has succeeded",
+			pod:      &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodSucceeded}},
+			expected: false,
+		},
+		{
+			name: "when the pod is a mirror pod",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{corev1.MirrorPodAnnotationKey: "hash"},
+			}},
+			expected: false,
+		},
+		{
+			name: "when the pod is managed by a DaemonSet",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "ds", Controller: pointer.BoolPtr(true)}},
+			}},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(evictedOnDrain(tc.pod)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestPatchUnhealthyTargetsRemediationBudget(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	namespace := defaultNamespaceName
+	clusterName := "test-cluster"
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+	}
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+	mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
+	mhc.Spec.MaxTotalRemediations = pointer.Int32Ptr(1)
+
+	machines := []*clusterv1.Machine{
+		newTestMachine("machine1", namespace, clusterName, "node1", labels),
+		newTestMachine("machine2", namespace, clusterName, "node2", labels),
+	}
+	objs := []client.Object{mhc}
+	for _, machine := range machines {
+		conditions.MarkFalse(machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "")
+		objs = append(objs, machine, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: machine.Status.NodeRef.Name}})
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+	r := &MachineHealthCheckReconciler{
+		Client:   cl,
+		recorder: record.NewFakeRecorder(32),
+		Tracker:  remote.NewTestClusterCacheTracker(log.NullLogger{}, cl, scheme.Scheme, client.ObjectKey{Name: clusterName, Namespace: namespace}, "machinehealthcheck-watchClusterNodes"),
+	}
+
+	targets := []healthCheckTarget{}
+	for _, machine := range machines {
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+		patchHelper, err := patch.NewHelper(machine, cl)
+		g.Expect(err).NotTo(HaveOccurred())
+		targets = append(targets, healthCheckTarget{
+			MHC:         mhc,
+			Machine:     machine,
+			Node:        &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: machine.Status.NodeRef.Name}},
+			patchHelper: patchHelper,
+		})
+	}
+
+	g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, targets, defaultCluster, mhc)).To(BeEmpty())
+
+	// Only the first unhealthy machine is remediated, exhausting the budget.
+	remediated := &clusterv1.Machine{}
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machines[0]), remediated)).To(Succeed())
+	g.Expect(conditions.IsFalse(remediated, clusterv1.MachineOwnerRemediatedCondition)).To(BeTrue())
+	notRemediated := &clusterv1.Machine{}
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machines[1]), notRemediated)).To(Succeed())
+	g.Expect(conditions.Has(notRemediated, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+
+	g.Expect(mhc.Status.TotalRemediations).To(Equal(int32(1)))
+	condition := conditions.Get(mhc, clusterv1.RemediationBudgetAvailableCondition)
+	g.Expect(condition).NotTo(BeNil())
+	g.Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(condition.Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+	g.Expect(condition.Reason).To(Equal(clusterv1.RemediationBudgetExhaustedReason))
+
+	// Further unhealthy machines are not remediated either.
+	g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, targets[1:], defaultCluster, mhc)).To(BeEmpty())
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machines[1]), notRemediated)).To(Succeed())
+	g.Expect(conditions.Has(notRemediated, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+	g.Expect(mhc.Status.TotalRemediations).To(Equal(int32(1)))
+}
+
+func TestPatchUnhealthyTargetsAwaitingReplacement(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	namespace := defaultNamespaceName
+	clusterName := "test-cluster"
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+	}
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+	mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
+	mhc.Spec.ReplacementTimeout = &metav1.Duration{Duration: time.Hour}
+
+	machines := []*clusterv1.Machine{
+		newTestMachine("machine1", namespace, clusterName, "node1", labels),
+		newTestMachine("machine2", namespace, clusterName, "node2", labels),
+	}
+	objs := []client.Object{mhc}
+	for _, machine := range machines {
+		conditions.MarkFalse(machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "")
+		objs = append(objs, machine, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: machine.Status.NodeRef.Name}})
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+	r := &MachineHealthCheckReconciler{
+		Client:   cl,
+		recorder: record.NewFakeRecorder(32),
+		Tracker:  remote.NewTestClusterCacheTracker(log.NullLogger{}, cl, scheme.Scheme, client.ObjectKey{Name: clusterName, Namespace: namespace}, "machinehealthcheck-watchClusterNodes"),
+	}
+
+	targets := []healthCheckTarget{}
+	for _, machine := range machines {
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+		patchHelper, err := patch.NewHelper(machine, cl)
+		g.Expect(err).NotTo(HaveOccurred())
+		targets = append(targets, healthCheckTarget{
+			MHC:         mhc,
+			Machine:     machine,
+			Node:        &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: machine.Status.NodeRef.Name}},
+			patchHelper: patchHelper,
+		})
+	}
+
+	g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, targets, defaultCluster, mhc)).To(BeEmpty())
+
+	// Only the first unhealthy machine is remediated, the second one awaits its replacement.
+	remediated := &clusterv1.Machine{}
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machines[0]), remediated)).To(Succeed())
+	g.Expect(conditions.IsFalse(remediated, clusterv1.MachineOwnerRemediatedCondition)).To(BeTrue())
+	notRemediated := &clusterv1.Machine{}
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machines[1]), notRemediated)).To(Succeed())
+	g.Expect(conditions.Has(notRemediated, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+
+	g.Expect(mhc.Status.LastRemediationTime).NotTo(BeNil())
+	condition := conditions.Get(mhc, clusterv1.AwaitingReplacementCondition)
+	g.Expect(condition).NotTo(BeNil())
+	g.Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(condition.Reason).To(Equal(clusterv1.ReplacementNotHealthyReason))
+
+	// A broken replacement, i.e. an unhealthy machine created since the remediation, stalls further remediation, and so
+	// does a healthy machine which existed before the remediation.
+	replacement := newTestMachine("machine3", namespace, clusterName, "node3", labels)
+	replacement.CreationTimestamp = metav1.NewTime(mhc.Status.LastRemediationTime.Add(time.Second))
+	existing := newTestMachine("machine4", namespace, clusterName, "node4", labels)
+	existing.CreationTimestamp = metav1.NewTime(mhc.Status.LastRemediationTime.Add(-time.Hour))
+	healthy := []healthCheckTarget{
+		{MHC: mhc, Machine: existing, Node: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node4"}}},
+	}
+	reconcileAwaitingReplacement(mhc, healthy, time.Now())
+	g.Expect(conditions.IsTrue(mhc, clusterv1.AwaitingReplacementCondition)).To(BeTrue())
+
+	g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, targets[1:], defaultCluster, mhc)).To(BeEmpty())
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machines[1]), notRemediated)).To(Succeed())
+	g.Expect(conditions.Has(notRemediated, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+	g.Expect(mhc.Status.TotalRemediations).To(Equal(int32(1)))
+
+	// Once the replacement is healthy, the next unhealthy machine is remediated.
+	healthy = append(healthy, healthCheckTarget{MHC: mhc, Machine: replacement, Node: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node3"}}})
+	reconcileAwaitingReplacement(mhc, healthy, time.Now())
+	g.Expect(conditions.Has(mhc, clusterv1.AwaitingReplacementCondition)).To(BeFalse())
+
+	g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, targets[1:], defaultCluster, mhc)).To(BeEmpty())
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machines[1]), remediated)).To(Succeed())
+	g.Expect(conditions.IsFalse(remediated, clusterv1.MachineOwnerRemediatedCondition)).To(BeTrue())
+	g.Expect(mhc.Status.TotalRemediations).To(Equal(int32(2)))
+	g.Expect(conditions.IsTrue(mhc, clusterv1.AwaitingReplacementCondition)).To(BeTrue())
+
+	// Remediation resumes once the replacement timeout expires, even if no replacement is healthy.
+	reconcileAwaitingReplacement(mhc, nil, mhc.Status.LastRemediationTime.Add(time.Hour))
+	g.Expect(conditions.Has(mhc, clusterv1.AwaitingReplacementCondition)).To(BeFalse())
+}
+
+func TestMachineHealthCheckReconcileAwaitingReplacementClock(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+	conditions.MarkTrue(cluster, clusterv1.InfrastructureReadyCondition)
+	kubeconfig := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.Name(cluster.Name, secret.Kubeconfig),
+			Namespace: cluster.Namespace,
+		},
+	}
+
+	// The reconciler clock is far behind the wall clock, the replacement timeout must only expire by the former.
+	fakeClock := clock.NewFakeClock(time.Date(2021, 3, 1, 2, 0, 0, 0, time.UTC))
+	labels := map[string]string{"nodepool": "a"}
+	mhc := newMachineHealthCheckWithLabels("test-mhc", cluster.Namespace, cluster.Name, labels)
+	mhc.Spec.ClusterOutageThreshold = &intstr.IntOrString{Type: intstr.Int, IntVal: 0}
+	mhc.Spec.ReplacementTimeout = &metav1.Duration{Duration: time.Hour}
+	lastRemediationTime := metav1.NewTime(fakeClock.Now().Add(-10 * time.Minute))
+	mhc.Status.LastRemediationTime = &lastRemediationTime
+
+	unhealthyMachine := newTestMachine("machine-0", cluster.Namespace, cluster.Name, "node-0", labels)
+	unhealthyNode := newTestNode("node-0")
+	unhealthyNode.Status.Conditions = []corev1.NodeCondition{
+		{
+			Type:               corev1.NodeReady,
+			Status:             corev1.ConditionUnknown,
+			LastHeartbeatTime:  metav1.NewTime(fakeClock.Now().Add(-time.Hour)),
+			LastTransitionTime: metav1.NewTime(fakeClock.Now().Add(-time.Hour)),
+		},
+	}
+	healthyMachine := newTestMachine("machine-1", cluster.Namespace, cluster.Name, "node-1", labels)
+	healthyNode := newTestNode("node-1")
+
+	r := newFakeMHCReconciler(client.ObjectKeyFromObject(cluster), cluster, kubeconfig, mhc, unhealthyMachine, unhealthyNode, healthyMachine, healthyNode)
+	r.clock = fakeClock
+
+	result, err := r.reconcile(ctx, log.NullLogger{}, cluster, mhc)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// The unhealthy machine awaits the replacement, until the replacement timeout expires by the reconciler clock.
+	g.Expect(conditions.IsTrue(mhc, clusterv1.AwaitingReplacementCondition)).To(BeTrue())
+	g.Expect(result.RequeueAfter).To(Equal(50 * time.Minute))
+	updated := &clusterv1.Machine{}
+	g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(unhealthyMachine), updated)).To(Succeed())
+	g.Expect(conditions.Has(updated, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+}
+
+func TestPatchUnhealthyTargetsRemediationOrder(t *testing.T) {
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	namespace := defaultNamespaceName
+	clusterName := "test-cluster"
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+	}
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+
+	newPod := func(name, nodeName string, priority int32) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			Spec:       corev1.PodSpec{NodeName: nodeName, Priority: pointer.Int32Ptr(priority)},
+		}
+	}
+
+	testCases := []struct {
+		name                string
+		remediationOrder    clusterv1.RemediationOrder
+		expectRemediated    string
+		expectNotRemediated string
+	}{
+		{
+			name:                "when remediating the oldest unhealthy machines first",
+			remediationOrder:    "",
+			expectRemediated:    "machine1",
+			expectNotRemediated: "machine2",
+		},
+		{
+			name:                "when remediating the least critical machines first",
+			remediationOrder:    clusterv1.RemediationOrderLeastCriticalFirst,
+			expectRemediated:    "machine2",
+			expectNotRemediated: "machine1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
+			mhc.Spec.MaxUnhealthy = &intstr.IntOrString{Type: intstr.Int, IntVal: 1}
+			mhc.Spec.MaxTotalRemediations = pointer.Int32Ptr(1)
+			mhc.Spec.RemediationOrder = tc.remediationOrder
+
+			// machine1 has been unhealthy for longer, but its node runs critical pods.
+			machines := []*clusterv1.Machine{
+				newTestMachine("machine1", namespace, clusterName, "node1", labels),
+				newTestMachine("machine2", namespace, clusterName, "node2", labels),
+			}
+			objs := []client.Object{
+				mhc,
+				newPod("critical", "node1", 1000),
+				newPod("best-effort", "node2", 0),
+			}
+			for i, machine := range machines {
+				conditions.Set(machine, &clusterv1.Condition{
+					Type:               clusterv1.MachineHealthCheckSuccededCondition,
+					Status:             corev1.ConditionFalse,
+					Severity:           clusterv1.ConditionSeverityWarning,
+					Reason:             clusterv1.UnhealthyNodeConditionReason,
+					LastTransitionTime: metav1.NewTime(time.Now().Add(time.Duration(i-2) * time.Hour).UTC().Truncate(time.Second)),
+				})
+				objs = append(objs, machine, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: machine.Status.NodeRef.Name}})
+			}
+
+			r := newFakeMHCReconciler(client.ObjectKey{Name: clusterName, Namespace: namespace}, objs...)
+
+			// Targets are listed in the opposite order to the one they should be remediated in by default.
+			targets := []healthCheckTarget{}
+			for i := len(machines) - 1; i >= 0; i-- {
+				machine := machines[i]
+				g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+				patchHelper, err := patch.NewHelper(machine, r.Client)
+				g.Expect(err).NotTo(HaveOccurred())
+				targets = append(targets, healthCheckTarget{
+					MHC:         mhc,
+					Machine:     machine,
+					Node:        &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: machine.Status.NodeRef.Name}},
+					patchHelper: patchHelper,
+				})
+			}
+
+			g.Expect(r.sortUnhealthyTargets(ctx, defaultCluster, mhc, targets)).To(Succeed())
+			g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, targets, defaultCluster, mhc)).To(BeEmpty())
+
+			remediated := &clusterv1.Machine{}
+			g.Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: tc.expectRemediated}, remediated)).To(Succeed())
+			g.Expect(conditions.IsFalse(remediated, clusterv1.MachineOwnerRemediatedCondition)).To(BeTrue())
+			notRemediated := &clusterv1.Machine{}
+			g.Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: tc.expectNotRemediated}, notRemediated)).To(Succeed())
+			g.Expect(conditions.Has(notRemediated, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+		})
+	}
+}
+
+func TestReconcileRemediationBudget(t *testing.T) {
+	testCases := []struct {
+		name                    string
+		maxTotalRemediations    *int32
+		totalRemediations       int32
+		resetAnnotation         bool
+		expectTotalRemediations int32
+		expectExhausted         bool
+	}{
+		{
+			name:                    "when MaxTotalRemediations is not set",
+			maxTotalRemediations:    nil,
+			totalRemediations:       10,
+			expectTotalRemediations: 10,
+			expectExhausted:         false,
+		},
+		{
+			name:                    "when the remediation budget is not exhausted",
+			maxTotalRemediations:    pointer.Int32Ptr(3),
+			totalRemediations:       2,
+			expectTotalRemediations: 2,
+			expectExhausted:         false,
+		},
+		{
+			name:                    "when the remediation budget is exhausted",
+			maxTotalRemediations:    pointer.Int32Ptr(3),
+			totalRemediations:       3,
+			expectTotalRemediations: 3,
+			expectExhausted:         true,
+		},
+		{
+			name:                    "when the remediation budget is reset",
+			maxTotalRemediations:    pointer.Int32Ptr(3),
+			totalRemediations:       3,
+			resetAnnotation:         true,
+			expectTotalRemediations: 0,
+			expectExhausted:         false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
 
-		// Before moving on we want to ensure that the machine has a valid
-		// status. That is, LastUpdated should not be nil.
-		g.Eventually(func() *metav1.Time {
-			k := client.ObjectKey{
-				Name:      machine.GetName(),
-				Namespace: machine.GetNamespace(),
+			mhc := &clusterv1.MachineHealthCheck{
+				Spec:   clusterv1.MachineHealthCheckSpec{MaxTotalRemediations: tc.maxTotalRemediations},
+				Status: clusterv1.MachineHealthCheckStatus{TotalRemediations: tc.totalRemediations},
 			}
-			err := testEnv.Get(ctx, k, machine)
-			if err != nil {
-				return nil
+			if tc.resetAnnotation {
+				mhc.Annotations = map[string]string{clusterv1.MachineHealthCheckResetRemediationBudgetAnnotation: ""}
 			}
-			return machine.Status.LastUpdated
-		}, timeout, 100*time.Millisecond).ShouldNot(BeNil())
 
-		machinePatchHelper, err := patch.NewHelper(machine, testEnv.Client)
-		g.Expect(err).To(BeNil())
+			reconcileRemediationBudget(log.NullLogger{}, mhc)
 
-		if o.createNodeRefForMachine {
-			// Create node
-			node := &corev1.Node{
-				ObjectMeta: metav1.ObjectMeta{
-					GenerateName: "test-mhc-node-",
-				},
-				Spec: corev1.NodeSpec{
-					ProviderID: providerID,
-				},
-			}
+			g.Expect(mhc.Status.TotalRemediations).To(Equal(tc.expectTotalRemediations))
+			g.Expect(mhc.Annotations).NotTo(HaveKey(clusterv1.MachineHealthCheckResetRemediationBudgetAnnotation))
+			g.Expect(conditions.IsFalse(mhc, clusterv1.RemediationBudgetAvailableCondition)).To(Equal(tc.expectExhausted))
+			g.Expect(conditions.Has(mhc, clusterv1.RemediationBudgetAvailableCondition)).To(Equal(tc.expectExhausted))
+		})
+	}
+}
 
-			g.Expect(testEnv.Create(ctx, node)).To(Succeed())
-			fmt.Printf("node created: %s\n", node.GetName())
+func TestPatchUnhealthyTargetsAlertOnly(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
 
-			// Patch node status
-			nodePatchHelper, err := patch.NewHelper(node, testEnv.Client)
-			g.Expect(err).To(BeNil())
+	namespace := defaultNamespaceName
+	clusterName := "test-cluster"
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+	}
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+	mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
 
-			node.Status.Conditions = []corev1.NodeCondition{
-				{
-					Type:               corev1.NodeReady,
-					Status:             o.nodeStatus,
-					LastTransitionTime: metav1.NewTime(time.Now().Add(-10 * time.Minute)),
-				},
-			}
+	machine := newTestMachine("machine1", namespace, clusterName, "node1", labels)
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(machine, mhc).Build()
+	recorder := record.NewFakeRecorder(32)
+	r := &MachineHealthCheckReconciler{
+		Client:   cl,
+		recorder: recorder,
+	}
 
-			g.Expect(nodePatchHelper.Patch(ctx, node)).To(Succeed())
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+	patchHelper, err := patch.NewHelper(machine, cl)
+	g.Expect(err).NotTo(HaveOccurred())
+	conditions.MarkFalse(machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "")
 
-			nodes = append(nodes, node)
+	target := healthCheckTarget{
+		MHC:         mhc,
+		Machine:     machine,
+		patchHelper: patchHelper,
+		alertOnly:   true,
+	}
+	g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
 
-			machine.Status.NodeRef = &corev1.ObjectReference{
-				Name: node.Name,
-			}
-		}
+	updated := &clusterv1.Machine{}
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), updated)).To(Succeed())
+	g.Expect(conditions.IsFalse(updated, clusterv1.MachineHealthCheckSuccededCondition)).To(BeTrue())
+	g.Expect(conditions.Has(updated, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+	g.Expect(recorder.Events).To(BeEmpty())
+}
 
-		if o.failureReason != "" {
-			failureReason := capierrors.MachineStatusError(o.failureReason)
-			machine.Status.FailureReason = &failureReason
-		}
-		if o.failureMessage != "" {
-			machine.Status.FailureMessage = pointer.StringPtr(o.failureMessage)
-		}
+func TestPatchTargetsCordonAndWait(t *testing.T) {
+	_ = clusterv1.AddToScheme(scheme.Scheme)
 
-		// Adding one second to ensure there is a difference from the
-		// original time so that the patch works. That is, ensure the
-		// precision isn't lost during conversions.
-		lastUp := metav1.NewTime(machine.Status.LastUpdated.Add(time.Second))
-		machine.Status.LastUpdated = &lastUp
+	namespace := defaultNamespaceName
+	clusterName := "test-cluster"
+	defaultCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+	}
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
 
-		// Patch the machine to record the status changes
-		g.Expect(machinePatchHelper.Patch(ctx, machine)).To(Succeed())
+	mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
+	mhc.Spec.RemediationStrategy = &clusterv1.RemediationStrategy{
+		Type:        clusterv1.RemediationStrategyCordonAndWait,
+		GracePeriod: &metav1.Duration{Duration: 5 * time.Minute},
+	}
 
-		machines = append(machines, machine)
+	// nextReconcile mimics a new reconcile, where the patch helper snapshots the current state of the machine.
+	nextReconcile := func(g *WithT, cl client.Client, target *healthCheckTarget) {
+		var err error
+		target.patchHelper, err = patch.NewHelper(target.Machine, cl)
+		g.Expect(err).NotTo(HaveOccurred())
 	}
 
-	cleanup := func() {
-		fmt.Println("Cleaning up nodes, machines and infra machines.")
-		for _, n := range nodes {
-			if err := testEnv.Delete(ctx, n); !apierrors.IsNotFound(err) {
-				g.Expect(err).NotTo(HaveOccurred())
-			}
-		}
-		for _, m := range machines {
-			g.Expect(testEnv.Delete(ctx, m)).To(Succeed())
+	setup := func(t *testing.T) (*MachineHealthCheckReconciler, client.Client, healthCheckTarget) {
+		g := NewWithT(t)
+
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+		machine := newTestMachine("machine1", namespace, clusterName, node.Name, labels)
+		conditions.MarkFalse(machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "")
+
+		cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(machine, node, mhc).Build()
+		r := &MachineHealthCheckReconciler{
+			Client:   cl,
+			recorder: record.NewFakeRecorder(32),
+			Tracker:  remote.NewTestClusterCacheTracker(log.NullLogger{}, cl, scheme.Scheme, client.ObjectKey{Name: clusterName, Namespace: namespace}, "machinehealthcheck-watchClusterNodes"),
+			clock:    clock.NewFakeClock(time.Date(2021, 3, 1, 2, 0, 0, 0, time.UTC)),
 		}
-		for _, im := range infraMachines {
-			if err := testEnv.Delete(ctx, im); !apierrors.IsNotFound(err) {
-				g.Expect(err).NotTo(HaveOccurred())
-			}
+
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		patchHelper, err := patch.NewHelper(machine, cl)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		return r, cl, healthCheckTarget{
+			MHC:         mhc,
+			Machine:     machine,
+			Node:        node,
+			patchHelper: patchHelper,
 		}
 	}
 
-	return nodes, machines, cleanup
-}
+	t.Run("recovering within the grace period uncordons the node without remediation", func(t *testing.T) {
+		g := NewWithT(t)
+		r, cl, target := setup(t)
+
+		g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
+
+		machine := &clusterv1.Machine{}
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(target.Machine), machine)).To(Succeed())
+		g.Expect(machine.Annotations).To(HaveKeyWithValue(clusterv1.MachineRemediationCordonedAnnotation, "2021-03-01T02:00:00Z"))
+		g.Expect(conditions.Has(machine, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+		node := &corev1.Node{}
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(target.Node), node)).To(Succeed())
+		g.Expect(node.Spec.Unschedulable).To(BeTrue())
+
+		// Still unhealthy, but within the grace period.
+		nextReconcile(g, cl, &target)
+		g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(target.Machine), machine)).To(Succeed())
+		g.Expect(conditions.Has(machine, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+
+		// The machine recovers.
+		nextReconcile(g, cl, &target)
+		conditions.MarkTrue(target.Machine, clusterv1.MachineHealthCheckSuccededCondition)
+		g.Expect(r.PatchHealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
+
+		machine = &clusterv1.Machine{}
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(target.Machine), machine)).To(Succeed())
+		g.Expect(machine.Annotations).NotTo(HaveKey(clusterv1.MachineRemediationCordonedAnnotation))
+		g.Expect(conditions.Has(machine, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+		node = &corev1.Node{}
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(target.Node), node)).To(Succeed())
+		g.Expect(node.Spec.Unschedulable).To(BeFalse())
+	})
 
-func newMachineHealthCheckWithLabels(name, namespace, cluster string, labels map[string]string) *clusterv1.MachineHealthCheck {
-	l := make(map[string]string, len(labels))
-	for k, v := range labels {
-		l[k] = v
-	}
-	l[clusterv1.ClusterLabelName] = cluster
+	t.Run("not recovering within the grace period marks the machine for remediation", func(t *testing.T) {
+		g := NewWithT(t)
+		r, cl, target := setup(t)
 
-	mhc := newMachineHealthCheck(namespace, cluster)
-	mhc.SetName(name)
-	mhc.Labels = l
-	mhc.Spec.Selector.MatchLabels = l
+		g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
 
-	return mhc
-}
+		// Simulate the grace period elapsing.
+		nextReconcile(g, cl, &target)
+		r.clock = clock.NewFakeClock(r.now().Add(10 * time.Minute))
+		g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
 
-func newMachineHealthCheck(namespace, clusterName string) *clusterv1.MachineHealthCheck {
-	maxUnhealthy := intstr.FromString("100%")
-	return &clusterv1.MachineHealthCheck{
-		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: "test-mhc-",
-			Namespace:    namespace,
-		},
-		Spec: clusterv1.MachineHealthCheckSpec{
-			ClusterName: clusterName,
-			Selector: metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"selector": string(uuid.NewUUID()),
-				},
-			},
-			MaxUnhealthy:       &maxUnhealthy,
-			NodeStartupTimeout: &metav1.Duration{Duration: 1 * time.Millisecond},
-			UnhealthyConditions: []clusterv1.UnhealthyCondition{
-				{
-					Type:    corev1.NodeReady,
-					Status:  corev1.ConditionUnknown,
-					Timeout: metav1.Duration{Duration: 5 * time.Minute},
-				},
-			},
-		},
-	}
+		machine := &clusterv1.Machine{}
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(target.Machine), machine)).To(Succeed())
+		g.Expect(conditions.Get(machine, clusterv1.MachineOwnerRemediatedCondition).Status).To(Equal(corev1.ConditionFalse))
+		node := &corev1.Node{}
+		g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(target.Node), node)).To(Succeed())
+		g.Expect(node.Spec.Unschedulable).To(BeTrue())
+	})
 }
 
-func TestPatchTargets(t *testing.T) {
-	_ = clusterv1.AddToScheme(scheme.Scheme)
+func TestPatchTargetsAnnotateOnly(t *testing.T) {
 	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
 
 	namespace := defaultNamespaceName
 	clusterName := "test-cluster"
@@ -2576,51 +5254,231 @@ func TestPatchTargets(t *testing.T) {
 		},
 	}
 	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
-
 	mhc := newMachineHealthCheckWithLabels("mhc", namespace, clusterName, labels)
-	machine1 := newTestMachine("machine1", namespace, clusterName, "nodeName", labels)
-	machine1.ResourceVersion = "1"
-	conditions.MarkTrue(machine1, clusterv1.MachineHealthCheckSuccededCondition)
-	machine2 := machine1.DeepCopy()
-	machine2.Name = "machine2"
+	mhc.Spec.RemediationStrategy = &clusterv1.RemediationStrategy{Type: clusterv1.RemediationStrategyAnnotateOnly}
 
-	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
-		machine1,
-		machine2,
-		mhc,
-	).Build()
+	machine := newTestMachine("machine1", namespace, clusterName, "node1", labels)
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(machine, mhc).Build()
 	r := &MachineHealthCheckReconciler{
 		Client:   cl,
 		recorder: record.NewFakeRecorder(32),
-		Tracker:  remote.NewTestClusterCacheTracker(log.NullLogger{}, cl, scheme.Scheme, client.ObjectKey{Name: clusterName, Namespace: namespace}, "machinehealthcheck-watchClusterNodes"),
 	}
 
-	// To make the patch fail, create patchHelper with a different client.
-	fakeMachine := machine1.DeepCopy()
-	fakeMachine.Name = "fake"
-	patchHelper, _ := patch.NewHelper(fakeMachine, fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(fakeMachine).Build())
-	// healthCheckTarget with fake patchHelper, patch should fail on this target.
-	target1 := healthCheckTarget{
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+	patchHelper, err := patch.NewHelper(machine, cl)
+	g.Expect(err).NotTo(HaveOccurred())
+	conditions.MarkFalse(machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "Condition Ready on node is reporting status False")
+
+	target := healthCheckTarget{
 		MHC:         mhc,
-		Machine:     machine1,
+		Machine:     machine,
 		patchHelper: patchHelper,
-		Node:        &corev1.Node{},
 	}
+	g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
+
+	updated := &clusterv1.Machine{}
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), updated)).To(Succeed())
+	g.Expect(updated.Annotations).To(HaveKeyWithValue(clusterv1.MachineNeedsRemediationAnnotation, "true"))
+	g.Expect(updated.Annotations).To(HaveKeyWithValue(clusterv1.MachineRemediationReasonAnnotation, "UnhealthyNode: Condition Ready on node is reporting status False"))
+	g.Expect(conditions.IsFalse(updated, clusterv1.MachineHealthCheckSuccededCondition)).To(BeTrue())
+	g.Expect(conditions.Has(updated, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+	g.Expect(mhc.Status.TotalRemediations).To(Equal(int32(1)))
+
+	// Failing the health check again does not count as another remediation.
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), target.Machine)).To(Succeed())
+	target.patchHelper, err = patch.NewHelper(target.Machine, cl)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(r.PatchUnhealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
+	g.Expect(mhc.Status.TotalRemediations).To(Equal(int32(1)))
+
+	// The machine recovers.
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), target.Machine)).To(Succeed())
+	target.patchHelper, err = patch.NewHelper(target.Machine, cl)
+	g.Expect(err).NotTo(HaveOccurred())
+	conditions.MarkTrue(target.Machine, clusterv1.MachineHealthCheckSuccededCondition)
+	g.Expect(r.PatchHealthyTargets(ctx, log.NullLogger{}, []healthCheckTarget{target}, defaultCluster, mhc)).To(BeEmpty())
+
+	updated = &clusterv1.Machine{}
+	g.Expect(cl.Get(ctx, client.ObjectKeyFromObject(machine), updated)).To(Succeed())
+	g.Expect(updated.Annotations).NotTo(HaveKey(clusterv1.MachineNeedsRemediationAnnotation))
+	g.Expect(conditions.Has(updated, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+}
 
-	// healthCheckTarget with correct patchHelper.
-	patchHelper2, _ := patch.NewHelper(machine2, cl)
-	target3 := healthCheckTarget{
-		MHC:         mhc,
-		Machine:     machine2,
-		patchHelper: patchHelper2,
-		Node:        &corev1.Node{},
+func TestMachineHealthCheckReconcileSummary(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+	kubeconfig := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.Name(cluster.Name, secret.Kubeconfig),
+			Namespace: cluster.Namespace,
+		},
+	}
+	labels := map[string]string{"nodepool": "a"}
+	mhc := newMachineHealthCheckWithLabels("test-mhc", cluster.Namespace, cluster.Name, labels)
+
+	// A single machine failed, as its FailureReason reports; the nodes of all the machines do exist.
+	objs := []client.Object{cluster, kubeconfig, mhc}
+	for i := 0; i < 3; i++ {
+		nodeName := fmt.Sprintf("node-%d", i)
+		machine := newTestMachine(fmt.Sprintf("machine-%d", i), cluster.Namespace, cluster.Name, nodeName, labels)
+		if i == 0 {
+			failureReason := capierrors.UpdateMachineError
+			machine.Status.FailureReason = &failureReason
+		}
+		objs = append(objs, machine, newTestNode(nodeName))
 	}
 
-	// Target with wrong patch helper will fail but the other one will be patched.
-	g.Expect(len(r.PatchUnhealthyTargets(context.TODO(), log.NullLogger{}, []healthCheckTarget{target1, target3}, defaultCluster, mhc))).To(BeNumerically(">", 0))
-	g.Expect(cl.Get(ctx, client.ObjectKey{Name: machine2.Name, Namespace: machine2.Namespace}, machine2)).NotTo(HaveOccurred())
-	g.Expect(conditions.Get(machine2, clusterv1.MachineOwnerRemediatedCondition).Status).To(Equal(corev1.ConditionFalse))
+	r := newFakeMHCReconciler(client.ObjectKeyFromObject(cluster), objs...)
 
-	// Target with wrong patch helper will fail but the other one will be patched.
-	g.Expect(len(r.PatchHealthyTargets(context.TODO(), log.NullLogger{}, []healthCheckTarget{target1, target3}, defaultCluster, mhc))).To(BeNumerically(">", 0))
+	logger := &summaryTestLogger{lines: &[]summaryTestLine{}}
+	_, err := r.Reconcile(log.IntoContext(ctx, logger), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(mhc)})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var summaries []summaryTestLine
+	for _, line := range *logger.lines {
+		if line.msg == "Reconcile summary" {
+			summaries = append(summaries, line)
+		}
+	}
+	g.Expect(summaries).To(HaveLen(1))
+	g.Expect(summaries[0].data).To(Equal(map[string]interface{}{
+		"cluster":               cluster.Name,
+		"mhc":                   mhc.Name,
+		"expected":              int32(3),
+		"healthy":               int32(2),
+		"unhealthy":             int32(1),
+		"flaggedForRemediation": int32(1),
+		"remediationAllowed":    true,
+	}))
+}
+
+func TestMachineHealthCheckReconcileLastSuccessfulReconcile(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+	conditions.MarkTrue(cluster, clusterv1.InfrastructureReadyCondition)
+	kubeconfig := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.Name(cluster.Name, secret.Kubeconfig),
+			Namespace: cluster.Namespace,
+		},
+	}
+	mhc := newMachineHealthCheckWithLabels("test-mhc", cluster.Namespace, cluster.Name, map[string]string{"nodepool": "a"})
+	lastSuccessfulReconcile := metav1.NewTime(time.Date(2021, 3, 1, 1, 0, 0, 0, time.UTC))
+	mhc.Status.LastSuccessfulReconcile = &lastSuccessfulReconcile
+	machine := newTestMachine("machine", cluster.Namespace, cluster.Name, "node", mhc.Spec.Selector.MatchLabels)
+	node := newTestNode("node")
+
+	// The workload cluster cannot be reached at first: the tracker knows another cluster only, and fails to
+	// create a client from the empty kubeconfig.
+	fakeClock := clock.NewFakeClock(time.Date(2021, 3, 1, 2, 0, 0, 0, time.UTC))
+	r := newFakeMHCReconciler(client.ObjectKey{Namespace: cluster.Namespace, Name: "other-cluster"}, cluster, kubeconfig, mhc, machine, node)
+	r.clock = fakeClock
+
+	// A failed reconciliation leaves the time of the last successful one unchanged.
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(mhc)})
+	g.Expect(err).To(HaveOccurred())
+
+	updated := &clusterv1.MachineHealthCheck{}
+	g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(mhc), updated)).To(Succeed())
+	g.Expect(updated.Status.LastSuccessfulReconcile).NotTo(BeNil())
+	g.Expect(updated.Status.LastSuccessfulReconcile.Time).To(BeTemporally("==", lastSuccessfulReconcile.Time))
+
+	// A successful reconciliation updates it.
+	r.Tracker = remote.NewTestClusterCacheTracker(log.NullLogger{}, r.Client, scheme.Scheme, client.ObjectKeyFromObject(cluster), "machinehealthcheck-watchClusterNodes")
+	_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(mhc)})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(mhc), updated)).To(Succeed())
+	g.Expect(updated.Status.ExpectedMachines).To(Equal(int32(1)))
+	g.Expect(updated.Status.LastSuccessfulReconcile).NotTo(BeNil())
+	g.Expect(updated.Status.LastSuccessfulReconcile.Time).To(BeTemporally("==", fakeClock.Now()))
+	g.Expect(updated.Status.LastUpdated).NotTo(BeNil())
+	g.Expect(updated.Status.LastUpdated.Time).To(BeTemporally("==", fakeClock.Now()))
+}
+
+func TestMachineHealthCheckReconcileClusterSelector(t *testing.T) {
+	g := NewWithT(t)
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+			Labels:    map[string]string{"env": "dev"},
+		},
+	}
+	mhc := newMachineHealthCheckWithLabels("test-mhc", cluster.Namespace, cluster.Name, map[string]string{"nodepool": "a"})
+	machine := newTestMachine("machine", cluster.Namespace, cluster.Name, "node", mhc.Spec.Selector.MatchLabels)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster, mhc, machine).Build()
+	r := &MachineHealthCheckReconciler{
+		Client:          fakeClient,
+		recorder:        record.NewFakeRecorder(32),
+		ClusterSelector: labels.SelectorFromSet(labels.Set{"env": "prod"}),
+	}
+
+	// The MachineHealthCheck of a Cluster not matching the selector is left untouched, and is not looked at on
+	// changes of its Cluster.
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(mhc)})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	updated := &clusterv1.MachineHealthCheck{}
+	g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(mhc), updated)).To(Succeed())
+	g.Expect(updated.Status).To(Equal(mhc.Status))
+	g.Expect(updated.Status.LastUpdated).To(BeNil())
+	g.Expect(r.clusterToMachineHealthCheck(cluster)).To(BeEmpty())
+
+	// The MachineHealthChecks of the Clusters matching the selector are.
+	cluster.Labels["env"] = "prod"
+	g.Expect(r.clusterToMachineHealthCheck(cluster)).To(ConsistOf(reconcile.Request{NamespacedName: client.ObjectKeyFromObject(mhc)}))
+}
+
+// summaryTestLogger records the info lines logged at the default verbosity, along with the values of the logger.
+type summaryTestLogger struct {
+	log.NullLogger
+	values []interface{}
+	lines  *[]summaryTestLine
+}
+
+type summaryTestLine struct {
+	msg  string
+	data map[string]interface{}
+}
+
+func (l *summaryTestLogger) Info(msg string, keysAndValues ...interface{}) {
+	data := make(map[string]interface{})
+	keysAndValues = append(append([]interface{}{}, l.values...), keysAndValues...)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		data[keysAndValues[i].(string)] = keysAndValues[i+1]
+	}
+	*l.lines = append(*l.lines, summaryTestLine{msg: msg, data: data})
+}
+
+func (l *summaryTestLogger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	return &summaryTestLogger{
+		values: append(append([]interface{}{}, l.values...), keysAndValues...),
+		lines:  l.lines,
+	}
+}
+
+func (l *summaryTestLogger) V(level int) logr.Logger {
+	if level > 0 {
+		return log.NullLogger{}
+	}
+	return l
 }
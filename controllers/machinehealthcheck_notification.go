@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// remediationWebhookTimeout is the timeout of each request notifying the remediation webhook.
+const remediationWebhookTimeout = 10 * time.Second
+
+// remediationWebhookBackoff is the backoff between the attempts to notify the remediation webhook.
+var remediationWebhookBackoff = wait.Backoff{
+	Duration: 1 * time.Second,
+	Factor:   2,
+	Steps:    3,
+}
+
+// RemediationNotification is the JSON payload sent to the remediation webhook when a Machine is marked for remediation.
+type RemediationNotification struct {
+	// Cluster is the name of the Cluster the Machine belongs to.
+	Cluster string `json:"cluster"`
+	// Namespace is the namespace of the Cluster and of the Machine.
+	Namespace string `json:"namespace"`
+	// Machine is the name of the Machine marked for remediation.
+	Machine string `json:"machine"`
+	// Node is the name of the Node of the Machine, empty if the Machine has no Node.
+	Node string `json:"node,omitempty"`
+	// Reason is the reason why the Machine failed its health check.
+	Reason string `json:"reason"`
+	// Timestamp is the time the Machine was marked for remediation.
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+// notifyRemediationWebhook posts the notification to the remediation webhook in the background, if configured.
+// Failures are only logged, so that the webhook never blocks remediation. The retries are not bound to the
+// reconcile context, which is canceled as soon as the reconcile returns.
+func (r *MachineHealthCheckReconciler) notifyRemediationWebhook(logger logr.Logger, notification RemediationNotification) {
+	if r.RemediationWebhookURL == "" {
+		return
+	}
+
+	go func() {
+		if err := postRemediationNotification(context.Background(), r.RemediationWebhookURL, notification); err != nil {
+			logger.Error(err, "Failed to notify the remediation webhook", "machine", notification.Machine)
+		}
+	}()
+}
+
+// postRemediationNotification posts the notification to the given URL, retrying on failures.
+func postRemediationNotification(ctx context.Context, url string, notification RemediationNotification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal remediation notification")
+	}
+
+	httpClient := &http.Client{Timeout: remediationWebhookTimeout}
+	var lastErr error
+	if err := wait.ExponentialBackoff(remediationWebhookBackoff, func() (bool, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return false, errors.Wrap(err, "failed to create remediation webhook request")
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			lastErr = errors.Errorf("remediation webhook responded with status %d", resp.StatusCode)
+			return false, nil
+		}
+		return true, nil
+	}); err != nil {
+		if errors.Is(err, wait.ErrWaitTimeout) && lastErr != nil {
+			return lastErr
+		}
+		return err
+	}
+	return nil
+}
@@ -46,6 +46,10 @@ func (m machineHealthCheckStatusMatcher) Match(actual interface{}) (success bool
 	if !ok {
 		return ok, err
 	}
+	ok, err = Equal(m.expected.CurrentSuspect).Match(actualStatus.CurrentSuspect)
+	if !ok {
+		return ok, err
+	}
 	ok, err = Equal(m.expected.ExpectedMachines).Match(actualStatus.ExpectedMachines)
 	if !ok {
 		return ok, err
@@ -54,6 +58,18 @@ func (m machineHealthCheckStatusMatcher) Match(actual interface{}) (success bool
 	if !ok {
 		return ok, err
 	}
+	ok, err = Equal(m.expected.ObservedGeneration).Match(actualStatus.ObservedGeneration)
+	if !ok {
+		return ok, err
+	}
+	// LastUpdated depends on when the last reconciliation completed, so it is only expected to be set,
+	// and not to be older than the expected one if any.
+	if actualStatus.LastUpdated == nil {
+		return false, nil
+	}
+	if m.expected.LastUpdated != nil && actualStatus.LastUpdated.Before(m.expected.LastUpdated) {
+		return false, nil
+	}
 	ok, err = Equal(m.expected.Targets).Match(actualStatus.Targets)
 	if !ok {
 		return ok, err
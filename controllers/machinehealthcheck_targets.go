@@ -18,7 +18,9 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -26,8 +28,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
@@ -35,13 +42,18 @@ import (
 )
 
 const (
-	// Event types.
+	// maxHealthTransitions is the number of HealthCheckSucceeded condition transitions kept on a machine.
+	maxHealthTransitions = 10
 
-	// EventMachineMarkedUnhealthy is emitted when machine was successfully marked as unhealthy.
-	EventMachineMarkedUnhealthy string = "MachineMarkedUnhealthy"
-	// EventDetectedUnhealthy is emitted in case a node associated with a
-	// machine was detected unhealthy.
-	EventDetectedUnhealthy string = "DetectedUnhealthy"
+	// nodeRefGracePeriod is how long after the NodeRef of a machine has been set a node which can't be found is
+	// considered not visible yet through the cache of the workload cluster, rather than gone.
+	nodeRefGracePeriod = time.Minute
+
+	// defaultAPIUnreachableTimeout is the APIUnreachableTimeout of MachineHealthChecks which do not set it.
+	defaultAPIUnreachableTimeout = time.Minute
+
+	// kubeletProbeTimeout is the timeout of the probes of the kubelet through the API server of the workload cluster.
+	kubeletProbeTimeout = 10 * time.Second
 )
 
 // healthCheckTarget contains the information required to perform a health check
@@ -53,6 +65,12 @@ type healthCheckTarget struct {
 	MHC         *clusterv1.MachineHealthCheck
 	patchHelper *patch.Helper
 	nodeMissing bool
+	// nodeRefSet is when the NodeRef of the machine has been set, if known,
+	// only set when the node is missing.
+	nodeRefSet time.Time
+	// alertOnly is set when the target is only unhealthy because of
+	// AlertOnly conditions, in which case it must not be remediated.
+	alertOnly bool
 }
 
 func (t *healthCheckTarget) string() string {
@@ -72,35 +90,60 @@ func (t *healthCheckTarget) nodeName() string {
 	return ""
 }
 
+// Get the failure domain of the target's machine, if any.
+func (t *healthCheckTarget) failureDomain() string {
+	if t.Machine.Spec.FailureDomain != nil {
+		return *t.Machine.Spec.FailureDomain
+	}
+	return ""
+}
+
+// remediateOnFailureReason returns whether machines with a FailureReason or FailureMessage are unhealthy
+// for the given MachineHealthCheck, which is the case unless RemediateOnFailureReason is false.
+func remediateOnFailureReason(mhc *clusterv1.MachineHealthCheck) bool {
+	return mhc == nil || mhc.Spec.RemediateOnFailureReason == nil || *mhc.Spec.RemediateOnFailureReason
+}
+
 // Determine whether or not a given target needs remediation.
 // The node will need remediation if any of the following are true:
-// - The Machine has failed for some reason
+// - The Machine has failed for some reason, unless RemediateOnFailureReason is false
 // - The Machine did not get a node before `timeoutForMachineToHaveNode` elapses
-// - The Node has gone away
+// - The Node has gone away, unless it may still come back according to the NodeGonePolicy
 // - Any condition on the node is matched for the given timeout
+// Targets only matching AlertOnly conditions are reported as unhealthy too,
+// but are flagged so that they are not remediated.
 // If the target doesn't currently need rememdiation, provide a duration after
 // which the target should next be checked.
 // The target should be requeued after this duration.
-func (t *healthCheckTarget) needsRemediation(logger logr.Logger, timeoutForMachineToHaveNode time.Duration) (bool, time.Duration) {
+// The timeouts are evaluated at the given time, from the clock of the reconciler.
+func (t *healthCheckTarget) needsRemediation(logger logr.Logger, timeoutForMachineToHaveNode time.Duration, now time.Time) (bool, time.Duration) {
 	var nextCheckTimes []time.Duration
-	now := time.Now()
 
-	if t.Machine.Status.FailureReason != nil {
-		conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.MachineHasFailureReason, clusterv1.ConditionSeverityWarning, "FailureReason: %v", t.Machine.Status.FailureReason)
+	if t.Machine.Status.FailureReason != nil && remediateOnFailureReason(t.MHC) {
+		conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.MachineHasFailureReason, clusterv1.ConditionSeverityError, "FailureReason: %v", t.Machine.Status.FailureReason)
 		logger.V(3).Info("Target is unhealthy", "failureReason", t.Machine.Status.FailureReason)
 		return true, time.Duration(0)
 	}
 
-	if t.Machine.Status.FailureMessage != nil {
-		conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.MachineHasFailureReason, clusterv1.ConditionSeverityWarning, "FailureMessage: %v", t.Machine.Status.FailureMessage)
+	if t.Machine.Status.FailureMessage != nil && remediateOnFailureReason(t.MHC) {
+		conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.MachineHasFailureReason, clusterv1.ConditionSeverityError, "FailureMessage: %v", t.Machine.Status.FailureMessage)
 		logger.V(3).Info("Target is unhealthy", "failureMessage", t.Machine.Status.FailureMessage)
 		return true, time.Duration(0)
 	}
 
 	// the node does not exist
 	if t.nodeMissing {
+		// A machine which only just got its NodeRef may not see its node yet through the cache of the workload cluster.
+		if remaining := t.nodeRefSet.Add(nodeRefGracePeriod).Sub(now); remaining > 0 {
+			logger.V(3).Info("Target node is not visible yet, waiting for it", "timeUntilNodeGone", remaining.Truncate(time.Second).String())
+			return false, remaining
+		}
+		if remaining := t.nodeGoneGracePeriodRemaining(timeoutForMachineToHaveNode, now); remaining > 0 {
+			logger.V(3).Info("Target node is gone along with the machine infrastructure, waiting for it to come back", "timeUntilNodeGone", remaining.Truncate(time.Second).String())
+			return false, remaining
+		}
 		logger.V(3).Info("Target is unhealthy: node is missing")
-		conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.NodeNotFoundReason, clusterv1.ConditionSeverityWarning, "")
+		conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.NodeNotFoundReason, clusterv1.ConditionSeverityError, "")
 		return true, time.Duration(0)
 	}
 
@@ -148,6 +191,9 @@ func (t *healthCheckTarget) needsRemediation(logger logr.Logger, timeoutForMachi
 	}
 
 	// check conditions
+	alertOnly := false
+	var unhealthyWeight int32
+	var weighedConditions []string
 	for _, c := range t.MHC.Spec.UnhealthyConditions {
 		nodeCondition := getNodeCondition(t.Node, c.Type)
 
@@ -160,8 +206,19 @@ func (t *healthCheckTarget) needsRemediation(logger logr.Logger, timeoutForMachi
 		// If the condition has been in the unhealthy state for longer than the
 		// timeout, return true with no requeue time.
 		if nodeCondition.LastTransitionTime.Add(c.Timeout.Duration).Before(now) {
+			// With a weight threshold, the conditions are weighed together before deciding whether the target is unhealthy.
+			if t.MHC.Spec.UnhealthyWeightThreshold != nil && c.Action != clusterv1.UnhealthyConditionActionAlertOnly {
+				unhealthyWeight += unhealthyConditionWeight(c)
+				weighedConditions = append(weighedConditions, fmt.Sprintf("%s=%s", c.Type, c.Status))
+				continue
+			}
 			conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "Condition %s on node is reporting status %s for more than %s", c.Type, c.Status, c.Timeout.Duration.String())
 			logger.V(3).Info("Target is unhealthy: condition is in state longer than allowed timeout", "condition", c.Type, "state", c.Status, "timeout", c.Timeout.Duration.String())
+			// Keep checking the other conditions, as one of them might still require remediation.
+			if c.Action == clusterv1.UnhealthyConditionActionAlertOnly {
+				alertOnly = true
+				continue
+			}
 			return true, time.Duration(0)
 		}
 
@@ -171,13 +228,49 @@ func (t *healthCheckTarget) needsRemediation(logger logr.Logger, timeoutForMachi
 			nextCheckTimes = append(nextCheckTimes, nextCheck)
 		}
 	}
+	if threshold := t.MHC.Spec.UnhealthyWeightThreshold; threshold != nil && len(weighedConditions) > 0 {
+		if unhealthyWeight >= *threshold {
+			conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "Conditions %s on node have a combined weight of %d, reaching the threshold of %d", strings.Join(weighedConditions, ", "), unhealthyWeight, *threshold)
+			logger.V(3).Info("Target is unhealthy: conditions reach the weight threshold", "conditions", weighedConditions, "weight", unhealthyWeight, "threshold", *threshold)
+			return true, time.Duration(0)
+		}
+		logger.V(3).Info("Target conditions are below the weight threshold", "conditions", weighedConditions, "weight", unhealthyWeight, "threshold", *threshold)
+	}
+	// An alert-only target is still checked again once the conditions to remediate may time out.
+	if alertOnly {
+		t.alertOnly = true
+		return true, minDuration(nextCheckTimes)
+	}
 	return false, minDuration(nextCheckTimes)
 }
 
+// nodeGoneGracePeriodRemaining returns how long the missing node of the target is still given to come back. With the
+// Classify NodeGonePolicy, a node gone while the infrastructure of its machine is not ready may come back with it, and
+// is given the timeout from the time the infrastructure stopped being ready. A node gone while the machine is being
+// deleted or its infrastructure is ready was deleted on purpose, and is not given any, as with the Immediate policy.
+func (t *healthCheckTarget) nodeGoneGracePeriodRemaining(timeout time.Duration, now time.Time) time.Duration {
+	if t.MHC.Spec.NodeGonePolicy != clusterv1.NodeGonePolicyClassify || !t.Machine.DeletionTimestamp.IsZero() {
+		return 0
+	}
+	if !conditions.Has(t.Machine, clusterv1.InfrastructureReadyCondition) || conditions.IsTrue(t.Machine, clusterv1.InfrastructureReadyCondition) {
+		return 0
+	}
+	infrastructureLost := conditions.GetLastTransitionTime(t.Machine, clusterv1.InfrastructureReadyCondition).Time
+	return infrastructureLost.Add(timeout).Sub(now)
+}
+
+// unhealthyConditionWeight returns the weight of the unhealthy condition, which defaults to 1.
+func unhealthyConditionWeight(c clusterv1.UnhealthyCondition) int32 {
+	if c.Weight == nil {
+		return 1
+	}
+	return *c.Weight
+}
+
 // getTargetsFromMHC uses the MachineHealthCheck's selector to fetch machines
 // and their nodes targeted by the health check, ready for health checking.
 func (r *MachineHealthCheckReconciler) getTargetsFromMHC(ctx context.Context, logger logr.Logger, clusterClient client.Reader, cluster *clusterv1.Cluster, mhc *clusterv1.MachineHealthCheck) ([]healthCheckTarget, error) {
-	machines, err := r.getMachinesFromMHC(ctx, mhc)
+	machines, err := r.GetTargets(ctx, mhc)
 	if err != nil {
 		return nil, errors.Wrap(err, "error getting machines from MachineHealthCheck")
 	}
@@ -211,6 +304,7 @@ func (r *MachineHealthCheckReconciler) getTargetsFromMHC(ctx context.Context, lo
 
 			// A node has been seen for this machine, but it no longer exists
 			target.nodeMissing = true
+			target.nodeRefSet = nodeRefSetTime(target.Machine)
 		}
 		target.Node = node
 		targets = append(targets, target)
@@ -218,9 +312,9 @@ func (r *MachineHealthCheckReconciler) getTargetsFromMHC(ctx context.Context, lo
 	return targets, nil
 }
 
-// getMachinesFromMHC fetches Machines matched by the MachineHealthCheck's
-// label selector.
-func (r *MachineHealthCheckReconciler) getMachinesFromMHC(ctx context.Context, mhc *clusterv1.MachineHealthCheck) ([]clusterv1.Machine, error) {
+// GetTargets returns the Machines currently targeted by the MachineHealthCheck, i.e. the Machines of its
+// Cluster, in its namespace, matched by its label selector. Machines skipping remediation are included.
+func (r *MachineHealthCheckReconciler) GetTargets(ctx context.Context, mhc *clusterv1.MachineHealthCheck) ([]clusterv1.Machine, error) {
 	selector, err := metav1.LabelSelectorAsSelector(metav1.CloneSelectorAndAddLabel(
 		&mhc.Spec.Selector, clusterv1.ClusterLabelName, mhc.Spec.ClusterName,
 	))
@@ -240,6 +334,72 @@ func (r *MachineHealthCheckReconciler) getMachinesFromMHC(ctx context.Context, m
 	return machineList.Items, nil
 }
 
+// getMachineDeploymentSiblings returns the machines of the MachineDeployments of the targets which are not selected
+// by the MachineHealthCheck, e.g. those of the other MachineSets of a MachineDeployment being rolled out.
+func (r *MachineHealthCheckReconciler) getMachineDeploymentSiblings(ctx context.Context, mhc *clusterv1.MachineHealthCheck, targets []healthCheckTarget) ([]clusterv1.Machine, error) {
+	deployments := sets.NewString()
+	for _, t := range targets {
+		if name, ok := t.Machine.Labels[clusterv1.MachineDeploymentLabelName]; ok {
+			deployments.Insert(name)
+		}
+	}
+	if deployments.Len() == 0 {
+		return nil, nil
+	}
+
+	mhcSelector, err := metav1.LabelSelectorAsSelector(&mhc.Spec.Selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build selector")
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+		MatchLabels: map[string]string{clusterv1.ClusterLabelName: mhc.Spec.ClusterName},
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{
+				Key:      clusterv1.MachineDeploymentLabelName,
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   deployments.List(),
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build MachineDeployment selector")
+	}
+
+	var machineList clusterv1.MachineList
+	if err := r.Client.List(
+		ctx,
+		&machineList,
+		client.MatchingLabelsSelector{Selector: selector},
+		client.InNamespace(mhc.GetNamespace()),
+	); err != nil {
+		return nil, errors.Wrap(err, "failed to list machines")
+	}
+
+	siblings := []clusterv1.Machine{}
+	for _, machine := range machineList.Items {
+		if !mhcSelector.Matches(labels.Set(machine.Labels)) {
+			siblings = append(siblings, machine)
+		}
+	}
+	return siblings, nil
+}
+
+// nodeRefSetTime returns when the NodeRef of the machine has been set, i.e. when the machine controller found
+// its node and the NodeHealthy condition of the machine left the WaitingForNodeRef and NodeProvisioning reasons.
+// The zero time is returned if unknown, or if the machine controller already found the node gone, in which case no
+// grace period applies.
+func nodeRefSetTime(machine *clusterv1.Machine) time.Time {
+	condition := conditions.Get(machine, clusterv1.MachineNodeHealthyCondition)
+	if condition == nil {
+		return time.Time{}
+	}
+	switch condition.Reason {
+	case clusterv1.WaitingForNodeRefReason, clusterv1.NodeProvisioningReason, clusterv1.NodeNotFoundReason:
+		return time.Time{}
+	}
+	return condition.LastTransitionTime.Time
+}
+
 // getNodeFromMachine fetches the node from a local or remote cluster for a
 // given machine.
 func (r *MachineHealthCheckReconciler) getNodeFromMachine(ctx context.Context, clusterClient client.Reader, machine *clusterv1.Machine) (*corev1.Node, error) {
@@ -261,18 +421,50 @@ func (r *MachineHealthCheckReconciler) getNodeFromMachine(ctx context.Context, c
 
 // healthCheckTargets health checks a slice of targets
 // and gives a data to measure the average health.
-func (r *MachineHealthCheckReconciler) healthCheckTargets(targets []healthCheckTarget, logger logr.Logger, timeoutForMachineToHaveNode time.Duration) ([]healthCheckTarget, []healthCheckTarget, []time.Duration) {
+func (r *MachineHealthCheckReconciler) healthCheckTargets(ctx context.Context, targets []healthCheckTarget, logger logr.Logger, timeoutForMachineToHaveNode time.Duration) ([]healthCheckTarget, []healthCheckTarget, []time.Duration) {
 	var nextCheckTimes []time.Duration
 	var unhealthy []healthCheckTarget
 	var healthy []healthCheckTarget
+	now := r.now()
 
 	for _, t := range targets {
 		logger = logger.WithValues("Target", t.string())
 		logger.V(3).Info("Health checking target")
-		needsRemediation, nextCheck := t.needsRemediation(logger, timeoutForMachineToHaveNode)
+		// A machine held by a pre-terminate hook is already being deleted, e.g. waiting for the graceful shutdown
+		// of its node: it is neither flagged for remediation again nor counted as healthy, i.e. it counts as a
+		// remediation in progress.
+		if waitingForPreTerminateHook(t.Machine) {
+			logger.V(3).Info("Target is waiting for a pre-terminate hook, skipping it")
+			continue
+		}
+		previousStatus := healthCheckStatus(t.Machine)
+		needsRemediation, nextCheck := t.needsRemediation(logger, timeoutForMachineToHaveNode, now)
+		if (!needsRemediation || t.alertOnly) && r.evaluateHealth(ctx, logger, &t) {
+			needsRemediation, t.alertOnly = true, false
+		}
+		if needsRemediation && !t.alertOnly {
+			if wait := r.waitForAPIUnreachable(ctx, logger, t, now); wait > 0 {
+				nextCheckTimes = append(nextCheckTimes, wait)
+				continue
+			}
+		}
 
 		if needsRemediation {
+			if conditions.GetReason(t.Machine, clusterv1.MachineHealthCheckSuccededCondition) == clusterv1.NodeStartupTimeoutReason {
+				r.recorder.Eventf(
+					t.Machine,
+					corev1.EventTypeWarning,
+					EventReasonNodeStartupTimeout,
+					"Machine %v did not get a node within %s",
+					t.string(),
+					timeoutForMachineToHaveNode.String(),
+				)
+			}
+			recordHealthTransition(logger, t.Machine, previousStatus)
 			unhealthy = append(unhealthy, t)
+			if t.alertOnly && nextCheck > 0 {
+				nextCheckTimes = append(nextCheckTimes, nextCheck)
+			}
 			continue
 		}
 
@@ -281,7 +473,7 @@ func (r *MachineHealthCheckReconciler) healthCheckTargets(targets []healthCheckT
 			r.recorder.Eventf(
 				t.Machine,
 				corev1.EventTypeNormal,
-				EventDetectedUnhealthy,
+				EventReasonDetectedUnhealthy,
 				"Machine %v has unhealthy node %v",
 				t.string(),
 				t.nodeName(),
@@ -292,12 +484,325 @@ func (r *MachineHealthCheckReconciler) healthCheckTargets(targets []healthCheckT
 
 		if t.Machine.DeletionTimestamp.IsZero() {
 			conditions.MarkTrue(t.Machine, clusterv1.MachineHealthCheckSuccededCondition)
+			recordHealthTransition(logger, t.Machine, previousStatus)
 			healthy = append(healthy, t)
 		}
 	}
 	return healthy, unhealthy, nextCheckTimes
 }
 
+// isSuspect returns whether the node of the target meets one of the unhealthy conditions of the MachineHealthCheck,
+// but for less than its timeout, i.e. whether the target is likely to go unhealthy.
+func (t *healthCheckTarget) isSuspect(now time.Time) bool {
+	if t.Node == nil || !t.Machine.DeletionTimestamp.IsZero() {
+		return false
+	}
+	for _, c := range t.MHC.Spec.UnhealthyConditions {
+		nodeCondition := getNodeCondition(t.Node, c.Type)
+		if nodeCondition != nil && nodeCondition.Status == c.Status && !nodeCondition.LastTransitionTime.Add(c.Timeout.Duration).Before(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasStaleNodeStatus returns whether none of the conditions of the node of the target had a heartbeat for the given
+// timeout, including when the node has no conditions at all.
+func (t *healthCheckTarget) hasStaleNodeStatus(timeout time.Duration, now time.Time) bool {
+	if t.Node == nil {
+		return false
+	}
+	var lastHeartbeat time.Time
+	for _, c := range t.Node.Status.Conditions {
+		if c.LastHeartbeatTime.After(lastHeartbeat) {
+			lastHeartbeat = c.LastHeartbeatTime.Time
+		}
+	}
+	return lastHeartbeat.Add(timeout).Before(now)
+}
+
+// waitingForPreTerminateHook returns whether the machine is being deleted and holds a pre-terminate hook annotation.
+func waitingForPreTerminateHook(m *clusterv1.Machine) bool {
+	return !m.DeletionTimestamp.IsZero() && annotations.HasWithPrefix(clusterv1.PreTerminateDeleteHookAnnotationPrefix, m.Annotations)
+}
+
+// requireAPIUnreachable returns whether the given MachineHealthCheck requires the kubelet of an unhealthy node
+// to be unreachable through the API server of the cluster before remediating its machine.
+func requireAPIUnreachable(mhc *clusterv1.MachineHealthCheck) bool {
+	return mhc.Spec.RequireAPIUnreachable != nil && *mhc.Spec.RequireAPIUnreachable
+}
+
+// apiUnreachableTimeout returns how long the kubelet of an unhealthy node must be unreachable before its
+// machine is remediated.
+func apiUnreachableTimeout(mhc *clusterv1.MachineHealthCheck) time.Duration {
+	if mhc.Spec.APIUnreachableTimeout == nil {
+		return defaultAPIUnreachableTimeout
+	}
+	return mhc.Spec.APIUnreachableTimeout.Duration
+}
+
+// waitForAPIUnreachable returns, for a target whose node matches unhealthy conditions of a MachineHealthCheck
+// requiring it, how long to wait before remediating it. The kubelet of the node is probed through the API server
+// of the cluster: while it is reachable the node is only slow to report its conditions, otherwise the target is
+// remediated once the kubelet has been unreachable for APIUnreachableTimeout. The time of the first failed probe
+// is recorded on the machine, and patched right away as targets which are waited for are not patched afterwards.
+// Targets which are unhealthy for other reasons, e.g. a missing node, are not waited for.
+func (r *MachineHealthCheckReconciler) waitForAPIUnreachable(ctx context.Context, logger logr.Logger, t healthCheckTarget, now time.Time) time.Duration {
+	if !requireAPIUnreachable(t.MHC) || t.Node == nil || conditions.GetReason(t.Machine, clusterv1.MachineHealthCheckSuccededCondition) != clusterv1.UnhealthyNodeConditionReason {
+		return 0
+	}
+
+	timeout := apiUnreachableTimeout(t.MHC)
+	prober := r.nodeProber
+	if prober == nil {
+		prober = r.probeKubelet
+	}
+	reachable, err := prober(ctx, t.Cluster, t.Node.Name)
+	if err != nil {
+		// Never remediate because of a probe which could not be run.
+		logger.Error(err, "Failed to probe the kubelet of the target, deferring remediation")
+		return timeout
+	}
+
+	original := t.Machine.DeepCopy()
+	since, unreachable := t.Machine.GetAnnotations()[clusterv1.MachineAPIUnreachableSinceAnnotation]
+	if reachable && unreachable {
+		delete(t.Machine.Annotations, clusterv1.MachineAPIUnreachableSinceAnnotation)
+	} else if !reachable && !unreachable {
+		since = now.UTC().Format(time.RFC3339)
+		annotations.AddAnnotations(t.Machine, map[string]string{clusterv1.MachineAPIUnreachableSinceAnnotation: since})
+	}
+	if reachable == unreachable {
+		if err := r.Client.Patch(ctx, t.Machine, client.MergeFrom(original)); err != nil {
+			logger.Error(err, "Failed to record the kubelet reachability of the target, deferring remediation")
+			return timeout
+		}
+	}
+
+	if reachable {
+		logger.V(3).Info("Target node reports unhealthy conditions, but its kubelet is reachable through the API server, not remediating")
+		return timeout
+	}
+
+	unreachableSince, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		logger.Info("Ignoring malformed kubelet unreachability annotation", "annotation", since, "error", err.Error())
+		return 0
+	}
+	if remaining := unreachableSince.Add(timeout).Sub(now); remaining > 0 {
+		logger.V(3).Info("Target node kubelet is unreachable, waiting before remediating it", "timeUntilUnreachable", remaining.Truncate(time.Second).String())
+		return remaining
+	}
+	return 0
+}
+
+// probeKubelet returns whether the kubelet of the given node answers its health endpoint, proxied by the API server
+// of the cluster. An error is only returned when the probe can't be run.
+func (r *MachineHealthCheckReconciler) probeKubelet(ctx context.Context, cluster *clusterv1.Cluster, nodeName string) (bool, error) {
+	restConfig, err := remote.RESTConfig(ctx, machineHealthCheckEventSource, r.Client, util.ObjectKey(cluster))
+	if err != nil {
+		return false, errors.Wrap(err, "error creating a remote client")
+	}
+	restConfig.Timeout = kubeletProbeTimeout
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return false, errors.Wrap(err, "error creating a remote client")
+	}
+
+	if err := kubeClient.CoreV1().RESTClient().Get().Resource("nodes").Name(nodeName).SubResource("proxy").Suffix("healthz").Do(ctx).Error(); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+// evaluateHealth returns whether any of the health evaluators reports the given target unhealthy, in which case
+// the MachineHealthCheckSucceeded condition of its Machine is set to false with the reason given by the evaluator.
+// Evaluators returning an error are ignored, so that remediation is never triggered by a failing evaluator.
+func (r *MachineHealthCheckReconciler) evaluateHealth(ctx context.Context, logger logr.Logger, t *healthCheckTarget) bool {
+	if len(r.HealthEvaluators) == 0 {
+		return false
+	}
+
+	// Don't penalize any Machine/Node if the control plane has not been initialized, or if the cluster
+	// infrastructure is not ready, as for the built-in checks.
+	if !conditions.IsTrue(t.Cluster, clusterv1.ControlPlaneInitializedCondition) || !conditions.IsTrue(t.Cluster, clusterv1.InfrastructureReadyCondition) {
+		return false
+	}
+
+	for _, evaluator := range r.HealthEvaluators {
+		healthy, reason, err := evaluator.Evaluate(ctx, t.Machine, t.Node)
+		if err != nil {
+			logger.Error(err, "Failed to evaluate target health, ignoring health evaluator", "evaluator", fmt.Sprintf("%T", evaluator))
+			continue
+		}
+		if !healthy {
+			conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.HealthEvaluatorReportedUnhealthyReason, clusterv1.ConditionSeverityWarning, "%s", reason)
+			logger.V(3).Info("Target is unhealthy: reported by health evaluator", "evaluator", fmt.Sprintf("%T", evaluator), "reason", reason)
+			return true
+		}
+	}
+	return false
+}
+
+// splitFailureDomainOutages returns, for a failure domain aware MachineHealthCheck, the unhealthy targets
+// which can be remediated and the ones which belong to a failure domain where all the targets are unhealthy,
+// along with the names of these failure domains. Such a failure domain is considered to suffer from an
+// infrastructure outage, which remediating its machines can't fix.
+func splitFailureDomainOutages(mhc *clusterv1.MachineHealthCheck, targets, unhealthy []healthCheckTarget) ([]healthCheckTarget, []healthCheckTarget, []string) {
+	if mhc.Spec.FailureDomainAware == nil || !*mhc.Spec.FailureDomainAware {
+		return unhealthy, nil, nil
+	}
+
+	total := map[string]int{}
+	for _, t := range targets {
+		if fd := t.failureDomain(); fd != "" {
+			total[fd]++
+		}
+	}
+	unhealthyCount := map[string]int{}
+	for _, t := range unhealthy {
+		if fd := t.failureDomain(); fd != "" {
+			unhealthyCount[fd]++
+		}
+	}
+
+	down := sets.NewString()
+	for fd, count := range unhealthyCount {
+		// A single unhealthy machine can't be told apart from an outage of its failure domain.
+		if total[fd] > 1 && count == total[fd] {
+			down.Insert(fd)
+		}
+	}
+	if down.Len() == 0 {
+		return unhealthy, nil, nil
+	}
+
+	var remediable, outage []healthCheckTarget
+	for _, t := range unhealthy {
+		if down.Has(t.failureDomain()) {
+			outage = append(outage, t)
+			continue
+		}
+		remediable = append(remediable, t)
+	}
+	return remediable, outage, down.List()
+}
+
+// healthCheckStatus returns the status of the HealthCheckSucceeded condition of the machine, if any.
+func healthCheckStatus(m *clusterv1.Machine) corev1.ConditionStatus {
+	if c := conditions.Get(m, clusterv1.MachineHealthCheckSuccededCondition); c != nil {
+		return c.Status
+	}
+	return ""
+}
+
+// healthTransition is an entry of the MachineHealthCheckTransitionsAnnotation.
+type healthTransition struct {
+	Time   metav1.Time            `json:"time"`
+	Status corev1.ConditionStatus `json:"status"`
+}
+
+// recordHealthTransition appends the current status of the HealthCheckSucceeded condition to the transition
+// history of the machine, if it differs from the previous one. Only the last maxHealthTransitions are kept.
+func recordHealthTransition(logger logr.Logger, m *clusterv1.Machine, previousStatus corev1.ConditionStatus) {
+	condition := conditions.Get(m, clusterv1.MachineHealthCheckSuccededCondition)
+	if condition == nil || condition.Status == previousStatus {
+		return
+	}
+
+	var transitions []healthTransition
+	if value, ok := m.GetAnnotations()[clusterv1.MachineHealthCheckTransitionsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(value), &transitions); err != nil {
+			logger.Info("Resetting malformed health check transitions annotation", "annotation", value, "error", err.Error())
+			transitions = nil
+		}
+	}
+
+	transitions = append(transitions, healthTransition{Time: condition.LastTransitionTime, Status: condition.Status})
+	if len(transitions) > maxHealthTransitions {
+		transitions = transitions[len(transitions)-maxHealthTransitions:]
+	}
+
+	value, err := json.Marshal(transitions)
+	if err != nil {
+		logger.Error(err, "Failed to record health check transition")
+		return
+	}
+	annotations.AddAnnotations(m, map[string]string{clusterv1.MachineHealthCheckTransitionsAnnotation: string(value)})
+}
+
+// healthCheckExplanation is the value of the MachineHealthCheckExplanationAnnotation. It holds no time of the
+// evaluation, so that the machine is only patched when the decision changes.
+type healthCheckExplanation struct {
+	MachineHealthCheck string                          `json:"machineHealthCheck"`
+	Healthy            bool                            `json:"healthy"`
+	Reason             string                          `json:"reason,omitempty"`
+	Message            string                          `json:"message,omitempty"`
+	Conditions         []unhealthyConditionExplanation `json:"conditions,omitempty"`
+	Remediation        remediationExplanation          `json:"remediation"`
+}
+
+// unhealthyConditionExplanation explains the evaluation of an unhealthy condition against the node of a machine.
+type unhealthyConditionExplanation struct {
+	Type    corev1.NodeConditionType `json:"type"`
+	Status  corev1.ConditionStatus   `json:"status"`
+	Timeout metav1.Duration          `json:"timeout"`
+	// Since is when the node started meeting the condition, if it does.
+	Since    *metav1.Time `json:"since,omitempty"`
+	TimedOut bool         `json:"timedOut"`
+}
+
+// remediationExplanation explains whether the remediation of a machine is allowed.
+type remediationExplanation struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// remediationAllowedExplanation explains whether remediation is allowed as told by the RemediationAllowed condition
+// of the MachineHealthCheck.
+func remediationAllowedExplanation(m *clusterv1.MachineHealthCheck) remediationExplanation {
+	return remediationExplanation{
+		Allowed: conditions.IsTrue(m, clusterv1.RemediationAllowedCondition),
+		Reason:  conditions.GetReason(m, clusterv1.RemediationAllowedCondition),
+		Message: conditions.GetMessage(m, clusterv1.RemediationAllowedCondition),
+	}
+}
+
+// explainHealthCheck sets the MachineHealthCheckExplanationAnnotation of the machine of the target, from its
+// HealthCheckSucceeded condition, the unhealthy conditions of the MachineHealthCheck and the given remediation.
+func explainHealthCheck(logger logr.Logger, t healthCheckTarget, remediation remediationExplanation, now time.Time) {
+	explanation := healthCheckExplanation{
+		MachineHealthCheck: t.MHC.Name,
+		Healthy:            conditions.IsTrue(t.Machine, clusterv1.MachineHealthCheckSuccededCondition),
+		Reason:             conditions.GetReason(t.Machine, clusterv1.MachineHealthCheckSuccededCondition),
+		Message:            conditions.GetMessage(t.Machine, clusterv1.MachineHealthCheckSuccededCondition),
+		Remediation:        remediation,
+	}
+	for _, c := range t.MHC.Spec.UnhealthyConditions {
+		conditionExplanation := unhealthyConditionExplanation{
+			Type:    c.Type,
+			Status:  c.Status,
+			Timeout: c.Timeout,
+		}
+		if t.Node != nil {
+			if nodeCondition := getNodeCondition(t.Node, c.Type); nodeCondition != nil && nodeCondition.Status == c.Status {
+				since := nodeCondition.LastTransitionTime.Rfc3339Copy()
+				conditionExplanation.Since = &since
+				conditionExplanation.TimedOut = now.Sub(nodeCondition.LastTransitionTime.Time) > c.Timeout.Duration
+			}
+		}
+		explanation.Conditions = append(explanation.Conditions, conditionExplanation)
+	}
+
+	value, err := json.Marshal(explanation)
+	if err != nil {
+		logger.Error(err, "Failed to explain the health check decision")
+		return
+	}
+	annotations.AddAnnotations(t.Machine, map[string]string{clusterv1.MachineHealthCheckExplanationAnnotation: string(value)})
+}
+
 // getNodeCondition returns node condition by type.
 func getNodeCondition(node *corev1.Node, conditionType corev1.NodeConditionType) *corev1.NodeCondition {
 	for _, cond := range node.Status.Conditions {
@@ -17,15 +17,22 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -184,6 +191,112 @@ func TestGetTargetsFromMHC(t *testing.T) {
 	}
 }
 
+func TestGetTargets(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	namespace := "test-mhc"
+	clusterName := "test-cluster"
+	mhcSelector := map[string]string{"machine-group": "foo"}
+
+	mhc := &clusterv1.MachineHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-mhc",
+			Namespace: namespace,
+		},
+		Spec: clusterv1.MachineHealthCheckSpec{
+			ClusterName: clusterName,
+			Selector: metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "machine-group", Operator: metav1.LabelSelectorOpIn, Values: []string{"foo"}},
+				},
+			},
+		},
+	}
+
+	matching := newTestMachine("matching", namespace, clusterName, "node1", mhcSelector)
+	skipped := newTestMachine("skipped", namespace, clusterName, "node2", mhcSelector)
+	skipped.Annotations = map[string]string{clusterv1.MachineSkipRemediationAnnotation: ""}
+	otherLabels := newTestMachine("other-labels", namespace, clusterName, "node3", map[string]string{"machine-group": "bar"})
+	otherCluster := newTestMachine("other-cluster", namespace, "other-cluster", "node4", mhcSelector)
+	otherNamespace := newTestMachine("other-namespace", "other-namespace", clusterName, "node5", mhcSelector)
+
+	r := &MachineHealthCheckReconciler{
+		Client: fake.NewClientBuilder().WithObjects(mhc, matching, skipped, otherLabels, otherCluster, otherNamespace).Build(),
+	}
+
+	machines, err := r.GetTargets(ctx, mhc)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	names := make([]string, 0, len(machines))
+	for _, m := range machines {
+		names = append(names, m.Name)
+	}
+	g.Expect(names).To(ConsistOf("matching", "skipped"))
+}
+
+func TestGetTargetsFromMHCNodeRefGracePeriod(t *testing.T) {
+	g := NewWithT(t)
+
+	namespace := "test-mhc"
+	clusterName := "test-cluster"
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      clusterName,
+		},
+	}
+	conditions.MarkTrue(cluster, clusterv1.InfrastructureReadyCondition)
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+
+	mhcSelector := map[string]string{"cluster": clusterName, "machine-group": "foo"}
+	testMHC := newMachineHealthCheckWithLabels("test-mhc", namespace, clusterName, mhcSelector)
+
+	// The machine just got its node ref, but its node is not visible yet.
+	fresh := newTestMachine("fresh", namespace, clusterName, "fresh", mhcSelector)
+	conditions.MarkTrue(fresh, clusterv1.MachineNodeHealthyCondition)
+	// The node of the machine has been there for long, its node is gone.
+	existing := newTestMachine("existing", namespace, clusterName, "existing", mhcSelector)
+	conditions.Set(existing, &clusterv1.Condition{
+		Type:               clusterv1.MachineNodeHealthyCondition,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+	})
+	// The machine controller already found the node gone.
+	gone := newTestMachine("gone", namespace, clusterName, "gone", mhcSelector)
+	conditions.MarkFalse(gone, clusterv1.MachineNodeHealthyCondition, clusterv1.NodeNotFoundReason, clusterv1.ConditionSeverityError, "")
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	k8sClient := fake.NewClientBuilder().WithObjects(cluster, testMHC, fresh, existing, gone).Build()
+	reconciler := &MachineHealthCheckReconciler{
+		Client:   k8sClient,
+		recorder: record.NewFakeRecorder(5),
+	}
+
+	targets, err := reconciler.getTargetsFromMHC(ctx, ctrl.LoggerFrom(ctx), k8sClient, cluster, testMHC)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(targets).To(HaveLen(3))
+	for _, target := range targets {
+		g.Expect(target.nodeMissing).To(BeTrue())
+	}
+
+	// Only the fresh machine is not remediated during the grace period, but checked again once it has elapsed.
+	healthy, unhealthy, nextCheckTimes := reconciler.healthCheckTargets(ctx, targets, ctrl.LoggerFrom(ctx), 10*time.Minute)
+	g.Expect(healthy).To(BeEmpty())
+	g.Expect(unhealthy).To(HaveLen(2))
+	for _, target := range unhealthy {
+		g.Expect(target.Machine.Name).NotTo(Equal("fresh"))
+		g.Expect(conditions.GetReason(target.Machine, clusterv1.MachineHealthCheckSuccededCondition)).To(Equal(clusterv1.NodeNotFoundReason))
+	}
+	g.Expect(nextCheckTimes).To(HaveLen(1))
+	g.Expect(nextCheckTimes[0]).To(BeNumerically("~", nodeRefGracePeriod, 5*time.Second))
+
+	// Once the grace period has elapsed, the node is considered gone.
+	reconciler.clock = clock.NewFakeClock(time.Now().Add(2 * nodeRefGracePeriod))
+	_, unhealthy, _ = reconciler.healthCheckTargets(ctx, targets, ctrl.LoggerFrom(ctx), 10*time.Minute)
+	g.Expect(unhealthy).To(HaveLen(3))
+}
+
 func TestHealthCheckTargets(t *testing.T) {
 	namespace := "test-mhc"
 	clusterName := "test-cluster"
@@ -223,6 +336,12 @@ func TestHealthCheckTargets(t *testing.T) {
 					Status:  corev1.ConditionFalse,
 					Timeout: metav1.Duration{Duration: 5 * time.Minute},
 				},
+				{
+					Type:    corev1.NodeMemoryPressure,
+					Status:  corev1.ConditionTrue,
+					Timeout: metav1.Duration{Duration: 5 * time.Minute},
+					Action:  clusterv1.UnhealthyConditionActionAlertOnly,
+				},
 			},
 		},
 	}
@@ -280,6 +399,36 @@ func TestHealthCheckTargets(t *testing.T) {
 		nodeMissing: false,
 	}
 
+	// Target for when the node has been under memory pressure, an AlertOnly condition, for longer than the timeout
+	testNodeMemoryPressure400 := newTestUnhealthyNode("node1", corev1.NodeMemoryPressure, corev1.ConditionTrue, 400*time.Second)
+	nodeMemoryPressure400 := healthCheckTarget{
+		Cluster:     cluster,
+		MHC:         testMHC,
+		Machine:     testMachine,
+		Node:        testNodeMemoryPressure400,
+		nodeMissing: false,
+	}
+	nodeMemoryPressure400AlertOnly := nodeMemoryPressure400
+	nodeMemoryPressure400AlertOnly.alertOnly = true
+
+	// Target for when the node has been under memory pressure for longer than the timeout, and in an unknown state,
+	// a condition to remediate, for shorter than the timeout
+	testNodeMemoryPressure400Unknown200 := newTestUnhealthyNode("node1", corev1.NodeMemoryPressure, corev1.ConditionTrue, 400*time.Second)
+	testNodeMemoryPressure400Unknown200.Status.Conditions = append(testNodeMemoryPressure400Unknown200.Status.Conditions, corev1.NodeCondition{
+		Type:               corev1.NodeReady,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: metav1.NewTime(time.Now().Add(-200 * time.Second)),
+	})
+	nodeMemoryPressure400Unknown200 := healthCheckTarget{
+		Cluster:     cluster,
+		MHC:         testMHC,
+		Machine:     testMachine.DeepCopy(),
+		Node:        testNodeMemoryPressure400Unknown200,
+		nodeMissing: false,
+	}
+	nodeMemoryPressure400Unknown200AlertOnly := nodeMemoryPressure400Unknown200
+	nodeMemoryPressure400Unknown200AlertOnly.alertOnly = true
+
 	// Target for when a node is healthy
 	testNodeHealthy := newTestNode("node1")
 	testNodeHealthy.UID = "12345"
@@ -291,6 +440,72 @@ func TestHealthCheckTargets(t *testing.T) {
 		nodeMissing: false,
 	}
 
+	// Target for when the machine reports a FailureReason while its node is healthy
+	machineFailureReason := capierrors.UpdateMachineError
+	testMachineFailed := testMachine.DeepCopy()
+	testMachineFailed.Status.FailureReason = &machineFailureReason
+	machineFailed := healthCheckTarget{
+		Cluster:     cluster,
+		MHC:         testMHC,
+		Machine:     testMachineFailed,
+		Node:        testNodeHealthy,
+		nodeMissing: false,
+	}
+
+	// Target for when the machine reports a FailureReason, but the MHC does not remediate on it
+	testMHCIgnoreFailureReason := testMHC.DeepCopy()
+	testMHCIgnoreFailureReason.Spec.RemediateOnFailureReason = pointer.BoolPtr(false)
+	machineFailedIgnored := healthCheckTarget{
+		Cluster:     cluster,
+		MHC:         testMHCIgnoreFailureReason,
+		Machine:     testMachineFailed.DeepCopy(),
+		Node:        testNodeHealthy,
+		nodeMissing: false,
+	}
+
+	// Targets for an MHC where the node is only unhealthy once conditions of a combined weight of 2 are met
+	testMHCWeighted := testMHC.DeepCopy()
+	testMHCWeighted.Spec.UnhealthyWeightThreshold = pointer.Int32Ptr(2)
+	testMHCWeighted.Spec.UnhealthyConditions = append(testMHCWeighted.Spec.UnhealthyConditions, clusterv1.UnhealthyCondition{
+		Type:    corev1.NodeDiskPressure,
+		Status:  corev1.ConditionTrue,
+		Timeout: metav1.Duration{Duration: 5 * time.Minute},
+	})
+	nodeUnknown400BelowThreshold := healthCheckTarget{
+		Cluster: cluster,
+		MHC:     testMHCWeighted,
+		Machine: testMachine.DeepCopy(),
+		Node:    testNodeUnknown400,
+	}
+	testNodeUnknown400DiskPressure200 := newTestUnhealthyNode("node1", corev1.NodeReady, corev1.ConditionUnknown, 400*time.Second)
+	testNodeUnknown400DiskPressure200.Status.Conditions = append(testNodeUnknown400DiskPressure200.Status.Conditions, corev1.NodeCondition{
+		Type:               corev1.NodeDiskPressure,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(time.Now().Add(-200 * time.Second)),
+	})
+	nodeUnknown400DiskPressure200 := healthCheckTarget{
+		Cluster: cluster,
+		MHC:     testMHCWeighted,
+		Machine: testMachine.DeepCopy(),
+		Node:    testNodeUnknown400DiskPressure200,
+	}
+	testNodeUnknown400DiskPressure400 := testNodeUnknown400DiskPressure200.DeepCopy()
+	testNodeUnknown400DiskPressure400.Status.Conditions[1].LastTransitionTime = metav1.NewTime(time.Now().Add(-400 * time.Second))
+	nodeUnknown400DiskPressure400 := healthCheckTarget{
+		Cluster: cluster,
+		MHC:     testMHCWeighted,
+		Machine: testMachine.DeepCopy(),
+		Node:    testNodeUnknown400DiskPressure400,
+	}
+	testMHCWeightedReady := testMHCWeighted.DeepCopy()
+	testMHCWeightedReady.Spec.UnhealthyConditions[0].Weight = pointer.Int32Ptr(2)
+	nodeUnknown400HeavyCondition := healthCheckTarget{
+		Cluster: cluster,
+		MHC:     testMHCWeightedReady,
+		Machine: testMachine.DeepCopy(),
+		Node:    testNodeUnknown400,
+	}
+
 	testCases := []struct {
 		desc                     string
 		targets                  []healthCheckTarget
@@ -326,6 +541,20 @@ func TestHealthCheckTargets(t *testing.T) {
 			expectedNeedsRemediation: []healthCheckTarget{nodeUnknown400},
 			expectedNextCheckTimes:   []time.Duration{},
 		},
+		{
+			desc:                     "when the node has matched an AlertOnly condition for longer than the timeout",
+			targets:                  []healthCheckTarget{nodeMemoryPressure400},
+			expectedHealthy:          []healthCheckTarget{},
+			expectedNeedsRemediation: []healthCheckTarget{nodeMemoryPressure400AlertOnly},
+			expectedNextCheckTimes:   []time.Duration{},
+		},
+		{
+			desc:                     "when the node has matched an AlertOnly condition for longer than the timeout, and another condition for shorter than the timeout",
+			targets:                  []healthCheckTarget{nodeMemoryPressure400Unknown200},
+			expectedHealthy:          []healthCheckTarget{},
+			expectedNeedsRemediation: []healthCheckTarget{nodeMemoryPressure400Unknown200AlertOnly},
+			expectedNextCheckTimes:   []time.Duration{100 * time.Second},
+		},
 		{
 			desc:                     "when the node is healthy",
 			targets:                  []healthCheckTarget{nodeHealthy},
@@ -333,6 +562,48 @@ func TestHealthCheckTargets(t *testing.T) {
 			expectedNeedsRemediation: []healthCheckTarget{},
 			expectedNextCheckTimes:   []time.Duration{},
 		},
+		{
+			desc:                     "when the machine has a failure reason",
+			targets:                  []healthCheckTarget{machineFailed},
+			expectedHealthy:          []healthCheckTarget{},
+			expectedNeedsRemediation: []healthCheckTarget{machineFailed},
+			expectedNextCheckTimes:   []time.Duration{},
+		},
+		{
+			desc:                     "when the machine has a failure reason and RemediateOnFailureReason is false",
+			targets:                  []healthCheckTarget{machineFailedIgnored},
+			expectedHealthy:          []healthCheckTarget{machineFailedIgnored},
+			expectedNeedsRemediation: []healthCheckTarget{},
+			expectedNextCheckTimes:   []time.Duration{},
+		},
+		{
+			desc:                     "when the node has met conditions below the weight threshold for longer than their timeouts",
+			targets:                  []healthCheckTarget{nodeUnknown400BelowThreshold},
+			expectedHealthy:          []healthCheckTarget{nodeUnknown400BelowThreshold},
+			expectedNeedsRemediation: []healthCheckTarget{},
+			expectedNextCheckTimes:   []time.Duration{},
+		},
+		{
+			desc:                     "when the node has met conditions reaching the weight threshold, but not all for longer than their timeouts",
+			targets:                  []healthCheckTarget{nodeUnknown400DiskPressure200},
+			expectedHealthy:          []healthCheckTarget{},
+			expectedNeedsRemediation: []healthCheckTarget{},
+			expectedNextCheckTimes:   []time.Duration{100 * time.Second},
+		},
+		{
+			desc:                     "when the node has met conditions reaching the weight threshold for longer than their timeouts",
+			targets:                  []healthCheckTarget{nodeUnknown400DiskPressure400},
+			expectedHealthy:          []healthCheckTarget{},
+			expectedNeedsRemediation: []healthCheckTarget{nodeUnknown400DiskPressure400},
+			expectedNextCheckTimes:   []time.Duration{},
+		},
+		{
+			desc:                     "when the node has met a condition whose weight reaches the threshold for longer than its timeout",
+			targets:                  []healthCheckTarget{nodeUnknown400HeavyCondition},
+			expectedHealthy:          []healthCheckTarget{},
+			expectedNeedsRemediation: []healthCheckTarget{nodeUnknown400HeavyCondition},
+			expectedNextCheckTimes:   []time.Duration{},
+		},
 		{
 			desc:                     "with a mix of healthy and unhealthy nodes",
 			targets:                  []healthCheckTarget{nodeUnknown100, nodeUnknown200, nodeUnknown400, nodeHealthy},
@@ -351,7 +622,7 @@ func TestHealthCheckTargets(t *testing.T) {
 				recorder: record.NewFakeRecorder(5),
 			}
 
-			healthy, unhealthy, nextCheckTimes := reconciler.healthCheckTargets(tc.targets, ctrl.LoggerFrom(ctx), timeoutForMachineToHaveNode)
+			healthy, unhealthy, nextCheckTimes := reconciler.healthCheckTargets(ctx, tc.targets, ctrl.LoggerFrom(ctx), timeoutForMachineToHaveNode)
 
 			// Round durations down to nearest second account for minute differences
 			// in timing when running tests
@@ -370,6 +641,568 @@ func TestHealthCheckTargets(t *testing.T) {
 	}
 }
 
+func TestHealthCheckTargetsTransitionHistory(t *testing.T) {
+	g := NewWithT(t)
+
+	namespace := "test-mhc"
+	clusterName := "test-cluster"
+	mhcSelector := map[string]string{"cluster": clusterName, "machine-group": "foo"}
+	timeoutForMachineToHaveNode := 10 * time.Minute
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      clusterName,
+		},
+	}
+	conditions.MarkTrue(cluster, clusterv1.InfrastructureReadyCondition)
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+
+	testMHC := newMachineHealthCheck(namespace, clusterName)
+	testMHC.Spec.UnhealthyConditions = []clusterv1.UnhealthyCondition{
+		{
+			Type:    corev1.NodeReady,
+			Status:  corev1.ConditionUnknown,
+			Timeout: metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+
+	machine := newTestMachine("machine1", namespace, clusterName, "node1", mhcSelector)
+	healthyNode := newTestNode("node1")
+	unhealthyNode := newTestUnhealthyNode("node1", corev1.NodeReady, corev1.ConditionUnknown, 10*time.Minute)
+
+	reconciler := &MachineHealthCheckReconciler{
+		recorder: record.NewFakeRecorder(100),
+	}
+	check := func(node *corev1.Node) []healthTransition {
+		target := healthCheckTarget{
+			Cluster: cluster,
+			MHC:     testMHC,
+			Machine: machine,
+			Node:    node,
+		}
+		reconciler.healthCheckTargets(ctx, []healthCheckTarget{target}, ctrl.LoggerFrom(ctx), timeoutForMachineToHaveNode)
+
+		var transitions []healthTransition
+		g.Expect(json.Unmarshal([]byte(machine.Annotations[clusterv1.MachineHealthCheckTransitionsAnnotation]), &transitions)).To(Succeed())
+		return transitions
+	}
+	statuses := func(transitions []healthTransition) []corev1.ConditionStatus {
+		out := []corev1.ConditionStatus{}
+		for _, t := range transitions {
+			out = append(out, t.Status)
+		}
+		return out
+	}
+
+	// The first health check is recorded.
+	g.Expect(statuses(check(healthyNode))).To(Equal([]corev1.ConditionStatus{corev1.ConditionTrue}))
+
+	// Only actual transitions are recorded.
+	g.Expect(statuses(check(healthyNode))).To(Equal([]corev1.ConditionStatus{corev1.ConditionTrue}))
+	g.Expect(statuses(check(unhealthyNode))).To(Equal([]corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionFalse}))
+	g.Expect(statuses(check(unhealthyNode))).To(Equal([]corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionFalse}))
+	g.Expect(statuses(check(healthyNode))).To(Equal([]corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionTrue}))
+
+	// The history is capped, dropping the oldest transitions.
+	for i := 0; i < maxHealthTransitions; i++ {
+		if i%2 == 0 {
+			check(unhealthyNode)
+		} else {
+			check(healthyNode)
+		}
+	}
+	transitions := check(unhealthyNode)
+	g.Expect(transitions).To(HaveLen(maxHealthTransitions))
+	g.Expect(transitions[0].Status).To(Equal(corev1.ConditionTrue))
+	g.Expect(transitions[maxHealthTransitions-1].Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(transitions[maxHealthTransitions-1].Time.Unix()).To(Equal(conditions.GetLastTransitionTime(machine, clusterv1.MachineHealthCheckSuccededCondition).Unix()))
+}
+
+func TestSplitFailureDomainOutages(t *testing.T) {
+	namespace := "test-mhc"
+	clusterName := "test-cluster"
+	mhcSelector := map[string]string{"cluster": clusterName, "machine-group": "foo"}
+
+	newTarget := func(name string, failureDomain *string) healthCheckTarget {
+		machine := newTestMachine(name, namespace, clusterName, name, mhcSelector)
+		machine.Spec.FailureDomain = failureDomain
+		return healthCheckTarget{Machine: machine}
+	}
+	fdA, fdB, fdC := "fd-a", "fd-b", "fd-c"
+
+	// fd-a is down, fd-b has one of two machines unhealthy, fd-c only has a single machine.
+	downA1 := newTarget("down-a-1", &fdA)
+	downA2 := newTarget("down-a-2", &fdA)
+	unhealthyB := newTarget("unhealthy-b", &fdB)
+	healthyB := newTarget("healthy-b", &fdB)
+	unhealthyC := newTarget("unhealthy-c", &fdC)
+	unhealthyNoFailureDomain := newTarget("unhealthy-none", nil)
+
+	targets := []healthCheckTarget{downA1, downA2, unhealthyB, healthyB, unhealthyC, unhealthyNoFailureDomain}
+	unhealthy := []healthCheckTarget{downA1, downA2, unhealthyB, unhealthyC, unhealthyNoFailureDomain}
+
+	t.Run("when the MachineHealthCheck is failure domain aware", func(t *testing.T) {
+		g := NewWithT(t)
+
+		mhc := newMachineHealthCheck(namespace, clusterName)
+		mhc.Spec.FailureDomainAware = pointer.BoolPtr(true)
+
+		remediable, outage, domains := splitFailureDomainOutages(mhc, targets, unhealthy)
+		g.Expect(remediable).To(ConsistOf(unhealthyB, unhealthyC, unhealthyNoFailureDomain))
+		g.Expect(outage).To(ConsistOf(downA1, downA2))
+		g.Expect(domains).To(ConsistOf(fdA))
+	})
+
+	t.Run("when the MachineHealthCheck is not failure domain aware", func(t *testing.T) {
+		g := NewWithT(t)
+
+		mhc := newMachineHealthCheck(namespace, clusterName)
+
+		remediable, outage, domains := splitFailureDomainOutages(mhc, targets, unhealthy)
+		g.Expect(remediable).To(Equal(unhealthy))
+		g.Expect(outage).To(BeEmpty())
+		g.Expect(domains).To(BeEmpty())
+	})
+}
+
+func TestHealthCheckTargetsEvents(t *testing.T) {
+	g := NewWithT(t)
+
+	namespace := "test-mhc"
+	clusterName := "test-cluster"
+	timeoutForMachineToHaveNode := 10 * time.Minute
+	nowMinus20m := metav1.NewTime(time.Now().Add(-20 * time.Minute))
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      clusterName,
+		},
+	}
+	conditions.Set(cluster, &clusterv1.Condition{Type: clusterv1.InfrastructureReadyCondition, Status: corev1.ConditionTrue, LastTransitionTime: nowMinus20m})
+	conditions.Set(cluster, &clusterv1.Condition{Type: clusterv1.ControlPlaneInitializedCondition, Status: corev1.ConditionTrue, LastTransitionTime: nowMinus20m})
+
+	testMHC := newMachineHealthCheck(namespace, clusterName)
+	testMHC.Spec.UnhealthyConditions = []clusterv1.UnhealthyCondition{
+		{
+			Type:    corev1.NodeReady,
+			Status:  corev1.ConditionUnknown,
+			Timeout: metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+
+	// Target for when the Machine did not get a Node within the timeout
+	nodeNotStartedMachine := newTestMachine("machine1", namespace, clusterName, "", nil)
+	nodeNotStartedMachine.Status.NodeRef = nil
+	nodeNotStartedMachine.CreationTimestamp = nowMinus20m
+	nodeNotStarted := healthCheckTarget{
+		Cluster: cluster,
+		MHC:     testMHC,
+		Machine: nodeNotStartedMachine,
+	}
+
+	// Target for when the node has been in an unknown state for shorter than the timeout
+	nodeUnknown200 := healthCheckTarget{
+		Cluster: cluster,
+		MHC:     testMHC,
+		Machine: newTestMachine("machine2", namespace, clusterName, "node2", nil),
+		Node:    newTestUnhealthyNode("node2", corev1.NodeReady, corev1.ConditionUnknown, 200*time.Second),
+	}
+
+	recorder := record.NewFakeRecorder(5)
+	reconciler := &MachineHealthCheckReconciler{
+		recorder: recorder,
+	}
+	_, unhealthy, _ := reconciler.healthCheckTargets(ctx, []healthCheckTarget{nodeNotStarted, nodeUnknown200}, ctrl.LoggerFrom(ctx), timeoutForMachineToHaveNode)
+	g.Expect(unhealthy).To(ConsistOf(nodeNotStarted))
+
+	g.Expect(recorder.Events).To(HaveLen(2))
+	g.Expect(<-recorder.Events).To(HavePrefix(corev1.EventTypeWarning + " " + EventReasonNodeStartupTimeout + " "))
+	g.Expect(<-recorder.Events).To(HavePrefix(corev1.EventTypeNormal + " " + EventReasonDetectedUnhealthy + " "))
+}
+
+func TestHealthCheckTargetsConditionSeverity(t *testing.T) {
+	namespace := "test-mhc"
+	clusterName := "test-cluster"
+	timeoutForMachineToHaveNode := 10 * time.Minute
+	nowMinus20m := metav1.NewTime(time.Now().Add(-20 * time.Minute))
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      clusterName,
+		},
+	}
+	conditions.Set(cluster, &clusterv1.Condition{Type: clusterv1.InfrastructureReadyCondition, Status: corev1.ConditionTrue, LastTransitionTime: nowMinus20m})
+	conditions.Set(cluster, &clusterv1.Condition{Type: clusterv1.ControlPlaneInitializedCondition, Status: corev1.ConditionTrue, LastTransitionTime: nowMinus20m})
+
+	testMHC := newMachineHealthCheck(namespace, clusterName)
+	testMHC.Spec.UnhealthyConditions = []clusterv1.UnhealthyCondition{
+		{
+			Type:    corev1.NodeReady,
+			Status:  corev1.ConditionUnknown,
+			Timeout: metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+
+	nodeNotStartedMachine := newTestMachine("machine1", namespace, clusterName, "", nil)
+	nodeNotStartedMachine.Status.NodeRef = nil
+	nodeNotStartedMachine.CreationTimestamp = nowMinus20m
+
+	failedMachine := newTestMachine("machine1", namespace, clusterName, "node1", nil)
+	failureReason := capierrors.UpdateMachineError
+	failedMachine.Status.FailureReason = &failureReason
+
+	testCases := []struct {
+		desc             string
+		target           healthCheckTarget
+		expectedReason   string
+		expectedSeverity clusterv1.ConditionSeverity
+	}{
+		{
+			desc: "when the node has not started within the timeout",
+			target: healthCheckTarget{
+				Cluster: cluster,
+				MHC:     testMHC,
+				Machine: nodeNotStartedMachine,
+			},
+			expectedReason:   clusterv1.NodeStartupTimeoutReason,
+			expectedSeverity: clusterv1.ConditionSeverityWarning,
+		},
+		{
+			desc: "when the node has been in an unknown state for longer than the timeout",
+			target: healthCheckTarget{
+				Cluster: cluster,
+				MHC:     testMHC,
+				Machine: newTestMachine("machine1", namespace, clusterName, "node1", nil),
+				Node:    newTestUnhealthyNode("node1", corev1.NodeReady, corev1.ConditionUnknown, 400*time.Second),
+			},
+			expectedReason:   clusterv1.UnhealthyNodeConditionReason,
+			expectedSeverity: clusterv1.ConditionSeverityWarning,
+		},
+		{
+			desc: "when the node has gone away",
+			target: healthCheckTarget{
+				Cluster:     cluster,
+				MHC:         testMHC,
+				Machine:     newTestMachine("machine1", namespace, clusterName, "node1", nil),
+				Node:        &corev1.Node{},
+				nodeMissing: true,
+			},
+			expectedReason:   clusterv1.NodeNotFoundReason,
+			expectedSeverity: clusterv1.ConditionSeverityError,
+		},
+		{
+			desc: "when the machine has a failure reason",
+			target: healthCheckTarget{
+				Cluster: cluster,
+				MHC:     testMHC,
+				Machine: failedMachine,
+				Node:    newTestNode("node1"),
+			},
+			expectedReason:   clusterv1.MachineHasFailureReason,
+			expectedSeverity: clusterv1.ConditionSeverityError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			g := NewWithT(t)
+
+			reconciler := &MachineHealthCheckReconciler{
+				recorder: record.NewFakeRecorder(5),
+			}
+			_, unhealthy, _ := reconciler.healthCheckTargets(ctx, []healthCheckTarget{tc.target}, ctrl.LoggerFrom(ctx), timeoutForMachineToHaveNode)
+			g.Expect(unhealthy).To(HaveLen(1))
+
+			condition := conditions.Get(unhealthy[0].Machine, clusterv1.MachineHealthCheckSuccededCondition)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+			g.Expect(condition.Reason).To(Equal(tc.expectedReason))
+			g.Expect(condition.Severity).To(Equal(tc.expectedSeverity))
+		})
+	}
+}
+
+func TestHealthCheckTargetsPreTerminateHook(t *testing.T) {
+	g := NewWithT(t)
+
+	namespace := "test-mhc"
+	clusterName := "test-cluster"
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      clusterName,
+		},
+	}
+	testMHC := newMachineHealthCheck(namespace, clusterName)
+
+	// The node of the machine is gone, as it is being shut down by the pre-terminate hook.
+	hookedMachine := newTestMachine("machine1", namespace, clusterName, "node1", nil)
+	hookedMachine.Annotations = map[string]string{clusterv1.PreTerminateDeleteHookAnnotationPrefix + "/graceful-shutdown": "hook-owner"}
+	now := metav1.Now()
+	hookedMachine.DeletionTimestamp = &now
+	target := healthCheckTarget{
+		Cluster:     cluster,
+		MHC:         testMHC,
+		Machine:     hookedMachine,
+		Node:        &corev1.Node{},
+		nodeMissing: true,
+	}
+
+	reconciler := &MachineHealthCheckReconciler{
+		recorder: record.NewFakeRecorder(5),
+	}
+	healthy, unhealthy, nextCheckTimes := reconciler.healthCheckTargets(ctx, []healthCheckTarget{target}, ctrl.LoggerFrom(ctx), 10*time.Minute)
+	g.Expect(healthy).To(BeEmpty())
+	g.Expect(unhealthy).To(BeEmpty())
+	g.Expect(nextCheckTimes).To(BeEmpty())
+	g.Expect(conditions.Has(hookedMachine, clusterv1.MachineHealthCheckSuccededCondition)).To(BeFalse())
+	g.Expect(conditions.Has(hookedMachine, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+
+	// The hook is only honored while the machine is being deleted.
+	hookedMachine.DeletionTimestamp = nil
+	_, unhealthy, _ = reconciler.healthCheckTargets(ctx, []healthCheckTarget{target}, ctrl.LoggerFrom(ctx), 10*time.Minute)
+	g.Expect(unhealthy).To(HaveLen(1))
+}
+
+// stubHealthEvaluator is a HealthEvaluator reporting the Machines named in unhealthy as unhealthy,
+// and failing for the ones named in failing.
+type stubHealthEvaluator struct {
+	unhealthy sets.String
+	failing   sets.String
+}
+
+func (e *stubHealthEvaluator) Evaluate(_ context.Context, machine *clusterv1.Machine, _ *corev1.Node) (bool, string, error) {
+	if e.failing.Has(machine.Name) {
+		return false, "", errors.New("BMC unreachable")
+	}
+	if e.unhealthy.Has(machine.Name) {
+		return false, "BMC reports a failed power supply", nil
+	}
+	return true, "", nil
+}
+
+func TestHealthCheckTargetsHealthEvaluators(t *testing.T) {
+	g := NewWithT(t)
+
+	namespace := "test-mhc"
+	clusterName := "test-cluster"
+	timeoutForMachineToHaveNode := 10 * time.Minute
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      clusterName,
+		},
+	}
+	conditions.MarkTrue(cluster, clusterv1.InfrastructureReadyCondition)
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+
+	testMHC := newMachineHealthCheck(namespace, clusterName)
+	testMHC.Spec.UnhealthyConditions = []clusterv1.UnhealthyCondition{
+		{
+			Type:    corev1.NodeReady,
+			Status:  corev1.ConditionUnknown,
+			Timeout: metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+
+	// Targets which look healthy to the built-in checks.
+	newHealthyTarget := func(name string) healthCheckTarget {
+		return healthCheckTarget{
+			Cluster: cluster,
+			MHC:     testMHC,
+			Machine: newTestMachine(name, namespace, clusterName, name, nil),
+			Node:    newTestNode(name),
+		}
+	}
+	healthy := newHealthyTarget("healthy")
+	flagged := newHealthyTarget("flagged")
+	failing := newHealthyTarget("failing")
+
+	reconciler := &MachineHealthCheckReconciler{
+		recorder: record.NewFakeRecorder(5),
+		HealthEvaluators: []HealthEvaluator{
+			&stubHealthEvaluator{},
+			&stubHealthEvaluator{unhealthy: sets.NewString("flagged"), failing: sets.NewString("failing")},
+		},
+	}
+	healthyTargets, unhealthyTargets, _ := reconciler.healthCheckTargets(ctx, []healthCheckTarget{healthy, flagged, failing}, ctrl.LoggerFrom(ctx), timeoutForMachineToHaveNode)
+
+	// The machine flagged by the evaluator is unhealthy, the failing evaluator is ignored.
+	g.Expect(healthyTargets).To(ConsistOf(healthy, failing))
+	g.Expect(unhealthyTargets).To(ConsistOf(flagged))
+	condition := conditions.Get(flagged.Machine, clusterv1.MachineHealthCheckSuccededCondition)
+	g.Expect(condition).NotTo(BeNil())
+	g.Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(condition.Reason).To(Equal(clusterv1.HealthEvaluatorReportedUnhealthyReason))
+	g.Expect(condition.Message).To(Equal("BMC reports a failed power supply"))
+
+	// Evaluators are not consulted before the control plane is initialized.
+	conditions.MarkFalse(cluster, clusterv1.ControlPlaneInitializedCondition, clusterv1.WaitingForControlPlaneProviderInitializedReason, clusterv1.ConditionSeverityInfo, "")
+	_, unhealthyTargets, _ = reconciler.healthCheckTargets(ctx, []healthCheckTarget{newHealthyTarget("flagged")}, ctrl.LoggerFrom(ctx), timeoutForMachineToHaveNode)
+	g.Expect(unhealthyTargets).To(BeEmpty())
+}
+
+func TestHealthCheckTargetsRequireAPIUnreachable(t *testing.T) {
+	g := NewWithT(t)
+
+	namespace := "test-mhc"
+	clusterName := "test-cluster"
+	timeoutForMachineToHaveNode := 10 * time.Minute
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      clusterName,
+		},
+	}
+	conditions.MarkTrue(cluster, clusterv1.InfrastructureReadyCondition)
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+
+	testMHC := newMachineHealthCheck(namespace, clusterName)
+	testMHC.Spec.RequireAPIUnreachable = pointer.BoolPtr(true)
+	testMHC.Spec.APIUnreachableTimeout = &metav1.Duration{Duration: 5 * time.Minute}
+	testMHC.Spec.UnhealthyConditions = []clusterv1.UnhealthyCondition{
+		{
+			Type:    corev1.NodeReady,
+			Status:  corev1.ConditionUnknown,
+			Timeout: metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+
+	// Targets whose node reported Ready=Unknown for longer than the timeout.
+	stale := healthCheckTarget{
+		Cluster: cluster,
+		MHC:     testMHC,
+		Machine: newTestMachine("stale", namespace, clusterName, "stale", nil),
+		Node:    newTestUnhealthyNode("stale", corev1.NodeReady, corev1.ConditionUnknown, 10*time.Minute),
+	}
+	stale.Machine.Annotations = map[string]string{
+		clusterv1.MachineAPIUnreachableSinceAnnotation: time.Now().Add(-10 * time.Minute).UTC().Format(time.RFC3339),
+	}
+	newlyUnreachable := healthCheckTarget{
+		Cluster: cluster,
+		MHC:     testMHC,
+		Machine: newTestMachine("newly-unreachable", namespace, clusterName, "newly-unreachable", nil),
+		Node:    newTestUnhealthyNode("newly-unreachable", corev1.NodeReady, corev1.ConditionUnknown, 10*time.Minute),
+	}
+	unreachable := healthCheckTarget{
+		Cluster: cluster,
+		MHC:     testMHC,
+		Machine: newTestMachine("unreachable", namespace, clusterName, "unreachable", nil),
+		Node:    newTestUnhealthyNode("unreachable", corev1.NodeReady, corev1.ConditionUnknown, 10*time.Minute),
+	}
+	unreachable.Machine.Annotations = map[string]string{
+		clusterv1.MachineAPIUnreachableSinceAnnotation: time.Now().Add(-10 * time.Minute).UTC().Format(time.RFC3339),
+	}
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	fakeClock := clock.NewFakeClock(time.Now().Add(time.Hour))
+	reconciler := &MachineHealthCheckReconciler{
+		clock:    fakeClock,
+		Client:   fake.NewClientBuilder().WithObjects(stale.Machine, newlyUnreachable.Machine, unreachable.Machine).Build(),
+		recorder: record.NewFakeRecorder(5),
+		nodeProber: func(_ context.Context, _ *clusterv1.Cluster, nodeName string) (bool, error) {
+			return nodeName == "stale", nil
+		},
+	}
+	_, unhealthyTargets, nextCheckTimes := reconciler.healthCheckTargets(ctx, []healthCheckTarget{stale, newlyUnreachable, unreachable}, ctrl.LoggerFrom(ctx), timeoutForMachineToHaveNode)
+
+	// Only the node whose kubelet has been unreachable for longer than the timeout is remediated.
+	g.Expect(unhealthyTargets).To(ConsistOf(unreachable))
+	g.Expect(nextCheckTimes).To(HaveLen(2))
+
+	// The stale but reachable node is not considered unreachable anymore.
+	machine := &clusterv1.Machine{}
+	g.Expect(reconciler.Client.Get(ctx, client.ObjectKeyFromObject(stale.Machine), machine)).To(Succeed())
+	g.Expect(machine.Annotations).NotTo(HaveKey(clusterv1.MachineAPIUnreachableSinceAnnotation))
+
+	// The time the kubelet was first found unreachable is recorded.
+	g.Expect(reconciler.Client.Get(ctx, client.ObjectKeyFromObject(newlyUnreachable.Machine), machine)).To(Succeed())
+	g.Expect(machine.Annotations).To(HaveKeyWithValue(clusterv1.MachineAPIUnreachableSinceAnnotation, fakeClock.Now().UTC().Format(time.RFC3339)))
+}
+
+func TestHealthCheckTargetsNodeGonePolicy(t *testing.T) {
+	g := NewWithT(t)
+
+	namespace := "test-mhc"
+	clusterName := "test-cluster"
+	timeoutForMachineToHaveNode := 10 * time.Minute
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      clusterName,
+		},
+	}
+	conditions.MarkTrue(cluster, clusterv1.InfrastructureReadyCondition)
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+
+	testMHC := newMachineHealthCheck(namespace, clusterName)
+	testMHC.Spec.NodeGonePolicy = clusterv1.NodeGonePolicyClassify
+
+	// Target whose node was deleted while the infrastructure of its machine is ready.
+	nodeDeleted := healthCheckTarget{
+		Cluster:     cluster,
+		MHC:         testMHC,
+		Machine:     newTestMachine("node-deleted", namespace, clusterName, "node-deleted", nil),
+		nodeMissing: true,
+	}
+	conditions.MarkTrue(nodeDeleted.Machine, clusterv1.InfrastructureReadyCondition)
+
+	// Target whose node is gone along with the infrastructure of its machine, lost for less than the timeout.
+	infrastructureLost := healthCheckTarget{
+		Cluster:     cluster,
+		MHC:         testMHC,
+		Machine:     newTestMachine("infrastructure-lost", namespace, clusterName, "infrastructure-lost", nil),
+		nodeMissing: true,
+	}
+	infrastructureLost.Machine.Status.Conditions = clusterv1.Conditions{
+		{
+			Type:               clusterv1.InfrastructureReadyCondition,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-4 * time.Minute)),
+		},
+	}
+
+	// Target whose node is gone along with the infrastructure of its machine, lost for longer than the timeout.
+	infrastructureLostLong := healthCheckTarget{
+		Cluster:     cluster,
+		MHC:         testMHC,
+		Machine:     newTestMachine("infrastructure-lost-long", namespace, clusterName, "infrastructure-lost-long", nil),
+		nodeMissing: true,
+	}
+	infrastructureLostLong.Machine.Status.Conditions = clusterv1.Conditions{
+		{
+			Type:               clusterv1.InfrastructureReadyCondition,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-20 * time.Minute)),
+		},
+	}
+
+	reconciler := &MachineHealthCheckReconciler{
+		recorder: record.NewFakeRecorder(5),
+	}
+	targets := []healthCheckTarget{nodeDeleted, infrastructureLost, infrastructureLostLong}
+	_, unhealthy, nextCheckTimes := reconciler.healthCheckTargets(ctx, targets, ctrl.LoggerFrom(ctx), timeoutForMachineToHaveNode)
+
+	// The node deleted on purpose is remediated right away, the node lost with the infrastructure only after the timeout.
+	g.Expect(unhealthy).To(ConsistOf(nodeDeleted, infrastructureLostLong))
+	g.Expect(nextCheckTimes).To(HaveLen(1))
+	g.Expect(nextCheckTimes[0]).To(BeNumerically("~", 6*time.Minute, time.Second))
+
+	// With the Immediate policy, the node lost with the infrastructure is remediated right away too.
+	immediateMHC := testMHC.DeepCopy()
+	immediateMHC.Spec.NodeGonePolicy = clusterv1.NodeGonePolicyImmediate
+	infrastructureLost.MHC = immediateMHC
+	_, unhealthy, nextCheckTimes = reconciler.healthCheckTargets(ctx, []healthCheckTarget{infrastructureLost}, ctrl.LoggerFrom(ctx), timeoutForMachineToHaveNode)
+	g.Expect(unhealthy).To(ConsistOf(infrastructureLost))
+	g.Expect(nextCheckTimes).To(BeEmpty())
+}
+
 func newTestMachine(name, namespace, clusterName, nodeName string, labels map[string]string) *clusterv1.Machine {
 	// Copy the labels so that the map is unique to each test Machine
 	l := make(map[string]string)
@@ -18,6 +18,7 @@ package v1alpha3
 
 import (
 	apiconversion "k8s.io/apimachinery/pkg/conversion"
+	cabpkv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
 
 	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
@@ -38,6 +39,7 @@ func (src *KubeadmControlPlane) ConvertTo(destRaw conversion.Hub) error {
 	}
 
 	dest.Spec.RolloutStrategy = restored.Spec.RolloutStrategy
+	cabpkv1.RestoreKubeadmConfigSpec(&restored.Spec.KubeadmConfigSpec, &dest.Spec.KubeadmConfigSpec)
 
 	return nil
 }
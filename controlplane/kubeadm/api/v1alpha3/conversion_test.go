@@ -59,6 +59,7 @@ func fuzzFuncs(_ runtimeserializer.CodecFactory) []interface{} {
 	return []interface{}{
 		kubeadmBootstrapTokenStringFuzzer,
 		cabpkBootstrapTokenStringFuzzer,
+		rawExtensionFuzzer,
 	}
 }
 
@@ -70,3 +71,10 @@ func cabpkBootstrapTokenStringFuzzer(in *cabpkv1.BootstrapTokenString, c fuzz.Co
 	in.ID = "abcdef"
 	in.Secret = "abcdef0123456789"
 }
+
+// rawExtensionFuzzer sets raw extensions, such as the audit policy, to a fixed JSON document,
+// as fuzzed documents are not preserved byte for byte through the annotations used by the conversion.
+func rawExtensionFuzzer(in *runtime.RawExtension, c fuzz.Continue) {
+	in.Raw = []byte(`{"apiVersion":"audit.k8s.io/v1","kind":"Policy"}`)
+	in.Object = nil
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/spf13/pflag"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	cliflag "k8s.io/component-base/cli/flag"
@@ -61,6 +62,7 @@ var (
 	leaderElectionRetryPeriod     time.Duration
 	watchNamespace                string
 	watchFilterValue              string
+	watchFilterLabel              string
 	profilerAddress               string
 	clusterConcurrency            int
 	machineConcurrency            int
@@ -69,6 +71,7 @@ var (
 	machinePoolConcurrency        int
 	clusterResourceSetConcurrency int
 	machineHealthCheckConcurrency int
+	remediationWebhookURL         string
 	syncPeriod                    time.Duration
 	webhookPort                   int
 	webhookCertDir                string
@@ -109,6 +112,9 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&watchFilterValue, "watch-filter", "",
 		fmt.Sprintf("Label value that the controller watches to reconcile cluster-api objects. Label key is always %s. If unspecified, the controller watches for all cluster-api objects.", clusterv1.WatchLabel))
 
+	fs.StringVar(&watchFilterLabel, "watch-filter-label", "",
+		"Label selector of the clusters whose machine health checks the controller reconciles (e.g. env=prod). If unspecified, the machine health checks of all clusters are reconciled.")
+
 	fs.StringVar(&profilerAddress, "profiler-address", "",
 		"Bind address to expose the pprof profiler (e.g. localhost:6060)")
 
@@ -133,6 +139,9 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&machineHealthCheckConcurrency, "machinehealthcheck-concurrency", 10,
 		"Number of machine health checks to process simultaneously")
 
+	fs.StringVar(&remediationWebhookURL, "remediation-webhook-url", "",
+		"URL to POST a JSON notification to every time a machine health check marks a machine for remediation")
+
 	fs.DurationVar(&syncPeriod, "sync-period", 10*time.Minute,
 		"The minimum interval at which watched resources are reconciled (e.g. 15m)")
 
@@ -294,10 +303,17 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 		}
 	}
 
+	clusterSelector, err := labels.Parse(watchFilterLabel)
+	if err != nil {
+		setupLog.Error(err, "unable to parse the watch filter label selector")
+		os.Exit(1)
+	}
 	if err := (&controllers.MachineHealthCheckReconciler{
-		Client:           mgr.GetClient(),
-		Tracker:          tracker,
-		WatchFilterValue: watchFilterValue,
+		Client:                mgr.GetClient(),
+		Tracker:               tracker,
+		WatchFilterValue:      watchFilterValue,
+		ClusterSelector:       clusterSelector,
+		RemediationWebhookURL: remediationWebhookURL,
 	}).SetupWithManager(ctx, mgr, concurrency(machineHealthCheckConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MachineHealthCheck")
 		os.Exit(1)
@@ -0,0 +1,144 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicates
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// MachineHealthChanged returns a predicate that returns true for update events of Machines which changed in a way
+// relevant to their health check: their labels, which select their MachineHealthChecks, their annotations pausing,
+// skipping or holding their remediation, their deletion timestamp, their NodeRef, FailureReason, FailureMessage or
+// phase, or the status or last transition time of their InfrastructureReady condition. Updates of other fields, e.g.
+// of the conditions set by the MachineHealthCheck itself, are filtered out.
+// Create, delete and generic events are processed.
+// Example use:
+//  Watches(
+//      &source.Kind{Type: &clusterv1.Machine{}},
+//      handler.EnqueueRequestsFromMapFunc(r.machineToMachineHealthCheck),
+//      builder.WithPredicates(predicates.MachineHealthChanged(r.Log)),
+//  )
+func MachineHealthChanged(logger logr.Logger) predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			log := logger.WithValues("predicate", "MachineHealthChanged", "eventType", "update")
+
+			oldMachine, ok := e.ObjectOld.(*clusterv1.Machine)
+			if !ok {
+				log.V(4).Info("Expected Machine", "type", e.ObjectOld.GetObjectKind().GroupVersionKind().String())
+				return false
+			}
+			log = log.WithValues("namespace", oldMachine.Namespace, "machine", oldMachine.Name)
+
+			newMachine := e.ObjectNew.(*clusterv1.Machine)
+
+			if !reflect.DeepEqual(oldMachine.Labels, newMachine.Labels) ||
+				!reflect.DeepEqual(remediationAnnotations(oldMachine.Annotations), remediationAnnotations(newMachine.Annotations)) ||
+				!oldMachine.DeletionTimestamp.Equal(newMachine.DeletionTimestamp) ||
+				!reflect.DeepEqual(oldMachine.Status.NodeRef, newMachine.Status.NodeRef) ||
+				!reflect.DeepEqual(oldMachine.Status.FailureReason, newMachine.Status.FailureReason) ||
+				!reflect.DeepEqual(oldMachine.Status.FailureMessage, newMachine.Status.FailureMessage) ||
+				oldMachine.Status.Phase != newMachine.Status.Phase ||
+				!reflect.DeepEqual(infrastructureReadyTransition(oldMachine), infrastructureReadyTransition(newMachine)) {
+				log.V(4).Info("Machine health relevant fields changed, allowing further processing")
+				return true
+			}
+
+			log.V(4).Info("Machine health relevant fields did not change, blocking further processing")
+			return false
+		},
+		CreateFunc:  func(e event.CreateEvent) bool { return true },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return true },
+		GenericFunc: func(e event.GenericEvent) bool { return true },
+	}
+}
+
+// NodeHealthChanged returns a predicate that returns true for update events of Nodes whose conditions changed,
+// i.e. a condition was added, removed, or changed its status or last transition time. Updates of other fields,
+// e.g. labels or the heartbeats of the conditions, are filtered out. Create, delete and generic events are processed.
+func NodeHealthChanged(logger logr.Logger) predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			log := logger.WithValues("predicate", "NodeHealthChanged", "eventType", "update")
+
+			oldNode, ok := e.ObjectOld.(*corev1.Node)
+			if !ok {
+				log.V(4).Info("Expected Node", "type", e.ObjectOld.GetObjectKind().GroupVersionKind().String())
+				return false
+			}
+			log = log.WithValues("node", oldNode.Name)
+
+			newNode := e.ObjectNew.(*corev1.Node)
+
+			if !reflect.DeepEqual(nodeConditionTransitions(oldNode), nodeConditionTransitions(newNode)) {
+				log.V(4).Info("Node conditions changed, allowing further processing")
+				return true
+			}
+
+			log.V(4).Info("Node conditions did not change, blocking further processing")
+			return false
+		},
+		CreateFunc:  func(e event.CreateEvent) bool { return true },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return true },
+		GenericFunc: func(e event.GenericEvent) bool { return true },
+	}
+}
+
+// remediationAnnotations returns the annotations pausing, skipping or holding the remediation of a Machine.
+func remediationAnnotations(annotations map[string]string) map[string]string {
+	relevant := map[string]string{}
+	for key, value := range annotations {
+		if key == clusterv1.PausedAnnotation || key == clusterv1.MachineSkipRemediationAnnotation ||
+			strings.HasPrefix(key, clusterv1.PreTerminateDeleteHookAnnotationPrefix) {
+			relevant[key] = value
+		}
+	}
+	return relevant
+}
+
+// infrastructureReadyTransition returns the status and last transition time of the InfrastructureReady condition
+// of a Machine, nil if the Machine has no such condition.
+func infrastructureReadyTransition(machine *clusterv1.Machine) *clusterv1.Condition {
+	c := conditions.Get(machine, clusterv1.InfrastructureReadyCondition)
+	if c == nil {
+		return nil
+	}
+	return &clusterv1.Condition{
+		Status:             c.Status,
+		LastTransitionTime: c.LastTransitionTime,
+	}
+}
+
+// nodeConditionTransitions returns the status and last transition time of the conditions of a Node, by type.
+func nodeConditionTransitions(node *corev1.Node) map[corev1.NodeConditionType]corev1.NodeCondition {
+	transitions := map[corev1.NodeConditionType]corev1.NodeCondition{}
+	for _, c := range node.Status.Conditions {
+		transitions[c.Type] = corev1.NodeCondition{
+			Status:             c.Status,
+			LastTransitionTime: c.LastTransitionTime,
+		}
+	}
+	return transitions
+}
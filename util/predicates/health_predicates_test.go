@@ -0,0 +1,181 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicates
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestNodeHealthChanged(t *testing.T) {
+	transitionTime := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node1",
+			Labels: map[string]string{"zone": "a"},
+		},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{
+					Type:               corev1.NodeReady,
+					Status:             corev1.ConditionTrue,
+					LastHeartbeatTime:  transitionTime,
+					LastTransitionTime: transitionTime,
+				},
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		update func(node *corev1.Node)
+		expect bool
+	}{
+		"label only update": {
+			update: func(node *corev1.Node) {
+				node.Labels["zone"] = "b"
+			},
+			expect: false,
+		},
+		"condition heartbeat": {
+			update: func(node *corev1.Node) {
+				node.Status.Conditions[0].LastHeartbeatTime = metav1.Now()
+			},
+			expect: false,
+		},
+		"condition status change": {
+			update: func(node *corev1.Node) {
+				node.Status.Conditions[0].Status = corev1.ConditionUnknown
+				node.Status.Conditions[0].LastTransitionTime = metav1.Now()
+			},
+			expect: true,
+		},
+		"condition added": {
+			update: func(node *corev1.Node) {
+				node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
+					Type:   corev1.NodeDiskPressure,
+					Status: corev1.ConditionTrue,
+				})
+			},
+			expect: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			newNode := node.DeepCopy()
+			tc.update(newNode)
+			g.Expect(NodeHealthChanged(log.Log).Update(event.UpdateEvent{ObjectOld: node, ObjectNew: newNode})).To(Equal(tc.expect))
+		})
+	}
+}
+
+func TestMachineHealthChanged(t *testing.T) {
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "machine1",
+			Namespace:   "default",
+			Labels:      map[string]string{"nodepool": "a"},
+			Annotations: map[string]string{},
+		},
+		Status: clusterv1.MachineStatus{
+			Phase: string(clusterv1.MachinePhaseRunning),
+			Conditions: clusterv1.Conditions{
+				{
+					Type:               clusterv1.InfrastructureReadyCondition,
+					Status:             corev1.ConditionFalse,
+					Severity:           clusterv1.ConditionSeverityInfo,
+					Reason:             clusterv1.WaitingForInfrastructureFallbackReason,
+					LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Minute)),
+				},
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		update func(machine *clusterv1.Machine)
+		expect bool
+	}{
+		"conditions only update": {
+			update: func(machine *clusterv1.Machine) {
+				conditions.MarkTrue(machine, clusterv1.MachineHealthCheckSuccededCondition)
+			},
+			expect: false,
+		},
+		"unrelated annotation update": {
+			update: func(machine *clusterv1.Machine) {
+				machine.Annotations["example.com/owner"] = "team-a"
+			},
+			expect: false,
+		},
+		"skip remediation annotation added": {
+			update: func(machine *clusterv1.Machine) {
+				machine.Annotations[clusterv1.MachineSkipRemediationAnnotation] = ""
+			},
+			expect: true,
+		},
+		"label update": {
+			update: func(machine *clusterv1.Machine) {
+				machine.Labels["nodepool"] = "b"
+			},
+			expect: true,
+		},
+		"node ref set": {
+			update: func(machine *clusterv1.Machine) {
+				machine.Status.NodeRef = &corev1.ObjectReference{Kind: "Node", Name: "node1"}
+			},
+			expect: true,
+		},
+		"infrastructure ready": {
+			update: func(machine *clusterv1.Machine) {
+				conditions.MarkTrue(machine, clusterv1.InfrastructureReadyCondition)
+			},
+			expect: true,
+		},
+		"infrastructure ready condition message update": {
+			update: func(machine *clusterv1.Machine) {
+				conditions.Get(machine, clusterv1.InfrastructureReadyCondition).Message = "still provisioning"
+			},
+			expect: false,
+		},
+		"phase change": {
+			update: func(machine *clusterv1.Machine) {
+				machine.Status.Phase = string(clusterv1.MachinePhaseFailed)
+			},
+			expect: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			newMachine := machine.DeepCopy()
+			tc.update(newMachine)
+			g.Expect(MachineHealthChanged(log.Log).Update(event.UpdateEvent{ObjectOld: machine, ObjectNew: newMachine})).To(Equal(tc.expect))
+		})
+	}
+}